@@ -0,0 +1,54 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuthConfig — правила входа по одноразовому коду
+type AuthConfig struct {
+	// OTPMaxAttempts — сколько попыток ввода кода даётся на номер, после чего проверка блокируется на OTPLockout
+	OTPMaxAttempts int
+	OTPLockout     time.Duration
+
+	// TestPhones — номера для стендов и ревью в сторах: SMS на них не отправляется, код всегда TestOTP (4 цифры).
+	// Работает, только если заданы оба параметра.
+	TestPhones []string
+	TestOTP    string
+
+	// MagicLinkURL — страница приложения, куда ведёт ссылка для входа по почте; к ней добавляются email и token.
+	// Ссылки подписываются MagicLinkSecret; без обоих параметров вход по ссылке отключён.
+	MagicLinkURL    string
+	MagicLinkSecret string
+	MagicLinkTTL    time.Duration
+}
+
+func NewAuthConfig() *AuthConfig {
+	var testPhones []string
+	for _, phone := range strings.Split(getEnvDefault("AUTH_TEST_PHONES", ""), ",") {
+		if phone = strings.TrimSpace(phone); phone != "" {
+			testPhones = append(testPhones, phone)
+		}
+	}
+
+	return &AuthConfig{
+		OTPMaxAttempts: parseInt("AUTH_OTP_MAX_ATTEMPTS", "5"),
+		OTPLockout:     parseDuration("AUTH_OTP_LOCKOUT", "15m"),
+		TestPhones:     testPhones,
+		TestOTP:        getEnvDefault("AUTH_TEST_OTP", ""),
+
+		MagicLinkURL:    getEnvDefault("AUTH_MAGIC_LINK_URL", ""),
+		MagicLinkSecret: getEnvDefault("AUTH_MAGIC_LINK_SECRET", ""),
+		MagicLinkTTL:    parseDuration("AUTH_MAGIC_LINK_TTL", "15m"),
+	}
+}
+
+func parseInt(key, fallback string) int {
+	n, err := strconv.Atoi(getEnvDefault(key, fallback))
+	if err != nil {
+		log.Fatalf("Ошибка преобразования %s в число: %v", key, err)
+	}
+	return n
+}
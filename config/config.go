@@ -20,6 +20,13 @@ type Config struct {
 	Telegram   *TelegramConfig
 	S3         *S3Config
 	Kafka      *KafkaConfig
+	Delivery   *DeliveryConfig
+	Payments   *PaymentsConfig
+	Orders     *OrdersConfig
+	Auth       *AuthConfig
+	SMS        *SMSConfig
+	RateLimit  *RateLimitConfig
+	Shutdown   *ShutdownConfig
 }
 
 type SMTPEmailConfig struct {
@@ -32,8 +39,19 @@ type SMTPEmailConfig struct {
 }
 
 type JWTConfig struct {
+	// Secret — ключ HS256. Если заданы Keys, по нему только проверяются ранее выданные токены до HS256Until
 	Secret []byte
-	Exp    time.Duration
+	// Exp — время жизни access-токена
+	Exp time.Duration
+	// HS256Until — до этого момента при заданных Keys ещё принимаются токены HS256,
+	// выданные до перехода на ключи. Нулевое значение — HS256 при Keys не принимается.
+	HS256Until time.Time
+	// RefreshExp — время жизни refresh-токена
+	RefreshExp time.Duration
+	// Keys — ключи RS256/EdDSA; публикуются в /.well-known/jwks.json.
+	// Новые токены подписываются ключом SigningKeyID, остальные только проверяют подпись.
+	Keys         []JWTKey
+	SigningKeyID string
 }
 
 type DBConfig struct {
@@ -50,11 +68,6 @@ type HostConfig struct {
 	Port string
 }
 
-type TelegramConfig struct {
-	Token     string
-	IsEnabled bool
-}
-
 type KafkaConfig struct {
 	URL string
 }
@@ -81,12 +94,9 @@ func GetConfig() *Config {
 	}
 
 	jwtSecret := os.Getenv("JWT_SECRET")
-	jwtExpStr := os.Getenv("JWT_EXP") // Время жизни токена в минутах
-	jwtExp, err := strconv.Atoi(jwtExpStr)
-	if err != nil {
-		log.Fatalf("Ошибка преобразования JWT_EXP в число: %v", err)
+	if os.Getenv("JWT_EXP") != "" {
+		log.Printf("JWT_EXP больше не используется, время жизни access-токена задаётся JWT_ACCESS_TTL")
 	}
-	jwtExpDuration := time.Duration(jwtExp * int(time.Minute))
 
 	redisConfig := NewRedisConfig()
 
@@ -106,12 +116,6 @@ func GetConfig() *Config {
 	// Преобразуем в time.Duration
 	retryDelayDuration := time.Duration(smtpRetryDelay) * time.Second
 
-	tgEnabled := os.Getenv("TG_ENABLED") == "true"
-	tgToken := os.Getenv("TG_TOKEN")
-	if tgToken == "" && tgEnabled {
-		log.Fatalf("Не задан токен Telegram бота")
-	}
-
 	s3Config, err := GetS3Config()
 	if err != nil {
 		log.Fatal(err)
@@ -134,8 +138,12 @@ func GetConfig() *Config {
 		},
 		Redis: redisConfig,
 		JWT: &JWTConfig{
-			Secret: []byte(jwtSecret),
-			Exp:    jwtExpDuration,
+			Secret:       []byte(jwtSecret),
+			Exp:          parseDuration("JWT_ACCESS_TTL", "15m"),
+			HS256Until:   loadHS256Until(),
+			RefreshExp:   parseDuration("JWT_REFRESH_EXP", "720h"),
+			Keys:         loadJWTKeys(),
+			SigningKeyID: getEnvDefault("JWT_SIGNING_KEY_ID", ""),
 		},
 		SMTP: &SMTPEmailConfig{
 			Host:       os.Getenv("SMTP_HOST"),
@@ -145,12 +153,16 @@ func GetConfig() *Config {
 			MaxRetries: smtpMaxRetries,
 			RetryDelay: retryDelayDuration,
 		},
-		Telegram: &TelegramConfig{
-			Token:     tgToken,
-			IsEnabled: tgEnabled,
-		},
-		S3:    s3Config,
-		Kafka: kafka,
+		Telegram:  NewTelegramConfig(),
+		S3:        s3Config,
+		Kafka:     kafka,
+		Delivery:  NewDeliveryConfig(),
+		Payments:  NewPaymentsConfig(),
+		Orders:    NewOrdersConfig(),
+		Auth:      NewAuthConfig(),
+		SMS:       NewSMSConfig(),
+		RateLimit: NewRateLimitConfig(),
+		Shutdown:  NewShutdownConfig(),
 	}
 }
 
@@ -159,3 +171,9 @@ func (db *DBConfig) GetDSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		db.Host, db.Port, db.User, db.Password, db.Name, db.SSLMode)
 }
+
+// devMode — локальный стенд разработчика (APP_DEV_MODE=true). Только в нём разрешены
+// фейковые провайдеры, которые одобряют любую оплату и пишут коды входа в лог
+func devMode() bool {
+	return getEnvDefault("APP_DEV_MODE", "false") == "true"
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"domashka-backend/internal/entity/money"
+)
+
+// DeliveryConfig — общий тариф доставки. Суммы задаются в рублях
+type DeliveryConfig struct {
+	BaseFee       money.Money
+	PerKmFee      money.Money
+	FreeThreshold money.Money
+	MaxDistanceKm float64
+	// Location — часовой пояс рабочих часов поваров и слотов доставки
+	Location *time.Location
+	// SlotsInterval — как часто заранее создаются слоты доставки на ближайшие дни
+	SlotsInterval time.Duration
+}
+
+func NewDeliveryConfig() *DeliveryConfig {
+	maxDistanceKm, err := strconv.ParseFloat(getEnvDefault("DELIVERY_MAX_DISTANCE_KM", "0"), 64)
+	if err != nil {
+		log.Fatalf("Ошибка преобразования DELIVERY_MAX_DISTANCE_KM в число: %v", err)
+	}
+	location, err := time.LoadLocation(getEnvDefault("DELIVERY_TIMEZONE", "Europe/Moscow"))
+	if err != nil {
+		log.Fatalf("Ошибка загрузки часового пояса DELIVERY_TIMEZONE: %v", err)
+	}
+
+	return &DeliveryConfig{
+		BaseFee:       parseRubles("DELIVERY_BASE_FEE", "169"),
+		PerKmFee:      parseRubles("DELIVERY_PER_KM_FEE", "0"),
+		FreeThreshold: parseRubles("DELIVERY_FREE_THRESHOLD", "0"),
+		MaxDistanceKm: maxDistanceKm,
+		Location:      location,
+		SlotsInterval: parseDuration("DELIVERY_SLOTS_INTERVAL", "1h"),
+	}
+}
+
+func parseRubles(key, fallback string) money.Money {
+	m, err := money.Parse(getEnvDefault(key, fallback), money.RUB)
+	if err != nil {
+		log.Fatalf("Ошибка преобразования %s в сумму: %v", key, err)
+	}
+	return m
+}
+
+func getEnvDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
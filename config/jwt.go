@@ -0,0 +1,51 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTKey — ключ подписи токенов в PEM. Закрытый ключ (PKCS#1/PKCS#8) подписывает и проверяет,
+// открытый (PKIX) только проверяет — так оставляют выведенный из ротации ключ до истечения его токенов.
+type JWTKey struct {
+	ID  string
+	PEM []byte
+}
+
+// loadJWTKeys читает ключи из JWT_KEYS в формате "kid1=/path/key1.pem,kid2=/path/key2.pem"
+func loadJWTKeys() []JWTKey {
+	raw := getEnvDefault("JWT_KEYS", "")
+	if raw == "" {
+		return nil
+	}
+
+	var keys []JWTKey
+	for _, entry := range strings.Split(raw, ",") {
+		id, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || id == "" || path == "" {
+			log.Fatalf("Ошибка разбора JWT_KEYS: ожидается kid=путь, получено %q", entry)
+		}
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Ошибка чтения ключа JWT %s: %v", id, err)
+		}
+		keys = append(keys, JWTKey{ID: id, PEM: pem})
+	}
+	return keys
+}
+
+// loadHS256Until читает из JWT_HS256_UNTIL (RFC3339) срок, до которого принимаются токены HS256.
+// Ставится на момент перехода на ключи плюс время жизни access-токена.
+func loadHS256Until() time.Time {
+	raw := getEnvDefault("JWT_HS256_UNTIL", "")
+	if raw == "" {
+		return time.Time{}
+	}
+	until, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		log.Fatalf("Ошибка преобразования JWT_HS256_UNTIL в дату RFC3339: %v", err)
+	}
+	return until
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"log"
+	"time"
+)
+
+// OrdersConfig — правила работы с заказами
+type OrdersConfig struct {
+	// CancelGracePeriod — сколько клиент может отменить заказ после того, как повар его принял
+	CancelGracePeriod time.Duration
+
+	// TimeoutsEnabled включает воркер, который отклоняет неоплаченные и непринятые и закрывает зависшие в доставке заказы
+	TimeoutsEnabled  bool
+	PaymentTimeout   time.Duration
+	AcceptTimeout    time.Duration
+	DeliveryTimeout  time.Duration
+	TimeoutsInterval time.Duration
+}
+
+func NewOrdersConfig() *OrdersConfig {
+	return &OrdersConfig{
+		CancelGracePeriod: parseDuration("ORDER_CANCEL_GRACE_PERIOD", "5m"),
+		TimeoutsEnabled:   getEnvDefault("ORDER_TIMEOUTS_ENABLED", "true") == "true",
+		PaymentTimeout:    parseDuration("ORDER_PAYMENT_TIMEOUT", "30m"),
+		AcceptTimeout:     parseDuration("ORDER_ACCEPT_TIMEOUT", "15m"),
+		DeliveryTimeout:   parseDuration("ORDER_DELIVERY_TIMEOUT", "3h"),
+		TimeoutsInterval:  parseDuration("ORDER_TIMEOUTS_INTERVAL", "1m"),
+	}
+}
+
+func parseDuration(key, fallback string) time.Duration {
+	d, err := time.ParseDuration(getEnvDefault(key, fallback))
+	if err != nil {
+		log.Fatalf("Ошибка преобразования %s в длительность: %v", key, err)
+	}
+	return d
+}
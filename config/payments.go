@@ -0,0 +1,42 @@
+package config
+
+import "log"
+
+// Платёжные провайдеры
+const (
+	// PaymentsProviderNone — приём оплаты выключен: заказ подтверждается сразу, оплата при получении
+	PaymentsProviderNone = "none"
+	// PaymentsProviderFake — одобряет любую оплату; только при APP_DEV_MODE=true
+	PaymentsProviderFake = "fake"
+)
+
+// PaymentsConfig — платёжный провайдер
+type PaymentsConfig struct {
+	Provider      string
+	WebhookSecret string
+}
+
+func NewPaymentsConfig() *PaymentsConfig {
+	cfg := &PaymentsConfig{
+		Provider:      getEnvDefault("PAYMENTS_PROVIDER", ""),
+		WebhookSecret: getEnvDefault("PAYMENTS_WEBHOOK_SECRET", ""),
+	}
+	switch cfg.Provider {
+	case "":
+		log.Fatalf("Не задан PAYMENTS_PROVIDER: укажите %s или %s", PaymentsProviderNone, PaymentsProviderFake)
+	case PaymentsProviderNone:
+		// Вебхуков нет, секрет не нужен
+		return cfg
+	case PaymentsProviderFake:
+		if !devMode() {
+			log.Fatalf("Платёжный провайдер fake разрешён только при APP_DEV_MODE=true")
+		}
+	default:
+		log.Fatalf("Неизвестный платёжный провайдер: %s", cfg.Provider)
+	}
+	// Без секрета подпись вебхука не проверить, и статус заказа может поменять кто угодно
+	if cfg.WebhookSecret == "" {
+		log.Fatalf("Не задан PAYMENTS_WEBHOOK_SECRET")
+	}
+	return cfg
+}
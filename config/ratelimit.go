@@ -0,0 +1,84 @@
+package config
+
+import (
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit — не больше Limit запросов за скользящее окно Window. Limit 0 отключает ограничение
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitConfig — ограничения частоты запросов. Значения задаются в виде "20/1m"; "0" отключает правило
+type RateLimitConfig struct {
+	// Public — все запросы к /v1 с одного IP
+	Public RateLimit
+	// AuthIP и AuthPhone — вход, подтверждение кода и вход через Telegram: с одного IP и на один номер или адрес почты
+	AuthIP    RateLimit
+	AuthPhone RateLimit
+	// User — запросы с токеном одного пользователя
+	User RateLimit
+	// TrustedProxies — адреса и подсети прокси, которым доверяем X-Forwarded-For. Пусто — IP берётся из RemoteAddr
+	TrustedProxies []string
+	// TrustedPlatform — заголовок с IP клиента от платформы (например, CF-Connecting-IP). Пусто — не используется
+	TrustedPlatform string
+}
+
+func NewRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		Public:    parseRateLimit("RATE_LIMIT_PUBLIC", "300/1m"),
+		AuthIP:    parseRateLimit("RATE_LIMIT_AUTH_IP", "20/1m"),
+		AuthPhone: parseRateLimit("RATE_LIMIT_AUTH_PHONE", "10/10m"),
+		User:      parseRateLimit("RATE_LIMIT_USER", "600/1m"),
+
+		TrustedProxies:  parseTrustedProxies("TRUSTED_PROXIES"),
+		TrustedPlatform: getEnvDefault("TRUSTED_PLATFORM", ""),
+	}
+}
+
+// parseTrustedProxies разбирает список IP и CIDR через запятую
+func parseTrustedProxies(key string) []string {
+	value := getEnvDefault(key, "")
+	if value == "" {
+		return nil
+	}
+
+	var proxies []string
+	for _, proxy := range strings.Split(value, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			log.Fatalf("Ошибка разбора %s: %q не является IP или CIDR", key, proxy)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
+
+func parseRateLimit(key, fallback string) RateLimit {
+	value := getEnvDefault(key, fallback)
+	if value == "0" {
+		return RateLimit{}
+	}
+
+	limitStr, windowStr, ok := strings.Cut(value, "/")
+	if !ok {
+		log.Fatalf("Ошибка разбора %s: ожидается формат <число>/<длительность>, получено %q", key, value)
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 0 {
+		log.Fatalf("Ошибка преобразования лимита %s в число: %q", key, limitStr)
+	}
+	window, err := time.ParseDuration(windowStr)
+	if err != nil || window <= 0 {
+		log.Fatalf("Ошибка преобразования окна %s в длительность: %q", key, windowStr)
+	}
+	return RateLimit{Limit: limit, Window: window}
+}
@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+// ShutdownConfig — остановка приложения по SIGTERM
+type ShutdownConfig struct {
+	// DrainDelay — сколько /ready отвечает 503 до остановки HTTP-сервера, чтобы балансировщик
+	// успел убрать реплику и не присылал новые запросы
+	DrainDelay time.Duration
+	// Timeout — общий срок на остановку всех компонентов после DrainDelay
+	Timeout time.Duration
+}
+
+func NewShutdownConfig() *ShutdownConfig {
+	return &ShutdownConfig{
+		DrainDelay: parseDuration("SHUTDOWN_DRAIN_DELAY", "5s"),
+		Timeout:    parseDuration("SHUTDOWN_TIMEOUT", "20s"),
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"log"
+	"time"
+)
+
+// SMSConfig — шлюз для отправки SMS
+type SMSConfig struct {
+	// Provider — http или fake; fake пишет сообщения в лог и в FakeFile, если он задан,
+	// поэтому разрешён только при APP_DEV_MODE=true
+	Provider string
+	APIURL   string
+	APIKey   string
+	// Sender — имя отправителя, согласованное со шлюзом
+	Sender  string
+	Timeout time.Duration
+
+	// MaxRetries — сколько всего попыток отправки; пауза между ними удваивается, начиная с RetryDelay
+	MaxRetries int
+	RetryDelay time.Duration
+
+	FakeFile string
+}
+
+func NewSMSConfig() *SMSConfig {
+	cfg := &SMSConfig{
+		Provider:   getEnvDefault("SMS_PROVIDER", ""),
+		APIURL:     getEnvDefault("SMS_API_URL", ""),
+		APIKey:     getEnvDefault("SMS_API_KEY", ""),
+		Sender:     getEnvDefault("SMS_SENDER", "Domashka"),
+		Timeout:    parseDuration("SMS_TIMEOUT", "5s"),
+		MaxRetries: parseInt("SMS_MAX_RETRIES", "3"),
+		RetryDelay: parseDuration("SMS_RETRY_DELAY", "500ms"),
+		FakeFile:   getEnvDefault("SMS_FAKE_FILE", ""),
+	}
+	if cfg.Provider == "" {
+		log.Fatalf("Не задан SMS_PROVIDER")
+	}
+	// В логе fake-шлюза коды входа видны открытым текстом
+	if cfg.Provider == "fake" && !devMode() {
+		log.Fatalf("SMS-шлюз fake разрешён только при APP_DEV_MODE=true")
+	}
+	return cfg
+}
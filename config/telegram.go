@@ -0,0 +1,63 @@
+package config
+
+import (
+	"log"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Режимы получения обновлений ботом
+const (
+	// TelegramModePolling — long polling; работает только при одной реплике приложения
+	TelegramModePolling = "polling"
+	// TelegramModeWebhook — Telegram присылает обновления на HTTP-сервер приложения
+	TelegramModeWebhook = "webhook"
+)
+
+type TelegramConfig struct {
+	Token     string
+	IsEnabled bool
+	// BotUsername — имя бота без @ для ссылок входа t.me/<бот>?start=<nonce>
+	BotUsername string
+
+	Mode string
+	// WebhookURL — публичный адрес, который регистрируется в Telegram; WebhookPath — его путь на нашем сервере
+	WebhookURL  string
+	WebhookPath string
+	// WebhookSecret Telegram передаёт в заголовке X-Telegram-Bot-Api-Secret-Token
+	WebhookSecret string
+}
+
+func NewTelegramConfig() *TelegramConfig {
+	cfg := &TelegramConfig{
+		Token:         os.Getenv("TG_TOKEN"),
+		IsEnabled:     os.Getenv("TG_ENABLED") == "true",
+		BotUsername:   strings.TrimPrefix(os.Getenv("TG_BOT_USERNAME"), "@"),
+		Mode:          getEnvDefault("TG_MODE", TelegramModePolling),
+		WebhookURL:    getEnvDefault("TG_WEBHOOK_URL", ""),
+		WebhookSecret: getEnvDefault("TG_WEBHOOK_SECRET", ""),
+	}
+	if !cfg.IsEnabled {
+		return cfg
+	}
+	if cfg.Token == "" {
+		log.Fatalf("Не задан токен Telegram бота")
+	}
+
+	switch cfg.Mode {
+	case TelegramModePolling:
+	case TelegramModeWebhook:
+		u, err := url.Parse(cfg.WebhookURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" || u.Path == "" {
+			log.Fatalf("TG_WEBHOOK_URL должен быть https-адресом с путём, получено %q", cfg.WebhookURL)
+		}
+		cfg.WebhookPath = u.Path
+		if cfg.WebhookSecret == "" {
+			log.Fatalf("Для режима webhook нужен TG_WEBHOOK_SECRET")
+		}
+	default:
+		log.Fatalf("Неизвестный режим Telegram бота TG_MODE: %s", cfg.Mode)
+	}
+	return cfg
+}
@@ -2,13 +2,20 @@
 package app
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/segmentio/kafka-go"
 	"log"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
 	tele "gopkg.in/telebot.v4"
 
 	"domashka-backend/config"
@@ -19,32 +26,42 @@ import (
 	"domashka-backend/pkg/redis"
 	"domashka-backend/pkg/sms"
 
+	paymentsclient "domashka-backend/internal/clients/payments"
 	"domashka-backend/internal/clients/s3"
 	v1 "domashka-backend/internal/controller/http/v1"
 	"domashka-backend/internal/controller/telegram"
+	"domashka-backend/internal/entity/delivery"
+	authrepo "domashka-backend/internal/repositories/auth"
 	cartrepo "domashka-backend/internal/repositories/cart"
 	chefsrepo "domashka-backend/internal/repositories/chefs"
+	deliveryrepo "domashka-backend/internal/repositories/delivery"
 	dishesrepo "domashka-backend/internal/repositories/dishes"
 	favoritesrepo "domashka-backend/internal/repositories/favorites"
 	geopgrepo "domashka-backend/internal/repositories/geo"
 	notifpgrepo "domashka-backend/internal/repositories/notifications"
 	ordersrepo "domashka-backend/internal/repositories/orders"
+	paymentsrepo "domashka-backend/internal/repositories/payments"
 	reviewsrepo "domashka-backend/internal/repositories/reviews"
 	shiftsrepo "domashka-backend/internal/repositories/shifts"
 	userspgrepo "domashka-backend/internal/repositories/users"
 	authusecase "domashka-backend/internal/usecase/auth"
 	cartusecase "domashka-backend/internal/usecase/cart"
 	chefsusecase "domashka-backend/internal/usecase/chefs"
+	deliveryusecase "domashka-backend/internal/usecase/delivery"
 	dishesusecase "domashka-backend/internal/usecase/dishes"
 	favoritesusecase "domashka-backend/internal/usecase/favorites"
 	geousecase "domashka-backend/internal/usecase/geo"
 	jwtusecase "domashka-backend/internal/usecase/jwt"
 	notifusecase "domashka-backend/internal/usecase/notifications"
 	ordersusecase "domashka-backend/internal/usecase/order"
+	paymentsusecase "domashka-backend/internal/usecase/payments"
 	reviewsusecase "domashka-backend/internal/usecase/reviews"
 	shiftsusecase "domashka-backend/internal/usecase/shifts"
 	"domashka-backend/internal/usecase/tg"
+	"domashka-backend/internal/usecase/tgbot"
+	"domashka-backend/internal/usecase/timeouts"
 	usersusecase "domashka-backend/internal/usecase/users"
+	"domashka-backend/internal/utils/telemetry"
 )
 
 type Application struct {
@@ -55,23 +72,46 @@ type Application struct {
 
 func Run(cfg *config.Config) {
 	l := logger.New()
+	// Компоненты регистрируют остановку сразу после создания и останавливаются в обратном порядке:
+	// HTTP, бот, воркеры, Kafka, Redis, Postgres и последней — телеметрия
+	lc := &lifecycle{}
+	// Сигнал, пришедший во время запуска, обработается, когда сервер начнёт работу
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	tp := telemetry.TracerProvider()
+	otel.SetTracerProvider(tp)
+	mp := telemetry.MeterProvider()
+	lc.onStop("otel", func(ctx context.Context) error {
+		return errors.Join(mp.Shutdown(ctx), tp.Shutdown(ctx))
+	})
+
 	pg, err := postgres.New(cfg.DB.GetDSN(), postgres.MaxPoolSize(cfg.DB.PoolCapacity))
 	if err != nil {
 		log.Fatalf("Ошибка инициализации БД: %v", err)
 	}
-	defer pg.Close()
+	lc.onStop("postgres", func(context.Context) error {
+		pg.Close()
+		return nil
+	})
 
 	redisClient, err := redis.New(cfg.Redis)
 	if err != nil || redisClient.Ping() != nil {
 		log.Fatalf("Ошибка инициализации Redis: %v", err)
 	}
+	lc.onStop("redis", func(context.Context) error {
+		return redisClient.Close()
+	})
 
 	s3client, err := s3.New(cfg.S3)
 	if err != nil {
 		log.Fatal(err)
 	}
 	smtpClient := smtpmail.New(cfg.SMTP)
-	smsClient := sms.New()
+	smsClient, err := sms.New(cfg.SMS)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации SMS-шлюза: %v", err)
+	}
 
 	dishReviewsWriter := &kafka.Writer{
 		Addr:         kafka.TCP(cfg.Kafka.URL),
@@ -80,12 +120,6 @@ func Run(cfg *config.Config) {
 		BatchSize:    1,
 		BatchTimeout: 10 * time.Millisecond,
 	}
-	defer func() {
-		err := dishReviewsWriter.Close()
-		if err != nil {
-			log.Println(err)
-		}
-	}()
 	chefReviewsWriter := &kafka.Writer{
 		Addr:         kafka.TCP(cfg.Kafka.URL),
 		Topic:        "chef_reviews",
@@ -93,12 +127,10 @@ func Run(cfg *config.Config) {
 		BatchSize:    1,
 		BatchTimeout: 10 * time.Millisecond,
 	}
-	defer func() {
-		err := chefReviewsWriter.Close()
-		if err != nil {
-			log.Println(err)
-		}
-	}()
+	// Писатели отправляют накопленные сообщения при закрытии, поэтому закрываются после HTTP
+	lc.onStop("kafka", func(context.Context) error {
+		return errors.Join(dishReviewsWriter.Close(), chefReviewsWriter.Close())
+	})
 	// Repositories
 	notifPGRepo := notifpgrepo.New(pg)
 	usersPGRepo := userspgrepo.New(pg)
@@ -110,37 +142,109 @@ func Run(cfg *config.Config) {
 	shiftsPGRepo := shiftsrepo.New(pg)
 	reviewsPGRepo := reviewsrepo.New(pg)
 	favoritesPGRepo := favoritesrepo.New(pg)
+	deliveryPGRepo := deliveryrepo.New(pg)
+	paymentsPGRepo := paymentsrepo.New(pg)
+	authPGRepo := authrepo.New(pg)
 
 	// Use Cases (сервисы)
 	userUseCase := usersusecase.New(usersPGRepo)
 	dishesUsecase := dishesusecase.New(dishesPGRepo, s3client)
 	chefsUsecase := chefsusecase.New(chefsPGRepo, geoPGRepo, s3client)
-	jwtUseCase := jwtusecase.New(cfg.JWT)
-	authUseCase := authusecase.New(usersPGRepo, redisClient, jwtUseCase, smsClient)
+	jwtUseCase, err := jwtusecase.New(cfg.JWT, redisClient)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки ключей JWT: %v", err)
+	}
+	notifUseCase := notifusecase.New(notifPGRepo, smtpClient, smsClient)
+	authUseCase := authusecase.New(usersPGRepo, redisClient, jwtUseCase, notifUseCase, notifUseCase, authPGRepo, authPGRepo, pg, cfg.JWT.RefreshExp, authusecase.OTPConfig{
+		MaxAttempts:     cfg.Auth.OTPMaxAttempts,
+		Lockout:         cfg.Auth.OTPLockout,
+		TestPhones:      cfg.Auth.TestPhones,
+		TestCode:        cfg.Auth.TestOTP,
+		MagicLinkURL:    cfg.Auth.MagicLinkURL,
+		MagicLinkSecret: cfg.Auth.MagicLinkSecret,
+		MagicLinkTTL:    cfg.Auth.MagicLinkTTL,
+		TelegramBot:     cfg.Telegram.BotUsername,
+	})
 	geoUseCase := geousecase.New(geoPGRepo)
-	notifUseCase := notifusecase.New(notifPGRepo, smtpClient)
-	cartUsecase := cartusecase.New(cartPGRepo)
+	cartUsecase := cartusecase.New(cartPGRepo, pg)
 	shiftsUsecase := shiftsusecase.New(shiftsPGRepo)
 	reviewsUsecase := reviewsusecase.New(reviewsPGRepo, usersPGRepo, ordersPGRepo, dishReviewsWriter, chefReviewsWriter)
-	ordersUsecase := ordersusecase.New(geoUseCase, cartUsecase, shiftsPGRepo, ordersPGRepo, dishesUsecase, chefsUsecase, reviewsUsecase)
-	favoritesUsecase := favoritesusecase.New(favoritesPGRepo)
-	// TG bot
+	deliveryUsecase := deliveryusecase.New(delivery.Tariff{
+		BaseFee:           cfg.Delivery.BaseFee,
+		PerKmFee:          cfg.Delivery.PerKmFee,
+		FreeThreshold:     cfg.Delivery.FreeThreshold,
+		MaxDistanceMeters: cfg.Delivery.MaxDistanceKm * 1000,
+	}, cfg.Delivery.Location, geoPGRepo, deliveryPGRepo, shiftsPGRepo, pg)
+	paymentsUsecase := paymentsusecase.New(newPaymentProvider(cfg.Payments), paymentsPGRepo)
 
+	// Бот создаётся до заказов: через него уходят уведомления о смене статуса
+	var bot *tele.Bot
 	if cfg.Telegram.IsEnabled {
-		tgUsecase := tg.New(redisClient, usersPGRepo, jwtUseCase)
-		bot, err := tele.NewBot(tele.Settings{
+		bot, err = tele.NewBot(tele.Settings{
 			Token: cfg.Telegram.Token,
+			// В режиме вебхука обновление обрабатывается внутри HTTP-запроса
+			Synchronous: cfg.Telegram.Mode == config.TelegramModeWebhook,
 		})
-
 		if err != nil {
 			log.Fatalf("Ошибка инициализации Telegram бота: %v", err)
 		}
-		telegram.NewBot(bot, tgUsecase)
-		go bot.Start()
 	}
+	orderNotifier := tgbot.NewNotifier(bot, usersPGRepo, ordersPGRepo)
+	lc.onStop("telegram notifications", orderNotifier.Shutdown)
+
+	ordersUsecase := ordersusecase.New(geoUseCase, cartUsecase, shiftsPGRepo, ordersPGRepo, dishesUsecase, chefsUsecase, reviewsUsecase, deliveryUsecase, paymentsUsecase, notifUseCase, orderNotifier, pg, cfg.Orders.CancelGracePeriod)
+	favoritesUsecase := favoritesusecase.New(favoritesPGRepo)
 
+	// Слоты доставки создаются заранее, чтобы показ интервалов клиенту ничего не писал в базу.
+	// Повторное создание слотов ничего не меняет, поэтому лидер здесь не нужен
+	slotsCtx, stopSlots := context.WithCancel(context.Background())
+	slotsDone := make(chan struct{})
+	go func() {
+		defer close(slotsDone)
+		deliveryUsecase.RunSlotGenerator(slotsCtx, cfg.Delivery.SlotsInterval)
+	}()
+	lc.onStop("delivery slots generator", func(ctx context.Context) error {
+		stopSlots()
+		select {
+		case <-slotsDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	// Воркер таймаутов заказов работает в каждом экземпляре, но обрабатывает заказы только лидер.
+	// Он же повторяет возвраты и списания, которые не прошли сразу после изменения заказа
+	if cfg.Orders.TimeoutsEnabled {
+		timeoutsWorker := timeouts.New(timeouts.Config{
+			PaymentTimeout:  cfg.Orders.PaymentTimeout,
+			AcceptTimeout:   cfg.Orders.AcceptTimeout,
+			DeliveryTimeout: cfg.Orders.DeliveryTimeout,
+			Interval:        cfg.Orders.TimeoutsInterval,
+		}, ordersPGRepo, ordersUsecase, paymentsUsecase, redisClient)
+		workersCtx, stopWorkers := context.WithCancel(context.Background())
+		workersDone := make(chan struct{})
+		go func() {
+			defer close(workersDone)
+			timeoutsWorker.Run(workersCtx)
+		}()
+		lc.onStop("order timeouts worker", func(ctx context.Context) error {
+			stopWorkers()
+			select {
+			case <-workersDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
 	// Http Server
 	handler := gin.New()
+	// Без доверенных прокси ClientIP берёт адрес из RemoteAddr и не верит X-Forwarded-For
+	if err := handler.SetTrustedProxies(cfg.RateLimit.TrustedProxies); err != nil {
+		log.Fatalf("Ошибка настройки доверенных прокси: %v", err)
+	}
+	handler.TrustedPlatform = cfg.RateLimit.TrustedPlatform
 	v1.NewRouter(
 		handler,
 		l,
@@ -156,10 +260,90 @@ func Run(cfg *config.Config) {
 		shiftsUsecase,
 		reviewsUsecase,
 		favoritesUsecase,
+		deliveryUsecase,
+		paymentsUsecase,
+		redisClient,
+		redisClient,
+		cfg.RateLimit,
+		lc,
 	)
 
-	err = handler.Run(fmt.Sprintf(":%s", cfg.HostConfig.Port))
+	// TG bot
+	if cfg.Telegram.IsEnabled {
+		tgUsecase := tg.New(redisClient, usersPGRepo)
+		tgBotUsecase := tgbot.New(usersPGRepo, ordersUsecase, shiftsUsecase)
+		telegram.NewBot(bot, tgUsecase, tgBotUsecase)
+		lc.onStop("telegram bot", startBot(bot, handler, cfg.Telegram))
+	}
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.HostConfig.Port),
+		Handler: handler,
+	}
+	listener, err := net.Listen("tcp", server.Addr)
 	if err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Serve(listener)
+	}()
+	// Сервер дожидается начатых запросов, в том числе обновлений от Telegram в режиме вебхука
+	lc.onStop("http", server.Shutdown)
+	lc.ready.Store(true)
+
+	// Если сервер упал сам, балансировщик ждать незачем
+	var drainDelay time.Duration
+	select {
+	case err := <-serverErr:
+		log.Printf("HTTP-сервер остановился: %v", err)
+	case <-ctx.Done():
+		// Повторный сигнал завершит процесс сразу, не дожидаясь остановки
+		stop()
+		log.Printf("Получен сигнал остановки, ждём %s, пока балансировщик уберёт реплику", cfg.Shutdown.DrainDelay)
+		drainDelay = cfg.Shutdown.DrainDelay
+	}
+
+	lc.terminate(drainDelay, cfg.Shutdown.Timeout)
+}
+
+// startBot запускает получение обновлений ботом и возвращает его остановку.
+// В режиме вебхука обновления приходят на HTTP-сервер приложения, поэтому их можно
+// раздавать на несколько реплик; long polling допускает только одну
+func startBot(bot *tele.Bot, handler *gin.Engine, cfg *config.TelegramConfig) stopFunc {
+	if cfg.Mode == config.TelegramModeWebhook {
+		handler.POST(cfg.WebhookPath, telegram.WebhookHandler(bot, cfg.WebhookSecret))
+		err := bot.SetWebhook(&tele.Webhook{
+			SecretToken: cfg.WebhookSecret,
+			Endpoint:    &tele.WebhookEndpoint{PublicURL: cfg.WebhookURL},
+		})
+		if err != nil {
+			log.Fatalf("Ошибка регистрации вебхука Telegram: %v", err)
+		}
+		// Вебхук не снимаем: остальные реплики продолжают принимать обновления.
+		// Начатые обновления дожидается остановка HTTP-сервера
+		return func(context.Context) error { return nil }
+	}
+
+	// getUpdates не работает, пока у бота зарегистрирован вебхук
+	if err := bot.RemoveWebhook(); err != nil {
+		log.Fatalf("Ошибка удаления вебхука Telegram: %v", err)
+	}
+	go bot.Start()
+	return func(context.Context) error {
+		bot.Stop()
+		return nil
+	}
+}
+
+// newPaymentProvider возвращает nil, если приём оплаты выключен
+func newPaymentProvider(cfg *config.PaymentsConfig) paymentsusecase.Provider {
+	switch cfg.Provider {
+	case config.PaymentsProviderNone:
+		return nil
+	case config.PaymentsProviderFake:
+		return paymentsclient.NewFake(cfg.WebhookSecret)
+	}
+	log.Fatalf("Неизвестный платёжный провайдер: %s", cfg.Provider)
+	return nil
 }
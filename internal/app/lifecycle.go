@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// lateStepTimeout — сколько ждём этап, если общий срок остановки уже вышел:
+// закрыть соединения с Redis и Postgres всё равно стоит
+const lateStepTimeout = time.Second
+
+// stopFunc останавливает компонент; ctx ограничивает время остановки
+type stopFunc func(ctx context.Context) error
+
+type shutdownStep struct {
+	name string
+	stop stopFunc
+}
+
+// lifecycle останавливает компоненты в порядке, обратном запуску, как defer, но в пределах
+// общего срока и с записью в лог по каждому этапу. Пока приложение не готово или уже
+// останавливается, Ready возвращает false, и /ready отвечает 503
+type lifecycle struct {
+	steps []shutdownStep
+	ready atomic.Bool
+}
+
+// onStop регистрирует остановку компонента; вызывается сразу после его создания
+func (l *lifecycle) onStop(name string, stop stopFunc) {
+	l.steps = append(l.steps, shutdownStep{name: name, stop: stop})
+}
+
+func (l *lifecycle) Ready() bool {
+	return l.ready.Load()
+}
+
+// terminate снимает реплику с балансировки: /ready отвечает 503 в течение drainDelay,
+// затем компоненты останавливаются в пределах timeout
+func (l *lifecycle) terminate(drainDelay, timeout time.Duration) {
+	l.ready.Store(false)
+	time.Sleep(drainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	l.shutdown(ctx)
+}
+
+// shutdown выполняет этапы с последнего зарегистрированного. Этап, не уложившийся в срок ctx,
+// бросается; после истечения срока каждому следующему даётся lateStepTimeout
+func (l *lifecycle) shutdown(ctx context.Context) {
+	l.ready.Store(false)
+	for i := len(l.steps) - 1; i >= 0; i-- {
+		l.runStep(ctx, l.steps[i])
+	}
+}
+
+func (l *lifecycle) runStep(ctx context.Context, step shutdownStep) {
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), lateStepTimeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- step.stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			log.Printf("Остановка %s: %v", step.name, err)
+			return
+		}
+		log.Printf("Остановка %s: готово", step.name)
+	case <-ctx.Done():
+		log.Printf("Остановка %s: не уложились в срок", step.name)
+	}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stopRecorder запоминает порядок остановки и состояние /ready в момент каждого вызова
+type stopRecorder struct {
+	mu      sync.Mutex
+	stopped []string
+	ready   []bool
+	at      []time.Time
+}
+
+func (r *stopRecorder) closer(lc *lifecycle, name string, err error) stopFunc {
+	return func(context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.stopped = append(r.stopped, name)
+		r.ready = append(r.ready, lc.Ready())
+		r.at = append(r.at, time.Now())
+		return err
+	}
+}
+
+func (r *stopRecorder) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.stopped...)
+}
+
+func TestLifecycle_Terminate(t *testing.T) {
+	lc := &lifecycle{}
+	rec := &stopRecorder{}
+	lc.onStop("postgres", rec.closer(lc, "postgres", nil))
+	lc.onStop("redis", rec.closer(lc, "redis", nil))
+	// Ошибка этапа не прерывает остановку остальных
+	lc.onStop("worker", rec.closer(lc, "worker", errors.New("worker busy")))
+	lc.onStop("http", rec.closer(lc, "http", nil))
+	lc.ready.Store(true)
+	require.True(t, lc.Ready())
+
+	const drainDelay = 50 * time.Millisecond
+	start := time.Now()
+	lc.terminate(drainDelay, time.Second)
+
+	require.False(t, lc.Ready())
+	// Компоненты останавливаются в порядке, обратном регистрации
+	require.Equal(t, []string{"http", "worker", "redis", "postgres"}, rec.names())
+	// Реплика снята с балансировки ещё до остановки первого компонента, и тот ждал drainDelay
+	require.Equal(t, []bool{false, false, false, false}, rec.ready)
+	require.GreaterOrEqual(t, rec.at[0].Sub(start), drainDelay)
+}
+
+func TestLifecycle_ShutdownTimeout(t *testing.T) {
+	lc := &lifecycle{}
+	rec := &stopRecorder{}
+	lc.onStop("postgres", rec.closer(lc, "postgres", nil))
+	// Зависший этап бросается по истечении срока, следующие всё равно останавливаются
+	lc.onStop("stuck", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Hour)
+		return nil
+	})
+	lc.onStop("http", rec.closer(lc, "http", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		lc.shutdown(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(lateStepTimeout):
+		t.Fatal("shutdown waited for the stuck step")
+	}
+	require.Equal(t, []string{"http", "postgres"}, rec.names())
+}
@@ -0,0 +1,170 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/payments"
+)
+
+// FakeProvider — платёжный провайдер в памяти процесса для локального запуска и тестов.
+// Сразу авторизует любую положительную сумму и отклоняет остальные.
+// Вебхуки подписываются HMAC-SHA256 от тела запроса.
+type FakeProvider struct {
+	secret []byte
+
+	mu        sync.Mutex
+	seq       int64
+	intents   map[string]*fakeIntent // по id платежа
+	byIdemKey map[string]string
+	refunds   map[string]bool // ключи идемпотентности выполненных возвратов
+}
+
+type fakeIntent struct {
+	amount   money.Money
+	captured money.Money
+	refunded money.Money
+	status   payments.Status
+}
+
+// fakeWebhook — тело вебхука фейкового провайдера
+type fakeWebhook struct {
+	PaymentID string `json:"payment_id"`
+	Status    string `json:"status"`
+}
+
+func NewFake(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		secret:    []byte(webhookSecret),
+		intents:   make(map[string]*fakeIntent),
+		byIdemKey: make(map[string]string),
+		refunds:   make(map[string]bool),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreateIntent(_ context.Context, req payments.IntentRequest) (*payments.Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if id, ok := p.byIdemKey[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return &payments.Intent{ProviderPaymentID: id, Status: p.intents[id].status}, nil
+	}
+
+	p.seq++
+	id := "fake_" + strconv.FormatInt(p.seq, 10)
+	status := payments.StatusAuthorized
+	if req.Amount.Amount <= 0 {
+		status = payments.StatusFailed
+	}
+	p.intents[id] = &fakeIntent{amount: req.Amount, status: status}
+	if req.IdempotencyKey != "" {
+		p.byIdemKey[req.IdempotencyKey] = id
+	}
+	return &payments.Intent{ProviderPaymentID: id, Status: status}, nil
+}
+
+func (p *FakeProvider) Capture(_ context.Context, providerPaymentID string, amount money.Money) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[providerPaymentID]
+	if !ok {
+		return payments.ErrPaymentNotFound
+	}
+	if intent.status != payments.StatusAuthorized {
+		return fmt.Errorf("fake provider: capture in status %s", intent.status)
+	}
+	tooLarge, err := intent.amount.Less(amount)
+	if err != nil {
+		return err
+	}
+	if tooLarge {
+		return fmt.Errorf("fake provider: capture %s exceeds authorized %s", amount, intent.amount)
+	}
+	intent.captured = amount
+	intent.status = payments.StatusCaptured
+	return nil
+}
+
+func (p *FakeProvider) Cancel(_ context.Context, providerPaymentID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[providerPaymentID]
+	if !ok {
+		return payments.ErrPaymentNotFound
+	}
+	if intent.status != payments.StatusAuthorized {
+		return fmt.Errorf("fake provider: cancel in status %s", intent.status)
+	}
+	intent.status = payments.StatusCancelled
+	return nil
+}
+
+func (p *FakeProvider) Refund(_ context.Context, providerPaymentID string, amount money.Money, idempotencyKey string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if idempotencyKey != "" && p.refunds[idempotencyKey] {
+		return nil
+	}
+
+	intent, ok := p.intents[providerPaymentID]
+	if !ok {
+		return payments.ErrPaymentNotFound
+	}
+	if intent.status != payments.StatusCaptured && intent.status != payments.StatusRefunded {
+		return fmt.Errorf("fake provider: refund in status %s", intent.status)
+	}
+	refunded, err := intent.refunded.Add(amount)
+	if err != nil {
+		return err
+	}
+	tooLarge, err := intent.captured.Less(refunded)
+	if err != nil {
+		return err
+	}
+	if tooLarge {
+		return fmt.Errorf("fake provider: refund %s exceeds captured %s", refunded, intent.captured)
+	}
+	intent.refunded = refunded
+	if refunded == intent.captured {
+		intent.status = payments.StatusRefunded
+	}
+	if idempotencyKey != "" {
+		p.refunds[idempotencyKey] = true
+	}
+	return nil
+}
+
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (*payments.Event, error) {
+	if !hmac.Equal([]byte(p.Sign(payload)), []byte(signature)) {
+		return nil, payments.ErrInvalidSignature
+	}
+	var webhook fakeWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("fake provider: decode webhook: %w", err)
+	}
+	return &payments.Event{
+		ProviderPaymentID: webhook.PaymentID,
+		Status:            payments.Status(webhook.Status),
+	}, nil
+}
+
+// Sign возвращает подпись тела вебхука, с которой его примет VerifyWebhook
+func (p *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -3,10 +3,12 @@ package v1
 import (
 	"domashka-backend/internal/custom_errors"
 	"domashka-backend/internal/entity/auth"
-	"domashka-backend/internal/utils/validation"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type AuthHandler struct {
@@ -14,17 +16,35 @@ type AuthHandler struct {
 	jwtUsecase  jwtUsecase
 }
 
-func newAuthHandler(rg *gin.RouterGroup, auth authUsecase, jwt jwtUsecase) {
+func newAuthHandler(root *gin.Engine, rg *gin.RouterGroup, authorized *gin.RouterGroup, auth authUsecase, jwt jwtUsecase, limits ...gin.HandlerFunc) {
 	h := &AuthHandler{authUsecase: auth, jwtUsecase: jwt}
 
+	root.GET("/.well-known/jwks.json", h.JWKS)
+
 	rg = rg.Group("/auth")
 	{
-		rg.POST("/login", h.Auth)
-		rg.POST("/verify", h.Verify)
+		// Ограничения частоты нужны только там, где можно перебирать номера и коды
+		limited := rg.Group("", limits...)
+		limited.POST("/login", h.Auth)
+		limited.POST("/verify", h.Verify)
+		rg.POST("/refresh", h.Refresh)
 		rg.GET("/user", h.ValidateToken)
-		rg.POST("/tg", h.TelegramAuth)
+		limited.POST("/tg", h.TelegramAuth)
+		// Клиент опрашивает статус, пока пользователь в боте; перебирать здесь нечего — секрет длинный
+		rg.POST("/tg/status", h.TelegramAuthStatus)
 	}
 
+	authorized.POST("/auth/logout", h.Logout)
+	authorized.GET("/auth/sessions", h.GetSessions)
+	authorized.DELETE("/auth/sessions", h.RevokeAllSessions)
+	authorized.DELETE("/auth/sessions/:id", h.RevokeSession)
+}
+
+// JWKS отдаёт открытые ключи, по которым партнёры проверяют наши токены
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	// Ключи меняются только при ротации; партнёры могут кешировать ответ
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtUsecase.JWKS())
 }
 
 func (h *AuthHandler) Auth(c *gin.Context) {
@@ -41,9 +61,16 @@ func (h *AuthHandler) Auth(c *gin.Context) {
 		return
 	}
 
+	message := "Код подтверждения отправлен на указанный номер телефона."
+	if request.Email != "" {
+		message = "Код подтверждения отправлен на указанную почту."
+		if request.Method == auth.LoginMethodLink {
+			message = "Ссылка для входа отправлена на указанную почту."
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
-		"message": "Код подтверждения отправлен на указанный номер телефона.",
+		"message": message,
 	})
 }
 
@@ -51,45 +78,189 @@ func (h *AuthHandler) Verify(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	var request struct {
-		Phone string `json:"phone" binding:"required"`
-		OTP   string `json:"otp" binding:"required"`
+		Phone string `json:"phone" binding:"required_without=Email"`
+		// Email — вход по почте; тогда в OTP передаётся код из письма или token из ссылки
+		Email      string `json:"email"`
+		OTP        string `json:"otp" binding:"required"`
+		DeviceName string `json:"device_name"`
+		Platform   string `json:"platform"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request"})
 		return
 	}
-	// todo: Убрать костыль перед выгрузкой в прод
-	if request.OTP == "0123" {
-		userID, chefID, token, _ := h.authUsecase.Verify(ctx, request.Phone, request.OTP, "admin")
-		c.JSON(http.StatusOK, gin.H{"status": "success",
-			"message": "Номер телефона успешно подтверждён.",
-			"token":   token,
-			"user_id": userID,
-			"chef_id": chefID,
-		})
-		return
+	device := auth.Device{
+		Name:      request.DeviceName,
+		Platform:  request.Platform,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
 	}
 
-	if len(request.OTP) != 4 {
+	if request.Email == "" && len(request.OTP) != 4 {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Неверный формат OTP."})
 		return
 	}
 
-	userID, chefID, token, err := h.authUsecase.Verify(ctx, request.Phone, request.OTP, "user")
+	var (
+		userID int64
+		chefID *int64
+		tokens *auth.Tokens
+		err    error
+	)
+	if request.Email != "" {
+		userID, chefID, tokens, err = h.authUsecase.VerifyEmail(ctx, request.Email, request.OTP, device)
+	} else {
+		userID, chefID, tokens, err = h.authUsecase.Verify(ctx, request.Phone, request.OTP, device)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, custom_errors.ErrOTPLocked):
+			c.JSON(http.StatusTooManyRequests, gin.H{"status": "error", "message": "Слишком много попыток. Попробуйте позже."})
+		case errors.Is(err, custom_errors.ErrInvalidOTP):
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Неверный или просроченный OTP."})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Не удалось проверить OTP."})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success",
+		"message":       "Вход подтверждён.",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    int64(tokens.ExpiresIn.Seconds()),
+		"user_id":       userID,
+		"chef_id":       chefID,
+	})
+}
+
+// Refresh меняет refresh-токен на новую пару токенов. Старый refresh-токен больше не действует.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request"})
+		return
+	}
+
+	tokens, err := h.authUsecase.Refresh(c.Request.Context(), request.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Неверный или просроченный OTP."})
+		if errors.Is(err, custom_errors.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "Токен недействителен или истек."})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Не удалось обновить токен."})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "success",
-		"message": "Номер телефона успешно подтверждён.",
-		"token":   token,
-		"user_id": userID,
-		"chef_id": chefID,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    int64(tokens.ExpiresIn.Seconds()),
 	})
 }
 
+// Logout отзывает токен из запроса и refresh-токены этого входа
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	claimsMap, _ := claims.(map[string]interface{})
+	tokenUUID, _ := claimsMap["uuid"].(string)
+	exp, _ := claimsMap["exp"].(float64)
+
+	if err := h.authUsecase.Logout(c.Request.Context(), tokenUUID, time.Unix(int64(exp), 0)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Не удалось выйти."})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type sessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	Platform   string    `json:"platform"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Current — сессия, которой принадлежит токен запроса
+	Current bool `json:"current"`
+}
+
+// GetSessions — активные входы пользователя на разных устройствах
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "Токен недействителен."})
+		return
+	}
+
+	sessions, err := h.authUsecase.GetSessions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Не удалось получить сессии."})
+		return
+	}
+
+	currentID := c.GetString("session_id")
+	resp := make([]sessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resp = append(resp, sessionResponse{
+			ID:         s.ID,
+			DeviceName: s.Device.Name,
+			Platform:   s.Device.Platform,
+			IP:         s.Device.IP,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			Current:    s.ID == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "sessions": resp})
+}
+
+// RevokeSession завершает вход на одном устройстве
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "Токен недействителен."})
+		return
+	}
+	sessionID := c.Param("id")
+	if _, err := uuid.Parse(sessionID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Некорректный id сессии."})
+		return
+	}
+
+	if err := h.authUsecase.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, custom_errors.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Сессия не найдена."})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Не удалось завершить сессию."})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAllSessions — выход на всех устройствах, включая текущее
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "Токен недействителен."})
+		return
+	}
+
+	if err := h.authUsecase.RevokeAllSessions(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Не удалось завершить сессии."})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (h *AuthHandler) ValidateToken(ctx *gin.Context) {
 	authHeader := ctx.GetHeader("Authorization")
 	if authHeader == "" {
@@ -107,48 +278,63 @@ func (h *AuthHandler) ValidateToken(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// TelegramAuth начинает вход через Telegram. Клиент открывает link (или бота с start=nonce)
+// и опрашивает /auth/tg/status с nonce и secret, пока вход не подтвердят в боте
 func (h *AuthHandler) TelegramAuth(ctx *gin.Context) {
-	var request struct {
-		Phone string `json:"phone" binding:"required"`
-	}
-
-	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request"})
-		return
-	}
-
-	if !validation.ValidatePhoneNumber(request.Phone) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid phone number"})
-		return
-	}
-
-	err := h.authUsecase.AuthViaTg(ctx.Request.Context(), request.Phone)
+	login, err := h.authUsecase.AuthViaTg(ctx.Request.Context())
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Error"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Не удалось начать вход через Telegram."})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": "pending", "message": "Ожидается подтверждение через Telegram."})
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":     "pending",
+		"message":    "Ожидается подтверждение через Telegram.",
+		"nonce":      login.Nonce,
+		"secret":     login.Secret,
+		"link":       login.Link,
+		"expires_in": int64(login.ExpiresIn.Seconds()),
+	})
 }
 
+// TelegramAuthStatus выдаёт токены, когда вход подтверждён в боте. Токены выдаются один раз
 func (h *AuthHandler) TelegramAuthStatus(ctx *gin.Context) {
-	phone := ctx.Query("phone")
-	if phone == "" {
+	var request struct {
+		Nonce      string `json:"nonce" binding:"required"`
+		Secret     string `json:"secret" binding:"required"`
+		DeviceName string `json:"device_name"`
+		Platform   string `json:"platform"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request"})
 		return
 	}
+	device := auth.Device{
+		Name:      request.DeviceName,
+		Platform:  request.Platform,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
 
-	token, err := h.authUsecase.AuthViaTgStatus(ctx, phone)
+	userID, chefID, tokens, err := h.authUsecase.AuthViaTgStatus(ctx.Request.Context(), request.Nonce, request.Secret, device)
 	if err != nil {
-		if errors.Is(err, custom_errors.ErrConfirmationNotReceived) {
+		switch {
+		case errors.Is(err, custom_errors.ErrConfirmationNotReceived):
 			ctx.JSON(http.StatusOK, gin.H{"status": "pending", "message": "Ожидается подтверждение через Telegram."})
-			return
-		}
-		if errors.Is(err, custom_errors.ErrExpiredTTL) {
+		case errors.Is(err, custom_errors.ErrExpiredTTL):
 			ctx.JSON(http.StatusOK, gin.H{"status": "error", "message": "Подтверждение через Telegram не получено."})
-			return
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Не удалось завершить вход через Telegram."})
 		}
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"status": "success", "token": token})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success",
+		"message":       "Вход подтверждён.",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    int64(tokens.ExpiresIn.Seconds()),
+		"user_id":       userID,
+		"chef_id":       chefID,
+	})
 }
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"slices"
@@ -10,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	cartentity "domashka-backend/internal/entity/cart"
+	deliveryentity "domashka-backend/internal/entity/delivery"
 	"domashka-backend/internal/entity/dishes"
 	"domashka-backend/internal/utils/pointers"
 )
@@ -19,6 +21,7 @@ type cartHandler struct {
 	cartUsecase   cartUsecase
 	dishesUsecase dishesUsecase
 	geoUsecase    geoUsecase
+	delivery      deliveryUsecase
 }
 
 func RegisterCartHandlers(
@@ -26,12 +29,14 @@ func RegisterCartHandlers(
 	chefUsecase chefUsecase,
 	cartUsecase cartUsecase,
 	dishesUsecase dishesUsecase,
-	geoUsecase geoUsecase) {
+	geoUsecase geoUsecase,
+	delivery deliveryUsecase) {
 	c := cartHandler{
 		chefUsecase:   chefUsecase,
 		cartUsecase:   cartUsecase,
 		dishesUsecase: dishesUsecase,
 		geoUsecase:    geoUsecase,
+		delivery:      delivery,
 	}
 
 	rg = rg.Group("/cart")
@@ -76,16 +81,8 @@ type Dish struct {
 
 func (h *cartHandler) GetCartView(c *gin.Context) {
 	ctx := c.Request.Context()
-	userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse{
-			Status: "error",
-			Err: errorMessage{
-				Code:    4002,
-				Message: "Invalid user ID",
-				Details: "Передан некорректный ID пользователя.",
-			},
-		})
+	userID, ok := cartUserID(c)
+	if !ok {
 		return
 	}
 	address, err := h.geoUsecase.GetLastUpdatedClientAddress(ctx, userID)
@@ -144,7 +141,18 @@ func (h *cartHandler) GetCartView(c *gin.Context) {
 			}
 			addIngredientsText := strings.Join(addIngredientsLabels, ", ")
 			removeIngredientsText := strings.Join(removeIngredientsLabels, ", ")
-			totalPrice := item.GetTotalPrice()
+			totalPrice, err := item.GetTotalPrice()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, errorResponse{
+					Status: "error",
+					Err: errorMessage{
+						Code:    4003,
+						Message: "Internal server error.",
+						Details: "Не удалось посчитать стоимость корзины.",
+					},
+				})
+				return
+			}
 			details := fmt.Sprintf("%s", item.Size.Label)
 			if addIngredientsText != "" {
 				details = fmt.Sprintf("%s, Добавить: %s", details, addIngredientsText)
@@ -157,16 +165,10 @@ func (h *cartHandler) GetCartView(c *gin.Context) {
 				CartItemID: pointers.To(item.ID),
 				Title:      item.Dish.Name,
 				Details:    details,
-				Price: &Price{
-					Value:    item.Size.PriceValue,
-					Currency: item.Size.PriceCurrency,
-				},
-				Quantity: item.Quantity,
-				TotalPrice: Price{
-					Value:    totalPrice.Value,
-					Currency: totalPrice.Currency,
-				},
-				ImageURL: item.Dish.ImageURL,
+				Price:      pointers.To(newPrice(item.Size.Price)),
+				Quantity:   item.Quantity,
+				TotalPrice: newPrice(totalPrice),
+				ImageURL:   item.Dish.ImageURL,
 			})
 			allDishIDs = append(allDishIDs, item.Dish.ID)
 		}
@@ -212,16 +214,39 @@ func (h *cartHandler) GetCartView(c *gin.Context) {
 				ID:       dish.ID,
 				Name:     dish.Name,
 				ImageURL: dish.ImageURL,
-				Price:    fmt.Sprintf("от %.0fр", price.Value),
+				Price:    price.FormatFrom(),
 				Rating:   pointers.From(dish.Rating),
 			})
 		}
 	}
-	deliveryCost := Price{
-		Value:    169,
-		Currency: "RUB",
+	totalCartPrice, err := cartentity.GetTotalCartPrice(cartItems)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4003,
+				Message: "Internal server error",
+				Details: "Не удалось посчитать стоимость корзины.",
+			},
+		})
+		return
+	}
+	deliveryFee, err := calculateDeliveryFee(ctx, h.delivery, address, cartItems, totalCartPrice)
+	if errors.Is(err, deliveryentity.ErrTooFar) {
+		c.JSON(http.StatusUnprocessableEntity, deliveryTooFarResponse)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4003,
+				Message: "Internal server error",
+				Details: "Не удалось посчитать стоимость доставки.",
+			},
+		})
+		return
 	}
-	totalCartPrice := cartentity.GetTotalCartPrice(cartItems)
 	var addressResp *Address
 	if address != nil {
 		addressResp = &Address{
@@ -233,11 +258,8 @@ func (h *cartHandler) GetCartView(c *gin.Context) {
 		Address:       addressResp,
 		DishGroups:    dishGroups,
 		RelatedDishes: dishSnippets,
-		DeliveryCost:  deliveryCost,
-		TotalCost: Price{
-			Value:    totalCartPrice.Value,
-			Currency: totalCartPrice.Currency,
-		},
+		DeliveryCost:  newPrice(deliveryFee),
+		TotalCost:     newPrice(totalCartPrice),
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
@@ -246,17 +268,22 @@ func (h *cartHandler) GetCartView(c *gin.Context) {
 }
 
 type AddItemToCartRequest struct {
-	UserID                   int64   `json:"user_id"`
 	DishID                   int64   `json:"dish_id"`
 	ChefID                   int64   `json:"chef_id"`
 	SizeID                   int64   `json:"size_id"`
 	AdditionalIngredientsIDs []int64 `json:"additional_ingredients_ids"`
 	RemovedIngredientsIDs    []int64 `json:"removed_ingredients_ids"`
 	Notes                    string  `json:"notes"`
+	// ReplaceCart очищает корзину, если в ней лежат блюда другого повара
+	ReplaceCart bool `json:"replace_cart"`
 }
 
 func (h *cartHandler) AddItemToCart(c *gin.Context) {
 	ctx := c.Request.Context()
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
 
 	var req AddItemToCartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -270,18 +297,55 @@ func (h *cartHandler) AddItemToCart(c *gin.Context) {
 		})
 		return
 	}
+	// Повара берём из блюда, а не из запроса: от него зависит проверка корзины
+	dish, err := h.dishesUsecase.GetDishByID(ctx, req.DishID)
+	if errors.Is(err, dishes.ErrDishNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4004,
+				Message: "Dish not found.",
+				Details: "Блюдо не найдено.",
+			},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4003,
+				Message: "Internal server error.",
+				Details: fmt.Sprintf("%v", err),
+			},
+		})
+		return
+	}
 	cartItemID, err := h.cartUsecase.AddItem(
 		ctx,
-		req.UserID,
+		userID,
 		dishes.Dish{
-			ID:     req.DishID,
-			ChefID: req.ChefID,
+			ID:     dish.ID,
+			ChefID: dish.ChefID,
 		},
 		req.SizeID,
 		req.AdditionalIngredientsIDs,
 		req.RemovedIngredientsIDs,
 		req.Notes,
+		req.ReplaceCart,
 	)
+	var conflictErr *cartentity.ChefConflictError
+	if errors.As(err, &conflictErr) {
+		c.JSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4093,
+				Message: "Cart contains dishes from another chef.",
+				Details: "В корзине уже есть блюда другого повара. Очистите корзину или передайте replace_cart.",
+			},
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse{
 			Status: "error",
@@ -303,7 +367,6 @@ func (h *cartHandler) AddItemToCart(c *gin.Context) {
 
 type UpdateCartItemRequest struct {
 	CartItemID               int64   `json:"cart_item_id"`
-	UserID                   int64   `json:"user_id"`
 	DishID                   int64   `json:"dish_id"`
 	ChefID                   int64   `json:"chef_id"`
 	SizeID                   int64   `json:"size_id"`
@@ -314,6 +377,10 @@ type UpdateCartItemRequest struct {
 
 func (h *cartHandler) UpdateCartItem(c *gin.Context) {
 	ctx := c.Request.Context()
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
 
 	var req UpdateCartItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -328,20 +395,57 @@ func (h *cartHandler) UpdateCartItem(c *gin.Context) {
 		return
 	}
 
+	// Как и при добавлении, повара берём из блюда, а не из запроса
+	dish, err := h.dishesUsecase.GetDishByID(ctx, req.DishID)
+	if errors.Is(err, dishes.ErrDishNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4004,
+				Message: "Dish not found.",
+				Details: "Блюдо не найдено.",
+			},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4003,
+				Message: "Internal server error.",
+				Details: fmt.Sprintf("%v", err),
+			},
+		})
+		return
+	}
 	// TODO:
 	// Сделать здесь изменение айтема
 	cartItemID, err := h.cartUsecase.AddItem(
 		ctx,
-		req.UserID,
+		userID,
 		dishes.Dish{
-			ID:     req.DishID,
-			ChefID: req.ChefID,
+			ID:     dish.ID,
+			ChefID: dish.ChefID,
 		},
 		req.SizeID,
 		req.AdditionalIngredientsIDs,
 		req.RemovedIngredientsIDs,
 		req.Notes,
+		false,
 	)
+	var conflictErr *cartentity.ChefConflictError
+	if errors.As(err, &conflictErr) {
+		c.JSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4093,
+				Message: "Cart contains dishes from another chef.",
+				Details: "В корзине уже есть блюда другого повара.",
+			},
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse{
 			Status: "error",
@@ -362,12 +466,15 @@ func (h *cartHandler) UpdateCartItem(c *gin.Context) {
 }
 
 type IncrementCartItemRequest struct {
-	UserID     int64 `json:"user_id"`
 	CartItemID int64 `json:"cart_item_id"`
 }
 
 func (h *cartHandler) IncrementCartItem(c *gin.Context) {
 	ctx := c.Request.Context()
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
 	var req IncrementCartItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, errorResponse{
@@ -380,7 +487,11 @@ func (h *cartHandler) IncrementCartItem(c *gin.Context) {
 		})
 		return
 	}
-	quantity, err := h.cartUsecase.IncrementCartItem(ctx, req.CartItemID)
+	quantity, err := h.cartUsecase.IncrementCartItem(ctx, userID, req.CartItemID)
+	if errors.Is(err, cartentity.ErrCartItemNotFound) {
+		c.JSON(http.StatusNotFound, cartItemNotFoundResponse)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse{
 			Status: "error",
@@ -401,12 +512,15 @@ func (h *cartHandler) IncrementCartItem(c *gin.Context) {
 }
 
 type DecrementCartItemRequest struct {
-	UserID     int64 `json:"user_id"`
 	CartItemID int64 `json:"cart_item_id"`
 }
 
 func (h *cartHandler) DecrementCartItem(c *gin.Context) {
 	ctx := c.Request.Context()
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
 	var req DecrementCartItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, errorResponse{
@@ -419,7 +533,11 @@ func (h *cartHandler) DecrementCartItem(c *gin.Context) {
 		})
 		return
 	}
-	quantity, err := h.cartUsecase.DecrementCartItem(ctx, req.CartItemID)
+	quantity, err := h.cartUsecase.DecrementCartItem(ctx, userID, req.CartItemID)
+	if errors.Is(err, cartentity.ErrCartItemNotFound) {
+		c.JSON(http.StatusNotFound, cartItemNotFoundResponse)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse{
 			Status: "error",
@@ -439,25 +557,13 @@ func (h *cartHandler) DecrementCartItem(c *gin.Context) {
 	})
 }
 
-type ClearCartRequest struct {
-	UserID int64 `json:"user_id"`
-}
-
 func (h *cartHandler) ClearCart(c *gin.Context) {
 	ctx := c.Request.Context()
-	var req ClearCartRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse{
-			Status: "error",
-			Err: errorMessage{
-				Code:    4002,
-				Message: "Invalid request body.",
-				Details: fmt.Sprintf("%v", err),
-			},
-		})
+	userID, ok := cartUserID(c)
+	if !ok {
 		return
 	}
-	err := h.cartUsecase.ClearCart(ctx, req.UserID)
+	err := h.cartUsecase.ClearCart(ctx, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse{
 			Status: "error",
@@ -476,12 +582,20 @@ func (h *cartHandler) ClearCart(c *gin.Context) {
 
 func (h *cartHandler) RemoveItem(c *gin.Context) {
 	ctx := c.Request.Context()
+	userID, ok := cartUserID(c)
+	if !ok {
+		return
+	}
 	cartItemID, err := strconv.ParseInt(c.Query("cart_item_id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, errorResponse{})
 		return
 	}
-	err = h.cartUsecase.RemoveItem(ctx, cartItemID)
+	err = h.cartUsecase.RemoveItem(ctx, userID, cartItemID)
+	if errors.Is(err, cartentity.ErrCartItemNotFound) {
+		c.JSON(http.StatusNotFound, cartItemNotFoundResponse)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse{
 			Status: "error",
@@ -497,3 +611,30 @@ func (h *cartHandler) RemoveItem(c *gin.Context) {
 		"status": "success",
 	})
 }
+
+// cartUserID возвращает владельца корзины. Это всегда пользователь из токена:
+// ID из запроса не принимается, чтобы нельзя было менять чужую корзину.
+func cartUserID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4002,
+				Message: "Invalid user ID",
+				Details: "Передан некорректный ID пользователя.",
+			},
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+var cartItemNotFoundResponse = errorResponse{
+	Status: "error",
+	Err: errorMessage{
+		Code:    4044,
+		Message: "Cart item not found.",
+		Details: "Позиция не найдена в корзине.",
+	},
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,9 +14,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"golang.org/x/sync/errgroup"
 
+	"domashka-backend/internal/entity/auth"
 	chefEntity "domashka-backend/internal/entity/chefs"
+	deliveryEntity "domashka-backend/internal/entity/delivery"
 	dishEntity "domashka-backend/internal/entity/dishes"
 	geoEntity "domashka-backend/internal/entity/geo"
+	"domashka-backend/internal/entity/money"
 	"domashka-backend/internal/utils/pointers"
 )
 
@@ -49,6 +54,7 @@ type chefsHendler struct {
 	usersUsecase   usersUsecase
 	reviewsUsecase reviewsUsecase
 	orderUsecase   orderUsecase
+	delivery       deliveryUsecase
 }
 
 func NewChefsHandler(
@@ -60,6 +66,7 @@ func NewChefsHandler(
 	usersUsecase usersUsecase,
 	reviewsUsecase reviewsUsecase,
 	orderUsecase orderUsecase,
+	delivery deliveryUsecase,
 ) {
 	ch := chefsHendler{
 		dishesUsecase:  dishesUsecase,
@@ -69,6 +76,7 @@ func NewChefsHandler(
 		usersUsecase:   usersUsecase,
 		reviewsUsecase: reviewsUsecase,
 		orderUsecase:   orderUsecase,
+		delivery:       delivery,
 	}
 
 	rg = rg.Group("/chefs")
@@ -82,6 +90,8 @@ func NewChefsHandler(
 		rg.GET("/menu", ch.getMenu)
 		rg.GET("/dish/form", ch.createDishForm)
 		rg.GET("/stats", ch.getStats)
+		rg.GET("/working-hours", ch.getWorkingHours)
+		rg.POST("/working-hours", ch.setWorkingHours)
 	}
 }
 
@@ -207,11 +217,8 @@ func (h *chefsHendler) getChefDetail(c *gin.Context) {
 				"image_url":     d.ImageURL,
 				"rating":        d.Rating,
 				"reviews_count": d.ReviewsCount,
-				"price_from": gin.H{
-					"value":    price.Value,
-					"currency": price.Currency,
-				},
-				"category": category,
+				"price_from":    newPrice(*price),
+				"category":      category,
 			}
 			return nil
 		})
@@ -465,12 +472,23 @@ func (h *chefsHendler) CreateDish(context *gin.Context) {
 	}
 	sizes := make([]dishEntity.Size, 0, len(req.Dish.Sizes))
 	for _, size := range req.Dish.Sizes {
+		price, err := size.Price.toMoney()
+		if err != nil {
+			context.JSON(http.StatusBadRequest, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    4001,
+					Message: err.Error(),
+					Details: "Некорректная цена размера блюда.",
+				}},
+			)
+			return
+		}
 		sizes = append(sizes, dishEntity.Size{
-			Label:         size.Label,
-			WeightUnit:    size.Weight.Unit,
-			WeightValue:   size.Weight.Value,
-			PriceValue:    size.Price.Value,
-			PriceCurrency: size.Price.Currency,
+			Label:       size.Label,
+			WeightUnit:  size.Weight.Unit,
+			WeightValue: size.Weight.Value,
+			Price:       price,
 		})
 	}
 	var nutrition *dishEntity.Nutrition
@@ -708,10 +726,7 @@ func (h *chefsHendler) createDishForm(context *gin.Context) {
 				Unit:  size.WeightUnit,
 				Value: size.WeightValue,
 			},
-			Price: Price{
-				Currency: size.PriceCurrency,
-				Value:    size.PriceValue,
-			},
+			Price: newPrice(size.Price),
 		})
 	}
 
@@ -805,8 +820,8 @@ func (h *chefsHendler) DeleteDish(c *gin.Context) {
 }
 
 type DailyIncome struct {
-	Date   string  `json:"date"`
-	Profit float32 `json:"profit"`
+	Date   string      `json:"date"`
+	Profit json.Number `json:"profit"`
 }
 type Monthly struct {
 	Month       string        `json:"month"`
@@ -873,10 +888,10 @@ func (h *chefsHendler) getStats(c *gin.Context) {
 		})
 		return
 	}
-	profitsMap := map[string]map[string]float32{}
+	profitsMap := map[string]map[string]money.Money{}
 	for _, profit := range profits {
 		if _, ok := profitsMap[profit.Month]; !ok {
-			profitsMap[profit.Month] = map[string]float32{}
+			profitsMap[profit.Month] = map[string]money.Money{}
 		}
 		profitsMap[profit.Month][profit.Date] = profit.Profit
 	}
@@ -886,7 +901,7 @@ func (h *chefsHendler) getStats(c *gin.Context) {
 		for date, prof := range profs {
 			dailyIncomes = append(dailyIncomes, DailyIncome{
 				Date:   date,
-				Profit: prof,
+				Profit: prof.Number(),
 			})
 		}
 		monthlyIncomes = append(monthlyIncomes, Monthly{
@@ -984,7 +999,7 @@ func (h *chefsHendler) getStats(c *gin.Context) {
 			DishID:      dish.ID,
 			ImageURL:    dish.ImageURL,
 			Name:        dish.Name,
-			Price:       fmt.Sprintf("от %.0fр", minPrice.Value),
+			Price:       minPrice.FormatFrom(),
 			Rating:      dish.Rating,
 			OrdersCount: int32(cnt),
 		})
@@ -999,3 +1014,154 @@ func (h *chefsHendler) getStats(c *gin.Context) {
 		"data":   stats,
 	})
 }
+
+// WorkingHoursItem — рабочий интервал повара в один из дней недели, время в формате "15:04"
+type WorkingHoursItem struct {
+	Weekday      int    `json:"weekday"` // 0 — воскресенье
+	Start        string `json:"start"`
+	End          string `json:"end"`
+	SlotMinutes  int32  `json:"slot_minutes"`
+	SlotCapacity int32  `json:"slot_capacity"`
+}
+
+type SetWorkingHoursRequest struct {
+	Hours []WorkingHoursItem `json:"hours"`
+}
+
+// workingHoursChefID — повар из токена; админ передаёт повара в chef_id
+func workingHoursChefID(c *gin.Context) (int64, bool) {
+	if chefID, ok := chefIDFromContext(c); ok {
+		return chefID, true
+	}
+	if c.GetString("role") != auth.RoleAdmin {
+		return 0, false
+	}
+	chefID, err := strconv.ParseInt(c.Query("chef_id"), 10, 64)
+	return chefID, err == nil
+}
+
+func (h *chefsHendler) getWorkingHours(c *gin.Context) {
+	chefID, ok := workingHoursChefID(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, forbiddenResponse)
+		return
+	}
+	hours, err := h.delivery.GetWorkingHours(c.Request.Context(), chefID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+	items := make([]WorkingHoursItem, 0, len(hours))
+	for _, wh := range hours {
+		items = append(items, WorkingHoursItem{
+			Weekday:      int(wh.Weekday),
+			Start:        formatClock(wh.Start),
+			End:          formatClock(wh.End),
+			SlotMinutes:  int32(wh.SlotDuration / time.Minute),
+			SlotCapacity: wh.SlotCapacity,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   gin.H{"hours": items},
+	})
+}
+
+// setWorkingHours заменяет все рабочие часы повара. По ним сразу создаются слоты доставки,
+// пустой список снимает требование выбирать слот при заказе
+func (h *chefsHendler) setWorkingHours(c *gin.Context) {
+	chefID, ok := workingHoursChefID(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, forbiddenResponse)
+		return
+	}
+	var req SetWorkingHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4002,
+				Message: "Invalid request body.",
+				Details: fmt.Sprintf("%v", err),
+			},
+		})
+		return
+	}
+	hours := make([]deliveryEntity.WorkingHours, 0, len(req.Hours))
+	for _, item := range req.Hours {
+		start, startErr := parseClock(item.Start)
+		end, endErr := parseClock(item.End)
+		if startErr != nil || endErr != nil {
+			c.JSON(http.StatusBadRequest, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    4002,
+					Message: "Invalid request body.",
+					Details: "Время указывается в формате ЧЧ:ММ.",
+				},
+			})
+			return
+		}
+		hours = append(hours, deliveryEntity.WorkingHours{
+			Weekday:      time.Weekday(item.Weekday),
+			Start:        start,
+			End:          end,
+			SlotDuration: time.Duration(item.SlotMinutes) * time.Minute,
+			SlotCapacity: item.SlotCapacity,
+		})
+	}
+	err := h.delivery.SetWorkingHours(c.Request.Context(), chefID, hours)
+	if errors.Is(err, deliveryEntity.ErrInvalidWorkingHours) {
+		c.JSON(http.StatusUnprocessableEntity, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4223,
+				Message: "Invalid working hours.",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+	})
+}
+
+// parseClock разбирает время суток "15:04"; "24:00" означает конец дня
+func parseClock(value string) (time.Duration, error) {
+	hours, minutes, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
@@ -6,12 +6,16 @@ import (
 	"domashka-backend/internal/entity/orders"
 	"domashka-backend/internal/entity/shifts"
 	"mime/multipart"
+	"time"
 
 	authEntity "domashka-backend/internal/entity/auth"
 	chefEntity "domashka-backend/internal/entity/chefs"
+	deliveryEntity "domashka-backend/internal/entity/delivery"
 	dishesEntity "domashka-backend/internal/entity/dishes"
 	geoEntity "domashka-backend/internal/entity/geo"
+	"domashka-backend/internal/entity/money"
 	notifEntity "domashka-backend/internal/entity/notifications"
+	paymentsEntity "domashka-backend/internal/entity/payments"
 	reviewsEntity "domashka-backend/internal/entity/reviews"
 	usersEntity "domashka-backend/internal/entity/users"
 )
@@ -22,13 +26,26 @@ type logger interface {
 
 type authUsecase interface {
 	Auth(ctx context.Context, req authEntity.Request) error
-	Verify(ctx context.Context, phone, otp, role string) (int64, *int64, string, error)
-	AuthViaTg(ctx context.Context, phoneNumber string) error
-	AuthViaTgStatus(ctx context.Context, phoneNumber string) (string, error)
+	Verify(ctx context.Context, phone, otp string, device authEntity.Device) (int64, *int64, *authEntity.Tokens, error)
+	VerifyEmail(ctx context.Context, email, otp string, device authEntity.Device) (int64, *int64, *authEntity.Tokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*authEntity.Tokens, error)
+	Logout(ctx context.Context, accessUUID string, accessExpiresAt time.Time) error
+	GetSessions(ctx context.Context, userID int64) ([]authEntity.Session, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID int64) error
+	TouchSession(ctx context.Context, sessionID, ip string) error
+	AuthViaTg(ctx context.Context) (*authEntity.TgLoginLink, error)
+	AuthViaTgStatus(ctx context.Context, nonce, secret string, device authEntity.Device) (userID int64, chefID *int64, tokens *authEntity.Tokens, err error)
+}
+
+// sessionTracker отмечает активность сессии из токена
+type sessionTracker interface {
+	TouchSession(ctx context.Context, sessionID, ip string) error
 }
 
 type jwtUsecase interface {
 	ValidateJWT(token string) (map[string]interface{}, error)
+	JWKS() authEntity.JWKS
 }
 
 type usersUsecase interface {
@@ -69,7 +86,7 @@ type dishesUsecase interface {
 	GetNutritionByDishID(ctx context.Context, dishID int64) (*dishesEntity.Nutrition, error)
 	GetDishSizesByDishID(ctx context.Context, dishID int64) ([]dishesEntity.Size, error)
 	GetIngredientsByDishID(ctx context.Context, dishID int64) ([]dishesEntity.Ingredient, error)
-	GetMinimalPriceByDishID(ctx context.Context, dishID int64) (*dishesEntity.Price, error)
+	GetMinimalPriceByDishID(ctx context.Context, dishID int64) (*money.Money, error)
 	GetTopDishes(ctx context.Context, limit int) ([]dishesEntity.Dish, error)
 	SetDishImage(ctx context.Context, dishID int64, image *multipart.FileHeader) (string, error)
 	SetIngredientImage(ctx context.Context, dishID int64, image *multipart.FileHeader) (string, error)
@@ -117,26 +134,30 @@ type cartUsecase interface {
 		addedIngredients []int64,
 		removedIngredients []int64,
 		notes string,
+		replaceCart bool,
 	) (int64, error)
-	RemoveItem(ctx context.Context, cartItemID int64) error
+	RemoveItem(ctx context.Context, userID, cartItemID int64) error
 	GetCartItems(ctx context.Context, userID int64) ([]cartentity.CartItem, error)
 
 	ClearCart(ctx context.Context, userID int64) error
-	IncrementCartItem(ctx context.Context, cartItemID int64) (newQuantity int32, err error)
-	DecrementCartItem(ctx context.Context, cartItemID int64) (newQuantity int32, err error)
-	GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cartentity.CartItem, error)
+	IncrementCartItem(ctx context.Context, userID, cartItemID int64) (newQuantity int32, err error)
+	DecrementCartItem(ctx context.Context, userID, cartItemID int64) (newQuantity int32, err error)
 }
 
 type orderUsecase interface {
-	CreateOrder(ctx context.Context, userID int64, leaveByTheDoor, callBeforehand bool) (int64, error)
+	CreateOrder(ctx context.Context, userID, deliverySlotID int64, leaveByTheDoor, callBeforehand bool) (int64, error)
+	HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error
 	GetOrdersByShiftID(ctx context.Context, shiftID int64) ([]orders.Order, error)
 	GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cartentity.CartItem, error)
-	SetStatus(ctx context.Context, orderID int64, status int32) error
-	Accept(ctx context.Context, orderID int64) error
-	CallDelivery(ctx context.Context, orderID int64) error
-	PickUp(ctx context.Context, orderID int64) error
-	Deliver(ctx context.Context, orderID int64) error
-	Reject(ctx context.Context, orderID int64) error
+	SetStatus(ctx context.Context, orderID int64, status int32, actor orders.Actor) error
+	Accept(ctx context.Context, orderID int64, actor orders.Actor) error
+	CallDelivery(ctx context.Context, orderID int64, actor orders.Actor) error
+	PickUp(ctx context.Context, orderID int64, actor orders.Actor) error
+	Deliver(ctx context.Context, orderID int64, actor orders.Actor) error
+	Reject(ctx context.Context, orderID int64, actor orders.Actor, reason string) error
+	Cancel(ctx context.Context, orderID int64, actor orders.Actor, reason string) error
+	RemoveItem(ctx context.Context, orderID, orderedItemID int64, actor orders.Actor, reason string) error
+	GetStatusHistory(ctx context.Context, orderID int64) ([]orders.StatusChange, error)
 	GetOrderedDishesAndChefsByUserID(ctx context.Context, userID int64) ([]dishesEntity.Dish, []chefEntity.Chef, error)
 	GetOrderByID(ctx context.Context, orderID int64) (*orders.Order, error)
 	GetOrdersByUserID(ctx context.Context, userID int64) ([]orders.OrderProfile, error)
@@ -164,3 +185,31 @@ type favoritesUsecase interface {
 	AddFavoriteDish(ctx context.Context, userID, dishID int64) error
 	RemoveFavoriteDish(ctx context.Context, userID, dishID int64) error
 }
+
+type deliveryUsecase interface {
+	CalculateFee(ctx context.Context, chefID int64, address *geoEntity.Address, cartTotal money.Money) (*deliveryEntity.Quote, error)
+	GetAvailableSlots(ctx context.Context, chefID int64) ([]deliveryEntity.Slot, error)
+	GetWorkingHours(ctx context.Context, chefID int64) ([]deliveryEntity.WorkingHours, error)
+	SetWorkingHours(ctx context.Context, chefID int64, hours []deliveryEntity.WorkingHours) error
+}
+
+type paymentsUsecase interface {
+	GetMethods() []paymentsEntity.Method
+}
+
+type idempotencyStore interface {
+	SetNX(key string, value string, ttl time.Duration) (bool, error)
+	Get(key string) (string, error)
+	Set(key string, value string, ttl time.Duration) error
+	Delete(key string) error
+}
+
+// rateLimiter считает запросы по ключу в скользящем окне
+type rateLimiter interface {
+	AllowSlidingWindow(key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+// readinessProbe сообщает, принимает ли реплика трафик
+type readinessProbe interface {
+	Ready() bool
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 
 	chefEntity "domashka-backend/internal/entity/chefs"
 	dishEntity "domashka-backend/internal/entity/dishes"
+	"domashka-backend/internal/entity/money"
 	"domashka-backend/internal/utils/pointers"
 )
 
@@ -82,9 +84,19 @@ type weight struct {
 }
 
 type Price struct {
-	Value    float32 `json:"value"`
-	Currency string  `json:"currency"`
+	Value    json.Number `json:"value"`
+	Currency string      `json:"currency"`
 }
+
+func newPrice(m money.Money) Price {
+	return Price{Value: m.Number(), Currency: string(m.Currency)}
+}
+
+// toMoney разбирает цену из запроса без потери точности
+func (p Price) toMoney() (money.Money, error) {
+	return money.Parse(p.Value.String(), money.Currency(p.Currency))
+}
+
 type ingredient struct {
 	ID          int64  `json:"id"`
 	Name        string `json:"name"`
@@ -249,7 +261,7 @@ func (h *dishesHandler) getDishDetail(c *gin.Context) {
 			ID:       relatedDish.ID,
 			Name:     relatedDish.Name,
 			ImageURL: relatedDish.ImageURL,
-			Price:    fmt.Sprintf("от %.0fр", price.Value),
+			Price:    price.FormatFrom(),
 			Rating:   pointers.From(relatedDish.Rating),
 		})
 	}
@@ -263,10 +275,7 @@ func (h *dishesHandler) getDishDetail(c *gin.Context) {
 				Value: val.WeightValue,
 				Unit:  val.WeightUnit,
 			},
-			Price: Price{
-				Value:    val.PriceValue,
-				Currency: val.PriceCurrency,
-			},
+			Price:        newPrice(val.Price),
 			Availability: true,
 		})
 	}
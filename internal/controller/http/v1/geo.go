@@ -1,10 +1,12 @@
 package v1
 
 import (
+	"domashka-backend/internal/entity/auth"
 	geoEntity "domashka-backend/internal/entity/geo"
 	"domashka-backend/internal/utils/validation"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"slices"
 	"strconv"
 )
 
@@ -12,30 +14,57 @@ type GeoHandler struct {
 	geoUsecase geoUsecase
 }
 
-func RegisterGeoHandlers(router *gin.RouterGroup, g geoUsecase) {
+// RegisterGeoHandlers регистрирует маршруты адресов. Без токена можно узнать только адрес повара,
+// адреса клиентов читает и меняет только их владелец из токена или админ.
+func RegisterGeoHandlers(public, authorized *gin.RouterGroup, g geoUsecase) {
 
 	h := GeoHandler{geoUsecase: g}
 
-	geoGroup := router.Group("/geo")
+	public.GET("/geo/chefs/:chef_id/address", h.GetChefAddress)
+
+	clientGroup := authorized.Group("/geo/clients/:client_id", requireSelf("client_id"))
 	{
-		geoGroup.POST("/clients/:client_id/addresses/push/:address_id", h.PushClientAddress)
-		geoGroup.GET("/clients/:client_id/addresses/:address_id", h.GetAddressDetails)
-		geoGroup.POST("/clients/:client_id/addresses", h.AddClientAddress)
-		geoGroup.POST("/clients/:client_id/addresses/:address_id", h.UpdateClientAddress)
-		geoGroup.GET("/clients/:client_id/addresses", h.GetClientAddresses)
-		geoGroup.GET("/chefs/:chef_id/address", h.GetChefAddress)
-		geoGroup.POST("/chefs/:chef_id/address", h.UpdateOrPostChefAddress)
-		geoGroup.GET("/search/chefs-near-client-address/:client_address_id", h.GetChefsAddrByRange)
-		geoGroup.GET("/search/clients-near-chef/:chef_id", h.GetClientsAddrByRange)
+		clientGroup.GET("/addresses/:address_id", h.GetAddressDetails)
+		clientGroup.GET("/addresses", h.GetClientAddresses)
+		clientGroup.POST("/addresses/push/:address_id", h.PushClientAddress)
+		clientGroup.POST("/addresses", h.AddClientAddress)
+		clientGroup.POST("/addresses/:address_id", h.UpdateClientAddress)
+	}
+	authorized.POST("/geo/chefs/:chef_id/address", requireSelfChef("chef_id"), h.UpdateOrPostChefAddress)
+	authorized.GET("/geo/search/chefs-near-client-address/:client_address_id", h.GetChefsAddrByRange)
+	authorized.GET("/geo/search/clients-near-chef/:chef_id", requireSelfChef("chef_id"), h.GetClientsAddrByRange)
+}
+
+// clientHasAddress проверяет, что адрес addressID принадлежит клиенту clientID
+func (h *GeoHandler) clientHasAddress(ctx *gin.Context, clientID int, addressID int64) (bool, error) {
+	addresses, err := h.geoUsecase.GetClientAddresses(ctx, clientID)
+	if err != nil {
+		return false, err
 	}
+	return slices.ContainsFunc(addresses, func(a geoEntity.Address) bool { return a.ID == addressID }), nil
 }
 
 func (h *GeoHandler) PushClientAddress(ctx *gin.Context) {
+	clientID, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid client_id"})
+		return
+	}
 	addressID, err := strconv.ParseInt(ctx.Param("address_id"), 10, 64)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid address_id"})
 		return
 	}
+	// Поднять можно только свой адрес
+	owns, err := h.clientHasAddress(ctx, clientID, addressID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !owns {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Address not found"})
+		return
+	}
 	err = h.geoUsecase.PushClientAddress(ctx, addressID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
@@ -45,11 +74,25 @@ func (h *GeoHandler) PushClientAddress(ctx *gin.Context) {
 }
 
 func (h *GeoHandler) GetAddressDetails(ctx *gin.Context) {
+	clientID, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid client_id"})
+		return
+	}
 	addressID, err := strconv.ParseInt(ctx.Param("address_id"), 10, 64)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid address_id"})
 		return
 	}
+	owns, err := h.clientHasAddress(ctx, clientID, addressID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !owns {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Address not found"})
+		return
+	}
 	address, err := h.geoUsecase.GetAddressByID(ctx, addressID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
@@ -199,7 +242,7 @@ func (h *GeoHandler) UpdateOrPostChefAddress(ctx *gin.Context) {
 
 func (h *GeoHandler) GetChefsAddrByRange(ctx *gin.Context) {
 	clientAddressID := ctx.Param("client_address_id")
-	radius := ctx.Param("radius")
+	radius := ctx.Query("radius")
 	clientAddressIDInt, err := strconv.Atoi(clientAddressID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid client_address_id"})
@@ -212,6 +255,24 @@ func (h *GeoHandler) GetChefsAddrByRange(ctx *gin.Context) {
 		return
 	}
 
+	// Искать можно только от своего адреса; админ — от любого
+	if ctx.GetString("role") != auth.RoleAdmin {
+		userID, err := strconv.Atoi(ctx.GetString("user_id"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, forbiddenResponse)
+			return
+		}
+		owns, err := h.clientHasAddress(ctx, userID, int64(clientAddressIDInt))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		if !owns {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Address not found"})
+			return
+		}
+	}
+
 	addresses, err := h.geoUsecase.FindChefsNearAddress(ctx, clientAddressIDInt, radiusFloat)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	geoEntity "domashka-backend/internal/entity/geo"
+)
+
+// fakeGeo хранит адреса клиентов; остальные методы geoUsecase тестам не нужны
+type fakeGeo struct {
+	geoUsecase
+	addresses map[int][]geoEntity.Address
+}
+
+func (f fakeGeo) GetClientAddresses(_ context.Context, clientID int) ([]geoEntity.Address, error) {
+	return f.addresses[clientID], nil
+}
+
+func (f fakeGeo) GetAddressByID(_ context.Context, id int64) (*geoEntity.Address, error) {
+	for _, addresses := range f.addresses {
+		for i := range addresses {
+			if addresses[i].ID == id {
+				return &addresses[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (f fakeGeo) FindChefsNearAddress(context.Context, int, float64) ([]geoEntity.Address, error) {
+	return []geoEntity.Address{}, nil
+}
+
+func (f fakeGeo) FindClientsNearAddress(context.Context, int, float64) ([]geoEntity.Address, error) {
+	return []geoEntity.Address{}, nil
+}
+
+func TestGeoHandlers_clientAddresses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	public := r.Group("/v1")
+	authorized := r.Group("/v1", AuthMiddleware(testTokens, noopSessions{}), authorizeRoute())
+	// Адрес 100 — клиента 2, адрес 200 — клиента 5
+	RegisterGeoHandlers(public, authorized, fakeGeo{addresses: map[int][]geoEntity.Address{
+		2: {{ID: 100}},
+		5: {{ID: 200}},
+	}})
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "no token", path: "/v1/geo/clients/2/addresses", wantStatus: http.StatusUnauthorized},
+		{name: "own addresses", path: "/v1/geo/clients/2/addresses", token: "client", wantStatus: http.StatusOK},
+		{name: "foreign addresses", path: "/v1/geo/clients/5/addresses", token: "client", wantStatus: http.StatusForbidden},
+		{name: "admin reads any addresses", path: "/v1/geo/clients/5/addresses", token: "admin", wantStatus: http.StatusOK},
+		{name: "own address", path: "/v1/geo/clients/2/addresses/100", token: "client", wantStatus: http.StatusOK},
+		{name: "foreign address under own id", path: "/v1/geo/clients/2/addresses/200", token: "client", wantStatus: http.StatusNotFound},
+		{name: "search from own address", path: "/v1/geo/search/chefs-near-client-address/100?radius=5", token: "client", wantStatus: http.StatusOK},
+		{name: "search from foreign address", path: "/v1/geo/search/chefs-near-client-address/200?radius=5", token: "client", wantStatus: http.StatusNotFound},
+		{name: "admin searches from any address", path: "/v1/geo/search/chefs-near-client-address/200?radius=5", token: "admin", wantStatus: http.StatusOK},
+		{name: "clients near own chef id", path: "/v1/geo/search/clients-near-chef/7?radius=5", token: "chef", wantStatus: http.StatusOK},
+		{name: "clients near another chef", path: "/v1/geo/search/clients-near-chef/8?radius=5", token: "chef", wantStatus: http.StatusForbidden},
+		{name: "client cannot list clients", path: "/v1/geo/search/clients-near-chef/7?radius=5", token: "client", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -23,7 +23,7 @@ type homeHandler struct {
 	reviewsUsecase
 }
 
-func NewHomeHandler(rg *gin.RouterGroup, jwt jwtUsecase, geo geoUsecase, dishes dishesUsecase, chef chefUsecase, order orderUsecase, reviews reviewsUsecase) {
+func NewHomeHandler(rg *gin.RouterGroup, geo geoUsecase, dishes dishesUsecase, chef chefUsecase, order orderUsecase, reviews reviewsUsecase) {
 	hh := homeHandler{
 		geoUsecase:     geo,
 		dishesUsecase:  dishes,
@@ -32,7 +32,8 @@ func NewHomeHandler(rg *gin.RouterGroup, jwt jwtUsecase, geo geoUsecase, dishes
 		reviewsUsecase: reviews,
 	}
 
-	rg.GET("/home", AuthMiddleware(jwt), hh.getHomePage)
+	// rg уже закрыта AuthMiddleware
+	rg.GET("/home", hh.getHomePage)
 }
 
 type ChefSnippet struct {
@@ -119,7 +120,7 @@ func (h *homeHandler) getHomePage(c *gin.Context) {
 			"suggests":        suggests,
 			"name":            dish.Name,
 			"rating":          dish.Rating,
-			"price":           fmt.Sprintf("от %.0fр", minPrice.Value),
+			"price":           minPrice.FormatFrom(),
 		})
 	}
 	response["top_dishes"] = dishes
@@ -200,7 +201,7 @@ func (h *homeHandler) getHomePage(c *gin.Context) {
 				"dish_id":        dish.ID,
 				"dish_image_url": dish.ImageURL,
 				"name":           dish.Name,
-				"price":          fmt.Sprintf("от %.0fр", minPrice.Value),
+				"price":          minPrice.FormatFrom(),
 				"rating":         dish.Rating,
 			})
 		}
@@ -0,0 +1,177 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	// Сколько живёт сохранённый ответ
+	idempotencyTTL = 24 * time.Hour
+	// Сколько держится блокировка на время обработки запроса:
+	// если инстанс упадёт посреди запроса, ключ освободится сам
+	idempotencyLockTTL   = time.Minute
+	idempotencyMaxKeyLen = 255
+
+	idempotencyStateInFlight  = "in_flight"
+	idempotencyStateCompleted = "completed"
+)
+
+type idempotencyRecord struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// idempotencyWriter дублирует тело ответа, чтобы сохранить его в хранилище
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware обрабатывает заголовок Idempotency-Key у POST-запросов.
+// Первый ответ сохраняется и отдаётся повторно на запросы с тем же ключом,
+// пока запрос с этим ключом выполняется — возвращается 409.
+// Ответы 5xx не сохраняются, чтобы клиент мог повторить запрос.
+// Ключ привязан к пользователю из токена, поэтому без AuthMiddleware запрос
+// выполняется как обычно, а ответ не сохраняется.
+func IdempotencyMiddleware(store idempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		userID := c.GetString("user_id")
+		if userID == "" {
+			// Иначе одинаковые ключи разных клиентов отдавали бы чужие ответы
+			c.Next()
+			return
+		}
+		if len(key) > idempotencyMaxKeyLen {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    4005,
+					Message: "Invalid Idempotency-Key.",
+					Details: "Слишком длинный ключ идемпотентности.",
+				},
+			})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    4002,
+					Message: "Invalid request body.",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		fingerprint := hex.EncodeToString(sum[:])
+		storeKey := "idempotency:" + userID + ":" + c.FullPath() + ":" + key
+
+		lock, _ := json.Marshal(idempotencyRecord{State: idempotencyStateInFlight, Fingerprint: fingerprint})
+		acquired, err := store.SetNX(storeKey, string(lock), idempotencyLockTTL)
+		if err != nil {
+			abortIdempotencyInternal(c)
+			return
+		}
+		if !acquired {
+			replayIdempotentResponse(c, store, storeKey, fingerprint)
+			return
+		}
+
+		w := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			_ = store.Delete(storeKey)
+			return
+		}
+		record, _ := json.Marshal(idempotencyRecord{
+			State:       idempotencyStateCompleted,
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        w.body.Bytes(),
+		})
+		_ = store.Set(storeKey, string(record), idempotencyTTL)
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, store idempotencyStore, storeKey, fingerprint string) {
+	raw, err := store.Get(storeKey)
+	if err != nil {
+		abortIdempotencyInternal(c)
+		return
+	}
+	var record idempotencyRecord
+	// Ключ мог истечь между SetNX и Get — тогда считаем, что запрос ещё выполняется
+	if raw == "" || json.Unmarshal([]byte(raw), &record) != nil || record.State == idempotencyStateInFlight {
+		c.AbortWithStatusJSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4095,
+				Message: "Request with this Idempotency-Key is in progress.",
+				Details: "Запрос с таким ключом идемпотентности ещё выполняется.",
+			},
+		})
+		return
+	}
+	if record.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4221,
+				Message: "Idempotency-Key reused with another request.",
+				Details: "Ключ идемпотентности уже использован для другого запроса.",
+			},
+		})
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.Status, record.ContentType, record.Body)
+	c.Abort()
+}
+
+func abortIdempotencyInternal(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse{
+		Status: "error",
+		Err: errorMessage{
+			Code:    5001,
+			Message: "Internal server error.",
+			Details: "Не удалось проверить ключ идемпотентности.",
+		},
+	})
+}
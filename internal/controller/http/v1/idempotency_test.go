@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryIdempotencyStore — хранилище ключей в памяти для тестов, TTL не учитывается
+type memoryIdempotencyStore struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{data: map[string]string{}}
+}
+
+func (s *memoryIdempotencyStore) SetNX(key string, value string, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[key]; ok {
+		return false, nil
+	}
+	s.data[key] = value
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key], nil
+}
+
+func (s *memoryIdempotencyStore) Set(key string, value string, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+type idempotencyTestRequest struct {
+	userID string
+	key    string
+	body   string
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		// status — что отвечает обработчик на каждый вызов по порядку
+		status     []int
+		requests   []idempotencyTestRequest
+		wantStatus []int
+		wantCalls  int
+		// wantReplayed — у последнего ответа стоит заголовок Idempotent-Replayed
+		wantReplayed bool
+	}{
+		{
+			name:   "replay",
+			status: []int{http.StatusOK},
+			requests: []idempotencyTestRequest{
+				{userID: "1", key: "k", body: `{"a":1}`},
+				{userID: "1", key: "k", body: `{"a":1}`},
+			},
+			wantStatus:   []int{http.StatusOK, http.StatusOK},
+			wantCalls:    1,
+			wantReplayed: true,
+		},
+		{
+			name:   "fingerprint mismatch",
+			status: []int{http.StatusOK},
+			requests: []idempotencyTestRequest{
+				{userID: "1", key: "k", body: `{"a":1}`},
+				{userID: "1", key: "k", body: `{"a":2}`},
+			},
+			wantStatus: []int{http.StatusOK, http.StatusUnprocessableEntity},
+			wantCalls:  1,
+		},
+		{
+			name:   "5xx is not stored",
+			status: []int{http.StatusInternalServerError, http.StatusOK},
+			requests: []idempotencyTestRequest{
+				{userID: "1", key: "k", body: `{"a":1}`},
+				{userID: "1", key: "k", body: `{"a":1}`},
+			},
+			wantStatus: []int{http.StatusInternalServerError, http.StatusOK},
+			wantCalls:  2,
+		},
+		{
+			name:   "keys of different users do not collide",
+			status: []int{http.StatusOK, http.StatusCreated},
+			requests: []idempotencyTestRequest{
+				{userID: "1", key: "k", body: `{"a":1}`},
+				{userID: "2", key: "k", body: `{"a":1}`},
+			},
+			wantStatus: []int{http.StatusOK, http.StatusCreated},
+			wantCalls:  2,
+		},
+		{
+			name:   "no user is not stored",
+			status: []int{http.StatusOK, http.StatusCreated},
+			requests: []idempotencyTestRequest{
+				{key: "k", body: `{"a":1}`},
+				{key: "k", body: `{"a":1}`},
+			},
+			wantStatus: []int{http.StatusOK, http.StatusCreated},
+			wantCalls:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			engine := gin.New()
+			engine.Use(func(c *gin.Context) {
+				if userID := c.GetHeader("X-Test-User"); userID != "" {
+					c.Set("user_id", userID)
+				}
+			})
+			engine.Use(IdempotencyMiddleware(newMemoryIdempotencyStore()))
+			engine.POST("/test", func(c *gin.Context) {
+				status := tt.status[calls]
+				calls++
+				c.JSON(status, gin.H{"call": calls})
+			})
+
+			var last *httptest.ResponseRecorder
+			for i, r := range tt.requests {
+				req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(r.body))
+				req.Header.Set(idempotencyHeader, r.key)
+				if r.userID != "" {
+					req.Header.Set("X-Test-User", r.userID)
+				}
+				last = httptest.NewRecorder()
+				engine.ServeHTTP(last, req)
+				if last.Code != tt.wantStatus[i] {
+					t.Errorf("request %d: status = %d, want %d", i, last.Code, tt.wantStatus[i])
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if got := last.Header().Get("Idempotent-Replayed") == "true"; got != tt.wantReplayed {
+				t.Errorf("Idempotent-Replayed = %v, want %v", got, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_InFlight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set("user_id", "1") })
+	engine.Use(IdempotencyMiddleware(newMemoryIdempotencyStore()))
+	engine.POST("/test", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{}`))
+		req.Header.Set(idempotencyHeader, "k")
+		return req
+	}
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		engine.ServeHTTP(first, newRequest())
+		close(done)
+	}()
+	<-started
+
+	second := httptest.NewRecorder()
+	engine.ServeHTTP(second, newRequest())
+	close(release)
+	<-done
+
+	if second.Code != http.StatusConflict {
+		t.Errorf("in-flight status = %d, want %d", second.Code, http.StatusConflict)
+	}
+	if first.Code != http.StatusOK {
+		t.Errorf("first status = %d, want %d", first.Code, http.StatusOK)
+	}
+}
@@ -1,13 +1,14 @@
 package v1
 
 import (
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwt jwtUsecase) gin.HandlerFunc {
+func AuthMiddleware(jwt jwtUsecase, sessions sessionTracker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
@@ -33,7 +34,28 @@ func AuthMiddleware(jwt jwtUsecase) gin.HandlerFunc {
 		ctx.Set("claims", claims)
 		ctx.Set("user_id", claims["user_id"])
 		ctx.Set("role", claims["role"])
+		// chef_id есть только у поваров; в JSON число приходит как float64
+		if chefID, ok := claims["chef_id"].(float64); ok {
+			ctx.Set("chef_id", int64(chefID))
+		}
+		// Токены, выданные до появления сессий, claim sid не содержат
+		if sessionID, ok := claims["sid"].(string); ok {
+			ctx.Set("session_id", sessionID)
+			if err := sessions.TouchSession(ctx.Request.Context(), sessionID, ctx.ClientIP()); err != nil {
+				log.Printf("auth: touch session %s: %v", sessionID, err)
+			}
+		}
 
 		ctx.Next()
 	}
 }
+
+// chefIDFromContext возвращает chef_id из токена; false, если пользователь не повар
+func chefIDFromContext(c *gin.Context) (int64, bool) {
+	v, ok := c.Get("chef_id")
+	if !ok {
+		return 0, false
+	}
+	chefID, ok := v.(int64)
+	return chefID, ok
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	notifEntity "domashka-backend/internal/entity/notifications"
 	"domashka-backend/internal/utils/validation"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
@@ -183,6 +184,10 @@ func (h *NotificationHandler) ResendNotification(c *gin.Context) {
 	err = h.notificationUsecase.ResendNotification(c, idInt)
 	if err != nil {
 		log.Printf("DEBUG: Ошибка повторной отправки уведомления с id=%d: %v", idInt, err)
+		if errors.Is(err, notifEntity.ErrNotResendable) {
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
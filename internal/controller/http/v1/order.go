@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,6 +11,11 @@ import (
 	"github.com/gin-gonic/gin"
 
 	cartentity "domashka-backend/internal/entity/cart"
+	deliveryentity "domashka-backend/internal/entity/delivery"
+	geoEntity "domashka-backend/internal/entity/geo"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/orders"
+	paymentsEntity "domashka-backend/internal/entity/payments"
 	"domashka-backend/internal/utils/pointers"
 )
 
@@ -18,6 +25,8 @@ type orderHandler struct {
 	orderUsecase  orderUsecase
 	shiftsUsecase shiftsUsecase
 	chefUsecase   chefUsecase
+	delivery      deliveryUsecase
+	payments      paymentsUsecase
 }
 
 func RegisterOrderHandlers(
@@ -27,6 +36,8 @@ func RegisterOrderHandlers(
 	orderUsecase orderUsecase,
 	shiftUsecase shiftsUsecase,
 	chefUsecase chefUsecase,
+	delivery deliveryUsecase,
+	payments paymentsUsecase,
 ) {
 	c := orderHandler{
 		geoUsecase:    geoUsecase,
@@ -34,20 +45,56 @@ func RegisterOrderHandlers(
 		orderUsecase:  orderUsecase,
 		shiftsUsecase: shiftUsecase,
 		chefUsecase:   chefUsecase,
+		delivery:      delivery,
+		payments:      payments,
 	}
 
+	// Пользователь и повар берутся из токена; действия над заказом проверяются политикой владения
+	policy := orderPolicy{orderUsecase: orderUsecase}
+
 	rg.GET("/chef/home", c.chefMain)
 	rg = rg.Group("/order")
 	rg.GET("/details_form", c.GetDetailsForm)
 	rg.GET("/final_form", c.GetFinalForm)
 	rg.POST("/create", c.createOrder)
 	rg.GET("/")
-	rg.POST("/accept", c.accept)
-	rg.POST("/reject", c.reject)
-	rg.POST("/call_delivery", c.callDelivery)
-	rg.POST("/pickup", c.pickUp)
-	rg.POST("/deliver", c.deliver)
-	rg.GET("/status", c.getStatus)
+	rg.POST("/accept", policy.requireChef(), c.accept)
+	rg.POST("/reject", policy.requireChef(), c.reject)
+	rg.POST("/remove_item", policy.requireChef(), c.removeItem)
+	rg.POST("/cancel", policy.requireClient(), c.cancel)
+	rg.POST("/call_delivery", policy.requireChef(), c.callDelivery)
+	rg.POST("/pickup", policy.requireChef(), c.pickUp)
+	rg.POST("/deliver", policy.requireChef(), c.deliver)
+	rg.GET("/status", policy.requireParticipant(), c.getStatus)
+}
+
+var deliveryTooFarResponse = errorResponse{
+	Status: "error",
+	Err: errorMessage{
+		Code:    4222,
+		Message: "Delivery address is too far from chef.",
+		Details: "Повар не доставляет по этому адресу. Выберите другой адрес.",
+	},
+}
+
+// calculateDeliveryFee считает доставку для корзины.
+// Пока нет адреса или корзина пуста, доставка не считается.
+func calculateDeliveryFee(
+	ctx context.Context,
+	delivery deliveryUsecase,
+	address *geoEntity.Address,
+	cartItems []cartentity.CartItem,
+	cartTotal money.Money,
+) (money.Money, error) {
+	chefIDs := cartentity.GetChefIDs(cartItems)
+	if address == nil || len(chefIDs) == 0 {
+		return money.New(0, money.RUB), nil
+	}
+	quote, err := delivery.CalculateFee(ctx, chefIDs[0], address, cartTotal)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return quote.Fee, nil
 }
 
 type GetOrderDetailsFormData struct {
@@ -65,7 +112,7 @@ type PaymentOption struct {
 
 func (h *orderHandler) GetDetailsForm(c *gin.Context) {
 	ctx := c.Request.Context()
-	userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, errorResponse{
 			Status: "error",
@@ -101,7 +148,34 @@ func (h *orderHandler) GetDetailsForm(c *gin.Context) {
 		})
 		return
 	}
-	totalCartPrice := cartentity.GetTotalCartPrice(cartItems)
+	totalCartPrice, err := cartentity.GetTotalCartPrice(cartItems)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4003,
+				Message: "Internal server error",
+				Details: "Не удалось посчитать стоимость корзины.",
+			},
+		})
+		return
+	}
+	deliveryFee, err := calculateDeliveryFee(ctx, h.delivery, address, cartItems, totalCartPrice)
+	if errors.Is(err, deliveryentity.ErrTooFar) {
+		c.JSON(http.StatusUnprocessableEntity, deliveryTooFarResponse)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4003,
+				Message: "Internal server error",
+				Details: "Не удалось посчитать стоимость доставки.",
+			},
+		})
+		return
+	}
 	var addressResp *Address
 	if address != nil {
 		addressResp = &Address{
@@ -109,22 +183,21 @@ func (h *orderHandler) GetDetailsForm(c *gin.Context) {
 			Title: pointers.From(address.Address),
 		}
 	}
+	paymentOptions := make([]PaymentOption, 0)
+	for _, method := range h.payments.GetMethods() {
+		paymentOptions = append(paymentOptions, PaymentOption{
+			ID:    method.ID,
+			Type:  method.Type,
+			Title: method.Title,
+		})
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data": GetOrderDetailsFormData{
-			Address: addressResp,
-			TotalPrice: Price{
-				Value:    totalCartPrice.Value,
-				Currency: totalCartPrice.Currency,
-			},
-			DeliveryCost: Price{Value: 169, Currency: totalCartPrice.Currency},
-			PaymentOptions: []PaymentOption{
-				{
-					ID:    1234,
-					Type:  "MASTERCARD",
-					Title: "1234",
-				},
-			},
+			Address:        addressResp,
+			TotalPrice:     newPrice(totalCartPrice),
+			DeliveryCost:   newPrice(deliveryFee),
+			PaymentOptions: paymentOptions,
 		},
 	})
 }
@@ -143,6 +216,7 @@ type DeliveryOption struct {
 }
 
 type TimeOption struct {
+	SlotID        int64  `json:"slot_id"`
 	Title         string `json:"title"`
 	IntervalStart string `json:"interval_start"`
 	IntervalEnd   string `json:"interval_end"`
@@ -150,7 +224,7 @@ type TimeOption struct {
 
 func (h *orderHandler) GetFinalForm(c *gin.Context) {
 	ctx := c.Request.Context()
-	userId, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
+	userId, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
 	if err != nil || userId == 400 {
 		c.JSON(http.StatusBadRequest, errorResponse{
 			Status: "error",
@@ -184,8 +258,18 @@ func (h *orderHandler) GetFinalForm(c *gin.Context) {
 		})
 		return
 	}
-	// TODO: временные интервалы брать из отдельной таблицы
-
+	address, err := h.geoUsecase.GetLastUpdatedClientAddress(ctx, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4003,
+				Message: "Internal server error",
+				Details: "Ошибка на сервере. Попробуйте позже.",
+			},
+		})
+		return
+	}
 	cartItems, err := h.cartUsecase.GetCartItems(ctx, userId)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse{
@@ -200,83 +284,121 @@ func (h *orderHandler) GetFinalForm(c *gin.Context) {
 	}
 	dishes := make([]Dish, 0)
 	for _, item := range cartItems {
+		itemPrice, err := item.GetTotalPrice()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    4003,
+					Message: "Internal server error",
+					Details: "Не удалось посчитать стоимость корзины.",
+				},
+			})
+			return
+		}
 		dish := Dish{
 			DishID:     item.Dish.ID,
 			CartItemID: pointers.To(item.ID),
 			Title:      item.Dish.Name,
 			Details:    item.GetDetailsString(),
-			Price: &Price{
-				Value:    item.Size.PriceValue,
-				Currency: item.Size.PriceCurrency,
-			},
-			Quantity: item.Quantity,
-			TotalPrice: Price{
-				Value:    item.GetTotalPrice().Value,
-				Currency: item.GetTotalPrice().Currency,
-			},
-			ImageURL: item.Dish.ImageURL,
+			Price:      pointers.To(newPrice(item.Size.Price)),
+			Quantity:   item.Quantity,
+			TotalPrice: newPrice(itemPrice),
+			ImageURL:   item.Dish.ImageURL,
 		}
 		dishes = append(dishes, dish)
 	}
 
-	totalPrice := cartentity.GetTotalCartPrice(cartItems)
+	totalPrice, err := cartentity.GetTotalCartPrice(cartItems)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4003,
+				Message: "Internal server error",
+				Details: "Не удалось посчитать стоимость корзины.",
+			},
+		})
+		return
+	}
+	deliveryFee, err := calculateDeliveryFee(ctx, h.delivery, address, cartItems, totalPrice)
+	if errors.Is(err, deliveryentity.ErrTooFar) {
+		c.JSON(http.StatusUnprocessableEntity, deliveryTooFarResponse)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4003,
+				Message: "Internal server error",
+				Details: "Не удалось посчитать стоимость доставки.",
+			},
+		})
+		return
+	}
+	deliveryOptions := make([]DeliveryOption, 0)
+	if chefIDs := cartentity.GetChefIDs(cartItems); len(chefIDs) > 0 {
+		slots, err := h.delivery.GetAvailableSlots(ctx, chefIDs[0])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    4003,
+					Message: "Internal server error",
+					Details: "Не удалось получить интервалы доставки.",
+				},
+			})
+			return
+		}
+		deliveryOptions = newDeliveryOptions(slots, time.Now())
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data": GetOrderFinalFormData{
-			Dishes: dishes,
-			DeliveryOptions: []DeliveryOption{
-				{
-					Title: "Сегодня",
-					Date:  time.Now().Format("2006-01-02"),
-					TimeOptions: []TimeOption{
-						{
-							Title:         "30-40 минут",
-							IntervalStart: time.Now().Add(time.Minute * 30).Format("2006-01-02T15:04:05"),
-							IntervalEnd:   time.Now().Add(time.Minute * 40).Format("2006-01-02T15:04:05"),
-						},
-						{
-							Title:         "16:30-17:30",
-							IntervalStart: time.Now().Add(time.Minute * 30).Format("2006-01-02T15:04:05"),
-							IntervalEnd:   time.Now().Add(time.Minute * 40).Format("2006-01-02T15:04:05"),
-						},
-						{
-							Title:         "17:30-18:30",
-							IntervalStart: time.Now().Add(time.Minute * 30).Format("2006-01-02T15:04:05"),
-							IntervalEnd:   time.Now().Add(time.Minute * 40).Format("2006-01-02T15:04:05"),
-						},
-					},
-				},
-				{
-					Title: "Завтра",
-					Date:  time.Now().Add(time.Hour * 24).Format("2006-01-02"),
-					TimeOptions: []TimeOption{
-						{
-							Title:         "16:30-17:30",
-							IntervalStart: time.Now().Add(time.Minute * 30).Format("2006-01-02T15:04:05"),
-							IntervalEnd:   time.Now().Add(time.Minute * 40).Format("2006-01-02T15:04:05"),
-						},
-						{
-							Title:         "17:30-18:30",
-							IntervalStart: time.Now().Add(time.Minute * 30).Format("2006-01-02T15:04:05"),
-							IntervalEnd:   time.Now().Add(time.Minute * 40).Format("2006-01-02T15:04:05"),
-						},
-					},
-				},
-			},
-			Total: Price{
-				Value:    totalPrice.Value,
-				Currency: totalPrice.Currency,
-			},
-			DeliveryCost: Price{
-				Value:    169.0,
-				Currency: "RUB",
-			},
+			Dishes:          dishes,
+			DeliveryOptions: deliveryOptions,
+			Total:           newPrice(totalPrice),
+			DeliveryCost:    newPrice(deliveryFee),
 		},
 	})
 }
 
+// newDeliveryOptions группирует слоты доставки по дням
+func newDeliveryOptions(slots []deliveryentity.Slot, now time.Time) []DeliveryOption {
+	options := make([]DeliveryOption, 0)
+	for _, slot := range slots {
+		date := slot.StartsAt.Format("2006-01-02")
+		if len(options) == 0 || options[len(options)-1].Date != date {
+			options = append(options, DeliveryOption{
+				Title:       deliveryDayTitle(slot.StartsAt, now.In(slot.StartsAt.Location())),
+				Date:        date,
+				TimeOptions: make([]TimeOption, 0),
+			})
+		}
+		day := &options[len(options)-1]
+		day.TimeOptions = append(day.TimeOptions, TimeOption{
+			SlotID:        slot.ID,
+			Title:         slot.StartsAt.Format("15:04") + "-" + slot.EndsAt.Format("15:04"),
+			IntervalStart: slot.StartsAt.Format("2006-01-02T15:04:05"),
+			IntervalEnd:   slot.EndsAt.Format("2006-01-02T15:04:05"),
+		})
+	}
+	return options
+}
+
+func deliveryDayTitle(day, now time.Time) string {
+	switch day.Format("2006-01-02") {
+	case now.Format("2006-01-02"):
+		return "Сегодня"
+	case now.AddDate(0, 0, 1).Format("2006-01-02"):
+		return "Завтра"
+	}
+	return day.Format("02.01")
+}
+
 type CreateOrderRequest struct {
-	UserID         int64 `json:"user_id"`
+	DeliverySlotID int64 `json:"delivery_slot_id"`
 	LeaveByTheDoor bool  `json:"leave_by_the_door"`
 	CallBeforehand bool  `json:"call_beforehand"`
 }
@@ -295,7 +417,82 @@ func (h *orderHandler) createOrder(c *gin.Context) {
 		})
 		return
 	}
-	orderID, err := h.orderUsecase.CreateOrder(ctx, req.UserID, req.LeaveByTheDoor, req.CallBeforehand)
+	// Заказ всегда оформляется на пользователя из токена
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4002,
+				Message: "Invalid user ID",
+				Details: "Передан некорректный ID пользователя.",
+			},
+		})
+		return
+	}
+	orderID, err := h.orderUsecase.CreateOrder(ctx, userID, req.DeliverySlotID, req.LeaveByTheDoor, req.CallBeforehand)
+	if errors.Is(err, cartentity.ErrMultipleChefs) {
+		c.JSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4094,
+				Message: "Cart contains dishes from several chefs.",
+				Details: "Заказ можно оформить только у одного повара. Оставьте в корзине блюда одного повара.",
+			},
+		})
+		return
+	}
+	if errors.Is(err, deliveryentity.ErrTooFar) {
+		c.JSON(http.StatusUnprocessableEntity, deliveryTooFarResponse)
+		return
+	}
+	if errors.Is(err, paymentsEntity.ErrDeclined) {
+		c.JSON(http.StatusPaymentRequired, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4021,
+				Message: "Payment declined.",
+				Details: "Оплата не прошла. Корзина сохранена — попробуйте ещё раз или выберите другую карту.",
+			},
+		})
+		return
+	}
+	// Слот можно не передавать, только пока повар не задал рабочие часы
+	if errors.Is(err, deliveryentity.ErrSlotRequired) {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4006,
+				Message: "Delivery slot is required.",
+				Details: "Выберите время доставки.",
+			},
+		})
+		return
+	}
+	if errors.Is(err, deliveryentity.ErrSlotUnavailable) {
+		c.JSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4096,
+				Message: "Delivery slot is unavailable.",
+				Details: "На это время доставка уже недоступна. Выберите другой интервал.",
+			},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+	// Пока оплата не подтверждена, заказ остаётся в статусе ожидания оплаты
+	order, err := h.orderUsecase.GetOrderByID(ctx, orderID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse{
 			Status: "error",
@@ -310,23 +507,17 @@ func (h *orderHandler) createOrder(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data": gin.H{
-			"order_id": orderID,
+			"order_id":     orderID,
+			"order_status": order.Status,
 		},
 	})
 }
 
 func (h *orderHandler) chefMain(c *gin.Context) {
 	ctx := c.Request.Context()
-	chefID, err := strconv.ParseInt(c.Query("chef_id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse{
-			Status: "error",
-			Err: errorMessage{
-				Code:    4002,
-				Message: "Invalid user ID",
-				Details: "Передан некорректный ID пользователя.",
-			},
-		})
+	chefID, ok := chefIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, forbiddenResponse)
 		return
 	}
 	response := map[string]interface{}{}
@@ -353,7 +544,7 @@ func (h *orderHandler) chefMain(c *gin.Context) {
 	response["shift_id"] = activeShift.ID
 	response["is_active"] = true
 	response["elapsed"] = fmt.Sprintf("%.0f мин", time.Since(activeShift.CreatedAt).Minutes())
-	response["total_profit"] = fmt.Sprintf("%.0f", activeShift.TotalProfit)
+	response["total_profit"] = activeShift.TotalProfit.Major()
 	// get orders by shift id
 	o, err := h.orderUsecase.GetOrdersByShiftID(ctx, activeShift.ID)
 	if err != nil {
@@ -372,7 +563,7 @@ func (h *orderHandler) chefMain(c *gin.Context) {
 	type kek struct {
 		orderID   int64
 		items     []cartentity.CartItem
-		totalCost float32
+		totalCost money.Money
 		status    int32
 	}
 	orderCartItemsMap := map[int64]kek{}
@@ -389,10 +580,22 @@ func (h *orderHandler) chefMain(c *gin.Context) {
 			})
 			return
 		}
+		totalCost, err := cartentity.GetTotalCartPrice(cartItems)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    http.StatusInternalServerError,
+					Message: err.Error(),
+					Details: err.Error(),
+				},
+			})
+			return
+		}
 		orderCartItemsMap[order.ID] = kek{
 			orderID:   order.ID,
 			items:     cartItems,
-			totalCost: cartentity.GetTotalCartPrice(cartItems).Value,
+			totalCost: totalCost,
 			status:    order.Status,
 		}
 	}
@@ -408,15 +611,16 @@ func (h *orderHandler) chefMain(c *gin.Context) {
 		for _, o := range os {
 			order := map[string]interface{}{}
 			order["order_id"] = o.orderID
-			order["total_cost"] = o.totalCost
+			order["total_cost"] = o.totalCost.Number()
 			dishes := []map[string]interface{}{}
 			for _, item := range o.items {
 				dish := map[string]interface{}{}
+				dish["ordered_item_id"] = item.ID
 				dish["dish_id"] = item.Dish.ID
 				dish["name"] = item.Dish.Name
 				dish["details"] = item.GetDetailsString()
 				dish["image_url"] = item.Dish.ImageURL
-				dish["price"] = item.Size.PriceValue
+				dish["price"] = item.Size.Price.Number()
 				dish["quantity"] = item.Quantity
 				dishes = append(dishes, dish)
 			}
@@ -433,6 +637,84 @@ func (h *orderHandler) chefMain(c *gin.Context) {
 	})
 }
 
+// actorFromContext собирает инициатора действия над заказом из данных авторизации
+func actorFromContext(c *gin.Context, role string) orders.Actor {
+	userID, _ := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	return orders.Actor{UserID: userID, Role: role}
+}
+
+// orderStatusError отвечает клиенту по ошибке смены статуса заказа
+func orderStatusError(c *gin.Context, err error) {
+	var transitionErr *orders.TransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		c.JSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4091,
+				Message: "Invalid status transition",
+				Details: fmt.Sprintf("Нельзя перевести заказ из статуса %d в статус %d.", transitionErr.From, transitionErr.To),
+			},
+		})
+	case errors.Is(err, orders.ErrNotModifiable):
+		c.JSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4097,
+				Message: "Order cannot be modified",
+				Details: "Заказ уже передан в доставку, изменить его нельзя.",
+			},
+		})
+	case errors.Is(err, orders.ErrLastItem):
+		c.JSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4098,
+				Message: "Cannot remove the last item",
+				Details: "Нельзя убрать единственную позицию. Отклоните заказ целиком.",
+			},
+		})
+	case errors.Is(err, orders.ErrItemNotFound):
+		c.JSON(http.StatusNotFound, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4042,
+				Message: "Ordered item not found",
+				Details: "Такой позиции нет в заказе.",
+			},
+		})
+	case errors.Is(err, orders.ErrCancelNotAllowed):
+		c.JSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4099,
+				Message: "Order can no longer be cancelled",
+				Details: "Повар уже готовит заказ, отменить его нельзя.",
+			},
+		})
+	case errors.Is(err, orders.ErrNotOrderOwner):
+		c.JSON(http.StatusForbidden, forbiddenResponse)
+	case errors.Is(err, orders.ErrStatusConflict):
+		c.JSON(http.StatusConflict, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4092,
+				Message: "Order status conflict",
+				Details: "Статус заказа уже изменился. Обновите данные и попробуйте снова.",
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Details: err.Error(),
+			},
+		})
+	}
+}
+
 func (h *orderHandler) accept(c *gin.Context) {
 	ctx := c.Request.Context()
 	orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64)
@@ -447,16 +729,9 @@ func (h *orderHandler) accept(c *gin.Context) {
 		})
 		return
 	}
-	err = h.orderUsecase.Accept(ctx, orderID)
+	err = h.orderUsecase.Accept(ctx, orderID, actorFromContext(c, orders.ActorRoleChef))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse{
-			Status: "error",
-			Err: errorMessage{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-				Details: err.Error(),
-			},
-		})
+		orderStatusError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -478,24 +753,41 @@ func (h *orderHandler) callDelivery(c *gin.Context) {
 		})
 		return
 	}
-	err = h.orderUsecase.CallDelivery(ctx, orderID)
+	err = h.orderUsecase.CallDelivery(ctx, orderID, actorFromContext(c, orders.ActorRoleChef))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse{
+		orderStatusError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+	})
+}
+
+func (h *orderHandler) pickUp(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
 			Status: "error",
 			Err: errorMessage{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-				Details: err.Error(),
+				Code:    4002,
+				Message: "Invalid order ID",
+				Details: "Передан некорректный ID заказа.",
 			},
 		})
 		return
 	}
+	err = h.orderUsecase.PickUp(ctx, orderID, actorFromContext(c, orders.ActorRoleChef))
+	if err != nil {
+		orderStatusError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 	})
 }
 
-func (h *orderHandler) pickUp(c *gin.Context) {
+func (h *orderHandler) deliver(c *gin.Context) {
 	ctx := c.Request.Context()
 	orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64)
 	if err != nil {
@@ -509,24 +801,42 @@ func (h *orderHandler) pickUp(c *gin.Context) {
 		})
 		return
 	}
-	err = h.orderUsecase.PickUp(ctx, orderID)
+	err = h.orderUsecase.Deliver(ctx, orderID, actorFromContext(c, orders.ActorRoleChef))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse{
+		orderStatusError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+	})
+}
+
+func (h *orderHandler) reject(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
 			Status: "error",
 			Err: errorMessage{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-				Details: err.Error(),
+				Code:    4002,
+				Message: "Invalid order ID",
+				Details: "Передан некорректный ID заказа.",
 			},
 		})
 		return
 	}
+	err = h.orderUsecase.Reject(ctx, orderID, actorFromContext(c, orders.ActorRoleChef), c.Query("reason"))
+	if err != nil {
+		orderStatusError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 	})
 }
 
-func (h *orderHandler) deliver(c *gin.Context) {
+// removeItem убирает позицию из заказа (например, закончился ингредиент); деньги за неё возвращаются клиенту
+func (h *orderHandler) removeItem(c *gin.Context) {
 	ctx := c.Request.Context()
 	orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64)
 	if err != nil {
@@ -540,24 +850,30 @@ func (h *orderHandler) deliver(c *gin.Context) {
 		})
 		return
 	}
-	err = h.orderUsecase.Deliver(ctx, orderID)
+	itemID, err := strconv.ParseInt(c.Query("item_id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse{
+		c.JSON(http.StatusBadRequest, errorResponse{
 			Status: "error",
 			Err: errorMessage{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-				Details: err.Error(),
+				Code:    4003,
+				Message: "Invalid item ID",
+				Details: "Передан некорректный ID позиции заказа.",
 			},
 		})
 		return
 	}
+	err = h.orderUsecase.RemoveItem(ctx, orderID, itemID, actorFromContext(c, orders.ActorRoleChef), c.Query("reason"))
+	if err != nil {
+		orderStatusError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 	})
 }
 
-func (h *orderHandler) reject(c *gin.Context) {
+// cancel отменяет заказ по просьбе клиента
+func (h *orderHandler) cancel(c *gin.Context) {
 	ctx := c.Request.Context()
 	orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64)
 	if err != nil {
@@ -571,16 +887,9 @@ func (h *orderHandler) reject(c *gin.Context) {
 		})
 		return
 	}
-	err = h.orderUsecase.Reject(ctx, orderID)
+	err = h.orderUsecase.Cancel(ctx, orderID, actorFromContext(c, orders.ActorRoleClient), c.Query("reason"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse{
-			Status: "error",
-			Err: errorMessage{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-				Details: err.Error(),
-			},
-		})
+		orderStatusError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -588,6 +897,15 @@ func (h *orderHandler) reject(c *gin.Context) {
 	})
 }
 
+type statusChangeResponse struct {
+	FromStatus *int32 `json:"from_status"`
+	ToStatus   int32  `json:"to_status"`
+	ActorID    *int64 `json:"actor_id,omitempty"`
+	ActorRole  string `json:"actor_role"`
+	Reason     string `json:"reason,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
 func (h *orderHandler) getStatus(c *gin.Context) {
 	ctx := c.Request.Context()
 	orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64)
@@ -674,16 +992,42 @@ func (h *orderHandler) getStatus(c *gin.Context) {
 			"quantity":   item.Quantity,
 			"image_url":  item.Dish.ImageURL,
 			"avatar_url": chef.SmallImageURL,
-			"price":      item.Size.PriceValue,
+			"price":      item.Size.Price.Number(),
 			"chef_id":    item.Dish.ChefID,
 		}
 		dishes = append(dishes, dish)
 	}
+
+	// получить историю статусов
+	history, err := h.orderUsecase.GetStatusHistory(ctx, orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+	timeline := make([]statusChangeResponse, 0, len(history))
+	for _, change := range history {
+		timeline = append(timeline, statusChangeResponse{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			ActorID:    change.ActorID,
+			ActorRole:  change.ActorRole,
+			Reason:     change.Reason,
+			CreatedAt:  change.CreatedAt.Format("2006-01-02T15:04:05"),
+		})
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data": gin.H{
-			"dishes": dishes,
-			"status": order.Status,
+			"dishes":  dishes,
+			"status":  order.Status,
+			"history": timeline,
 			"client_address": gin.H{
 				"address":   clientAddress.Address,
 				"longitude": clientAddress.Longitude,
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"domashka-backend/internal/entity/payments"
+)
+
+// paymentSignatureHeader — заголовок с подписью вебхука платёжного провайдера
+const paymentSignatureHeader = "X-Payment-Signature"
+
+type paymentsHandler struct {
+	orderUsecase orderUsecase
+}
+
+func RegisterPaymentHandlers(rg *gin.RouterGroup, orderUsecase orderUsecase) {
+	h := paymentsHandler{orderUsecase: orderUsecase}
+
+	rg = rg.Group("/payments")
+	rg.POST("/webhook", h.webhook)
+}
+
+// webhook принимает уведомления провайдера о смене статуса платежа.
+// Провайдер повторяет уведомление, пока не получит 2xx, поэтому на
+// невалидную подпись и неизвестный платёж отвечаем 4xx, а на сбой — 5xx.
+func (h *paymentsHandler) webhook(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4002,
+				Message: "Invalid request body",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	err = h.orderUsecase.HandlePaymentWebhook(c.Request.Context(), payload, c.GetHeader(paymentSignatureHeader))
+	switch {
+	case errors.Is(err, payments.ErrInvalidSignature):
+		c.JSON(http.StatusUnauthorized, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4011,
+				Message: "Invalid webhook signature",
+				Details: "Подпись уведомления не прошла проверку.",
+			},
+		})
+		return
+	case errors.Is(err, payments.ErrDisabled):
+		c.JSON(http.StatusNotFound, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4045,
+				Message: "Payments disabled",
+				Details: "Приём оплаты выключен.",
+			},
+		})
+		return
+	case errors.Is(err, payments.ErrPaymentNotFound):
+		c.JSON(http.StatusNotFound, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4041,
+				Message: "Payment not found",
+				Details: "Платёж не найден.",
+			},
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    5001,
+				Message: "Internal server error",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
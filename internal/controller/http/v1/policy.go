@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"domashka-backend/internal/entity/auth"
+	"domashka-backend/internal/entity/orders"
+)
+
+// orderPolicy пропускает действие над заказом из order_id, только если заказ
+// принадлежит пользователю из токена. Админ допускается к любому заказу. Работает после AuthMiddleware.
+type orderPolicy struct {
+	orderUsecase orderUsecase
+}
+
+// orderOwner решает, может ли пользователь из токена действовать над заказом
+type orderOwner func(c *gin.Context, order *orders.Order) bool
+
+// isOrderChef — заказ приготовлен поваром из токена
+func isOrderChef(c *gin.Context, order *orders.Order) bool {
+	chefID, ok := chefIDFromContext(c)
+	return ok && order.ChefID == chefID
+}
+
+// isOrderClient — заказ оформлен пользователем из токена
+func isOrderClient(c *gin.Context, order *orders.Order) bool {
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	return err == nil && order.UserID == userID
+}
+
+// requireChef — действия повара: принять, отклонить, передать в доставку
+func (p orderPolicy) requireChef() gin.HandlerFunc {
+	return p.require(isOrderChef)
+}
+
+// requireClient — действия клиента: отменить заказ
+func (p orderPolicy) requireClient() gin.HandlerFunc {
+	return p.require(isOrderClient)
+}
+
+// requireParticipant — просмотр заказа доступен и повару, и клиенту
+func (p orderPolicy) requireParticipant() gin.HandlerFunc {
+	return p.require(func(c *gin.Context, order *orders.Order) bool {
+		return isOrderChef(c, order) || isOrderClient(c, order)
+	})
+}
+
+func (p orderPolicy) require(owns orderOwner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    4002,
+					Message: "Invalid order ID",
+					Details: "Передан некорректный ID заказа.",
+				},
+			})
+			return
+		}
+		order, err := p.orderUsecase.GetOrderByID(c.Request.Context(), orderID)
+		if errors.Is(err, orders.ErrOrderNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    4043,
+					Message: "Order not found",
+					Details: "Заказ не найден.",
+				},
+			})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse{
+				Status: "error",
+				Err: errorMessage{
+					Code:    http.StatusInternalServerError,
+					Message: err.Error(),
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+		if c.GetString("role") != auth.RoleAdmin && !owns(c, order) {
+			c.AbortWithStatusJSON(http.StatusForbidden, forbiddenResponse)
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireSelf пропускает запрос, только если параметр пути param — ID пользователя из токена.
+// Админ может действовать за любого пользователя. Работает после AuthMiddleware.
+func requireSelf(param string) gin.HandlerFunc {
+	return requireOwner(param, func(c *gin.Context) (int64, bool) {
+		userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+		return userID, err == nil
+	})
+}
+
+// requireSelfChef — как requireSelf, но параметр пути сравнивается с chef_id из токена
+func requireSelfChef(param string) gin.HandlerFunc {
+	return requireOwner(param, chefIDFromContext)
+}
+
+func requireOwner(param string, ownerID func(c *gin.Context) (int64, bool)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") == auth.RoleAdmin {
+			c.Next()
+			return
+		}
+		id, err := strconv.ParseInt(c.Param(param), 10, 64)
+		owner, ok := ownerID(c)
+		if err != nil || !ok || id != owner {
+			c.AbortWithStatusJSON(http.StatusForbidden, forbiddenResponse)
+			return
+		}
+		c.Next()
+	}
+}
+
+var forbiddenResponse = errorResponse{
+	Status: "error",
+	Err: errorMessage{
+		Code:    4031,
+		Message: "Forbidden",
+		Details: "Недостаточно прав для этого действия.",
+	},
+}
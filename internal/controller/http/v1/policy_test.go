@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"domashka-backend/internal/entity/orders"
+)
+
+// fakeOrders отдаёт заказы из таблицы; остальные методы orderUsecase политике не нужны
+type fakeOrders struct {
+	orderUsecase
+	orders map[int64]*orders.Order
+	err    error
+}
+
+func (f fakeOrders) GetOrderByID(_ context.Context, orderID int64) (*orders.Order, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, orders.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func newPolicyRouter(orderUsecase orderUsecase) *gin.Engine {
+	policy := orderPolicy{orderUsecase: orderUsecase}
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	r := gin.New()
+	rg := r.Group("/v1/order", AuthMiddleware(testTokens, noopSessions{}))
+	rg.POST("/accept", policy.requireChef(), ok)
+	rg.POST("/cancel", policy.requireClient(), ok)
+	rg.GET("/status", policy.requireParticipant(), ok)
+	return r
+}
+
+func TestOrderPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Заказ 10 оформил клиент 2 у повара 7, заказ 20 — чужой для обоих
+	ordersByID := map[int64]*orders.Order{
+		10: {ID: 10, UserID: 2, ChefID: 7},
+		20: {ID: 20, UserID: 5, ChefID: 8},
+	}
+	tests := []struct {
+		name       string
+		repoErr    error
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "no token", method: http.MethodPost, path: "/v1/order/accept?order_id=10", wantStatus: http.StatusUnauthorized},
+		{name: "invalid order id", method: http.MethodPost, path: "/v1/order/accept?order_id=abc", token: "chef", wantStatus: http.StatusBadRequest},
+		{name: "order not found", method: http.MethodPost, path: "/v1/order/accept?order_id=99", token: "chef", wantStatus: http.StatusNotFound},
+		{name: "repo error", repoErr: errors.New("db is down"), method: http.MethodPost, path: "/v1/order/accept?order_id=10", token: "chef", wantStatus: http.StatusInternalServerError},
+		{name: "chef of the order", method: http.MethodPost, path: "/v1/order/accept?order_id=10", token: "chef", wantStatus: http.StatusOK},
+		{name: "another chef's order", method: http.MethodPost, path: "/v1/order/accept?order_id=20", token: "chef", wantStatus: http.StatusForbidden},
+		{name: "client cannot act as chef", method: http.MethodPost, path: "/v1/order/accept?order_id=10", token: "client", wantStatus: http.StatusForbidden},
+		{name: "client of the order", method: http.MethodPost, path: "/v1/order/cancel?order_id=10", token: "client", wantStatus: http.StatusOK},
+		{name: "another client's order", method: http.MethodPost, path: "/v1/order/cancel?order_id=20", token: "client", wantStatus: http.StatusForbidden},
+		{name: "chef cannot act as client", method: http.MethodPost, path: "/v1/order/cancel?order_id=10", token: "chef", wantStatus: http.StatusForbidden},
+		{name: "participant client", method: http.MethodGet, path: "/v1/order/status?order_id=10", token: "client", wantStatus: http.StatusOK},
+		{name: "participant chef", method: http.MethodGet, path: "/v1/order/status?order_id=10", token: "chef", wantStatus: http.StatusOK},
+		{name: "not a participant", method: http.MethodGet, path: "/v1/order/status?order_id=20", token: "client", wantStatus: http.StatusForbidden},
+		{name: "admin on chef action", method: http.MethodPost, path: "/v1/order/accept?order_id=20", token: "admin", wantStatus: http.StatusOK},
+		{name: "admin on participant action", method: http.MethodGet, path: "/v1/order/status?order_id=20", token: "admin", wantStatus: http.StatusOK},
+		{name: "admin on missing order", method: http.MethodGet, path: "/v1/order/status?order_id=99", token: "admin", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newPolicyRouter(fakeOrders{orders: ordersByID, err: tt.repoErr})
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRequireSelf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r := gin.New()
+	authorized := r.Group("/v1", AuthMiddleware(testTokens, noopSessions{}))
+	authorized.POST("/users/:id/update", requireSelf("id"), ok)
+	authorized.POST("/geo/chefs/:chef_id/address", requireSelfChef("chef_id"), ok)
+
+	// В testTokens клиент — пользователь 2, повар — пользователь 3 с chef_id 7
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "own user id", path: "/v1/users/2/update", token: "client", wantStatus: http.StatusOK},
+		{name: "foreign user id", path: "/v1/users/5/update", token: "client", wantStatus: http.StatusForbidden},
+		{name: "chef user id", path: "/v1/users/3/update", token: "chef", wantStatus: http.StatusOK},
+		{name: "chef id is not user id", path: "/v1/users/7/update", token: "chef", wantStatus: http.StatusForbidden},
+		{name: "invalid user id", path: "/v1/users/abc/update", token: "client", wantStatus: http.StatusForbidden},
+		{name: "admin for another user", path: "/v1/users/5/update", token: "admin", wantStatus: http.StatusOK},
+		{name: "own chef id", path: "/v1/geo/chefs/7/address", token: "chef", wantStatus: http.StatusOK},
+		{name: "foreign chef id", path: "/v1/geo/chefs/8/address", token: "chef", wantStatus: http.StatusForbidden},
+		{name: "client is not a chef", path: "/v1/geo/chefs/2/address", token: "client", wantStatus: http.StatusForbidden},
+		{name: "admin for another chef", path: "/v1/geo/chefs/8/address", token: "admin", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("POST %s status = %d, want %d", tt.path, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
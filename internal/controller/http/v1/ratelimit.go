@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"domashka-backend/config"
+)
+
+// rateLimitKeyFunc возвращает, по чему считаются запросы. Пустая строка — правило к запросу не применяется
+type rateLimitKeyFunc func(c *gin.Context) string
+
+// rateLimitByIP считает по IP клиента. X-Forwarded-For учитывается только от доверенных прокси
+// (TRUSTED_PROXIES), иначе берётся адрес соединения
+func rateLimitByIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+func rateLimitByUserID(c *gin.Context) string {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(userID)
+}
+
+// rateLimitByLogin берёт номер или почту из JSON-тела запроса и возвращает тело обратно для обработчика
+func rateLimitByLogin(c *gin.Context) string {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var request struct {
+		Phone string `json:"phone"`
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(body, &request) != nil {
+		return ""
+	}
+	if request.Email != "" {
+		return strings.ToLower(strings.TrimSpace(request.Email))
+	}
+	return strings.TrimSpace(request.Phone)
+}
+
+var rateLimitRequests, _ = otel.Meter("domashka-ratelimit").Int64Counter(
+	"rate_limit_requests_total",
+	metric.WithDescription("Requests checked by the rate limiter"),
+)
+
+// RateLimitMiddleware ограничивает частоту запросов по ключу из key в скользящем окне limit.Window.
+// Сверх лимита отвечает 429 с заголовком Retry-After. Если хранилище недоступно, запрос пропускается.
+func RateLimitMiddleware(store rateLimiter, name string, limit config.RateLimit, key rateLimitKeyFunc) gin.HandlerFunc {
+	if limit.Limit <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		allowed, retryAfter, err := store.AllowSlidingWindow("ratelimit:"+name+":"+k, limit.Limit, limit.Window)
+		if err != nil {
+			log.Printf("ratelimit %s: %v", name, err)
+			rateLimitRequests.Add(ctx, 1, metric.WithAttributes(attribute.String("rule", name), attribute.String("result", "error")))
+			c.Next()
+			return
+		}
+		if allowed {
+			rateLimitRequests.Add(ctx, 1, metric.WithAttributes(attribute.String("rule", name), attribute.String("result", "allowed")))
+			c.Next()
+			return
+		}
+
+		rateLimitRequests.Add(ctx, 1, metric.WithAttributes(attribute.String("rule", name), attribute.String("result", "limited")))
+		c.Header("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(retryAfter.Seconds())))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, errorResponse{
+			Status: "error",
+			Err: errorMessage{
+				Code:    4291,
+				Message: "Too many requests.",
+				Details: "Слишком много запросов. Попробуйте позже.",
+			},
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"domashka-backend/config"
+)
+
+// fakeLimiter пропускает первые allow запросов по каждому ключу и запоминает ключи
+type fakeLimiter struct {
+	allow      int
+	retryAfter time.Duration
+	err        error
+	counts     map[string]int
+}
+
+func (f *fakeLimiter) AllowSlidingWindow(key string, _ int, _ time.Duration) (bool, time.Duration, error) {
+	if f.err != nil {
+		return false, 0, f.err
+	}
+	if f.counts == nil {
+		f.counts = make(map[string]int)
+	}
+	f.counts[key]++
+	if f.counts[key] > f.allow {
+		return false, f.retryAfter, nil
+	}
+	return true, 0, nil
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limit := config.RateLimit{Limit: 1, Window: time.Minute}
+	tests := []struct {
+		name           string
+		limiter        *fakeLimiter
+		limit          config.RateLimit
+		wantStatuses   []int
+		wantRetryAfter string
+	}{
+		{
+			name:           "over the limit",
+			limiter:        &fakeLimiter{allow: 1, retryAfter: 1500 * time.Millisecond},
+			limit:          limit,
+			wantStatuses:   []int{http.StatusOK, http.StatusTooManyRequests},
+			wantRetryAfter: "2",
+		},
+		{
+			name:           "retry after at least a second",
+			limiter:        &fakeLimiter{allow: 0, retryAfter: 10 * time.Millisecond},
+			limit:          limit,
+			wantStatuses:   []int{http.StatusTooManyRequests},
+			wantRetryAfter: "1",
+		},
+		{
+			name:         "store unavailable",
+			limiter:      &fakeLimiter{err: errors.New("redis down")},
+			limit:        limit,
+			wantStatuses: []int{http.StatusOK, http.StatusOK},
+		},
+		{
+			name:         "rule disabled",
+			limiter:      &fakeLimiter{allow: 0},
+			limit:        config.RateLimit{},
+			wantStatuses: []int{http.StatusOK, http.StatusOK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/", RateLimitMiddleware(tt.limiter, "test", tt.limit, rateLimitByIP), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			var w *httptest.ResponseRecorder
+			for i, want := range tt.wantStatuses {
+				w = httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+				if w.Code != want {
+					t.Fatalf("request %d status = %d, want %d", i+1, w.Code, want)
+				}
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestRateLimitByLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		body    string
+		wantKey string
+	}{
+		{name: "phone", body: `{"phone":" 79991234567 "}`, wantKey: "ratelimit:auth_phone:79991234567"},
+		{name: "email wins over phone", body: `{"phone":"79991234567","email":" User@Example.com"}`, wantKey: "ratelimit:auth_phone:user@example.com"},
+		{name: "not json", body: `phone=79991234567`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := &fakeLimiter{allow: 1}
+			var gotBody string
+			r := gin.New()
+			r.POST("/login", RateLimitMiddleware(limiter, "auth_phone", config.RateLimit{Limit: 1, Window: time.Minute}, rateLimitByLogin), func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				gotBody = string(body)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			// Обработчик читает то же тело, что пришло в запросе
+			if gotBody != tt.body {
+				t.Errorf("handler body = %q, want %q", gotBody, tt.body)
+			}
+			if tt.wantKey == "" {
+				if len(limiter.counts) != 0 {
+					t.Errorf("limiter called with %v, want no calls", limiter.counts)
+				}
+				return
+			}
+			if limiter.counts[tt.wantKey] != 1 {
+				t.Errorf("limiter keys = %v, want %q", limiter.counts, tt.wantKey)
+			}
+		})
+	}
+}
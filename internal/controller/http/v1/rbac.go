@@ -0,0 +1,153 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"domashka-backend/internal/entity/auth"
+)
+
+var (
+	// anyRole — маршрут доступен любому пользователю с валидным токеном
+	anyRole = []string{auth.RoleClient, auth.RoleChef, auth.RoleAdmin}
+	// chefRoles — кабинет повара; админ видит всё, что видит повар
+	chefRoles = []string{auth.RoleChef, auth.RoleAdmin}
+	// adminRoles — служебные операции
+	adminRoles = []string{auth.RoleAdmin}
+)
+
+// routeRoles — какие роли допускаются к маршруту. Ключ — метод и шаблон пути из gin.
+// nil — публичный маршрут без токена. Маршрута без записи в таблице быть не должно:
+// checkRouteRoles проверяет это при старте, а authorizeRoute отвечает 403.
+var routeRoles = map[string][]string{
+	"GET /health":                nil,
+	"GET /ready":                 nil,
+	"GET /.well-known/jwks.json": nil,
+
+	// Регистрация, вход и чтение публичных данных доступны без токена
+	"POST /v1/users/create":              nil,
+	"GET /v1/users/:id":                  nil,
+	"POST /v1/auth/login":                nil,
+	"POST /v1/auth/verify":               nil,
+	"POST /v1/auth/refresh":              nil,
+	"POST /v1/auth/logout":               anyRole,
+	"GET /v1/auth/sessions":              anyRole,
+	"DELETE /v1/auth/sessions":           anyRole,
+	"DELETE /v1/auth/sessions/:id":       anyRole,
+	"GET /v1/auth/user":                  nil,
+	"POST /v1/auth/tg":                   nil,
+	"POST /v1/auth/tg/status":            nil,
+	"GET /v1/geo/chefs/:chef_id/address": nil,
+	"GET /v1/reviews/chef/:chefId":       nil,
+	"POST /v1/payments/webhook":          nil,
+
+	// Профиль, избранное, адреса клиента и поиск по ним — только их владельцу из токена или админу (см. requireSelf)
+	"POST /v1/users/:id/update":                                       anyRole,
+	"DELETE /v1/users/:id":                                            anyRole,
+	"POST /v1/users/:id/favorite/chef":                                anyRole,
+	"DELETE /v1/users/:id/favorite/chef":                              anyRole,
+	"POST /v1/users/:id/favorite/dish":                                anyRole,
+	"DELETE /v1/users/:id/favorite/dish":                              anyRole,
+	"POST /v1/geo/clients/:client_id/addresses":                       anyRole,
+	"POST /v1/geo/clients/:client_id/addresses/:address_id":           anyRole,
+	"POST /v1/geo/clients/:client_id/addresses/push/:address_id":      anyRole,
+	"POST /v1/geo/chefs/:chef_id/address":                             chefRoles,
+	"GET /v1/geo/clients/:client_id/addresses":                        anyRole,
+	"GET /v1/geo/clients/:client_id/addresses/:address_id":            anyRole,
+	"GET /v1/geo/search/chefs-near-client-address/:client_address_id": anyRole,
+	"GET /v1/geo/search/clients-near-chef/:chef_id":                   chefRoles,
+
+	// Корзина и отзывы — всегда пользователя из токена
+	"GET /v1/cart/view":       anyRole,
+	"POST /v1/cart/add":       anyRole,
+	"POST /v1/cart/remove":    anyRole,
+	"POST /v1/cart/clear":     anyRole,
+	"POST /v1/cart/increment": anyRole,
+	"POST /v1/cart/decrement": anyRole,
+	"POST /v1/reviews":        anyRole,
+
+	// Уведомления: читать может любой, создавать и переотправлять — только админ
+	"GET /v1/notifications/":            anyRole,
+	"GET /v1/notifications/:id":         anyRole,
+	"POST /v1/notifications/":           adminRoles,
+	"POST /v1/notifications/:id/resend": adminRoles,
+
+	// Каталог
+	"GET /v1/home":          anyRole,
+	"GET /v1/search":        anyRole,
+	"GET /v1/chefs/:chefId": anyRole,
+	"GET /v1/dish/:dishId":  anyRole,
+
+	// Кабинет повара
+	"GET /v1/chef/home":                                    chefRoles,
+	"POST /v1/chefs/avatar/:chefId":                        chefRoles,
+	"POST /v1/chefs/shifts/open":                           chefRoles,
+	"POST /v1/chefs/shifts/close":                          chefRoles,
+	"POST /v1/chefs/dishes/create":                         chefRoles,
+	"DELETE /v1/chefs/dishes/delete":                       chefRoles,
+	"GET /v1/chefs/menu":                                   chefRoles,
+	"GET /v1/chefs/dish/form":                              chefRoles,
+	"GET /v1/chefs/stats":                                  chefRoles,
+	"GET /v1/chefs/working-hours":                          chefRoles,
+	"POST /v1/chefs/working-hours":                         chefRoles,
+	"POST /v1/dish/upload/image/:dishId":                   chefRoles,
+	"POST /v1/dish/ingredients/upload/image/:ingredientId": chefRoles,
+
+	// Заказы клиента; повар тоже может заказывать у других поваров
+	"GET /v1/order/":             anyRole,
+	"GET /v1/order/details_form": anyRole,
+	"GET /v1/order/final_form":   anyRole,
+	"POST /v1/order/create":      anyRole,
+	"POST /v1/order/cancel":      anyRole,
+	"GET /v1/order/status":       anyRole,
+
+	// Обработка заказа поваром
+	"POST /v1/order/accept":        chefRoles,
+	"POST /v1/order/reject":        chefRoles,
+	"POST /v1/order/remove_item":   chefRoles,
+	"POST /v1/order/call_delivery": chefRoles,
+	"POST /v1/order/pickup":        chefRoles,
+	"POST /v1/order/deliver":       chefRoles,
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// RequireRole пропускает запрос, только если роль из токена входит в roles.
+// Работает после AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(roles, c.GetString("role")) {
+			c.AbortWithStatusJSON(http.StatusForbidden, forbiddenResponse)
+			return
+		}
+		c.Next()
+	}
+}
+
+// authorizeRoute проверяет роль по таблице routeRoles. Маршрут без записи
+// или публичный маршрут в группе с токеном запрещаются: это ошибка настройки.
+func authorizeRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles, ok := routeRoles[routeKey(c.Request.Method, c.FullPath())]
+		if !ok || roles == nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, forbiddenResponse)
+			return
+		}
+		RequireRole(roles...)(c)
+	}
+}
+
+// checkRouteRoles возвращает ошибку, если у зарегистрированного маршрута нет записи в routeRoles
+func checkRouteRoles(routes gin.RoutesInfo) error {
+	for _, r := range routes {
+		if _, ok := routeRoles[routeKey(r.Method, r.Path)]; !ok {
+			return fmt.Errorf("route %s %s has no roles in routeRoles", r.Method, r.Path)
+		}
+	}
+	return nil
+}
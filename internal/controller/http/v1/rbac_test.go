@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	authEntity "domashka-backend/internal/entity/auth"
+)
+
+// fakeJWT принимает токены из таблицы и возвращает их claims
+type fakeJWT struct {
+	tokens map[string]map[string]interface{}
+}
+
+func (f fakeJWT) ValidateJWT(token string) (map[string]interface{}, error) {
+	claims, ok := f.tokens[token]
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func (f fakeJWT) JWKS() authEntity.JWKS {
+	return authEntity.JWKS{}
+}
+
+type noopSessions struct{}
+
+func (noopSessions) TouchSession(context.Context, string, string) error {
+	return nil
+}
+
+var testTokens = fakeJWT{tokens: map[string]map[string]interface{}{
+	"client": {"user_id": "2", "role": authEntity.RoleClient},
+	"chef":   {"user_id": "3", "role": authEntity.RoleChef, "chef_id": float64(7)},
+	"admin":  {"user_id": "1", "role": authEntity.RoleAdmin},
+}}
+
+func TestAuthorizeRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	authorized := r.Group("/v1", AuthMiddleware(testTokens, noopSessions{}), authorizeRoute())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	authorized.GET("/notifications/", ok)     // anyRole
+	authorized.POST("/notifications/", ok)    // adminRoles
+	authorized.GET("/chef/home", ok)          // chefRoles
+	authorized.GET("/users/:id", ok)          // публичный маршрут в группе с токеном
+	authorized.GET("/not-in-route-roles", ok) // маршрута нет в таблице
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "no token", method: http.MethodGet, path: "/v1/notifications/", wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", method: http.MethodGet, path: "/v1/notifications/", token: "forged", wantStatus: http.StatusUnauthorized},
+		{name: "any role", method: http.MethodGet, path: "/v1/notifications/", token: "client", wantStatus: http.StatusOK},
+		{name: "client on admin route", method: http.MethodPost, path: "/v1/notifications/", token: "client", wantStatus: http.StatusForbidden},
+		{name: "chef on admin route", method: http.MethodPost, path: "/v1/notifications/", token: "chef", wantStatus: http.StatusForbidden},
+		{name: "admin on admin route", method: http.MethodPost, path: "/v1/notifications/", token: "admin", wantStatus: http.StatusOK},
+		{name: "client on chef route", method: http.MethodGet, path: "/v1/chef/home", token: "client", wantStatus: http.StatusForbidden},
+		{name: "chef on chef route", method: http.MethodGet, path: "/v1/chef/home", token: "chef", wantStatus: http.StatusOK},
+		{name: "admin on chef route", method: http.MethodGet, path: "/v1/chef/home", token: "admin", wantStatus: http.StatusOK},
+		{name: "public route behind auth", method: http.MethodGet, path: "/v1/users/2", token: "admin", wantStatus: http.StatusForbidden},
+		{name: "route without roles", method: http.MethodGet, path: "/v1/not-in-route-roles", token: "admin", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCheckRouteRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		routes  gin.RoutesInfo
+		wantErr bool
+	}{
+		{
+			name: "all routes have roles",
+			routes: gin.RoutesInfo{
+				{Method: http.MethodGet, Path: "/health"},
+				{Method: http.MethodPost, Path: "/v1/order/accept"},
+			},
+		},
+		{
+			name: "unmapped route",
+			routes: gin.RoutesInfo{
+				{Method: http.MethodGet, Path: "/health"},
+				{Method: http.MethodPost, Path: "/v1/order/secret"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mapped path with another method",
+			routes: gin.RoutesInfo{
+				{Method: http.MethodDelete, Path: "/v1/order/accept"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkRouteRoles(tt.routes); (err != nil) != tt.wantErr {
+				t.Errorf("checkRouteRoles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
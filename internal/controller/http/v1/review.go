@@ -3,35 +3,34 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"domashka-backend/internal/entity/orders"
 	reviewEntity "domashka-backend/internal/entity/reviews"
 )
 
 // reviewsHandler отвечает за эндпоинты работы с отзывами.
 type reviewsHandler struct {
 	reviewsUsecase reviewsUsecase
+	orderUsecase   orderUsecase
 }
 
-// RegisterReviewHandlers регистрирует маршруты для отзывов.
-func RegisterReviewHandlers(rg *gin.RouterGroup, reviewsUsecase reviewsUsecase) {
-	h := &reviewsHandler{reviewsUsecase: reviewsUsecase}
-	grp := rg.Group("/reviews")
-	{
-		grp.POST("", h.createReview)
-		grp.GET("/chef/:chefId", h.getReviewsByChefID)
-	}
+// RegisterReviewHandlers регистрирует маршруты для отзывов: читать отзывы можно без токена,
+// оставить отзыв — только по токену и только на свой заказ.
+func RegisterReviewHandlers(public, authorized *gin.RouterGroup, reviewsUsecase reviewsUsecase, orderUsecase orderUsecase) {
+	h := &reviewsHandler{reviewsUsecase: reviewsUsecase, orderUsecase: orderUsecase}
+	authorized.POST("/reviews", h.createReview)
+	public.GET("/reviews/chef/:chefId", h.getReviewsByChefID)
 }
 
 // request/response DTOs
 
 type createReviewRequest struct {
-	ChefID  int64  `json:"chef_id"  binding:"required"`
-	UserID  int64  `json:"user_id"  binding:"required"`
 	Stars   int16  `json:"stars"    binding:"required,min=1,max=5"`
 	Comment string `json:"comment"  binding:"max=255"`
 	OrderID int64  `json:"order_id" binding:"required"`
@@ -70,9 +69,38 @@ func (h *reviewsHandler) createReview(c *gin.Context) {
 		return
 	}
 
+	// Автор отзыва — пользователь из токена, повар — из заказа
+	userID, err := strconv.ParseInt(c.GetString("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			Status: "error",
+			Err:    errorMessage{Code: 4002, Message: "Invalid user ID"},
+		})
+		return
+	}
+	order, err := h.orderUsecase.GetOrderByID(c.Request.Context(), req.OrderID)
+	if errors.Is(err, orders.ErrOrderNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse{
+			Status: "error",
+			Err:    errorMessage{Code: 4043, Message: "Order not found", Details: "Заказ не найден."},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse{
+			Status: "error",
+			Err:    errorMessage{Code: 5001, Message: "Failed to create review", Details: err.Error()},
+		})
+		return
+	}
+	if order.UserID != userID {
+		c.JSON(http.StatusForbidden, forbiddenResponse)
+		return
+	}
+
 	rv := reviewEntity.Review{
-		ChefID:  req.ChefID,
-		UserID:  req.UserID,
+		ChefID:  order.ChefID,
+		UserID:  userID,
 		Stars:   req.Stars,
 		Comment: req.Comment,
 		OrderID: req.OrderID,
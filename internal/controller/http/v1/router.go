@@ -3,13 +3,12 @@ package v1
 import (
 	"context"
 	"log"
-	"time"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
-	"go.opentelemetry.io/otel/sdk/metric"
 
+	"domashka-backend/config"
 	"domashka-backend/internal/utils/telemetry"
 )
 
@@ -28,27 +27,18 @@ func NewRouter(
 	shiftsUsecase shiftsUsecase,
 	reviewsUsecase reviewsUsecase,
 	favoritesUsecase favoritesUsecase,
+	deliveryUsecase deliveryUsecase,
+	paymentsUsecase paymentsUsecase,
+	idempotencyStore idempotencyStore,
+	limiter rateLimiter,
+	limits *config.RateLimitConfig,
+	readiness readinessProbe,
 ) {
 	// Options
 	handler.Use(gin.Logger())
 	handler.Use(gin.Recovery())
 
-	tp := telemetry.TracerProvider()
-	defer func() { _ = tp.Shutdown(context.Background()) }()
-
-	exporter, err := otlpmetricgrpc.New(context.Background(),
-		otlpmetricgrpc.WithEndpoint("otel-collector:4317"),
-		otlpmetricgrpc.WithInsecure(),
-	)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	provider := metric.NewMeterProvider(
-		metric.WithReader(metric.NewPeriodicReader(exporter, metric.WithInterval(time.Second*5))),
-	)
-	otel.SetMeterProvider(provider)
-
+	// Провайдеры телеметрии создаёт и останавливает приложение
 	handler.Use(telemetry.OtelGinMiddleware())
 
 	meter := otel.Meter("domashka-app")
@@ -59,25 +49,49 @@ func NewRouter(
 		requestCounter.Add(context.Background(), 400)
 		c.JSON(200, gin.H{})
 	})
+	// /health — процесс жив; /ready — реплика принимает трафик. При остановке /ready
+	// отвечает 503 раньше, чем закрывается сервер
+	handler.GET("/ready", func(c *gin.Context) {
+		if !readiness.Ready() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+	})
 
 	// Routers
 	h := handler.Group("/v1")
+	h.Use(RateLimitMiddleware(limiter, "public_ip", limits.Public, rateLimitByIP))
 	{
-
-		newUsersHandler(h, l, u, reviewsUsecase, orderUsecase, favoritesUsecase, dishesUsecase, chefsUsecase)
-		newAuthHandler(h, a, jwt)
 		authorized := h.Group("/")
-		authorized.Use(AuthMiddleware(jwt))
+		// Роль проверяется по таблице routeRoles, а не в каждом обработчике
+		authorized.Use(AuthMiddleware(jwt, a), RateLimitMiddleware(limiter, "user", limits.User, rateLimitByUserID), authorizeRoute())
+		newUsersHandler(h, authorized, l, u, reviewsUsecase, orderUsecase, favoritesUsecase, dishesUsecase, chefsUsecase)
+		// Вход ограничивается и по IP, и по номеру телефона или почте: перебор кодов идёт с разных адресов
+		authLimits := []gin.HandlerFunc{
+			RateLimitMiddleware(limiter, "auth_ip", limits.AuthIP, rateLimitByIP),
+			RateLimitMiddleware(limiter, "auth_phone", limits.AuthPhone, rateLimitByLogin),
+		}
+		newAuthHandler(handler, h, authorized, a, jwt, authLimits...)
 		{
 			newNotificationHandler(authorized, n)
-			RegisterGeoHandlers(h, g)
-			NewChefsHandler(authorized, dishesUsecase, chefsUsecase, g, shiftsUsecase, u, reviewsUsecase, orderUsecase)
+			RegisterGeoHandlers(h, authorized, g)
+			NewChefsHandler(authorized, dishesUsecase, chefsUsecase, g, shiftsUsecase, u, reviewsUsecase, orderUsecase, deliveryUsecase)
 			RegisterSearchHandler(authorized, dishesUsecase, chefsUsecase, orderUsecase, reviewsUsecase, u)
 			NewDishesHandler(authorized, dishesUsecase, chefsUsecase, u)
 		}
-		RegisterCartHandlers(h, chefsUsecase, cartUsecase, dishesUsecase, g)
-		RegisterOrderHandlers(h, g, cartUsecase, orderUsecase, shiftsUsecase, chefsUsecase)
-		NewHomeHandler(authorized, jwt, g, dishesUsecase, chefsUsecase, orderUsecase, reviewsUsecase)
-		RegisterReviewHandlers(h, reviewsUsecase)
+		// POST-запросы с Idempotency-Key не выполняются повторно при ретраях клиента.
+		// Корзина, заказы и отзывы доступны только по токену: владелец берётся из user_id и chef_id в нём
+		authorizedIdempotent := authorized.Group("/")
+		authorizedIdempotent.Use(IdempotencyMiddleware(idempotencyStore))
+		RegisterCartHandlers(authorizedIdempotent, chefsUsecase, cartUsecase, dishesUsecase, g, deliveryUsecase)
+		RegisterOrderHandlers(authorizedIdempotent, g, cartUsecase, orderUsecase, shiftsUsecase, chefsUsecase, deliveryUsecase, paymentsUsecase)
+		RegisterPaymentHandlers(h, orderUsecase)
+		NewHomeHandler(authorized, g, dishesUsecase, chefsUsecase, orderUsecase, reviewsUsecase)
+		RegisterReviewHandlers(h, authorizedIdempotent, reviewsUsecase, orderUsecase)
+	}
+
+	if err := checkRouteRoles(handler.Routes()); err != nil {
+		log.Fatalf("rbac: %v", err)
 	}
 }
@@ -114,7 +114,7 @@ func (h *searchHandler) getAll(c *gin.Context) {
 			Name:          dish.Name,
 			ImageURL:      dish.ImageURL,
 			ChefAvatarURL: chefAvatars[dish.ChefID],
-			MinPrice:      fmt.Sprintf("от %.0fр", minPrice.Value),
+			MinPrice:      minPrice.FormatFrom(),
 			Badges:        []string{category},
 			Rating:        dish.Rating,
 		})
@@ -177,14 +177,14 @@ func (h *searchHandler) getAll(c *gin.Context) {
 			Name:          dish.Name,
 			ImageURL:      dish.ImageURL,
 			ChefAvatarURL: chefAvatars[dish.ChefID],
-			MinPrice:      fmt.Sprintf("от %.0fр", minPrice.Value),
+			MinPrice:      minPrice.FormatFrom(),
 			Badges:        []string{category},
 		})
 		chefsDishes[dish.ChefID] = append(chefsDishes[dish.ChefID], map[string]interface{}{
 			"dish_id":        dish.ID,
 			"dish_image_url": dish.ImageURL,
 			"name":           dish.Name,
-			"price":          fmt.Sprintf("от %.0fр", minPrice.Value),
+			"price":          minPrice.FormatFrom(),
 			"rating":         dish.Rating,
 		})
 	}
@@ -31,7 +31,7 @@ type usersHandler struct {
 	chefUsecase     chefUsecase
 }
 
-func newUsersHandler(rg *gin.RouterGroup,
+func newUsersHandler(public, authorized *gin.RouterGroup,
 	log logger,
 	uu usersUsecase,
 	ru reviewsUsecase,
@@ -50,16 +50,20 @@ func newUsersHandler(rg *gin.RouterGroup,
 		chefUsecase:     chefUsecase,
 	}
 
-	rg = rg.Group("/users")
+	public = public.Group("/users")
 	{
-		rg.POST("/create", u.Create)
-		rg.GET("/:id", u.GetProfile)
-		rg.POST("/:id/update", u.Update)
-		rg.DELETE("/:id", u.Delete)
-		rg.POST("/:id/favorite/chef", u.AddFavoriteChef)
-		rg.DELETE("/:id/favorite/chef", u.RemoveFavoriteChef)
-		rg.POST("/:id/favorite/dish", u.AddFavoriteDish)
-		rg.DELETE("/:id/favorite/dish", u.RemoveFavoriteDish)
+		public.POST("/create", u.Create)
+		public.GET("/:id", u.GetProfile)
+	}
+	// Менять профиль и избранное может только сам пользователь или админ
+	self := authorized.Group("/users/:id", requireSelf("id"))
+	{
+		self.POST("/update", u.Update)
+		self.DELETE("", u.Delete)
+		self.POST("/favorite/chef", u.AddFavoriteChef)
+		self.DELETE("/favorite/chef", u.RemoveFavoriteChef)
+		self.POST("/favorite/dish", u.AddFavoriteDish)
+		self.DELETE("/favorite/dish", u.RemoveFavoriteDish)
 	}
 }
 
@@ -69,6 +73,13 @@ type orderDetail struct {
 	Dishes  []dishDetail          `json:"dishes"`
 	Chef    chefSnippet           `json:"chef"`
 	Review  reviewResponseProfile `json:"review"`
+	Refunds []refundResponse      `json:"refunds,omitempty"`
+}
+
+type refundResponse struct {
+	Amount    Price  `json:"amount"`
+	Reason    string `json:"reason,omitempty"`
+	CreatedAt string `json:"created_at"`
 }
 
 type dishDetail struct {
@@ -124,14 +135,11 @@ func (h *usersHandler) mapDishList(ctx context.Context, in []dishesEntity.Dish)
 			continue
 		}
 		out = append(out, dishDetail{
-			DishID:   d.ID,
-			Title:    d.Name,
-			ImageURL: d.ImageURL,
-			Rating:   d.Rating,
-			PriceFrom: Price{
-				Value:    minPrice.Value,
-				Currency: minPrice.Currency,
-			},
+			DishID:    d.ID,
+			Title:     d.Name,
+			ImageURL:  d.ImageURL,
+			Rating:    d.Rating,
+			PriceFrom: newPrice(*minPrice),
 		})
 	}
 	return out
@@ -155,15 +163,12 @@ func mapOrderProfile(p orderEntity.OrderProfile) *orderDetail {
 		}
 
 		dishes = append(dishes, dishDetail{
-			DishID:   it.Dish.ID,
-			Title:    it.Dish.Name,
-			ImageURL: it.Dish.ImageURL,
-			Rating:   it.Dish.Rating,
-			Details:  details,
-			PriceFrom: Price{
-				Value:    it.Size.PriceValue,
-				Currency: it.Size.PriceCurrency,
-			},
+			DishID:    it.Dish.ID,
+			Title:     it.Dish.Name,
+			ImageURL:  it.Dish.ImageURL,
+			Rating:    it.Dish.Rating,
+			Details:   details,
+			PriceFrom: newPrice(it.Size.Price),
 		})
 	}
 
@@ -191,12 +196,23 @@ func mapOrderProfile(p orderEntity.OrderProfile) *orderDetail {
 		review.CanWriteReview = true
 	}
 
+	// Маппинг возвратов
+	refunds := make([]refundResponse, 0, len(p.Refunds))
+	for _, r := range p.Refunds {
+		refunds = append(refunds, refundResponse{
+			Amount:    newPrice(r.Amount),
+			Reason:    r.Reason,
+			CreatedAt: r.CreatedAt.Format("2006-01-02T15:04:05"),
+		})
+	}
+
 	return &orderDetail{
 		OrderID: p.Order.ID,
 		Label:   label,
 		Dishes:  dishes,
 		Chef:    chef,
 		Review:  review,
+		Refunds: refunds,
 	}
 }
 
@@ -562,7 +578,7 @@ func (h *usersHandler) GetProfile(c *gin.Context) {
 				"dish_id":        dish.ID,
 				"dish_image_url": dish.ImageURL,
 				"name":           dish.Name,
-				"price":          fmt.Sprintf("от %.0fр", minPrice.Value),
+				"price":          minPrice.FormatFrom(),
 				"rating":         dish.Rating,
 			})
 		}
@@ -611,7 +627,7 @@ func (h *usersHandler) GetProfile(c *gin.Context) {
 			"suggests":        suggests,
 			"name":            dish.Name,
 			"rating":          dish.Rating,
-			"price":           fmt.Sprintf("от %.0fр", minPrice.Value),
+			"price":           minPrice.FormatFrom(),
 		})
 	}
 
@@ -1,9 +1,11 @@
 package telegram
 
 import (
+	"context"
 	"domashka-backend/internal/custom_errors"
 	"errors"
 	tele "gopkg.in/telebot.v4"
+	"log"
 )
 
 type ContactHandler struct {
@@ -14,7 +16,21 @@ func NewContactHandler(tg ContactUseCase) *ContactHandler {
 	return &ContactHandler{contactUsecase: tg}
 }
 
+// Start обрабатывает /start <nonce> из ссылки входа, выданной приложением
 func (h *ContactHandler) Start(c tele.Context) error {
+	nonce := c.Message().Payload
+	if nonce == "" {
+		return c.Reply("Чтобы войти, нажмите «Войти через Telegram» в приложении.")
+	}
+
+	confirmed, err := h.contactUsecase.StartLogin(context.Background(), nonce, c.Sender().ID)
+	if err != nil {
+		return c.Reply(loginErrorText(err))
+	}
+	if confirmed {
+		return c.Reply("Вход подтверждён. Вернитесь в приложение.")
+	}
+
 	replyKeyboard := &tele.ReplyMarkup{}
 
 	btnContact := replyKeyboard.Contact("Отправить контакт")
@@ -32,20 +48,26 @@ func (h *ContactHandler) HandleContact(c tele.Context) error {
 	if contact == nil {
 		return c.Reply("Пожалуйста, отправьте контакт, используя кнопку ниже.")
 	}
+	// Чужой контакт привязал бы этот чат к другому аккаунту
+	if contact.UserID != c.Sender().ID {
+		return c.Reply("Пожалуйста, отправьте свой контакт, используя кнопку ниже.")
+	}
 
-	err := h.contactUsecase.HandleContact(c, contact)
+	err := h.contactUsecase.HandleContact(context.Background(), contact)
 	if err != nil {
-		if errors.Is(err, custom_errors.ErrPhoneNumberMismatch) {
-			replyKeyboard := &tele.ReplyMarkup{}
-			btnRetry := replyKeyboard.Contact("Отправить контакт ещё раз")
-			replyKeyboard.Reply(replyKeyboard.Row(btnRetry))
-			replyKeyboard.OneTimeKeyboard = true
+		return c.Reply(loginErrorText(err), &tele.ReplyMarkup{RemoveKeyboard: true})
+	}
 
-			return c.Reply("Номер телефона не совпадает с введённым. Попробуйте снова.", replyKeyboard)
-		}
+	return c.Reply("Вход подтверждён. Вернитесь в приложение.", &tele.ReplyMarkup{RemoveKeyboard: true})
+}
 
-		return c.Reply("Произошла ошибка. Попробуйте снова позже.")
+func loginErrorText(err error) string {
+	switch {
+	case errors.Is(err, custom_errors.ErrExpiredTTL):
+		return "Ссылка для входа устарела. Начните вход в приложении заново."
+	case errors.Is(err, custom_errors.ErrTgLoginTaken):
+		return "Эта ссылка для входа уже открыта другим пользователем Telegram."
 	}
-
-	return c.Reply("Авторизация успешна!")
+	log.Printf("telegram: вход: %v", err)
+	return "Произошла ошибка. Попробуйте снова позже."
 }
@@ -1,7 +1,22 @@
 package telegram
 
-import tele "gopkg.in/telebot.v4"
+import (
+	"context"
+
+	tele "gopkg.in/telebot.v4"
+
+	"domashka-backend/internal/entity/money"
+)
 
 type ContactUseCase interface {
-	HandleContact(c tele.Context, contact *tele.Contact) error
+	StartLogin(ctx context.Context, nonce string, telegramID int64) (bool, error)
+	HandleContact(ctx context.Context, contact *tele.Contact) error
+}
+
+type OrdersUseCase interface {
+	OpenShift(ctx context.Context, chatID int64) error
+	CloseShift(ctx context.Context, chatID int64) (money.Money, error)
+	TodayRevenue(ctx context.Context, chatID int64) (money.Money, error)
+	AcceptOrder(ctx context.Context, chatID, orderID int64) error
+	RejectOrder(ctx context.Context, chatID, orderID int64) error
 }
@@ -0,0 +1,93 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	tele "gopkg.in/telebot.v4"
+
+	"domashka-backend/internal/custom_errors"
+	"domashka-backend/internal/entity/orders"
+	"domashka-backend/internal/entity/shifts"
+)
+
+type OrdersHandler struct {
+	ordersUsecase OrdersUseCase
+}
+
+func NewOrdersHandler(uc OrdersUseCase) *OrdersHandler {
+	return &OrdersHandler{ordersUsecase: uc}
+}
+
+func (h *OrdersHandler) OpenShift(c tele.Context) error {
+	if err := h.ordersUsecase.OpenShift(context.Background(), c.Chat().ID); err != nil {
+		return c.Reply(errorText(err))
+	}
+	return c.Reply("Смена открыта. Новые заказы будут приходить сюда.")
+}
+
+func (h *OrdersHandler) CloseShift(c tele.Context) error {
+	profit, err := h.ordersUsecase.CloseShift(context.Background(), c.Chat().ID)
+	if err != nil {
+		return c.Reply(errorText(err))
+	}
+	return c.Reply(fmt.Sprintf("Смена закрыта. Выручка за смену: %s", profit.Format()))
+}
+
+func (h *OrdersHandler) TodayRevenue(c tele.Context) error {
+	profit, err := h.ordersUsecase.TodayRevenue(context.Background(), c.Chat().ID)
+	if err != nil {
+		return c.Reply(errorText(err))
+	}
+	return c.Reply(fmt.Sprintf("Выручка за сегодня: %s", profit.Format()))
+}
+
+func (h *OrdersHandler) AcceptOrder(c tele.Context) error {
+	return h.handleOrderButton(c, h.ordersUsecase.AcceptOrder, "Заказ принят")
+}
+
+func (h *OrdersHandler) RejectOrder(c tele.Context) error {
+	return h.handleOrderButton(c, h.ordersUsecase.RejectOrder, "Заказ отклонён")
+}
+
+// handleOrderButton выполняет действие по кнопке под заказом и убирает кнопки из сообщения
+func (h *OrdersHandler) handleOrderButton(c tele.Context, action func(ctx context.Context, chatID, orderID int64) error, done string) error {
+	orderID, err := strconv.ParseInt(c.Data(), 10, 64)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Некорректный заказ", ShowAlert: true})
+	}
+
+	if err := action(context.Background(), c.Chat().ID, orderID); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: errorText(err), ShowAlert: true})
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: done}); err != nil {
+		return err
+	}
+	return c.Edit(c.Message().Text + "\n\n" + done + ".")
+}
+
+func errorText(err error) string {
+	var transitionErr *orders.TransitionError
+	switch {
+	case errors.Is(err, custom_errors.ErrChatNotBound):
+		return "Этот чат не привязан к аккаунту. Войдите в приложение через Telegram, чтобы привязать его."
+	case errors.Is(err, custom_errors.ErrNotChef):
+		return "Команда доступна только поварам."
+	case errors.Is(err, shifts.ErrShiftAlreadyOpen):
+		return "Смена уже открыта."
+	case errors.Is(err, shifts.ErrShiftNotOpen):
+		return "Открытой смены нет."
+	case errors.Is(err, orders.ErrNotOrderOwner):
+		return "Это заказ другого повара."
+	case errors.Is(err, orders.ErrOrderNotFound):
+		return "Заказ не найден."
+	case errors.As(err, &transitionErr), errors.Is(err, orders.ErrStatusConflict):
+		return "Статус заказа уже изменился."
+	}
+	log.Printf("telegram: %v", err)
+	return "Произошла ошибка. Попробуйте снова позже."
+}
@@ -2,11 +2,21 @@ package telegram
 
 import (
 	tele "gopkg.in/telebot.v4"
+
+	"domashka-backend/internal/usecase/tgbot"
 )
 
-func NewBot(bot *tele.Bot, contactUseCase ContactUseCase) {
+func NewBot(bot *tele.Bot, contactUseCase ContactUseCase, ordersUseCase OrdersUseCase) {
 	tgHandler := NewContactHandler(contactUseCase)
 
 	bot.Handle("/start", tgHandler.Start)
 	bot.Handle(tele.OnContact, tgHandler.HandleContact)
+
+	ordersHandler := NewOrdersHandler(ordersUseCase)
+
+	bot.Handle("/shift_open", ordersHandler.OpenShift)
+	bot.Handle("/shift_close", ordersHandler.CloseShift)
+	bot.Handle("/revenue", ordersHandler.TodayRevenue)
+	bot.Handle(&tele.Btn{Unique: tgbot.CallbackAcceptOrder}, ordersHandler.AcceptOrder)
+	bot.Handle(&tele.Btn{Unique: tgbot.CallbackRejectOrder}, ordersHandler.RejectOrder)
 }
@@ -0,0 +1,33 @@
+package telegram
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	tele "gopkg.in/telebot.v4"
+)
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookHandler принимает обновления от Telegram в режиме вебхука. Бот должен быть создан
+// с Synchronous: обновление обрабатывается внутри запроса, и остановка HTTP-сервера
+// дожидается уже начатых обработчиков
+func WebhookHandler(bot *tele.Bot, secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader(secretTokenHeader)), []byte(secret)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		var update tele.Update
+		if err := json.NewDecoder(c.Request.Body).Decode(&update); err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		bot.ProcessUpdate(update)
+		c.Status(http.StatusOK)
+	}
+}
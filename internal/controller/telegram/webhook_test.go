@@ -0,0 +1,60 @@
+package telegram
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	tele "gopkg.in/telebot.v4"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const update = `{"update_id":1,"message":{"message_id":1,"text":"/start","chat":{"id":42,"type":"private"},"from":{"id":42}}}`
+	tests := []struct {
+		name        string
+		secret      string
+		body        string
+		wantStatus  int
+		wantHandled bool
+	}{
+		{name: "no secret header", body: update, wantStatus: http.StatusUnauthorized},
+		{name: "wrong secret", secret: "wrong", body: update, wantStatus: http.StatusUnauthorized},
+		{name: "secret prefix", secret: "webhook", body: update, wantStatus: http.StatusUnauthorized},
+		{name: "correct secret", secret: "webhook-secret", body: update, wantStatus: http.StatusOK, wantHandled: true},
+		{name: "correct secret, broken body", secret: "webhook-secret", body: "{", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, err := tele.NewBot(tele.Settings{Offline: true, Synchronous: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			handled := false
+			bot.Handle("/start", func(tele.Context) error {
+				handled = true
+				return nil
+			})
+
+			r := gin.New()
+			r.POST("/telegram/webhook", WebhookHandler(bot, "webhook-secret"))
+			req := httptest.NewRequest(http.MethodPost, "/telegram/webhook", strings.NewReader(tt.body))
+			if tt.secret != "" {
+				req.Header.Set(secretTokenHeader, tt.secret)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			// Synchronous-бот обрабатывает обновление до ответа на запрос
+			if handled != tt.wantHandled {
+				t.Errorf("update handled = %v, want %v", handled, tt.wantHandled)
+			}
+		})
+	}
+}
@@ -7,5 +7,11 @@ var (
 	ErrUserIsSpam              = fmt.Errorf("user is spam")
 	ErrExpiredTTL              = fmt.Errorf("expired ttl")
 	ErrConfirmationNotReceived = fmt.Errorf("confirmation not received")
-	ErrPhoneNumberMismatch     = fmt.Errorf("phone number mismatch")
+	ErrInvalidRefreshToken     = fmt.Errorf("invalid refresh token")
+	ErrTokenRevoked            = fmt.Errorf("token revoked")
+	ErrSessionNotFound         = fmt.Errorf("session not found")
+	ErrInvalidOTP              = fmt.Errorf("invalid or expired OTP")
+	ErrOTPLocked               = fmt.Errorf("too many OTP attempts")
+	ErrMagicLinkDisabled       = fmt.Errorf("magic link login is not configured")
+	ErrTgLoginTaken            = fmt.Errorf("telegram login link is used by another account")
 )
@@ -0,0 +1,8 @@
+package custom_errors
+
+import "fmt"
+
+var (
+	ErrChatNotBound = fmt.Errorf("telegram chat is not bound to a user")
+	ErrNotChef      = fmt.Errorf("user is not a chef")
+)
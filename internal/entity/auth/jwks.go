@@ -0,0 +1,20 @@
+package auth
+
+// JWK — открытый ключ подписи токенов в формате RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS — набор ключей, по которым партнёры проверяют наши токены
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -1,6 +1,15 @@
 package auth
 
+// Способы входа по почте: код в письме или одноразовая ссылка
+const (
+	LoginMethodCode = "code"
+	LoginMethodLink = "link"
+)
+
+// Request — запрос на вход. Указывается телефон или почта; Method учитывается только для почты
 type Request struct {
-	Phone     string `json:"phone" binding:"required"`
+	Phone     string `json:"phone" binding:"required_without=Email"`
+	Email     string `json:"email" binding:"omitempty,email"`
+	Method    string `json:"method" binding:"omitempty,oneof=code link"`
 	AuthViaTg bool   `json:"auth_via_tg"`
 }
@@ -0,0 +1,21 @@
+package auth
+
+// Роли пользователей, совпадают со значениями users.role
+const (
+	RoleClient = "client"
+	RoleChef   = "chef"
+	RoleAdmin  = "admin"
+)
+
+// ResolveRole определяет роль для токена по данным из БД: роль из users.role
+// и привязку к повару (users_chefs). Роль из запроса клиента не учитывается.
+func ResolveRole(userRole string, isChef bool) string {
+	switch {
+	case userRole == RoleAdmin:
+		return RoleAdmin
+	case isChef || userRole == RoleChef:
+		return RoleChef
+	default:
+		return RoleClient
+	}
+}
@@ -0,0 +1,22 @@
+package auth
+
+import "time"
+
+// Device — откуда выполнен вход
+type Device struct {
+	Name      string
+	Platform  string
+	IP        string
+	UserAgent string
+}
+
+// Session — вход пользователя с одного устройства. ID совпадает с FamilyID его refresh-токенов
+// и передаётся в access-токене в claim sid.
+type Session struct {
+	ID         string
+	UserID     int64
+	Device     Device
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+}
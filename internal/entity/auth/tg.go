@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+)
+
+// TgLoginTTL — сколько действует ссылка для входа через Telegram
+const TgLoginTTL = 5 * time.Minute
+
+// TgLoginLink выдаётся клиенту, начавшему вход через Telegram. Nonce передаётся боту в ссылке
+// t.me/<бот>?start=<Nonce>, а Secret остаётся у клиента: без него токены по Nonce не выдаются.
+type TgLoginLink struct {
+	Nonce     string
+	Secret    string
+	Link      string
+	ExpiresIn time.Duration
+}
+
+// TgLogin — состояние входа через Telegram, хранится в Redis по ключу TgLoginKey(nonce)
+type TgLogin struct {
+	SecretHash string `json:"secret_hash"`
+	// TelegramID — пользователь Telegram, первым открывший ссылку; другим она уже не подходит
+	TelegramID int64 `json:"telegram_id,omitempty"`
+	// UserID заполняется, когда бот подтвердил вход
+	UserID int64 `json:"user_id,omitempty"`
+}
+
+func TgLoginKey(nonce string) string {
+	return "tg_login:" + nonce
+}
+
+// TgLoginPendingKey хранит nonce, для которого бот ждёт контакт от пользователя Telegram
+func TgLoginPendingKey(telegramID int64) string {
+	return "tg_login_pending:" + strconv.FormatInt(telegramID, 10)
+}
@@ -0,0 +1,32 @@
+package auth
+
+import "time"
+
+// Tokens — пара токенов, которую получает клиент после входа или обновления
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresIn — сколько живёт access-токен
+	ExpiresIn time.Duration
+}
+
+// AccessToken — подписанный JWT и его uuid для отзыва
+type AccessToken struct {
+	Token     string
+	UUID      string
+	ExpiresAt time.Time
+}
+
+// RefreshToken — запись о выданном refresh-токене. Токены одного входа
+// образуют семейство: при ротации старый отзывается, новый получает тот же FamilyID.
+type RefreshToken struct {
+	ID              int64
+	UserID          int64
+	TokenHash       string
+	FamilyID        string
+	AccessUUID      string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	RevokedAt       *time.Time
+	CreatedAt       time.Time
+}
@@ -2,6 +2,7 @@ package cart
 
 import (
 	dishentity "domashka-backend/internal/entity/dishes"
+	"domashka-backend/internal/entity/money"
 	"fmt"
 	"strings"
 )
@@ -16,20 +17,41 @@ type CartItem struct {
 	Notes              string
 }
 
-func (c *CartItem) GetTotalPrice() dishentity.Price {
-	return dishentity.Price{
-		Value:    float32(c.Quantity) * c.Size.PriceValue,
-		Currency: c.Size.PriceCurrency,
+func (c *CartItem) GetTotalPrice() (money.Money, error) {
+	return c.Size.Price.Mul(int64(c.Quantity))
+}
+
+// GetTotalCartPrice суммирует стоимость позиций; у пустой корзины — ноль в рублях
+func GetTotalCartPrice(cartItems []CartItem) (money.Money, error) {
+	totalPrice := money.New(0, money.RUB)
+	if len(cartItems) > 0 {
+		totalPrice.Currency = cartItems[0].Size.Price.Currency
+	}
+	for _, item := range cartItems {
+		itemPrice, err := item.GetTotalPrice()
+		if err != nil {
+			return money.Money{}, err
+		}
+		totalPrice, err = totalPrice.Add(itemPrice)
+		if err != nil {
+			return money.Money{}, err
+		}
 	}
+	return totalPrice, nil
 }
 
-func GetTotalCartPrice(cartItems []CartItem) dishentity.Price {
-	totalPrice := dishentity.Price{Value: 0, Currency: "RUB"}
+// GetChefIDs возвращает ID поваров из корзины без повторов, в порядке появления
+func GetChefIDs(cartItems []CartItem) []int64 {
+	chefIDs := make([]int64, 0, 1)
+	seen := make(map[int64]struct{}, 1)
 	for _, item := range cartItems {
-		totalPrice.Value += float32(item.Quantity) * item.Size.PriceValue
-		totalPrice.Currency = item.Size.PriceCurrency
+		if _, ok := seen[item.Dish.ChefID]; ok {
+			continue
+		}
+		seen[item.Dish.ChefID] = struct{}{}
+		chefIDs = append(chefIDs, item.Dish.ChefID)
 	}
-	return totalPrice
+	return chefIDs
 }
 
 func (c *CartItem) GetDetailsString() string {
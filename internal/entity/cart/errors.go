@@ -0,0 +1,26 @@
+package cart
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrChefConflict     = errors.New("cart already contains dishes from another chef")
+	ErrMultipleChefs    = errors.New("cart contains dishes from several chefs")
+	ErrCartItemNotFound = errors.New("cart item not found")
+)
+
+// ChefConflictError возвращается при попытке положить в корзину блюдо другого повара
+type ChefConflictError struct {
+	CartChefID int64
+	DishChefID int64
+}
+
+func (e *ChefConflictError) Error() string {
+	return fmt.Sprintf("%s: cart chef %d, dish chef %d", ErrChefConflict, e.CartChefID, e.DishChefID)
+}
+
+func (e *ChefConflictError) Unwrap() error {
+	return ErrChefConflict
+}
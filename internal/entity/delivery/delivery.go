@@ -0,0 +1,87 @@
+package delivery
+
+import (
+	"errors"
+	"math"
+
+	"domashka-backend/internal/entity/money"
+)
+
+var ErrTooFar = errors.New("delivery address is too far from chef")
+
+// Tariff — правила расчёта стоимости доставки
+type Tariff struct {
+	BaseFee  money.Money // фиксированная часть
+	PerKmFee money.Money // за каждый начатый километр
+	// FreeThreshold — сумма корзины, от которой доставка бесплатна; ноль — без порога
+	FreeThreshold money.Money
+	// MaxDistanceMeters — максимальное расстояние доставки; ноль — без ограничения
+	MaxDistanceMeters float64
+}
+
+// TariffOverride — настройки доставки конкретного повара.
+// Незаданные (nil) поля берутся из общего тарифа.
+type TariffOverride struct {
+	ChefID            int64
+	BaseFee           *money.Money
+	PerKmFee          *money.Money
+	FreeThreshold     *money.Money
+	MaxDistanceMeters *float64
+}
+
+// Quote — рассчитанная стоимость доставки
+type Quote struct {
+	Fee            money.Money
+	DistanceMeters float64
+	IsFree         bool
+}
+
+// WithOverride возвращает тариф с применёнными настройками повара
+func (t Tariff) WithOverride(o *TariffOverride) Tariff {
+	if o == nil {
+		return t
+	}
+	if o.BaseFee != nil {
+		t.BaseFee = *o.BaseFee
+	}
+	if o.PerKmFee != nil {
+		t.PerKmFee = *o.PerKmFee
+	}
+	if o.FreeThreshold != nil {
+		t.FreeThreshold = *o.FreeThreshold
+	}
+	if o.MaxDistanceMeters != nil {
+		t.MaxDistanceMeters = *o.MaxDistanceMeters
+	}
+	return t
+}
+
+// Quote считает стоимость доставки на расстояние distanceMeters для корзины на сумму cartTotal:
+// база плюс плата за каждый начатый километр, либо ноль при сумме корзины от порога.
+func (t Tariff) Quote(distanceMeters float64, cartTotal money.Money) (Quote, error) {
+	if t.MaxDistanceMeters > 0 && distanceMeters > t.MaxDistanceMeters {
+		return Quote{}, ErrTooFar
+	}
+	belowThreshold, err := cartTotal.Less(t.FreeThreshold)
+	if err != nil {
+		return Quote{}, err
+	}
+	if !t.FreeThreshold.IsZero() && !belowThreshold {
+		return Quote{
+			Fee:            money.New(0, t.BaseFee.Currency),
+			DistanceMeters: distanceMeters,
+			IsFree:         true,
+		}, nil
+	}
+
+	km := int64(math.Ceil(distanceMeters / 1000))
+	distanceFee, err := t.PerKmFee.Mul(km)
+	if err != nil {
+		return Quote{}, err
+	}
+	fee, err := t.BaseFee.Add(distanceFee)
+	if err != nil {
+		return Quote{}, err
+	}
+	return Quote{Fee: fee, DistanceMeters: distanceMeters}, nil
+}
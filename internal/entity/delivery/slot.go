@@ -0,0 +1,101 @@
+package delivery
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrSlotUnavailable = errors.New("delivery slot is unavailable")
+	// ErrSlotRequired — у повара заданы рабочие часы, значит заказ оформляется только на слот
+	ErrSlotRequired        = errors.New("delivery slot is required")
+	ErrInvalidWorkingHours = errors.New("invalid working hours")
+)
+
+// WorkingHours — рабочие часы повара в один из дней недели.
+// Start и End — смещение от начала дня в часовом поясе доставки.
+type WorkingHours struct {
+	ChefID       int64
+	Weekday      time.Weekday
+	Start        time.Duration
+	End          time.Duration
+	SlotDuration time.Duration
+	SlotCapacity int32 // сколько заказов повар успевает доставить за слот
+}
+
+// Validate проверяет, что из интервала нарезается хотя бы один слот.
+// Длительность слота хранится в минутах, поэтому должна делиться на минуту нацело.
+func (h WorkingHours) Validate() error {
+	switch {
+	case h.Weekday < time.Sunday || h.Weekday > time.Saturday:
+		return fmt.Errorf("%w: weekday %d", ErrInvalidWorkingHours, h.Weekday)
+	case h.Start < 0 || h.End > 24*time.Hour:
+		return fmt.Errorf("%w: interval %s-%s is outside of a day", ErrInvalidWorkingHours, h.Start, h.End)
+	case h.SlotDuration <= 0 || h.SlotDuration%time.Minute != 0:
+		return fmt.Errorf("%w: slot duration %s", ErrInvalidWorkingHours, h.SlotDuration)
+	case h.Start+h.SlotDuration > h.End:
+		return fmt.Errorf("%w: interval %s-%s is shorter than a slot", ErrInvalidWorkingHours, h.Start, h.End)
+	case h.SlotCapacity <= 0:
+		return fmt.Errorf("%w: slot capacity %d", ErrInvalidWorkingHours, h.SlotCapacity)
+	}
+	return nil
+}
+
+// ValidateWorkingHours проверяет каждый интервал и то, что интервалы одного дня не пересекаются
+func ValidateWorkingHours(hours []WorkingHours) error {
+	sorted := slices.Clone(hours)
+	slices.SortFunc(sorted, func(a, b WorkingHours) int {
+		if a.Weekday != b.Weekday {
+			return cmp.Compare(a.Weekday, b.Weekday)
+		}
+		return cmp.Compare(a.Start, b.Start)
+	})
+	for i, h := range sorted {
+		if err := h.Validate(); err != nil {
+			return err
+		}
+		if i > 0 && sorted[i-1].Weekday == h.Weekday && sorted[i-1].End > h.Start {
+			return fmt.Errorf("%w: intervals overlap on weekday %d", ErrInvalidWorkingHours, h.Weekday)
+		}
+	}
+	return nil
+}
+
+// Slot — интервал доставки повара с ограниченным числом заказов
+type Slot struct {
+	ID       int64
+	ChefID   int64
+	StartsAt time.Time
+	EndsAt   time.Time
+	Capacity int32
+	Reserved int32
+}
+
+func (s Slot) IsAvailable() bool {
+	return s.Reserved < s.Capacity
+}
+
+// GenerateSlots нарезает рабочие часы на слоты на день day (берётся дата в его часовом поясе)
+func GenerateSlots(hours []WorkingHours, day time.Time) []Slot {
+	year, month, date := day.Date()
+	midnight := time.Date(year, month, date, 0, 0, 0, 0, day.Location())
+
+	slots := make([]Slot, 0)
+	for _, h := range hours {
+		if h.Weekday != day.Weekday() || h.SlotDuration <= 0 {
+			continue
+		}
+		for start := h.Start; start+h.SlotDuration <= h.End; start += h.SlotDuration {
+			slots = append(slots, Slot{
+				ChefID:   h.ChefID,
+				StartsAt: midnight.Add(start),
+				EndsAt:   midnight.Add(start + h.SlotDuration),
+				Capacity: h.SlotCapacity,
+			})
+		}
+	}
+	return slots
+}
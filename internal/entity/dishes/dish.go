@@ -1,5 +1,7 @@
 package dishes
 
+import "domashka-backend/internal/entity/money"
+
 type Dish struct {
 	ID           int64  `db:"id"`
 	Name         string `db:"name"`
@@ -30,18 +32,12 @@ type Ingredient struct {
 }
 
 type Size struct {
-	ID            int64   `db:"id"`
-	DishID        int64   `db:"dish_id"`
-	Label         string  `db:"label"`
-	WeightValue   float32 `db:"weight_value"`
-	WeightUnit    string  `db:"weight_unit"`
-	PriceValue    float32 `db:"price_value"`
-	PriceCurrency string  `db:"price_currency"`
-}
-
-type Price struct {
-	Value    float32
-	Currency string
+	ID          int64   `db:"id"`
+	DishID      int64   `db:"dish_id"`
+	Label       string  `db:"label"`
+	WeightValue float32 `db:"weight_value"`
+	WeightUnit  string  `db:"weight_unit"`
+	Price       money.Money
 }
 
 type Category struct {
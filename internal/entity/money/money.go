@@ -0,0 +1,197 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Currency string
+
+const (
+	RUB Currency = "RUB"
+
+	// minorUnits — сколько минимальных единиц (копеек) в одной основной
+	minorUnits = 100
+	// minorDigits — число знаков после запятой у minorUnits
+	minorDigits = 2
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid money amount")
+)
+
+// currencySigns — как валюта подписывается в интерфейсе
+var currencySigns = map[Currency]string{
+	RUB: "р",
+}
+
+// Money — денежная сумма в минимальных единицах валюты (копейках).
+// Нулевое значение без валюты — пустая сумма, которую можно сложить с любой валютой.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+func New(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse разбирает десятичную запись суммы в основных единицах ("169", "169.5", "169,50")
+func Parse(value string, currency Currency) (Money, error) {
+	s := strings.TrimSpace(strings.Replace(value, ",", ".", 1))
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" || len(frac) > minorDigits {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	frac += strings.Repeat("0", minorDigits-len(frac))
+
+	w, err := strconv.ParseUint(whole, 10, 63)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	f, err := strconv.ParseUint(frac, 10, 63)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	// Сумма в копейках должна поместиться в int64
+	if w > (math.MaxInt64-f)/minorUnits {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+
+	amount := int64(w)*minorUnits + int64(f)
+	if negative {
+		amount = -amount
+	}
+	return New(amount, currency), nil
+}
+
+// Add складывает суммы в одной валюте
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := m.commonCurrency(other)
+	if err != nil {
+		return Money{}, err
+	}
+	amount, ok := addInt64(m.Amount, other.Amount)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s + %s overflows", ErrInvalidAmount, m, other)
+	}
+	return New(amount, currency), nil
+}
+
+// Sub вычитает сумму в той же валюте
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := m.commonCurrency(other)
+	if err != nil {
+		return Money{}, err
+	}
+	if other.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %s - %s overflows", ErrInvalidAmount, m, other)
+	}
+	amount, ok := addInt64(m.Amount, -other.Amount)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s - %s overflows", ErrInvalidAmount, m, other)
+	}
+	return New(amount, currency), nil
+}
+
+// Mul умножает сумму на количество
+func (m Money) Mul(quantity int64) (Money, error) {
+	if m.Amount == 0 || quantity == 0 {
+		return New(0, m.Currency), nil
+	}
+	amount := m.Amount * quantity
+	if amount/quantity != m.Amount || (quantity == -1 && m.Amount == math.MinInt64) {
+		return Money{}, fmt.Errorf("%w: %s * %d overflows", ErrInvalidAmount, m, quantity)
+	}
+	return New(amount, m.Currency), nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Less сравнивает суммы в одной валюте
+func (m Money) Less(other Money) (bool, error) {
+	if _, err := m.commonCurrency(other); err != nil {
+		return false, err
+	}
+	return m.Amount < other.Amount, nil
+}
+
+// Major возвращает точную десятичную запись в основных единицах без лишних нулей: "169", "169.5"
+func (m Money) Major() string {
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	whole := strconv.FormatInt(amount/minorUnits, 10)
+	frac := amount % minorUnits
+	if frac == 0 {
+		return sign + whole
+	}
+	fracStr := strings.TrimRight(fmt.Sprintf("%0*d", minorDigits, frac), "0")
+	return sign + whole + "." + fracStr
+}
+
+// Number возвращает сумму как JSON-число в основных единицах
+func (m Money) Number() json.Number {
+	return json.Number(m.Major())
+}
+
+// Format возвращает сумму для показа пользователю: "169р", "169,50р"
+func (m Money) Format() string {
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	value := strconv.FormatInt(amount/minorUnits, 10)
+	if frac := amount % minorUnits; frac != 0 {
+		value += fmt.Sprintf(",%0*d", minorDigits, frac)
+	}
+	if currencySign, ok := currencySigns[m.Currency]; ok {
+		return sign + value + currencySign
+	}
+	return sign + value + " " + string(m.Currency)
+}
+
+// FormatFrom возвращает цену «от»: "от 169р"
+func (m Money) FormatFrom() string {
+	return "от " + m.Format()
+}
+
+func (m Money) String() string {
+	return m.Major() + " " + string(m.Currency)
+}
+
+func (m Money) commonCurrency(other Money) (Currency, error) {
+	switch {
+	case m.Currency == other.Currency:
+		return m.Currency, nil
+	case m.Currency == "" && m.Amount == 0:
+		return other.Currency, nil
+	case other.Currency == "" && other.Amount == 0:
+		return m.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+}
+
+// addInt64 складывает с проверкой переполнения
+func addInt64(a, b int64) (int64, bool) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, false
+	}
+	return sum, true
+}
@@ -0,0 +1,230 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Money
+		wantErr error
+	}{
+		{name: "whole", value: "169", want: New(16900, RUB)},
+		{name: "one fraction digit", value: "169.5", want: New(16950, RUB)},
+		{name: "comma", value: "169,50", want: New(16950, RUB)},
+		{name: "spaces", value: " 1.05 ", want: New(105, RUB)},
+		{name: "zero", value: "0", want: New(0, RUB)},
+		{name: "negative", value: "-12.34", want: New(-1234, RUB)},
+		{name: "negative kopecks", value: "-0.05", want: New(-5, RUB)},
+		{name: "max", value: "92233720368547758.07", want: New(9223372036854775807, RUB)},
+		{name: "plus sign", value: "+5", wantErr: ErrInvalidAmount},
+		{name: "double minus", value: "--5", wantErr: ErrInvalidAmount},
+		{name: "only minus", value: "-", wantErr: ErrInvalidAmount},
+		{name: "empty", value: "", wantErr: ErrInvalidAmount},
+		{name: "no whole part", value: ".5", wantErr: ErrInvalidAmount},
+		{name: "too many fraction digits", value: "1.005", wantErr: ErrInvalidAmount},
+		{name: "letters", value: "12a", wantErr: ErrInvalidAmount},
+		{name: "overflow", value: "92233720368547758.08", wantErr: ErrInvalidAmount},
+		{name: "whole overflow", value: "92233720368547759", wantErr: ErrInvalidAmount},
+		{name: "uint overflow", value: "99999999999999999999", wantErr: ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, RUB)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Format(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{name: "whole", money: New(16900, RUB), want: "169р"},
+		{name: "kopecks", money: New(16950, RUB), want: "169,50р"},
+		{name: "leading zero kopecks", money: New(16905, RUB), want: "169,05р"},
+		{name: "only kopecks", money: New(5, RUB), want: "0,05р"},
+		{name: "zero", money: New(0, RUB), want: "0р"},
+		{name: "negative", money: New(-16950, RUB), want: "-169,50р"},
+		{name: "unknown currency", money: New(100, "USD"), want: "1 USD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.Format(); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Major(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{name: "whole", money: New(16900, RUB), want: "169"},
+		{name: "trailing zero trimmed", money: New(16950, RUB), want: "169.5"},
+		{name: "kopecks", money: New(16905, RUB), want: "169.05"},
+		{name: "only kopecks", money: New(5, RUB), want: "0.05"},
+		{name: "zero", money: New(0, RUB), want: "0"},
+		{name: "negative", money: New(-1234, RUB), want: "-12.34"},
+		{name: "negative kopecks", money: New(-5, RUB), want: "-0.05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.Major(); got != tt.want {
+				t.Errorf("Major() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_AddSub(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		wantAdd Money
+		wantSub Money
+		wantErr error
+	}{
+		{
+			name:    "same currency",
+			a:       New(500, RUB),
+			b:       New(200, RUB),
+			wantAdd: New(700, RUB),
+			wantSub: New(300, RUB),
+		},
+		{
+			name:    "empty left",
+			a:       Money{},
+			b:       New(200, RUB),
+			wantAdd: New(200, RUB),
+			wantSub: New(-200, RUB),
+		},
+		{
+			name:    "empty right",
+			a:       New(500, RUB),
+			b:       Money{},
+			wantAdd: New(500, RUB),
+			wantSub: New(500, RUB),
+		},
+		{
+			name:    "mismatched currencies",
+			a:       New(500, RUB),
+			b:       New(200, "USD"),
+			wantErr: ErrCurrencyMismatch,
+		},
+		{
+			name:    "non-zero amount without currency",
+			a:       New(500, RUB),
+			b:       New(200, ""),
+			wantErr: ErrCurrencyMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAdd, err := tt.a.Add(tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Add() error = %v, wantErr %v", err, tt.wantErr)
+			} else if gotAdd != tt.wantAdd {
+				t.Errorf("Add() = %v, want %v", gotAdd, tt.wantAdd)
+			}
+
+			gotSub, err := tt.a.Sub(tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Sub() error = %v, wantErr %v", err, tt.wantErr)
+			} else if gotSub != tt.wantSub {
+				t.Errorf("Sub() = %v, want %v", gotSub, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestMoney_Overflow(t *testing.T) {
+	maxAmount := New(math.MaxInt64, RUB)
+	minAmount := New(math.MinInt64, RUB)
+
+	if _, err := maxAmount.Add(New(1, RUB)); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Add() error = %v, want %v", err, ErrInvalidAmount)
+	}
+	if _, err := minAmount.Add(New(-1, RUB)); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Add() error = %v, want %v", err, ErrInvalidAmount)
+	}
+	if _, err := minAmount.Sub(New(1, RUB)); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Sub() error = %v, want %v", err, ErrInvalidAmount)
+	}
+	if _, err := New(0, RUB).Sub(minAmount); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Sub() error = %v, want %v", err, ErrInvalidAmount)
+	}
+	if got, err := maxAmount.Sub(maxAmount); err != nil || got != New(0, RUB) {
+		t.Errorf("Sub() = %v, %v, want %v", got, err, New(0, RUB))
+	}
+}
+
+func TestMoney_Mul(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		quantity int64
+		want     Money
+		wantErr  error
+	}{
+		{name: "quantity", money: New(16950, RUB), quantity: 3, want: New(50850, RUB)},
+		{name: "zero quantity", money: New(16950, RUB), quantity: 0, want: New(0, RUB)},
+		{name: "negative", money: New(100, RUB), quantity: -2, want: New(-200, RUB)},
+		{name: "overflow", money: New(math.MaxInt64/2+1, RUB), quantity: 2, wantErr: ErrInvalidAmount},
+		{name: "min by minus one", money: New(math.MinInt64, RUB), quantity: -1, wantErr: ErrInvalidAmount},
+		{name: "minus one by min", money: New(-1, RUB), quantity: math.MinInt64, wantErr: ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.money.Mul(tt.quantity)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Mul() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Mul() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Less(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		want    bool
+		wantErr error
+	}{
+		{name: "less", a: New(100, RUB), b: New(200, RUB), want: true},
+		{name: "equal", a: New(200, RUB), b: New(200, RUB), want: false},
+		{name: "empty left", a: Money{}, b: New(200, RUB), want: true},
+		{name: "mismatched currencies", a: New(100, RUB), b: New(200, "USD"), wantErr: ErrCurrencyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Less(tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Less() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Less() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
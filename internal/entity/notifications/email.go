@@ -0,0 +1,63 @@
+package notifications
+
+import (
+	"errors"
+	"strings"
+)
+
+// EmailTemplate — имя шаблона системного письма
+type EmailTemplate string
+
+const (
+	EmailTemplateOTP       EmailTemplate = "otp"
+	EmailTemplateMagicLink EmailTemplate = "magic_link"
+)
+
+// EmailTemplateData — подстановки для шаблонов писем
+type EmailTemplateData struct {
+	Code string
+	Link string
+}
+
+// Render собирает тему и содержимое письма
+func (t EmailTemplate) Render(data EmailTemplateData) (string, EmailData, error) {
+	switch t {
+	case EmailTemplateOTP:
+		return "Код для входа в Домашку", EmailData{
+			Title:  "Код для входа",
+			Body:   "Ваш код для входа: " + data.Code + ". Никому его не сообщайте.",
+			Footer: "Команда Домашки",
+		}, nil
+	case EmailTemplateMagicLink:
+		return "Вход в Домашку", EmailData{
+			Title:    "Вход по ссылке",
+			Body:     "Нажмите на кнопку, чтобы войти. Ссылка одноразовая. Если вы не запрашивали вход, просто удалите это письмо.",
+			Footer:   "Команда Домашки",
+			Link:     data.Link,
+			LinkText: "Войти",
+		}, nil
+	}
+	return "", EmailData{}, errors.New("unknown email template: " + string(t))
+}
+
+// Masked возвращает данные без секретов — в таком виде письмо сохраняется в notifications
+func (d EmailTemplateData) Masked() EmailTemplateData {
+	if d.Code != "" {
+		d.Code = strings.Repeat("*", len(d.Code))
+	}
+	if d.Link != "" {
+		d.Link = "***"
+	}
+	return d
+}
+
+// Sensitive — в данных есть секрет, и сохранённое письмо отличается от отправленного
+func (d EmailTemplateData) Sensitive() bool {
+	return d.Code != "" || d.Link != ""
+}
+
+// EmailMetadata — что сохраняем в notifications.metadata для системных писем
+type EmailMetadata struct {
+	Template EmailTemplate `json:"template,omitempty"`
+	Masked   bool          `json:"masked,omitempty"`
+}
@@ -37,4 +37,7 @@ type EmailData struct {
 	UserName string
 	Body     string
 	Footer   string
+	// Link выводится кнопкой с текстом LinkText под текстом письма
+	Link     string
+	LinkText string
 }
@@ -0,0 +1,60 @@
+package notifications
+
+import (
+	"errors"
+	"strings"
+	"text/template"
+)
+
+// ErrNotResendable — текст уведомления сохранён без секрета (например, кода входа), повторить его нельзя
+var ErrNotResendable = errors.New("notification cannot be resent")
+
+// SMSTemplate — имя шаблона текста SMS
+type SMSTemplate string
+
+const (
+	SMSTemplateOTP SMSTemplate = "otp"
+)
+
+var smsTemplates = map[SMSTemplate]*template.Template{
+	SMSTemplateOTP: template.Must(template.New(string(SMSTemplateOTP)).Parse(
+		"Код для входа в Домашку: {{.Code}}. Никому его не сообщайте.")),
+}
+
+// SMSData — подстановки для шаблонов SMS
+type SMSData struct {
+	Code string
+}
+
+// Render собирает текст сообщения
+func (t SMSTemplate) Render(data SMSData) (string, error) {
+	tmpl, ok := smsTemplates[t]
+	if !ok {
+		return "", errors.New("unknown sms template: " + string(t))
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Masked возвращает данные без секретов — в таком виде текст сохраняется в notifications
+func (d SMSData) Masked() SMSData {
+	if d.Code != "" {
+		d.Code = strings.Repeat("*", len(d.Code))
+	}
+	return d
+}
+
+// Sensitive — в данных есть секрет, и сохранённый текст отличается от отправленного
+func (d SMSData) Sensitive() bool {
+	return d.Code != ""
+}
+
+// SMSMetadata — что сохраняем в notifications.metadata для SMS
+type SMSMetadata struct {
+	Template          SMSTemplate `json:"template,omitempty"`
+	Masked            bool        `json:"masked,omitempty"`
+	ProviderMessageID string      `json:"provider_message_id,omitempty"`
+}
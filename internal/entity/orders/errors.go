@@ -0,0 +1,31 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrStatusConflict    = errors.New("order status was changed concurrently")
+	ErrNotModifiable     = errors.New("order cannot be modified in current status")
+	ErrItemNotFound      = errors.New("ordered item not found")
+	ErrLastItem          = errors.New("cannot remove the last ordered item")
+	ErrNotOrderOwner     = errors.New("order belongs to another user")
+	ErrCancelNotAllowed  = errors.New("order can no longer be cancelled")
+)
+
+// TransitionError возвращается при попытке недопустимой смены статуса заказа
+type TransitionError struct {
+	From int32
+	To   int32
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s: %d -> %d", ErrInvalidTransition, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
@@ -5,6 +5,8 @@ import (
 
 	cartentity "domashka-backend/internal/entity/cart"
 	chefEntity "domashka-backend/internal/entity/chefs"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/payments"
 )
 
 const (
@@ -14,19 +16,29 @@ const (
 	StatusCooked
 	StatusInDelivery
 	StatusDelivered
+	// StatusAwaitingPayment — заказ создан, но оплата ещё не авторизована;
+	// повар видит заказ только после перехода в StatusCreated
+	StatusAwaitingPayment
+	// StatusCancelled — заказ отменён клиентом
+	StatusCancelled
+	// StatusDeliveryUnconfirmed — заказ слишком долго был в доставке и закрыт системой.
+	// Деньги не списаны: доставку подтверждают вручную либо заказ отклоняют с возвратом
+	StatusDeliveryUnconfirmed
 )
 
 type Order struct {
-	ID              int64     `db:"id"`
-	ChefID          int64     `db:"chef_id"`
-	ShiftID         int64     `db:"shift"`
-	Status          int32     `db:"status"`
-	CreatedAt       time.Time `db:"created_at"`
-	UpdatedAt       time.Time `db:"updated_at"`
-	TotalCost       float64   `db:"total_cost"`
-	LeaveByTheDoor  bool      `db:"leave_by_the_door"`
-	ClientAddressID int64     `db:"client_address_id"`
-	UserID          int64     `db:"user_id"`
+	ID              int64       `db:"id"`
+	ChefID          int64       `db:"chef_id"`
+	ShiftID         int64       `db:"shift"`
+	Status          int32       `db:"status"`
+	CreatedAt       time.Time   `db:"created_at"`
+	UpdatedAt       time.Time   `db:"updated_at"`
+	TotalCost       money.Money // с учётом доставки
+	DeliveryFee     money.Money
+	DeliverySlotID  *int64 `db:"delivery_slot_id"` // у старых заказов слота нет
+	LeaveByTheDoor  bool   `db:"leave_by_the_door"`
+	ClientAddressID int64  `db:"client_address_id"`
+	UserID          int64  `db:"user_id"`
 }
 
 // ReviewDetail должен быть определён в том же пакете или импортирован
@@ -38,8 +50,9 @@ type ReviewDetail struct {
 
 // OrderProfile объединяет основные данные заказа и сопутствующую информацию
 type OrderProfile struct {
-	Order  *Order                // основная сущность заказа из этой же модели
-	Items  []cartentity.CartItem // позиции в заказе
-	Chef   *chefEntity.Chef      // данные шеф-повара
-	Review *ReviewDetail         // отзыв пользователя по этому заказу
+	Order   *Order                // основная сущность заказа из этой же модели
+	Items   []cartentity.CartItem // позиции в заказе
+	Chef    *chefEntity.Chef      // данные шеф-повара
+	Review  *ReviewDetail         // отзыв пользователя по этому заказу
+	Refunds []payments.Refund     // возвраты денег по заказу
 }
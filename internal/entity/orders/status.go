@@ -0,0 +1,70 @@
+package orders
+
+import "time"
+
+// Роли инициаторов смены статуса заказа
+const (
+	ActorRoleClient = "client"
+	ActorRoleChef   = "chef"
+	ActorRoleSystem = "system"
+)
+
+// transitions описывает допустимые переходы между статусами заказа.
+// Статусы Delivered, Rejected и Cancelled конечные — из них переходов нет.
+// Отмена из Accepted дополнительно ограничена по времени, см. CanCancel.
+var transitions = map[int32][]int32{
+	StatusAwaitingPayment:     {StatusCreated, StatusRejected},
+	StatusCreated:             {StatusAccepted, StatusRejected, StatusCancelled},
+	StatusAccepted:            {StatusCooked, StatusRejected, StatusCancelled},
+	StatusCooked:              {StatusInDelivery, StatusRejected},
+	StatusInDelivery:          {StatusDelivered, StatusDeliveryUnconfirmed},
+	StatusDeliveryUnconfirmed: {StatusDelivered, StatusRejected},
+}
+
+// Actor описывает, кто инициировал смену статуса
+type Actor struct {
+	UserID int64  // 0, если действие выполнено системой или пользователь неизвестен
+	Role   string // client, chef, system
+}
+
+// StatusChange — запись в истории статусов заказа
+type StatusChange struct {
+	ID         int64     `db:"id"`
+	OrderID    int64     `db:"order_id"`
+	FromStatus *int32    `db:"from_status"` // nil для первой записи при создании заказа
+	ToStatus   int32     `db:"to_status"`
+	ActorID    *int64    `db:"actor_id"`
+	ActorRole  string    `db:"actor_role"`
+	Reason     string    `db:"reason"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// CanTransition проверяет, можно ли перевести заказ из статуса from в статус to
+func CanTransition(from, to int32) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanCancel проверяет, может ли клиент отменить заказ в момент now.
+// Принятый заказ можно отменить только в течение grace после принятия (acceptedAt).
+func CanCancel(status int32, acceptedAt, now time.Time, grace time.Duration) bool {
+	switch status {
+	case StatusCreated:
+		return true
+	case StatusAccepted:
+		return now.Before(acceptedAt.Add(grace))
+	}
+	return false
+}
+
+// ValidateTransition возвращает *TransitionError, если переход недопустим
+func ValidateTransition(from, to int32) error {
+	if !CanTransition(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package payments
+
+import (
+	"errors"
+	"time"
+
+	"domashka-backend/internal/entity/money"
+)
+
+type Status string
+
+const (
+	StatusPending    Status = "pending"    // ждём подтверждения от провайдера (3-D Secure и т.п.)
+	StatusAuthorized Status = "authorized" // деньги заблокированы на карте клиента
+	StatusCaptured   Status = "captured"   // деньги списаны
+	StatusRefunded   Status = "refunded"
+	StatusCancelled  Status = "cancelled" // блокировка снята без списания
+	StatusFailed     Status = "failed"
+)
+
+// RefundStatus — статус возврата. Возврат сохраняется вместе с изменением заказа в статусе pending,
+// а completed становится после ответа провайдера.
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusCompleted RefundStatus = "completed"
+)
+
+var (
+	ErrPaymentNotFound  = errors.New("payment not found")
+	ErrDeclined         = errors.New("payment declined")
+	ErrInvalidSignature = errors.New("invalid payment webhook signature")
+	ErrStatusConflict   = errors.New("payment status was changed concurrently")
+	ErrRefundTooLarge   = errors.New("refund exceeds paid amount")
+	ErrNotRefundable    = errors.New("payment cannot be refunded in current status")
+	ErrDisabled         = errors.New("payments are disabled")
+)
+
+// transitions описывает допустимые переходы между статусами платежа
+var transitions = map[Status][]Status{
+	StatusPending:    {StatusAuthorized, StatusFailed},
+	StatusAuthorized: {StatusCaptured, StatusCancelled},
+	StatusCaptured:   {StatusRefunded},
+}
+
+func CanTransition(from, to Status) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Payment — оплата заказа у платёжного провайдера
+type Payment struct {
+	ID                int64
+	OrderID           int64
+	UserID            int64
+	Provider          string
+	ProviderPaymentID string
+	Amount            money.Money
+	Status            Status
+	// CaptureRequestedAt — когда заказ доставили и деньги нужно списать; nil — списание не запрошено
+	CaptureRequestedAt *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// Refund — возврат денег клиенту за весь заказ или за одну позицию
+type Refund struct {
+	ID            int64
+	OrderID       int64
+	PaymentID     int64
+	OrderedItemID *int64 // nil — возврат за весь заказ
+	Amount        money.Money
+	Reason        string
+	Status        RefundStatus
+	CreatedAt     time.Time
+}
+
+// Method — способ оплаты, который предлагается клиенту
+type Method struct {
+	ID    int64
+	Type  string
+	Title string
+}
+
+// IntentRequest — запрос провайдеру на блокировку суммы заказа
+type IntentRequest struct {
+	OrderID int64
+	UserID  int64
+	Amount  money.Money
+	// IdempotencyKey не даёт провайдеру заблокировать деньги дважды при повторе запроса
+	IdempotencyKey string
+}
+
+// Intent — ответ провайдера на создание платежа
+type Intent struct {
+	ProviderPaymentID string
+	Status            Status
+}
+
+// Event — уведомление провайдера о смене статуса платежа
+type Event struct {
+	ProviderPaymentID string
+	Status            Status
+}
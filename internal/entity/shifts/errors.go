@@ -0,0 +1,8 @@
+package shifts
+
+import "errors"
+
+var (
+	ErrShiftAlreadyOpen = errors.New("shift is already open")
+	ErrShiftNotOpen     = errors.New("no open shift")
+)
@@ -1,6 +1,10 @@
 package shifts
 
-import "time"
+import (
+	"time"
+
+	"domashka-backend/internal/entity/money"
+)
 
 type Shift struct {
 	ID          int64
@@ -8,11 +12,11 @@ type Shift struct {
 	IsActive    bool
 	CreatedAt   time.Time
 	ClosedAt    *time.Time
-	TotalProfit float64
+	TotalProfit money.Money
 }
 
 type DailyProfit struct {
 	Month  string
 	Date   string
-	Profit float32
+	Profit money.Money
 }
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+
+	"domashka-backend/internal/custom_errors"
+	authentity "domashka-backend/internal/entity/auth"
+)
+
+func (r *Repository) CreateSession(ctx context.Context, s *authentity.Session) error {
+	_, err := r.pg.Conn(ctx).Exec(ctx, `
+		INSERT INTO sessions (id, user_id, device_name, platform, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, s.ID, s.UserID, s.Device.Name, s.Device.Platform, s.Device.IP, s.Device.UserAgent)
+	return err
+}
+
+// GetActiveSessionsByUserID возвращает неотозванные сессии, последние активные — первыми
+func (r *Repository) GetActiveSessionsByUserID(ctx context.Context, userID int64) ([]authentity.Session, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		SELECT id, user_id, device_name, platform, ip, user_agent, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY last_seen_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []authentity.Session
+	for rows.Next() {
+		var s authentity.Session
+		if err := rows.Scan(
+			&s.ID,
+			&s.UserID,
+			&s.Device.Name,
+			&s.Device.Platform,
+			&s.Device.IP,
+			&s.Device.UserAgent,
+			&s.CreatedAt,
+			&s.LastSeenAt,
+			&s.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession отзывает сессию пользователя; чужая или уже отозванная — ErrSessionNotFound
+func (r *Repository) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	tag, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return custom_errors.ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions отзывает все сессии пользователя и возвращает их id
+func (r *Repository) RevokeUserSessions(ctx context.Context, userID int64) ([]string, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		UPDATE sessions
+		SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL
+		RETURNING id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// TouchSession обновляет время последней активности и адрес, с которого она была
+func (r *Repository) TouchSession(ctx context.Context, sessionID, ip string) error {
+	_, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE sessions
+		SET last_seen_at = now(), ip = COALESCE(NULLIF($2, ''), ip)
+		WHERE id = $1 AND revoked_at IS NULL
+	`, sessionID, ip)
+	return err
+}
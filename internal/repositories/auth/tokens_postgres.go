@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+
+	"domashka-backend/internal/custom_errors"
+	authentity "domashka-backend/internal/entity/auth"
+	"domashka-backend/pkg/postgres"
+)
+
+type Repository struct {
+	pg *postgres.Postgres
+}
+
+func New(pg *postgres.Postgres) *Repository {
+	return &Repository{
+		pg: pg,
+	}
+}
+
+const refreshTokenColumns = `
+	id,
+	user_id,
+	token_hash,
+	family_id,
+	access_uuid,
+	access_expires_at,
+	expires_at,
+	revoked_at,
+	created_at
+`
+
+func (r *Repository) CreateRefreshToken(ctx context.Context, t *authentity.RefreshToken) (int64, error) {
+	var id int64
+	err := r.pg.Conn(ctx).QueryRow(ctx, `
+		INSERT INTO refresh_tokens (user_id, token_hash, family_id, access_uuid, access_expires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, t.UserID, t.TokenHash, t.FamilyID, t.AccessUUID, t.AccessExpiresAt, t.ExpiresAt).Scan(&id)
+	return id, err
+}
+
+// GetRefreshTokenByHash возвращает токен вместе с отозванными: по ним ловим повторное использование
+func (r *Repository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*authentity.RefreshToken, error) {
+	row := r.pg.Conn(ctx).QueryRow(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_hash = $1`, tokenHash)
+	return scanRefreshToken(row)
+}
+
+func (r *Repository) GetRefreshTokenByAccessUUID(ctx context.Context, accessUUID string) (*authentity.RefreshToken, error) {
+	row := r.pg.Conn(ctx).QueryRow(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE access_uuid = $1`, accessUUID)
+	return scanRefreshToken(row)
+}
+
+// RevokeRefreshToken отзывает токен, только если он ещё не отозван
+func (r *Repository) RevokeRefreshToken(ctx context.Context, id int64) error {
+	tag, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = now()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return custom_errors.ErrInvalidRefreshToken
+	}
+	return nil
+}
+
+// RevokeFamily отзывает все действующие токены семейства и возвращает их,
+// чтобы можно было отозвать и выданные вместе с ними access-токены
+func (r *Repository) RevokeFamily(ctx context.Context, familyID string) ([]authentity.RefreshToken, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = now()
+		WHERE family_id = $1 AND revoked_at IS NULL
+		RETURNING `+refreshTokenColumns, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []authentity.RefreshToken
+	for rows.Next() {
+		t, err := scanRefreshToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+	return tokens, rows.Err()
+}
+
+func scanRefreshToken(row pgx.Row) (*authentity.RefreshToken, error) {
+	var t authentity.RefreshToken
+	err := row.Scan(
+		&t.ID,
+		&t.UserID,
+		&t.TokenHash,
+		&t.FamilyID,
+		&t.AccessUUID,
+		&t.AccessExpiresAt,
+		&t.ExpiresAt,
+		&t.RevokedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, custom_errors.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return &t, nil
+}
@@ -22,10 +22,23 @@ func New(pg *postgres.Postgres) *Repository {
 }
 
 func (r *Repository) Create(ctx context.Context, userID int64) error {
-	_, err := r.pg.Pool.Exec(ctx, "INSERT INTO carts (user_id) VALUES ($1)", userID)
+	_, err := r.pg.Conn(ctx).Exec(ctx, "INSERT INTO carts (user_id) VALUES ($1)", userID)
 	return err
 }
 
+// LockCart блокирует корзину пользователя до конца транзакции.
+// Строки в carts может ещё не быть, поэтому она создаётся на лету.
+func (r *Repository) LockCart(ctx context.Context, userID int64) error {
+	_, err := r.pg.Conn(ctx).Exec(ctx, `
+		INSERT INTO carts (user_id) VALUES ($1)
+		ON CONFLICT (user_id) DO UPDATE SET updated_at = now()
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to lock cart: %w", err)
+	}
+	return nil
+}
+
 func (r *Repository) AddItem(
 	ctx context.Context,
 	userID int64,
@@ -35,9 +48,9 @@ func (r *Repository) AddItem(
 	removedIngredients []int64,
 	notes string,
 ) (cartItemID int64, err error) {
-	tx, err := r.pg.Pool.Begin(ctx)
+	tx, err := r.pg.Begin(ctx)
 	if err != nil {
-		return 0, nil
+		return 0, err
 	}
 	defer tx.Rollback(ctx)
 
@@ -77,16 +90,21 @@ func (r *Repository) AddItem(
 	return cartItemID, nil
 }
 
-func (r *Repository) RemoveItem(ctx context.Context, cartItemID int64) error {
-	tx, err := r.pg.Pool.Begin(ctx)
+// RemoveItem удаляет позицию из корзины пользователя userID.
+// Чужую или несуществующую позицию не трогает и возвращает cartentity.ErrCartItemNotFound.
+func (r *Repository) RemoveItem(ctx context.Context, userID, cartItemID int64) error {
+	tx, err := r.pg.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
-	_, err = tx.Exec(ctx, "DELETE FROM cart_items WHERE id = $1", cartItemID)
+	tag, err := tx.Exec(ctx, "DELETE FROM cart_items WHERE id = $1 AND user_id = $2", cartItemID, userID)
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return cartentity.ErrCartItemNotFound
+	}
 	_, err = tx.Exec(ctx, "DELETE FROM cart_item_added_ingredients WHERE cart_item_id = $1", cartItemID)
 	if err != nil {
 		return err
@@ -99,11 +117,11 @@ func (r *Repository) RemoveItem(ctx context.Context, cartItemID int64) error {
 }
 
 func (r *Repository) GetCartItems(ctx context.Context, userID int64) ([]cartentity.CartItem, error) {
-	rows, err := r.pg.Pool.Query(ctx, `
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
 		SELECT
 			ci.id, ci.quantity, ci.customer_notes,
 			d.id, d.name, d.description, d.chef_id, d.image_url,
-			ds.id, ds.dish_id, ds.label, ds.weight_value, ds.weight_unit, ds.price_value, ds.price_currency
+			ds.id, ds.dish_id, ds.label, ds.weight_value, ds.weight_unit, ROUND(ds.price_value * 100)::BIGINT, ds.price_currency
 		FROM cart_items ci
 		JOIN dishes d ON ci.dish_id = d.id
 		JOIN dish_sizes ds ON ci.dish_size_id = ds.id
@@ -128,7 +146,7 @@ func (r *Repository) GetCartItems(ctx context.Context, userID int64) ([]cartenti
 		err := rows.Scan(
 			&item.ID, &item.Quantity, &item.Notes,
 			&dish.ID, &dish.Name, &dish.Description, &dish.ChefID, &dish.ImageURL,
-			&size.ID, &size.DishID, &size.Label, &size.WeightValue, &size.WeightUnit, &size.PriceValue, &size.PriceCurrency,
+			&size.ID, &size.DishID, &size.Label, &size.WeightValue, &size.WeightUnit, &size.Price.Amount, &size.Price.Currency,
 		)
 		if err != nil {
 			return nil, err
@@ -164,7 +182,7 @@ func (r *Repository) getCartItemIngredientsMap(ctx context.Context, tableName st
 		return map[int64][]dishentity.Ingredient{}, nil
 	}
 
-	rows, err := r.pg.Pool.Query(ctx, fmt.Sprintf(`
+	rows, err := r.pg.Conn(ctx).Query(ctx, fmt.Sprintf(`
 		SELECT
 			ci.cart_item_id,
 			i.id, i.name, i.image_url, i.is_allergen, i.category_id
@@ -195,40 +213,46 @@ func (r *Repository) getCartItemIngredientsMap(ctx context.Context, tableName st
 }
 
 func (r *Repository) Clear(ctx context.Context, userID int64) error {
-	var carItemIDs []int64
-	rows, err := r.pg.Pool.Query(ctx, `SELECT id FROM cart_items WHERE user_id = $1`, userID)
+	tx, err := r.pg.Begin(ctx)
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
-	for rows.Next() {
-		var cartItemID int64
-		err := rows.Scan(&cartItemID)
-		if err != nil {
-			return err
-		}
-		carItemIDs = append(carItemIDs, cartItemID)
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM cart_item_added_ingredients
+		WHERE cart_item_id IN (SELECT id FROM cart_items WHERE user_id = $1)
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to clear added ingredients: %w", err)
 	}
-	_, err = r.pg.Pool.Exec(ctx, `DELETE FROM cart_items WHERE user_id=$1`, userID)
-	for _, cartItemID := range carItemIDs {
-		err = r.RemoveItem(ctx, cartItemID)
-		if err != nil {
-			return err
-		}
+	_, err = tx.Exec(ctx, `
+		DELETE FROM cart_item_removed_ingredients
+		WHERE cart_item_id IN (SELECT id FROM cart_items WHERE user_id = $1)
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to clear removed ingredients: %w", err)
 	}
-	return nil
+	_, err = tx.Exec(ctx, `DELETE FROM cart_items WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to clear cart items: %w", err)
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *Repository) IncrementCartItemQuantity(ctx context.Context, cartItemID int64) (int32, error) {
+func (r *Repository) IncrementCartItemQuantity(ctx context.Context, userID, cartItemID int64) (int32, error) {
 	var newQuantity int32
 
-	err := r.pg.Pool.QueryRow(ctx, `
+	err := r.pg.Conn(ctx).QueryRow(ctx, `
 		UPDATE cart_items
 		SET quantity = quantity + 1
-		WHERE id = $1
+		WHERE id = $1 AND user_id = $2
 		RETURNING quantity
-	`, cartItemID).Scan(&newQuantity)
+	`, cartItemID, userID).Scan(&newQuantity)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, cartentity.ErrCartItemNotFound
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to increment quantity for cart item %d: %w", cartItemID, err)
 	}
@@ -236,24 +260,27 @@ func (r *Repository) IncrementCartItemQuantity(ctx context.Context, cartItemID i
 	return newQuantity, nil
 }
 
-func (r *Repository) DecrementCartItemQuantity(ctx context.Context, cartItemID int64) (int32, error) {
+func (r *Repository) DecrementCartItemQuantity(ctx context.Context, userID, cartItemID int64) (int32, error) {
 	var quantity int32
-	err := r.pg.Pool.QueryRow(ctx, `
-		SELECT quantity FROM cart_items where id = $1
-	`, cartItemID).Scan(&quantity)
+	err := r.pg.Conn(ctx).QueryRow(ctx, `
+		SELECT quantity FROM cart_items where id = $1 AND user_id = $2
+	`, cartItemID, userID).Scan(&quantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, cartentity.ErrCartItemNotFound
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to get quantity for cart item %d: %w", cartItemID, err)
 	}
 	if quantity <= 1 {
-		return 0, r.RemoveItem(ctx, cartItemID)
+		return 0, r.RemoveItem(ctx, userID, cartItemID)
 	}
 	var newQuantity int32
-	err = r.pg.Pool.QueryRow(ctx, `
+	err = r.pg.Conn(ctx).QueryRow(ctx, `
 		UPDATE cart_items
 		SET quantity = quantity - 1
-		WHERE id = $1
+		WHERE id = $1 AND user_id = $2
 		RETURNING quantity
-	`, cartItemID).Scan(&newQuantity)
+	`, cartItemID, userID).Scan(&newQuantity)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to decrement quantity for cart item %d: %w", cartItemID, err)
@@ -261,65 +288,3 @@ func (r *Repository) DecrementCartItemQuantity(ctx context.Context, cartItemID i
 
 	return newQuantity, nil
 }
-
-func (r *Repository) GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cartentity.CartItem, error) {
-	rows, err := r.pg.Pool.Query(ctx, `
-		SELECT
-			ci.id, ci.quantity, ci.customer_notes,
-			d.id, d.name, d.description, d.chef_id, d.image_url,
-			ds.id, ds.dish_id, ds.label, ds.weight_value, ds.weight_unit, ds.price_value, ds.price_currency
-		FROM orders_cart_items oci
-		JOIN cart_items ci ON oci.cart_item_id = ci.id
-		JOIN dishes d ON ci.dish_id = d.id
-		JOIN dish_sizes ds ON ci.dish_size_id = ds.id
-		WHERE oci.order_id = $1
-	`, orderID)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return []cartentity.CartItem{}, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var items []cartentity.CartItem
-	cartItemIDs := make([]int64, 0)
-
-	for rows.Next() {
-		var item cartentity.CartItem
-		var dish dishentity.Dish
-		var size dishentity.Size
-
-		err := rows.Scan(
-			&item.ID, &item.Quantity, &item.Notes,
-			&dish.ID, &dish.Name, &dish.Description, &dish.ChefID, &dish.ImageURL,
-			&size.ID, &size.DishID, &size.Label, &size.WeightValue, &size.WeightUnit, &size.PriceValue, &size.PriceCurrency,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		item.Dish = dish
-		item.Size = size
-		cartItemIDs = append(cartItemIDs, item.ID)
-		items = append(items, item)
-	}
-
-	// ingredients (added and removed)
-	addedMap, err := r.getCartItemIngredientsMap(ctx, "cart_item_added_ingredients", cartItemIDs)
-	if err != nil {
-		return nil, err
-	}
-	removedMap, err := r.getCartItemIngredientsMap(ctx, "cart_item_removed_ingredients", cartItemIDs)
-	if err != nil {
-		return nil, err
-	}
-
-	// заполняем ингредиенты
-	for i, item := range items {
-		items[i].AddedIngredients = addedMap[item.ID]
-		items[i].RemovedIngredients = removedMap[item.ID]
-	}
-
-	return items, nil
-}
@@ -8,6 +8,7 @@ import (
 	"github.com/jackc/pgx/v4"
 	"log"
 
+	"domashka-backend/internal/custom_errors"
 	entity "domashka-backend/internal/entity/chefs"
 	"domashka-backend/internal/utils/pointers"
 	"domashka-backend/pkg/postgres"
@@ -102,6 +103,16 @@ func (r *Repository) SetSmallAvatar(ctx context.Context, chefID int64, publicURL
 	return nil
 }
 
+// GetUserIDByChefID возвращает пользователя, привязанного к повару в users_chefs
+func (r *Repository) GetUserIDByChefID(ctx context.Context, chefID int64) (int64, error) {
+	var userID int64
+	err := r.pg.Conn(ctx).QueryRow(ctx, `SELECT user_id FROM users_chefs WHERE chef_id = $1 LIMIT 1`, chefID).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, custom_errors.ErrUserNotFound
+	}
+	return userID, err
+}
+
 func (r *Repository) GetChefRatingByChefID(ctx context.Context, chefID int64) (*entity.Chef, error) {
 	var chefRating ChefRating
 	err := r.pg.Pool.QueryRow(ctx, "SELECT chef_id, rating, reviews_count FROM chef_ratings WHERE chef_id = $1", chefID).Scan(
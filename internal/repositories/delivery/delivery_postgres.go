@@ -0,0 +1,227 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"domashka-backend/internal/entity/delivery"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/pkg/postgres"
+)
+
+type Repository struct {
+	pg *postgres.Postgres
+}
+
+func New(pg *postgres.Postgres) *Repository {
+	return &Repository{
+		pg: pg,
+	}
+}
+
+// GetChefTariffOverride возвращает настройки доставки повара, либо nil, если их нет
+func (r *Repository) GetChefTariffOverride(ctx context.Context, chefID int64) (*delivery.TariffOverride, error) {
+	var (
+		baseFee, perKmFee, freeThreshold *int64
+		override                         = delivery.TariffOverride{ChefID: chefID}
+	)
+	err := r.pg.Conn(ctx).QueryRow(ctx, `
+		SELECT
+			ROUND(base_fee * 100)::BIGINT,
+			ROUND(per_km_fee * 100)::BIGINT,
+			ROUND(free_threshold * 100)::BIGINT,
+			max_distance_meters
+		FROM chef_delivery_tariffs
+		WHERE chef_id = $1
+	`, chefID).Scan(&baseFee, &perKmFee, &freeThreshold, &override.MaxDistanceMeters)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	override.BaseFee = rubles(baseFee)
+	override.PerKmFee = rubles(perKmFee)
+	override.FreeThreshold = rubles(freeThreshold)
+	return &override, nil
+}
+
+func rubles(amount *int64) *money.Money {
+	if amount == nil {
+		return nil
+	}
+	m := money.New(*amount, money.RUB)
+	return &m
+}
+
+func (r *Repository) GetWorkingHours(ctx context.Context, chefID int64) ([]delivery.WorkingHours, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		SELECT
+			chef_id,
+			weekday,
+			EXTRACT(EPOCH FROM start_time)::BIGINT,
+			EXTRACT(EPOCH FROM end_time)::BIGINT,
+			slot_minutes,
+			slot_capacity
+		FROM chef_working_hours
+		WHERE chef_id = $1
+		ORDER BY weekday, start_time
+	`, chefID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWorkingHours(rows)
+}
+
+// GetAllWorkingHours возвращает рабочие часы всех поваров, по ним заранее создаются слоты
+func (r *Repository) GetAllWorkingHours(ctx context.Context) ([]delivery.WorkingHours, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		SELECT
+			chef_id,
+			weekday,
+			EXTRACT(EPOCH FROM start_time)::BIGINT,
+			EXTRACT(EPOCH FROM end_time)::BIGINT,
+			slot_minutes,
+			slot_capacity
+		FROM chef_working_hours
+		ORDER BY chef_id, weekday, start_time
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanWorkingHours(rows)
+}
+
+func scanWorkingHours(rows pgx.Rows) ([]delivery.WorkingHours, error) {
+	defer rows.Close()
+
+	hours := make([]delivery.WorkingHours, 0)
+	for rows.Next() {
+		var (
+			weekday     int16
+			start, end  int64
+			slotMinutes int32
+			wh          delivery.WorkingHours
+		)
+		if err := rows.Scan(&wh.ChefID, &weekday, &start, &end, &slotMinutes, &wh.SlotCapacity); err != nil {
+			return nil, err
+		}
+		wh.Weekday = time.Weekday(weekday)
+		wh.Start = time.Duration(start) * time.Second
+		wh.End = time.Duration(end) * time.Second
+		wh.SlotDuration = time.Duration(slotMinutes) * time.Minute
+		hours = append(hours, wh)
+	}
+	return hours, rows.Err()
+}
+
+// ReplaceWorkingHours заменяет все рабочие часы повара на hours
+func (r *Repository) ReplaceWorkingHours(ctx context.Context, chefID int64, hours []delivery.WorkingHours) error {
+	if _, err := r.pg.Conn(ctx).Exec(ctx, `DELETE FROM chef_working_hours WHERE chef_id = $1`, chefID); err != nil {
+		return err
+	}
+	for _, h := range hours {
+		_, err := r.pg.Conn(ctx).Exec(ctx, `
+			INSERT INTO chef_working_hours (chef_id, weekday, start_time, end_time, slot_minutes, slot_capacity)
+			VALUES ($1, $2, $3::TIME, $4::TIME, $5, $6)
+		`, chefID, int16(h.Weekday), clock(h.Start), clock(h.End), int32(h.SlotDuration/time.Minute), h.SlotCapacity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clock записывает смещение от начала дня как время суток; TIME допускает и 24:00:00
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second))
+}
+
+// DeleteFreeSlots удаляет слоты повара, начинающиеся не раньше from, на которые нет заказов.
+// Нужен при смене рабочих часов: занятые слоты остаются, чтобы не потерять брони.
+func (r *Repository) DeleteFreeSlots(ctx context.Context, chefID int64, from time.Time) error {
+	_, err := r.pg.Conn(ctx).Exec(ctx, `
+		DELETE FROM delivery_slots s
+		WHERE s.chef_id = $1 AND s.starts_at >= $2 AND s.reserved = 0
+			AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.delivery_slot_id = s.id)
+	`, chefID, from)
+	return err
+}
+
+// EnsureSlots создаёт недостающие слоты; уже созданные (и их брони) не трогает
+func (r *Repository) EnsureSlots(ctx context.Context, slots []delivery.Slot) error {
+	if len(slots) == 0 {
+		return nil
+	}
+	chefIDs := make([]int64, 0, len(slots))
+	startsAt := make([]time.Time, 0, len(slots))
+	endsAt := make([]time.Time, 0, len(slots))
+	capacities := make([]int32, 0, len(slots))
+	for _, s := range slots {
+		chefIDs = append(chefIDs, s.ChefID)
+		startsAt = append(startsAt, s.StartsAt)
+		endsAt = append(endsAt, s.EndsAt)
+		capacities = append(capacities, s.Capacity)
+	}
+	_, err := r.pg.Conn(ctx).Exec(ctx, `
+		INSERT INTO delivery_slots (chef_id, starts_at, ends_at, capacity)
+		SELECT * FROM unnest($1::BIGINT[], $2::TIMESTAMPTZ[], $3::TIMESTAMPTZ[], $4::INTEGER[])
+		ON CONFLICT (chef_id, starts_at) DO NOTHING
+	`, chefIDs, startsAt, endsAt, capacities)
+	return err
+}
+
+// GetSlots возвращает слоты повара, начинающиеся в интервале [from, to)
+func (r *Repository) GetSlots(ctx context.Context, chefID int64, from, to time.Time) ([]delivery.Slot, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		SELECT id, chef_id, starts_at, ends_at, capacity, reserved
+		FROM delivery_slots
+		WHERE chef_id = $1 AND starts_at >= $2 AND starts_at < $3
+		ORDER BY starts_at
+	`, chefID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := make([]delivery.Slot, 0)
+	for rows.Next() {
+		var s delivery.Slot
+		if err := rows.Scan(&s.ID, &s.ChefID, &s.StartsAt, &s.EndsAt, &s.Capacity, &s.Reserved); err != nil {
+			return nil, err
+		}
+		slots = append(slots, s)
+	}
+	return slots, rows.Err()
+}
+
+// ReserveSlot занимает место в слоте повара одним UPDATE, поэтому два заказа
+// не смогут занять последнее место одновременно
+func (r *Repository) ReserveSlot(ctx context.Context, chefID, slotID int64, notBefore time.Time) error {
+	tag, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE delivery_slots
+		SET reserved = reserved + 1
+		WHERE id = $1 AND chef_id = $2 AND reserved < capacity AND starts_at >= $3
+	`, slotID, chefID, notBefore)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return delivery.ErrSlotUnavailable
+	}
+	return nil
+}
+
+// ReleaseSlot возвращает место в слоте, когда заказ отклонён или отменён
+func (r *Repository) ReleaseSlot(ctx context.Context, slotID int64) error {
+	_, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE delivery_slots
+		SET reserved = reserved - 1
+		WHERE id = $1 AND reserved > 0
+	`, slotID)
+	return err
+}
@@ -106,7 +106,7 @@ func (r *Repository) GetNutritionByDishID(ctx context.Context, dishID int64) (*d
 
 func (r *Repository) GetDishSizesByDishID(ctx context.Context, dishID int64) ([]dishEntity.Size, error) {
 	var sizes []dishEntity.Size
-	rows, err := r.pg.Pool.Query(ctx, "SELECT * FROM dish_sizes WHERE dish_id = $1", dishID)
+	rows, err := r.pg.Pool.Query(ctx, `SELECT id, dish_id, label, weight_value, weight_unit, ROUND(price_value * 100)::BIGINT, price_currency FROM dish_sizes WHERE dish_id = $1`, dishID)
 	if err != nil {
 		return nil, err
 	}
@@ -119,8 +119,8 @@ func (r *Repository) GetDishSizesByDishID(ctx context.Context, dishID int64) ([]
 			&size.Label,
 			&size.WeightValue,
 			&size.WeightUnit,
-			&size.PriceValue,
-			&size.PriceCurrency,
+			&size.Price.Amount,
+			&size.Price.Currency,
 		)
 		if err != nil {
 			return nil, err
@@ -310,7 +310,7 @@ func (r *Repository) CreateDish(ctx context.Context, dish *dishEntity.Dish, nutr
 		_, err = tx.Exec(ctx, "INSERT INTO nutritions (dish_id, calories, fat, carbohydrates, protein) VALUES ($1, $2, $3, $4, $5)", dishID, nutrition.Calories, nutrition.Fat, nutrition.Carbohydrates, nutrition.Protein)
 	}
 	for _, size := range sizes {
-		_, err = tx.Exec(ctx, "INSERT INTO dish_sizes (dish_id, label, weight_value, weight_unit, price_value, price_currency) VALUES ($1, $2, $3, $4, $5, $6)", dishID, size.Label, size.WeightValue, size.WeightUnit, size.PriceValue, size.PriceCurrency)
+		_, err = tx.Exec(ctx, "INSERT INTO dish_sizes (dish_id, label, weight_value, weight_unit, price_value, price_currency) VALUES ($1, $2, $3, $4, $5::NUMERIC / 100, $6)", dishID, size.Label, size.WeightValue, size.WeightUnit, size.Price.Amount, string(size.Price.Currency))
 		if err != nil {
 			return 0, err
 		}
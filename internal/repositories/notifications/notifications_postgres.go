@@ -22,7 +22,7 @@ func New(pg *postgres.Postgres) *Repository {
 
 func (r *Repository) CreateNotification(ctx context.Context, n notifications.Notification) (int, error) {
 	var ID int
-	err := r.pg.Pool.QueryRow(ctx, `
+	err := r.pg.Conn(ctx).QueryRow(ctx, `
 		INSERT INTO notifications (user_id, channel, scenario, subject, message, recipient, status, created_at, updated_at, send_attempts, metadata)
 		VALUES ($1, $2, $3, $4, $5, $6, 'created', NOW(), NOW(), 0, $7)
 		RETURNING id`,
@@ -61,7 +61,7 @@ func (r *Repository) GetNotifications(ctx context.Context, filters map[string]st
 	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", i, i+1)
 	params = append(params, limit, offset)
 
-	rows, err := r.pg.Pool.Query(ctx, query, params...)
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, params...)
 	if err != nil {
 		log.Printf("Ошибка запроса списка уведомлений: %v", err)
 		return nil, 0, err
@@ -88,7 +88,7 @@ func (r *Repository) GetNotifications(ctx context.Context, filters map[string]st
 			countQuery += " AND " + conditions[j]
 		}
 	}
-	err = r.pg.Pool.QueryRow(ctx, countQuery, params[:len(params)-2]...).Scan(&total)
+	err = r.pg.Conn(ctx).QueryRow(ctx, countQuery, params[:len(params)-2]...).Scan(&total)
 	if err != nil {
 		log.Printf("Ошибка при подсчете записей: %v", err)
 	}
@@ -98,7 +98,7 @@ func (r *Repository) GetNotifications(ctx context.Context, filters map[string]st
 
 func (r *Repository) GetNotificationByID(ctx context.Context, id int) (*notifications.Notification, error) {
 	var n notifications.Notification
-	err := r.pg.Pool.QueryRow(ctx, `
+	err := r.pg.Conn(ctx).QueryRow(ctx, `
 		SELECT id, user_id, channel, scenario, subject, message, recipient, status, created_at, updated_at, send_attempts, metadata
 		FROM notifications WHERE id = $1`, id).
 		Scan(&n.ID, &n.UserID, &n.Channel, &n.Scenario, &n.Subject, &n.Message, &n.Recipient, &n.Status, &n.CreatedAt, &n.UpdatedAt, &n.SendAttempts, &n.Metadata)
@@ -113,9 +113,21 @@ func (r *Repository) GetNotificationByID(ctx context.Context, id int) (*notifica
 	return &n, nil
 }
 
+// UpdateDeliveryStatus сохраняет результат отправки, не трогая содержимое уведомления.
+// metadata без значения оставляет прежние метаданные.
+func (r *Repository) UpdateDeliveryStatus(ctx context.Context, id int, status string, attempts int, errorMessage, metadata sql.NullString) error {
+	_, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE notifications
+		SET status = $2, send_attempts = send_attempts + $3, error_message = $4,
+		    metadata = COALESCE($5::JSONB, metadata), updated_at = NOW()
+		WHERE id = $1`,
+		id, status, attempts, errorMessage, metadata)
+	return err
+}
+
 func (r *Repository) UpdateNotification(ctx context.Context, id int, n notifications.Notification) error {
 	log.Printf("DEBUG: Обновление уведомления id=%d, новый статус=%s, send_attempts=%d", id, n.Status, n.SendAttempts)
-	_, err := r.pg.Pool.Exec(ctx,
+	_, err := r.pg.Conn(ctx).Exec(ctx,
 		`UPDATE notifications
 		SET user_id = $1, channel = $2, scenario = $3, subject = $4, message = $5, 
 		    recipient = $6, status = $7, updated_at = NOW(), 
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 
 	cartentity "domashka-backend/internal/entity/cart"
 	dishentity "domashka-backend/internal/entity/dishes"
+	"domashka-backend/internal/entity/money"
 	"domashka-backend/internal/entity/orders"
 	"domashka-backend/pkg/postgres"
 )
@@ -29,43 +31,64 @@ func (r *Repository) CreateOrder(
 	shiftID int64,
 	userID int64,
 	clientAddressID int64,
-	totalCost float32,
+	totalCost money.Money,
+	deliveryFee money.Money,
+	deliverySlotID int64,
 	leaveByTheDoor bool,
 	callBeforehand bool,
 ) (int64, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
 	var orderID int64
-	err := r.pg.Pool.QueryRow(ctx, `
-		INSERT INTO orders (chef_id, shift_id, total_cost, leave_by_the_door, call_beforehand, client_address_id, user_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	// Пока у повара нет рабочих часов, заказ оформляется без слота: deliverySlotID = 0 хранится как NULL
+	err = tx.QueryRow(ctx, `
+		INSERT INTO orders (chef_id, shift_id, status, total_cost, delivery_fee, delivery_slot_id, leave_by_the_door, call_beforehand, client_address_id, user_id)
+		VALUES ($1, $2, $3, $4::NUMERIC / 100, $5::NUMERIC / 100, NULLIF($6::BIGINT, 0), $7, $8, $9, $10)
 		RETURNING id
-	`, chefID, shiftID, totalCost, leaveByTheDoor, callBeforehand, clientAddressID, userID).Scan(&orderID)
+	`, chefID, shiftID, orders.StatusAwaitingPayment, totalCost.Amount, deliveryFee.Amount, deliverySlotID, leaveByTheDoor, callBeforehand, clientAddressID, userID).Scan(&orderID)
 	if err != nil {
 		return 0, err
 	}
+	// Первая запись в истории статусов — создание заказа клиентом; до оплаты заказ ждёт её
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor_id, actor_role)
+		VALUES ($1, NULL, $2, $3, $4)
+	`, orderID, orders.StatusAwaitingPayment, userID, orders.ActorRoleClient)
+	if err != nil {
+		return 0, fmt.Errorf("insert status history: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
 	return orderID, nil
 }
 
 func (r *Repository) AddCartItemToOrder(ctx context.Context, cartItem *cartentity.CartItem, orderID, userID int64) error {
-	tx, err := r.pg.Pool.Begin(ctx)
+	tx, err := r.pg.Begin(ctx)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = tx.Rollback(ctx)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}()
+	defer tx.Rollback(ctx)
+
 	var orderedItemID int64
-	err = r.pg.Pool.QueryRow(ctx, `INSERT INTO ordered_items
-    	(user_id, dish_id, chef_id, order_id, dish_size_id, quantity, customer_notes)
+	// Название, размер и цена сохраняются на момент оформления,
+	// чтобы правки каталога не меняли историю заказов
+	err = tx.QueryRow(ctx, `INSERT INTO ordered_items
+    	(user_id, dish_id, chef_id, order_id, dish_size_id, quantity, customer_notes,
+    	 dish_name, size_label, weight_value, weight_unit, unit_price, price_currency)
 	VALUES 
-		($1, $2, $3, $4, $5, $6, $7)
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12::NUMERIC / 100, $13)
 	RETURNING id`,
-		userID, cartItem.Dish.ID, cartItem.Dish.ChefID, orderID, cartItem.Size.ID, cartItem.Quantity, cartItem.Notes).
+		userID, cartItem.Dish.ID, cartItem.Dish.ChefID, orderID, cartItem.Size.ID, cartItem.Quantity, cartItem.Notes,
+		cartItem.Dish.Name, cartItem.Size.Label, cartItem.Size.WeightValue, cartItem.Size.WeightUnit,
+		cartItem.Size.Price.Amount, string(cartItem.Size.Price.Currency)).
 		Scan(&orderedItemID)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to insert ordered item: %w", err)
 	}
 	// Добавляем добавленные ингредиенты
 	for _, ingredient := range cartItem.AddedIngredients {
@@ -81,7 +104,7 @@ func (r *Repository) AddCartItemToOrder(ctx context.Context, cartItem *cartentit
 	// Добавляем удалённые ингредиенты
 	for _, ingredient := range cartItem.RemovedIngredients {
 		_, err := tx.Exec(ctx, `
-			INSERT INTO ordered_item_added_ingredients (ordered_item_id, ingredient_id)
+			INSERT INTO ordered_item_removed_ingredients (ordered_item_id, ingredient_id)
 			VALUES ($1, $2)
 		`, orderedItemID, ingredient.ID)
 		if err != nil {
@@ -91,18 +114,17 @@ func (r *Repository) AddCartItemToOrder(ctx context.Context, cartItem *cartentit
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit tx: %w", err)
 	}
-	return err
+	return nil
 }
 
 func (r *Repository) GetCartItems(ctx context.Context, userID int64) ([]cartentity.CartItem, error) {
-	rows, err := r.pg.Pool.Query(ctx, `
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
 		SELECT
 			ci.id, ci.quantity, ci.customer_notes,
-			d.id, d.name, d.description, d.chef_id, d.image_url,
-			ds.id, ds.dish_id, ds.label, ds.weight_value, ds.weight_unit, ds.price_value, ds.price_currency
+			d.id, ci.dish_name, d.description, d.chef_id, d.image_url,
+			ci.dish_size_id, ci.dish_id, ci.size_label, ci.weight_value, ci.weight_unit, ROUND(ci.unit_price * 100)::BIGINT, ci.price_currency
 		FROM ordered_items ci
 		JOIN dishes d ON ci.dish_id = d.id
-		JOIN dish_sizes ds ON ci.dish_size_id = ds.id
 		WHERE ci.user_id = $1
 	`, userID)
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -124,7 +146,7 @@ func (r *Repository) GetCartItems(ctx context.Context, userID int64) ([]cartenti
 		err := rows.Scan(
 			&item.ID, &item.Quantity, &item.Notes,
 			&dish.ID, &dish.Name, &dish.Description, &dish.ChefID, &dish.ImageURL,
-			&size.ID, &size.DishID, &size.Label, &size.WeightValue, &size.WeightUnit, &size.PriceValue, &size.PriceCurrency,
+			&size.ID, &size.DishID, &size.Label, &size.WeightValue, &size.WeightUnit, &size.Price.Amount, &size.Price.Currency,
 		)
 		if err != nil {
 			return nil, err
@@ -156,15 +178,14 @@ func (r *Repository) GetCartItems(ctx context.Context, userID int64) ([]cartenti
 }
 
 func (r *Repository) GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cartentity.CartItem, error) {
-	rows, err := r.pg.Pool.Query(ctx, `
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
 		SELECT
 			ci.id, ci.quantity, ci.customer_notes,
-			d.id, d.name, d.description, d.chef_id, d.image_url,
-			ds.id, ds.dish_id, ds.label, ds.weight_value, ds.weight_unit, ds.price_value, ds.price_currency
+			d.id, ci.dish_name, d.description, d.chef_id, d.image_url,
+			ci.dish_size_id, ci.dish_id, ci.size_label, ci.weight_value, ci.weight_unit, ROUND(ci.unit_price * 100)::BIGINT, ci.price_currency
 		FROM ordered_items ci
 		JOIN dishes d ON ci.dish_id = d.id
-		JOIN dish_sizes ds ON ci.dish_size_id = ds.id
-		WHERE ci.order_id = $1
+		WHERE ci.order_id = $1 AND ci.removed_at IS NULL
 	`, orderID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return []cartentity.CartItem{}, nil
@@ -185,7 +206,7 @@ func (r *Repository) GetCartItemsByOrderID(ctx context.Context, orderID int64) (
 		err := rows.Scan(
 			&item.ID, &item.Quantity, &item.Notes,
 			&dish.ID, &dish.Name, &dish.Description, &dish.ChefID, &dish.ImageURL,
-			&size.ID, &size.DishID, &size.Label, &size.WeightValue, &size.WeightUnit, &size.PriceValue, &size.PriceCurrency,
+			&size.ID, &size.DishID, &size.Label, &size.WeightValue, &size.WeightUnit, &size.Price.Amount, &size.Price.Currency,
 		)
 		if err != nil {
 			return nil, err
@@ -221,7 +242,7 @@ func (r *Repository) getCartItemIngredientsMap(ctx context.Context, tableName st
 		return map[int64][]dishentity.Ingredient{}, nil
 	}
 
-	rows, err := r.pg.Pool.Query(ctx, fmt.Sprintf(`
+	rows, err := r.pg.Conn(ctx).Query(ctx, fmt.Sprintf(`
 		SELECT
 			ci.ordered_item_id,
 			i.id, i.name, i.image_url, i.is_allergen, i.category_id
@@ -259,13 +280,15 @@ func (r *Repository) GetOrdersByShiftID(ctx context.Context, shiftID int64) ([]o
             orders
         WHERE shift_id = $1
     `
-	rows, err := r.pg.Pool.Query(ctx, query, shiftID)
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, shiftID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return []orders.Order{}, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
 	var o []orders.Order
 	for rows.Next() {
 		var order orders.Order
@@ -277,9 +300,116 @@ func (r *Repository) GetOrdersByShiftID(ctx context.Context, shiftID int64) ([]o
 	return o, nil
 }
 
-func (r *Repository) SetStatus(ctx context.Context, orderID int64, status int32) error {
-	_, err := r.pg.Pool.Exec(ctx, `UPDATE orders SET status = $1, updated_at = now() WHERE id = $2`, status, orderID)
-	return err
+// GetStaleOrderIDs возвращает заказы, которые находятся в статусе status дольше olderThan.
+// updated_at меняется при каждой смене статуса, поэтому это время входа в текущий статус.
+func (r *Repository) GetStaleOrderIDs(ctx context.Context, status int32, olderThan time.Duration, limit int) ([]int64, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		SELECT id
+		FROM orders
+		WHERE status = $1 AND updated_at < now() - make_interval(secs => $2)
+		ORDER BY updated_at
+		LIMIT $3
+	`, status, olderThan.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RemoveOrderedItem убирает позицию из заказа. Строка остаётся в таблице, чтобы на неё ссылался возврат.
+func (r *Repository) RemoveOrderedItem(ctx context.Context, orderID, orderedItemID int64) error {
+	tag, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE ordered_items
+		SET removed_at = now()
+		WHERE id = $1 AND order_id = $2 AND removed_at IS NULL
+	`, orderedItemID, orderID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return orders.ErrItemNotFound
+	}
+	return nil
+}
+
+// ChangeStatus переводит заказ из статуса from в статус to и пишет запись в историю.
+// Если статус заказа к этому моменту уже не from, возвращает orders.ErrStatusConflict.
+func (r *Repository) ChangeStatus(ctx context.Context, orderID int64, from, to int32, actor orders.Actor, reason string) error {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE orders SET status = $1, updated_at = now() WHERE id = $2 AND status = $3`, to, orderID, from)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return orders.ErrStatusConflict
+	}
+
+	var actorID *int64
+	if actor.UserID != 0 {
+		actorID = &actor.UserID
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor_id, actor_role, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, orderID, from, to, actorID, actor.Role, reason)
+	if err != nil {
+		return fmt.Errorf("insert status history: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetStatusHistory(ctx context.Context, orderID int64) ([]orders.StatusChange, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		SELECT
+			id, order_id, from_status, to_status, actor_id, actor_role, reason, created_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY created_at, id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("GetStatusHistory query: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]orders.StatusChange, 0)
+	for rows.Next() {
+		var h orders.StatusChange
+		if err := rows.Scan(
+			&h.ID,
+			&h.OrderID,
+			&h.FromStatus,
+			&h.ToStatus,
+			&h.ActorID,
+			&h.ActorRole,
+			&h.Reason,
+			&h.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("GetStatusHistory scan: %w", err)
+		}
+		history = append(history, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetStatusHistory rows: %w", err)
+	}
+	return history, nil
 }
 
 func (r *Repository) GetShiftIDByOrderID(ctx context.Context, orderID int64) (int64, error) {
@@ -291,35 +421,63 @@ func (r *Repository) GetShiftIDByOrderID(ctx context.Context, orderID int64) (in
         WHERE id = $1
     `
 	var shiftID int64
-	err := r.pg.Pool.QueryRow(ctx, query, orderID).Scan(&shiftID)
+	err := r.pg.Conn(ctx).QueryRow(ctx, query, orderID).Scan(&shiftID)
 	return shiftID, err
 }
 
 func (r *Repository) GetOrderByID(ctx context.Context, orderID int64) (*orders.Order, error) {
-	query := `
+	return scanOrder(r.pg.Conn(ctx).QueryRow(ctx, orderQuery, orderID))
+}
+
+// GetOrderByIDForUpdate читает заказ и блокирует его строку до конца транзакции,
+// чтобы статус и состав заказа не поменялись между проверкой и изменением
+func (r *Repository) GetOrderByIDForUpdate(ctx context.Context, orderID int64) (*orders.Order, error) {
+	return scanOrder(r.pg.Conn(ctx).QueryRow(ctx, orderQuery+` FOR UPDATE`, orderID))
+}
+
+const orderQuery = `
         SELECT 
 			id,
 			shift_id,
 			chef_id,
 			status,
-			total_cost,
+			ROUND(total_cost * 100)::BIGINT,
+			ROUND(delivery_fee * 100)::BIGINT,
+			delivery_slot_id,
 			leave_by_the_door,
-			client_address_id
+			client_address_id,
+			user_id,
+			created_at,
+			updated_at
         FROM 
             orders
         WHERE id = $1
     `
-	var order orders.Order
-	err := r.pg.Pool.QueryRow(ctx, query, orderID).Scan(
+
+func scanOrder(row pgx.Row) (*orders.Order, error) {
+	// Стоимость заказа хранится в рублях
+	order := orders.Order{TotalCost: money.New(0, money.RUB), DeliveryFee: money.New(0, money.RUB)}
+	err := row.Scan(
 		&order.ID,
 		&order.ShiftID,
 		&order.ChefID,
 		&order.Status,
-		&order.TotalCost,
+		&order.TotalCost.Amount,
+		&order.DeliveryFee.Amount,
+		&order.DeliverySlotID,
 		&order.LeaveByTheDoor,
 		&order.ClientAddressID,
+		&order.UserID,
+		&order.CreatedAt,
+		&order.UpdatedAt,
 	)
-	return &order, err
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, orders.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
 }
 
 func (r *Repository) GetOrderedDishesIDsAndChefsIDs(ctx context.Context, userID int64, dishesLimit, chefsLimit int) ([]int64, []int64, error) {
@@ -333,13 +491,15 @@ func (r *Repository) GetOrderedDishesIDsAndChefsIDs(ctx context.Context, userID
     `
 	chefsIDs := make([]int64, 0)
 	dishesIDs := make([]int64, 0)
-	rows, err := r.pg.Pool.Query(ctx, query, userID)
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, userID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return []int64{}, []int64{}, nil
 		}
 		return nil, nil, err
 	}
+	defer rows.Close()
+
 	dishesIDsSet := map[int64]bool{}
 	chefsIDsSet := map[int64]bool{}
 	for rows.Next() {
@@ -377,7 +537,7 @@ func (r *Repository) GetStatus(ctx context.Context, orderID int64) (int32, error
         WHERE id = $1
     `
 	var status int32
-	err := r.pg.Pool.QueryRow(ctx, query, orderID).Scan(&status)
+	err := r.pg.Conn(ctx).QueryRow(ctx, query, orderID).Scan(&status)
 	return status, err
 }
 func (r *Repository) GetOrdersByUserID(ctx context.Context, userID int64) ([]orders.Order, error) {
@@ -389,7 +549,9 @@ func (r *Repository) GetOrdersByUserID(ctx context.Context, userID int64) ([]ord
             status,
             created_at,
             updated_at,
-            total_cost,
+            ROUND(total_cost * 100)::BIGINT,
+            ROUND(delivery_fee * 100)::BIGINT,
+            delivery_slot_id,
             leave_by_the_door,
             client_address_id
         FROM orders
@@ -397,7 +559,7 @@ func (r *Repository) GetOrdersByUserID(ctx context.Context, userID int64) ([]ord
         ORDER BY created_at DESC
     `
 
-	rows, err := r.pg.Pool.Query(ctx, query, userID)
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("GetOrdersByUserID query: %w", err)
 	}
@@ -405,7 +567,7 @@ func (r *Repository) GetOrdersByUserID(ctx context.Context, userID int64) ([]ord
 
 	var result []orders.Order
 	for rows.Next() {
-		var o orders.Order
+		o := orders.Order{TotalCost: money.New(0, money.RUB), DeliveryFee: money.New(0, money.RUB)}
 		if err := rows.Scan(
 			&o.ID,
 			&o.ShiftID,
@@ -413,7 +575,9 @@ func (r *Repository) GetOrdersByUserID(ctx context.Context, userID int64) ([]ord
 			&o.Status,
 			&o.CreatedAt,
 			&o.UpdatedAt,
-			&o.TotalCost,
+			&o.TotalCost.Amount,
+			&o.DeliveryFee.Amount,
+			&o.DeliverySlotID,
 			&o.LeaveByTheDoor,
 			&o.ClientAddressID,
 		); err != nil {
@@ -430,6 +594,6 @@ func (r *Repository) GetOrdersByUserID(ctx context.Context, userID int64) ([]ord
 
 func (r *Repository) CountDishInOrders(ctx context.Context, dishID int64) (int, error) {
 	var count int
-	err := r.pg.Pool.QueryRow(ctx, `SELECT count(*) FROM ordered_items WHERE dish_id = $1`, dishID).Scan(&count)
+	err := r.pg.Conn(ctx).QueryRow(ctx, `SELECT count(*) FROM ordered_items WHERE dish_id = $1`, dishID).Scan(&count)
 	return count, err
 }
@@ -0,0 +1,232 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/payments"
+	"domashka-backend/pkg/postgres"
+)
+
+type Repository struct {
+	pg *postgres.Postgres
+}
+
+func New(pg *postgres.Postgres) *Repository {
+	return &Repository{
+		pg: pg,
+	}
+}
+
+const paymentColumns = `
+	id,
+	order_id,
+	user_id,
+	provider,
+	provider_payment_id,
+	ROUND(amount * 100)::BIGINT,
+	currency,
+	status,
+	capture_requested_at,
+	created_at,
+	updated_at
+`
+
+func (r *Repository) CreatePayment(ctx context.Context, p *payments.Payment) (int64, error) {
+	var id int64
+	err := r.pg.Conn(ctx).QueryRow(ctx, `
+		INSERT INTO payments (order_id, user_id, provider, provider_payment_id, amount, currency, status)
+		VALUES ($1, $2, $3, $4, $5::NUMERIC / 100, $6, $7)
+		RETURNING id
+	`, p.OrderID, p.UserID, p.Provider, p.ProviderPaymentID, p.Amount.Amount, p.Amount.Currency, p.Status).Scan(&id)
+	return id, err
+}
+
+func (r *Repository) GetPaymentByOrderID(ctx context.Context, orderID int64) (*payments.Payment, error) {
+	row := r.pg.Conn(ctx).QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE order_id = $1`, orderID)
+	return scanPayment(row)
+}
+
+func (r *Repository) GetPaymentByProviderID(ctx context.Context, provider, providerPaymentID string) (*payments.Payment, error) {
+	row := r.pg.Conn(ctx).QueryRow(ctx, `
+		SELECT `+paymentColumns+`
+		FROM payments
+		WHERE provider = $1 AND provider_payment_id = $2
+	`, provider, providerPaymentID)
+	return scanPayment(row)
+}
+
+// UpdatePaymentStatus меняет статус, только если платёж всё ещё в статусе from
+func (r *Repository) UpdatePaymentStatus(ctx context.Context, paymentID int64, from, to payments.Status) error {
+	tag, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE payments
+		SET status = $3, updated_at = now()
+		WHERE id = $1 AND status = $2
+	`, paymentID, from, to)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return payments.ErrStatusConflict
+	}
+	return nil
+}
+
+// RequestCapture отмечает, что деньги по платежу нужно списать. Отметка делается в одной транзакции
+// с доставкой заказа, само списание — после фиксации, а неудавшиеся списания повторяются по ней.
+func (r *Repository) RequestCapture(ctx context.Context, paymentID int64) error {
+	_, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE payments
+		SET capture_requested_at = now(), updated_at = now()
+		WHERE id = $1 AND capture_requested_at IS NULL
+	`, paymentID)
+	return err
+}
+
+// GetPendingCaptureOrderIDs возвращает заказы, списание по которым запрошено больше olderThan назад,
+// но так и не выполнено
+func (r *Repository) GetPendingCaptureOrderIDs(ctx context.Context, olderThan time.Duration, limit int) ([]int64, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		SELECT order_id
+		FROM payments
+		WHERE status = $1
+		  AND capture_requested_at IS NOT NULL
+		  AND capture_requested_at < now() - make_interval(secs => $2)
+		ORDER BY capture_requested_at
+		LIMIT $3
+	`, payments.StatusAuthorized, olderThan.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orderIDs := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		orderIDs = append(orderIDs, id)
+	}
+	return orderIDs, rows.Err()
+}
+
+func scanPayment(row pgx.Row) (*payments.Payment, error) {
+	var (
+		p        payments.Payment
+		currency string
+	)
+	err := row.Scan(
+		&p.ID,
+		&p.OrderID,
+		&p.UserID,
+		&p.Provider,
+		&p.ProviderPaymentID,
+		&p.Amount.Amount,
+		&currency,
+		&p.Status,
+		&p.CaptureRequestedAt,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, payments.ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Amount.Currency = money.Currency(currency)
+	return &p, nil
+}
+
+func (r *Repository) CreateRefund(ctx context.Context, refund *payments.Refund) (int64, error) {
+	var id int64
+	err := r.pg.Conn(ctx).QueryRow(ctx, `
+		INSERT INTO refunds (order_id, payment_id, ordered_item_id, amount, currency, reason, status)
+		VALUES ($1, $2, $3, $4::NUMERIC / 100, $5, $6, $7)
+		RETURNING id
+	`, refund.OrderID, refund.PaymentID, refund.OrderedItemID, refund.Amount.Amount, refund.Amount.Currency, refund.Reason, refund.Status).Scan(&id)
+	return id, err
+}
+
+func (r *Repository) CompleteRefund(ctx context.Context, refundID int64) error {
+	_, err := r.pg.Conn(ctx).Exec(ctx, `
+		UPDATE refunds
+		SET status = $2
+		WHERE id = $1
+	`, refundID, payments.RefundStatusCompleted)
+	return err
+}
+
+// GetPendingRefunds возвращает возвраты, которые висят в pending дольше olderThan
+func (r *Repository) GetPendingRefunds(ctx context.Context, olderThan time.Duration, limit int) ([]payments.Refund, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		SELECT `+refundColumns+`
+		FROM refunds
+		WHERE status = $1 AND created_at < now() - make_interval(secs => $2)
+		ORDER BY created_at
+		LIMIT $3
+	`, payments.RefundStatusPending, olderThan.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanRefunds(rows)
+}
+
+const refundColumns = `
+	id,
+	order_id,
+	payment_id,
+	ordered_item_id,
+	ROUND(amount * 100)::BIGINT,
+	currency,
+	reason,
+	status,
+	created_at
+`
+
+func (r *Repository) GetRefundsByOrderID(ctx context.Context, orderID int64) ([]payments.Refund, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
+		SELECT `+refundColumns+`
+		FROM refunds
+		WHERE order_id = $1
+		ORDER BY created_at
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return scanRefunds(rows)
+}
+
+func scanRefunds(rows pgx.Rows) ([]payments.Refund, error) {
+	defer rows.Close()
+
+	refunds := make([]payments.Refund, 0)
+	for rows.Next() {
+		var (
+			refund   payments.Refund
+			currency string
+		)
+		err := rows.Scan(
+			&refund.ID,
+			&refund.OrderID,
+			&refund.PaymentID,
+			&refund.OrderedItemID,
+			&refund.Amount.Amount,
+			&currency,
+			&refund.Reason,
+			&refund.Status,
+			&refund.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		refund.Amount.Currency = money.Currency(currency)
+		refunds = append(refunds, refund)
+	}
+	return refunds, rows.Err()
+}
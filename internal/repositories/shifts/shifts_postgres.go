@@ -7,6 +7,7 @@ import (
 
 	"github.com/jackc/pgx/v4"
 
+	"domashka-backend/internal/entity/money"
 	"domashka-backend/internal/entity/shifts"
 	"domashka-backend/internal/utils/types"
 	"domashka-backend/pkg/postgres"
@@ -24,7 +25,7 @@ func New(pg *postgres.Postgres) *Repository {
 
 func (r *Repository) GetActiveShiftIDByChefID(ctx context.Context, chefID int64) (int64, error) {
 	var shiftID int64
-	err := r.pg.Pool.QueryRow(ctx, "SELECT id FROM shifts WHERE chef_id = $1 AND is_active = true", chefID).Scan(&shiftID)
+	err := r.pg.Conn(ctx).QueryRow(ctx, "SELECT id FROM shifts WHERE chef_id = $1 AND is_active = true", chefID).Scan(&shiftID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return 0, nil
 	}
@@ -35,14 +36,15 @@ func (r *Repository) GetActiveShiftIDByChefID(ctx context.Context, chefID int64)
 }
 
 func (r *Repository) GetActiveShiftByChefID(ctx context.Context, chefID int64) (*shifts.Shift, error) {
-	var shift shifts.Shift
-	err := r.pg.Pool.QueryRow(ctx, "SELECT id, chef_id, is_active, created_at, closed_at, total_profit FROM shifts WHERE chef_id = $1 AND is_active = true", chefID).Scan(
+	// Выручка смены хранится в рублях
+	shift := shifts.Shift{TotalProfit: money.New(0, money.RUB)}
+	err := r.pg.Conn(ctx).QueryRow(ctx, "SELECT id, chef_id, is_active, created_at, closed_at, ROUND(total_profit * 100)::BIGINT FROM shifts WHERE chef_id = $1 AND is_active = true", chefID).Scan(
 		&shift.ID,
 		&shift.ChefID,
 		&shift.IsActive,
 		&shift.CreatedAt,
 		&shift.ClosedAt,
-		&shift.TotalProfit,
+		&shift.TotalProfit.Amount,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
@@ -53,26 +55,26 @@ func (r *Repository) GetActiveShiftByChefID(ctx context.Context, chefID int64) (
 	return &shift, nil
 }
 
-func (r *Repository) AddToTotalProfit(ctx context.Context, shiftID int64, profit float64) error {
-	_, err := r.pg.Pool.Exec(ctx, "UPDATE shifts SET total_profit = total_profit + $1 WHERE id = $2", profit, shiftID)
+func (r *Repository) AddToTotalProfit(ctx context.Context, shiftID int64, profit money.Money) error {
+	_, err := r.pg.Conn(ctx).Exec(ctx, "UPDATE shifts SET total_profit = total_profit + $1::NUMERIC / 100 WHERE id = $2", profit.Amount, shiftID)
 	return err
 }
 
 func (r *Repository) CloseActiveShiftByChefID(ctx context.Context, chefID int64) error {
-	_, err := r.pg.Pool.Exec(ctx, "UPDATE shifts SET is_active = false, closed_at = now() WHERE chef_id = $1 AND is_active = true", chefID)
+	_, err := r.pg.Conn(ctx).Exec(ctx, "UPDATE shifts SET is_active = false, closed_at = now() WHERE chef_id = $1 AND is_active = true", chefID)
 	return err
 }
 
 func (r *Repository) OpenShift(ctx context.Context, chefID int64) error {
-	_, err := r.pg.Pool.Exec(ctx, "INSERT INTO shifts (chef_id, is_active) VALUES ($1, true)", chefID)
+	_, err := r.pg.Conn(ctx).Exec(ctx, "INSERT INTO shifts (chef_id, is_active) VALUES ($1, true)", chefID)
 	return err
 }
 
 func (r *Repository) GetDailyProfits(ctx context.Context, chefID int64) ([]shifts.DailyProfit, error) {
-	rows, err := r.pg.Pool.Query(ctx, `
+	rows, err := r.pg.Conn(ctx).Query(ctx, `
 		SELECT
 			DATE(closed_at) AS day,
-			SUM(total_profit) AS daily_profit
+			ROUND(SUM(total_profit) * 100)::BIGINT AS daily_profit
 		FROM
 			shifts
 		WHERE
@@ -90,9 +92,9 @@ func (r *Repository) GetDailyProfits(ctx context.Context, chefID int64) ([]shift
 	}
 	dailyProfits := make([]shifts.DailyProfit, 0)
 	for rows.Next() {
-		var dailyProfit shifts.DailyProfit
+		dailyProfit := shifts.DailyProfit{Profit: money.New(0, money.RUB)}
 		var dateTime time.Time
-		if err := rows.Scan(&dateTime, &dailyProfit.Profit); err != nil {
+		if err := rows.Scan(&dateTime, &dailyProfit.Profit.Amount); err != nil {
 			return nil, err
 		}
 		dailyProfit.Date = dateTime.Format("02.01")
@@ -101,3 +103,16 @@ func (r *Repository) GetDailyProfits(ctx context.Context, chefID int64) ([]shift
 	}
 	return dailyProfits, nil
 }
+
+// GetProfitSince возвращает выручку открытой смены повара и смен, закрытых не раньше since
+func (r *Repository) GetProfitSince(ctx context.Context, chefID int64, since time.Time) (money.Money, error) {
+	profit := money.New(0, money.RUB)
+	err := r.pg.Conn(ctx).QueryRow(ctx, `
+		SELECT ROUND(COALESCE(SUM(total_profit), 0) * 100)::BIGINT
+		FROM shifts
+		WHERE chef_id = $1 AND (is_active = true OR closed_at >= $2)`, chefID, since).Scan(&profit.Amount)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return profit, nil
+}
@@ -314,6 +314,207 @@ func (r *Repository) CreateWithPhone(ctx context.Context, phone string) (*usersE
 	return &user, nil
 }
 
+// GetByEmail возвращает пользователя по почте без учёта регистра. Почта не уникальна — берём самого раннего.
+func (r *Repository) GetByEmail(ctx context.Context, email string) (*usersEntity.User, error) {
+	var user usersEntity.User
+	row := r.pg.Pool.QueryRow(ctx, `
+		SELECT 
+			id, 
+			username, 
+			name, 
+			alias, 
+			first_name, 
+			second_name, 
+			last_name, 
+			email, 
+			number_phone, 
+			status, 
+			external_type, 
+			telegram_name, 
+			external_id, 
+			COALESCE(chat_id, '') as chat_id,
+			notification_flag, 
+			role, 
+			birthday, 
+			created_at, 
+			updated_at 
+		FROM users 
+		WHERE lower(email) = lower($1)
+		ORDER BY id
+		LIMIT 1
+	`, email)
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Name,
+		&user.Alias,
+		&user.FirstName,
+		&user.SecondName,
+		&user.LastName,
+		&user.Email,
+		&user.NumberPhone,
+		&user.Status,
+		&user.ExternalType,
+		&user.TelegramName,
+		&user.ExternalID,
+		&user.ChatID,
+		&user.NotificationFlag,
+		&user.Role,
+		&user.Birthday,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, custom_errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateWithEmail регистрирует пользователя, который входит по почте
+func (r *Repository) CreateWithEmail(ctx context.Context, email string) (*usersEntity.User, error) {
+	var user usersEntity.User
+
+	query := `
+		INSERT INTO users (
+			username, 
+			name, 
+			alias, 
+			first_name, 
+			email, 
+			status, 
+			external_type, 
+			notification_flag, 
+			role
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING 
+			id, 
+			username, 
+			name, 
+			alias, 
+			first_name, 
+			second_name, 
+			last_name, 
+			email, 
+			number_phone, 
+			status, 
+			external_type, 
+			telegram_name, 
+			external_id, 
+			COALESCE(chat_id, '') as chat_id,
+			notification_flag, 
+			role, 
+			birthday, 
+			created_at, 
+			updated_at
+	`
+	err := r.pg.Pool.QueryRow(ctx, query,
+		email,    // username
+		"",       // name
+		email,    // alias
+		"",       // first_name
+		email,    // email
+		0,        // status
+		0,        // external_type
+		1,        // notification_flag
+		"client", // role
+	).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Name,
+		&user.Alias,
+		&user.FirstName,
+		&user.SecondName,
+		&user.LastName,
+		&user.Email,
+		&user.NumberPhone,
+		&user.Status,
+		&user.ExternalType,
+		&user.TelegramName,
+		&user.ExternalID,
+		&user.ChatID,
+		&user.NotificationFlag,
+		&user.Role,
+		&user.Birthday,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByChatID возвращает пользователя, к которому привязан чат Telegram.
+// Если чат входил в несколько аккаунтов, привязан последний.
+func (r *Repository) GetByChatID(ctx context.Context, chatID string) (*usersEntity.User, error) {
+	var user usersEntity.User
+	row := r.pg.Pool.QueryRow(ctx, `
+		SELECT 
+			id, 
+			username, 
+			name, 
+			alias, 
+			first_name, 
+			email, 
+			number_phone, 
+			status, 
+			COALESCE(chat_id, '') as chat_id,
+			role
+		FROM users 
+		WHERE chat_id = $1
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`, chatID)
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Name,
+		&user.Alias,
+		&user.FirstName,
+		&user.Email,
+		&user.NumberPhone,
+		&user.Status,
+		&user.ChatID,
+		&user.Role,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, custom_errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetChatIDByUserID возвращает чат Telegram пользователя; пустая строка — чат не привязан
+func (r *Repository) GetChatIDByUserID(ctx context.Context, userID int64) (string, error) {
+	var chatID string
+	err := r.pg.Pool.QueryRow(ctx, `SELECT COALESCE(chat_id, '') FROM users WHERE id = $1`, userID).Scan(&chatID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", custom_errors.ErrUserNotFound
+	}
+	return chatID, err
+}
+
+// GetChatIDByChefID возвращает чат Telegram пользователя-повара; пустая строка — чат не привязан
+func (r *Repository) GetChatIDByChefID(ctx context.Context, chefID int64) (string, error) {
+	var chatID string
+	err := r.pg.Pool.QueryRow(ctx, `
+		SELECT COALESCE(u.chat_id, '')
+		FROM users_chefs uc
+		JOIN users u ON u.id = uc.user_id
+		WHERE uc.chef_id = $1
+		LIMIT 1
+	`, chefID).Scan(&chatID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", custom_errors.ErrUserNotFound
+	}
+	return chatID, err
+}
+
 func (r *Repository) CheckIfUserIsChef(ctx context.Context, userID int64) (*int64, bool, error) {
 	query := `SELECT chef_id FROM users_chefs WHERE user_id = $1`
 	var chefID int64
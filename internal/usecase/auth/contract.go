@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	authentity "domashka-backend/internal/entity/auth"
+	notifentity "domashka-backend/internal/entity/notifications"
 	usersentity "domashka-backend/internal/entity/users"
 )
 
@@ -12,6 +14,9 @@ import (
 type usersRepo interface {
 	CreateWithPhone(ctx context.Context, phone string) (*usersentity.User, error)
 	GetByPhone(ctx context.Context, phone string) (*usersentity.User, error)
+	CreateWithEmail(ctx context.Context, email string) (*usersentity.User, error)
+	GetByEmail(ctx context.Context, email string) (*usersentity.User, error)
+	GetByID(ctx context.Context, id int64) (*usersentity.User, error)
 	Update(ctx context.Context, id int64, user usersentity.User) error
 	CheckIfUserIsChef(ctx context.Context, userID int64) (*int64, bool, error)
 }
@@ -19,14 +24,44 @@ type usersRepo interface {
 type redisClient interface {
 	Set(key string, value string, ttl time.Duration) error
 	Get(key string) (string, error)
-	IsExpired(key string) (bool, error)
+	GetDel(key string) (string, error)
+	SetNX(key string, value string, ttl time.Duration) (bool, error)
+	Incr(key string, ttl time.Duration) (int64, error)
+	Delete(key string) error
 }
 
 type jwtUsecase interface {
-	ValidateJWT(tokenString string) (map[string]interface{}, error)
-	GenerateJWT(userID int64, chefID *int64, role string) (string, error)
+	IssueAccessToken(userID int64, chefID *int64, role string, sessionID string) (*authentity.AccessToken, error)
+	Revoke(tokenUUID string, expiresAt time.Time) error
 }
 
+type refreshTokensRepo interface {
+	CreateRefreshToken(ctx context.Context, t *authentity.RefreshToken) (int64, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*authentity.RefreshToken, error)
+	GetRefreshTokenByAccessUUID(ctx context.Context, accessUUID string) (*authentity.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id int64) error
+	RevokeFamily(ctx context.Context, familyID string) ([]authentity.RefreshToken, error)
+}
+
+type sessionsRepo interface {
+	CreateSession(ctx context.Context, s *authentity.Session) error
+	GetActiveSessionsByUserID(ctx context.Context, userID int64) ([]authentity.Session, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+	RevokeUserSessions(ctx context.Context, userID int64) ([]string, error)
+	TouchSession(ctx context.Context, sessionID, ip string) error
+}
+
+// transactor выполняет fn в одной транзакции БД (unit of work)
+type transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// SMSClient отправляет SMS по шаблону и сохраняет статус доставки
 type SMSClient interface {
-	Send(phone, message string) error
+	SendSMS(ctx context.Context, userID int64, phone string, tpl notifentity.SMSTemplate, data notifentity.SMSData) error
+}
+
+// EmailClient отправляет системное письмо по шаблону и сохраняет статус доставки
+type EmailClient interface {
+	SendEmail(ctx context.Context, userID int64, email string, tpl notifentity.EmailTemplate, data notifentity.EmailTemplateData) error
 }
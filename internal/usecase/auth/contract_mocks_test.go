@@ -6,6 +6,8 @@ package auth
 
 import (
 	context "context"
+	auth "domashka-backend/internal/entity/auth"
+	notifications "domashka-backend/internal/entity/notifications"
 	users "domashka-backend/internal/entity/users"
 	reflect "reflect"
 	time "time"
@@ -52,6 +54,21 @@ func (mr *MockusersRepoMockRecorder) CheckIfUserIsChef(ctx, userID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIfUserIsChef", reflect.TypeOf((*MockusersRepo)(nil).CheckIfUserIsChef), ctx, userID)
 }
 
+// CreateWithEmail mocks base method.
+func (m *MockusersRepo) CreateWithEmail(ctx context.Context, email string) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithEmail", ctx, email)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithEmail indicates an expected call of CreateWithEmail.
+func (mr *MockusersRepoMockRecorder) CreateWithEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithEmail", reflect.TypeOf((*MockusersRepo)(nil).CreateWithEmail), ctx, email)
+}
+
 // CreateWithPhone mocks base method.
 func (m *MockusersRepo) CreateWithPhone(ctx context.Context, phone string) (*users.User, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +84,36 @@ func (mr *MockusersRepoMockRecorder) CreateWithPhone(ctx, phone interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithPhone", reflect.TypeOf((*MockusersRepo)(nil).CreateWithPhone), ctx, phone)
 }
 
+// GetByEmail mocks base method.
+func (m *MockusersRepo) GetByEmail(ctx context.Context, email string) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockusersRepoMockRecorder) GetByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockusersRepo)(nil).GetByEmail), ctx, email)
+}
+
+// GetByID mocks base method.
+func (m *MockusersRepo) GetByID(ctx context.Context, id int64) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockusersRepoMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockusersRepo)(nil).GetByID), ctx, id)
+}
+
 // GetByPhone mocks base method.
 func (m *MockusersRepo) GetByPhone(ctx context.Context, phone string) (*users.User, error) {
 	m.ctrl.T.Helper()
@@ -119,6 +166,20 @@ func (m *MockredisClient) EXPECT() *MockredisClientMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockredisClient) Delete(key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockredisClientMockRecorder) Delete(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockredisClient)(nil).Delete), key)
+}
+
 // Get mocks base method.
 func (m *MockredisClient) Get(key string) (string, error) {
 	m.ctrl.T.Helper()
@@ -134,19 +195,34 @@ func (mr *MockredisClientMockRecorder) Get(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockredisClient)(nil).Get), key)
 }
 
-// IsExpired mocks base method.
-func (m *MockredisClient) IsExpired(key string) (bool, error) {
+// GetDel mocks base method.
+func (m *MockredisClient) GetDel(key string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsExpired", key)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "GetDel", key)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// IsExpired indicates an expected call of IsExpired.
-func (mr *MockredisClientMockRecorder) IsExpired(key interface{}) *gomock.Call {
+// GetDel indicates an expected call of GetDel.
+func (mr *MockredisClientMockRecorder) GetDel(key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsExpired", reflect.TypeOf((*MockredisClient)(nil).IsExpired), key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDel", reflect.TypeOf((*MockredisClient)(nil).GetDel), key)
+}
+
+// Incr mocks base method.
+func (m *MockredisClient) Incr(key string, ttl time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", key, ttl)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockredisClientMockRecorder) Incr(key, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockredisClient)(nil).Incr), key, ttl)
 }
 
 // Set mocks base method.
@@ -163,6 +239,21 @@ func (mr *MockredisClientMockRecorder) Set(key, value, ttl interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockredisClient)(nil).Set), key, value, ttl)
 }
 
+// SetNX mocks base method.
+func (m *MockredisClient) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNX", key, value, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetNX indicates an expected call of SetNX.
+func (mr *MockredisClientMockRecorder) SetNX(key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNX", reflect.TypeOf((*MockredisClient)(nil).SetNX), key, value, ttl)
+}
+
 // MockjwtUsecase is a mock of jwtUsecase interface.
 type MockjwtUsecase struct {
 	ctrl     *gomock.Controller
@@ -186,34 +277,262 @@ func (m *MockjwtUsecase) EXPECT() *MockjwtUsecaseMockRecorder {
 	return m.recorder
 }
 
-// GenerateJWT mocks base method.
-func (m *MockjwtUsecase) GenerateJWT(userID int64, chefID *int64, role string) (string, error) {
+// IssueAccessToken mocks base method.
+func (m *MockjwtUsecase) IssueAccessToken(userID int64, chefID *int64, role, sessionID string) (*auth.AccessToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateJWT", userID, chefID, role)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "IssueAccessToken", userID, chefID, role, sessionID)
+	ret0, _ := ret[0].(*auth.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueAccessToken indicates an expected call of IssueAccessToken.
+func (mr *MockjwtUsecaseMockRecorder) IssueAccessToken(userID, chefID, role, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueAccessToken", reflect.TypeOf((*MockjwtUsecase)(nil).IssueAccessToken), userID, chefID, role, sessionID)
+}
+
+// Revoke mocks base method.
+func (m *MockjwtUsecase) Revoke(tokenUUID string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", tokenUUID, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockjwtUsecaseMockRecorder) Revoke(tokenUUID, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockjwtUsecase)(nil).Revoke), tokenUUID, expiresAt)
+}
+
+// MockrefreshTokensRepo is a mock of refreshTokensRepo interface.
+type MockrefreshTokensRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockrefreshTokensRepoMockRecorder
+}
+
+// MockrefreshTokensRepoMockRecorder is the mock recorder for MockrefreshTokensRepo.
+type MockrefreshTokensRepoMockRecorder struct {
+	mock *MockrefreshTokensRepo
+}
+
+// NewMockrefreshTokensRepo creates a new mock instance.
+func NewMockrefreshTokensRepo(ctrl *gomock.Controller) *MockrefreshTokensRepo {
+	mock := &MockrefreshTokensRepo{ctrl: ctrl}
+	mock.recorder = &MockrefreshTokensRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrefreshTokensRepo) EXPECT() *MockrefreshTokensRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockrefreshTokensRepo) CreateRefreshToken(ctx context.Context, t *auth.RefreshToken) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, t)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GenerateJWT indicates an expected call of GenerateJWT.
-func (mr *MockjwtUsecaseMockRecorder) GenerateJWT(userID, chefID, role interface{}) *gomock.Call {
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockrefreshTokensRepoMockRecorder) CreateRefreshToken(ctx, t interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateJWT", reflect.TypeOf((*MockjwtUsecase)(nil).GenerateJWT), userID, chefID, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockrefreshTokensRepo)(nil).CreateRefreshToken), ctx, t)
 }
 
-// ValidateJWT mocks base method.
-func (m *MockjwtUsecase) ValidateJWT(tokenString string) (map[string]interface{}, error) {
+// GetRefreshTokenByAccessUUID mocks base method.
+func (m *MockrefreshTokensRepo) GetRefreshTokenByAccessUUID(ctx context.Context, accessUUID string) (*auth.RefreshToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ValidateJWT", tokenString)
-	ret0, _ := ret[0].(map[string]interface{})
+	ret := m.ctrl.Call(m, "GetRefreshTokenByAccessUUID", ctx, accessUUID)
+	ret0, _ := ret[0].(*auth.RefreshToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ValidateJWT indicates an expected call of ValidateJWT.
-func (mr *MockjwtUsecaseMockRecorder) ValidateJWT(tokenString interface{}) *gomock.Call {
+// GetRefreshTokenByAccessUUID indicates an expected call of GetRefreshTokenByAccessUUID.
+func (mr *MockrefreshTokensRepoMockRecorder) GetRefreshTokenByAccessUUID(ctx, accessUUID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateJWT", reflect.TypeOf((*MockjwtUsecase)(nil).ValidateJWT), tokenString)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByAccessUUID", reflect.TypeOf((*MockrefreshTokensRepo)(nil).GetRefreshTokenByAccessUUID), ctx, accessUUID)
+}
+
+// GetRefreshTokenByHash mocks base method.
+func (m *MockrefreshTokensRepo) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*auth.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*auth.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenByHash indicates an expected call of GetRefreshTokenByHash.
+func (mr *MockrefreshTokensRepoMockRecorder) GetRefreshTokenByHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByHash", reflect.TypeOf((*MockrefreshTokensRepo)(nil).GetRefreshTokenByHash), ctx, tokenHash)
+}
+
+// RevokeFamily mocks base method.
+func (m *MockrefreshTokensRepo) RevokeFamily(ctx context.Context, familyID string) ([]auth.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", ctx, familyID)
+	ret0, _ := ret[0].([]auth.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockrefreshTokensRepoMockRecorder) RevokeFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockrefreshTokensRepo)(nil).RevokeFamily), ctx, familyID)
+}
+
+// RevokeRefreshToken mocks base method.
+func (m *MockrefreshTokensRepo) RevokeRefreshToken(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken.
+func (mr *MockrefreshTokensRepoMockRecorder) RevokeRefreshToken(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockrefreshTokensRepo)(nil).RevokeRefreshToken), ctx, id)
+}
+
+// MocksessionsRepo is a mock of sessionsRepo interface.
+type MocksessionsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MocksessionsRepoMockRecorder
+}
+
+// MocksessionsRepoMockRecorder is the mock recorder for MocksessionsRepo.
+type MocksessionsRepoMockRecorder struct {
+	mock *MocksessionsRepo
+}
+
+// NewMocksessionsRepo creates a new mock instance.
+func NewMocksessionsRepo(ctrl *gomock.Controller) *MocksessionsRepo {
+	mock := &MocksessionsRepo{ctrl: ctrl}
+	mock.recorder = &MocksessionsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksessionsRepo) EXPECT() *MocksessionsRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateSession mocks base method.
+func (m *MocksessionsRepo) CreateSession(ctx context.Context, s *auth.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MocksessionsRepoMockRecorder) CreateSession(ctx, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MocksessionsRepo)(nil).CreateSession), ctx, s)
+}
+
+// GetActiveSessionsByUserID mocks base method.
+func (m *MocksessionsRepo) GetActiveSessionsByUserID(ctx context.Context, userID int64) ([]auth.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveSessionsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]auth.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveSessionsByUserID indicates an expected call of GetActiveSessionsByUserID.
+func (mr *MocksessionsRepoMockRecorder) GetActiveSessionsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveSessionsByUserID", reflect.TypeOf((*MocksessionsRepo)(nil).GetActiveSessionsByUserID), ctx, userID)
+}
+
+// RevokeSession mocks base method.
+func (m *MocksessionsRepo) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MocksessionsRepoMockRecorder) RevokeSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MocksessionsRepo)(nil).RevokeSession), ctx, userID, sessionID)
+}
+
+// RevokeUserSessions mocks base method.
+func (m *MocksessionsRepo) RevokeUserSessions(ctx context.Context, userID int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MocksessionsRepoMockRecorder) RevokeUserSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MocksessionsRepo)(nil).RevokeUserSessions), ctx, userID)
+}
+
+// TouchSession mocks base method.
+func (m *MocksessionsRepo) TouchSession(ctx context.Context, sessionID, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, sessionID, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MocksessionsRepoMockRecorder) TouchSession(ctx, sessionID, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MocksessionsRepo)(nil).TouchSession), ctx, sessionID, ip)
+}
+
+// Mocktransactor is a mock of transactor interface.
+type Mocktransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MocktransactorMockRecorder
+}
+
+// MocktransactorMockRecorder is the mock recorder for Mocktransactor.
+type MocktransactorMockRecorder struct {
+	mock *Mocktransactor
+}
+
+// NewMocktransactor creates a new mock instance.
+func NewMocktransactor(ctrl *gomock.Controller) *Mocktransactor {
+	mock := &Mocktransactor{ctrl: ctrl}
+	mock.recorder = &MocktransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mocktransactor) EXPECT() *MocktransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTransaction mocks base method.
+func (m *Mocktransactor) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MocktransactorMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*Mocktransactor)(nil).WithinTransaction), ctx, fn)
 }
 
 // MockSMSClient is a mock of SMSClient interface.
@@ -239,16 +558,53 @@ func (m *MockSMSClient) EXPECT() *MockSMSClientMockRecorder {
 	return m.recorder
 }
 
-// Send mocks base method.
-func (m *MockSMSClient) Send(phone, message string) error {
+// SendSMS mocks base method.
+func (m *MockSMSClient) SendSMS(ctx context.Context, userID int64, phone string, tpl notifications.SMSTemplate, data notifications.SMSData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSMS", ctx, userID, phone, tpl, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendSMS indicates an expected call of SendSMS.
+func (mr *MockSMSClientMockRecorder) SendSMS(ctx, userID, phone, tpl, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSMS", reflect.TypeOf((*MockSMSClient)(nil).SendSMS), ctx, userID, phone, tpl, data)
+}
+
+// MockEmailClient is a mock of EmailClient interface.
+type MockEmailClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailClientMockRecorder
+}
+
+// MockEmailClientMockRecorder is the mock recorder for MockEmailClient.
+type MockEmailClientMockRecorder struct {
+	mock *MockEmailClient
+}
+
+// NewMockEmailClient creates a new mock instance.
+func NewMockEmailClient(ctrl *gomock.Controller) *MockEmailClient {
+	mock := &MockEmailClient{ctrl: ctrl}
+	mock.recorder = &MockEmailClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailClient) EXPECT() *MockEmailClientMockRecorder {
+	return m.recorder
+}
+
+// SendEmail mocks base method.
+func (m *MockEmailClient) SendEmail(ctx context.Context, userID int64, email string, tpl notifications.EmailTemplate, data notifications.EmailTemplateData) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Send", phone, message)
+	ret := m.ctrl.Call(m, "SendEmail", ctx, userID, email, tpl, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Send indicates an expected call of Send.
-func (mr *MockSMSClientMockRecorder) Send(phone, message interface{}) *gomock.Call {
+// SendEmail indicates an expected call of SendEmail.
+func (mr *MockEmailClientMockRecorder) SendEmail(ctx, userID, email, tpl, data interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSMSClient)(nil).Send), phone, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmail", reflect.TypeOf((*MockEmailClient)(nil).SendEmail), ctx, userID, email, tpl, data)
 }
@@ -2,13 +2,22 @@ package auth
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"domashka-backend/internal/entity/auth"
+	notifentity "domashka-backend/internal/entity/notifications"
 	userentity "domashka-backend/internal/entity/users"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
-	"fmt"
-	"github.com/redis/go-redis/v9"
+	"github.com/google/uuid"
 	"log"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"domashka-backend/internal/custom_errors"
@@ -19,25 +28,87 @@ const (
 	InitialDelay = 1 * time.Minute
 	SecondDelay  = 2 * time.Minute
 	MaxDelay     = 5 * time.Minute
+
+	// sessionTouchInterval — не чаще этого обновляем last_seen_at сессии
+	sessionTouchInterval = time.Minute
 )
 
+// OTPConfig — ограничения проверки одноразовых кодов
+type OTPConfig struct {
+	// MaxAttempts — попыток ввода кода на номер за Lockout
+	MaxAttempts int
+	Lockout     time.Duration
+	// TestPhones получают фиксированный TestCode без SMS; пустой TestCode отключает их
+	TestPhones []string
+	TestCode   string
+	// MagicLinkURL и MagicLinkSecret включают вход по ссылке из письма; ссылка живёт MagicLinkTTL
+	MagicLinkURL    string
+	MagicLinkSecret string
+	MagicLinkTTL    time.Duration
+	// TelegramBot — имя бота без @ для ссылки входа через Telegram
+	TelegramBot string
+}
+
 type UseCase struct {
-	usersRepo usersRepo
-	redis     redisClient
-	sms       SMSClient
-	jwt       jwtUsecase
+	usersRepo    usersRepo
+	redis        redisClient
+	sms          SMSClient
+	email        EmailClient
+	jwt          jwtUsecase
+	tokensRepo   refreshTokensRepo
+	sessionsRepo sessionsRepo
+	transactor   transactor
+	// refreshTTL — сколько живёт refresh-токен
+	refreshTTL time.Duration
+	otp        OTPConfig
 }
 
-func New(repo usersRepo, redis redisClient, jwt jwtUsecase, sms SMSClient) *UseCase {
+func New(repo usersRepo, redis redisClient, jwt jwtUsecase, sms SMSClient, email EmailClient, tokensRepo refreshTokensRepo, sessionsRepo sessionsRepo, transactor transactor, refreshTTL time.Duration, otp OTPConfig) *UseCase {
 	return &UseCase{
-		usersRepo: repo,
-		redis:     redis,
-		jwt:       jwt,
-		sms:       sms,
+		usersRepo:    repo,
+		redis:        redis,
+		jwt:          jwt,
+		sms:          sms,
+		email:        email,
+		tokensRepo:   tokensRepo,
+		sessionsRepo: sessionsRepo,
+		transactor:   transactor,
+		refreshTTL:   refreshTTL,
+		otp:          otp,
 	}
 }
 
 func (u *UseCase) sendOTP(ctx context.Context, user *userentity.User, otp string) error {
+	if err := u.checkOTPLimits(ctx, user); err != nil {
+		return err
+	}
+
+	if user.NumberPhone == nil || *user.NumberPhone == "" {
+		return errors.New("номер телефона отсутствует")
+	}
+
+	if !u.isTestPhone(*user.NumberPhone) {
+		if err := u.sms.SendSMS(ctx, user.ID, *user.NumberPhone, notifentity.SMSTemplateOTP, notifentity.SMSData{Code: otp}); err != nil {
+			return err
+		}
+	}
+
+	return u.recordOTPSent(ctx, user)
+}
+
+// sendEmailOTP отправляет код или ссылку для входа на почту. Ограничения те же, что у SMS
+func (u *UseCase) sendEmailOTP(ctx context.Context, user *userentity.User, email string, tpl notifentity.EmailTemplate, data notifentity.EmailTemplateData) error {
+	if err := u.checkOTPLimits(ctx, user); err != nil {
+		return err
+	}
+	if err := u.email.SendEmail(ctx, user.ID, email, tpl, data); err != nil {
+		return err
+	}
+	return u.recordOTPSent(ctx, user)
+}
+
+// checkOTPLimits не даёт запрашивать коды слишком часто; после MaxAttempts отправок пользователь помечается спамом
+func (u *UseCase) checkOTPLimits(ctx context.Context, user *userentity.User) error {
 	if user.IsSpam == 1 {
 		return custom_errors.ErrUserIsSpam
 	}
@@ -49,36 +120,28 @@ func (u *UseCase) sendOTP(ctx context.Context, user *userentity.User, otp string
 		}
 	}
 
-	if user.NumberPhone == nil || *user.NumberPhone == "" {
-		return errors.New("номер телефона отсутствует")
-	}
-
-	userID := user.ID
-
 	if user.SMSAttempts >= MaxAttempts {
 		user.IsSpam = 1
 
-		if err := u.usersRepo.Update(ctx, userID, *user); err != nil {
+		if err := u.usersRepo.Update(ctx, user.ID, *user); err != nil {
 			return err
 		}
 		return custom_errors.ErrUserIsSpam
 	}
+	return nil
+}
 
-	if err := u.sms.Send(*user.NumberPhone, otp); err != nil {
-		return err
-	}
-
+func (u *UseCase) recordOTPSent(ctx context.Context, user *userentity.User) error {
 	now := time.Now()
 	user.LastSMSRequest = &now
 	user.SMSAttempts++
-	if err := u.usersRepo.Update(ctx, userID, *user); err != nil {
-		return err
-	}
-
-	return nil
+	return u.usersRepo.Update(ctx, user.ID, *user)
 }
 
 func (u *UseCase) Auth(ctx context.Context, req auth.Request) error {
+	if req.Email != "" {
+		return u.authByEmail(ctx, normalizeEmail(req.Email), req.Method)
+	}
 	log.Printf("DEBUG: Начало аутентификации для номера: %s", req.Phone)
 	user, err := u.usersRepo.GetByPhone(ctx, req.Phone)
 
@@ -99,45 +162,64 @@ func (u *UseCase) Auth(ctx context.Context, req auth.Request) error {
 	return u.login(ctx, user)
 }
 
-func (u *UseCase) AuthViaTg(ctx context.Context, phoneNumber string) error {
-	key := fmt.Sprintf("tg_auth:%s", phoneNumber)
-
-	user, err := u.usersRepo.GetByPhone(ctx, phoneNumber)
-	if err != nil && !errors.Is(err, custom_errors.ErrUserNotFound) {
-		return err
+// AuthViaTg начинает вход через Telegram: выдаёт одноразовую ссылку на бота и секрет,
+// по которому создавший её клиент заберёт токены
+func (u *UseCase) AuthViaTg(_ context.Context) (*auth.TgLoginLink, error) {
+	nonce := randomToken(16)
+	secret := randomToken(32)
+	// Секрет хешируется так же, как refresh-токен: в Redis он не хранится в открытом виде
+	data, err := json.Marshal(auth.TgLogin{SecretHash: hashRefreshToken(secret)})
+	if err != nil {
+		return nil, err
 	}
-	if user == nil {
-		err = u.redis.Set(key, "register", 5*time.Minute)
-		return err
+	if err := u.redis.Set(auth.TgLoginKey(nonce), string(data), auth.TgLoginTTL); err != nil {
+		return nil, err
 	}
 
-	err = u.redis.Set(key, "login", 5*time.Minute)
-	return err
+	link := &auth.TgLoginLink{Nonce: nonce, Secret: secret, ExpiresIn: auth.TgLoginTTL}
+	if u.otp.TelegramBot != "" {
+		link.Link = "https://t.me/" + u.otp.TelegramBot + "?start=" + nonce
+	}
+	return link, nil
 }
 
-func (u *UseCase) AuthViaTgStatus(_ context.Context, phoneNumber string) (string, error) {
-	key := fmt.Sprintf("tg_auth:%s", phoneNumber)
-	isExpired, err := u.redis.IsExpired(key)
+// AuthViaTgStatus обменивает подтверждённый в боте вход на сессию. Обмен возможен один раз
+// и только с секретом, выданным вместе с nonce
+func (u *UseCase) AuthViaTgStatus(ctx context.Context, nonce, secret string, device auth.Device) (userID int64, chefID *int64, tokens *auth.Tokens, err error) {
+	key := auth.TgLoginKey(nonce)
+	raw, err := u.redis.Get(key)
 	if err != nil {
-		return "", err
+		return 0, nil, nil, err
+	}
+	if raw == "" {
+		return 0, nil, nil, custom_errors.ErrExpiredTTL
 	}
-	if isExpired {
-		return "", custom_errors.ErrExpiredTTL
+	var login auth.TgLogin
+	if err := json.Unmarshal([]byte(raw), &login); err != nil {
+		return 0, nil, nil, err
+	}
+	// Чужой секрет не отличаем от истёкшей ссылки, чтобы не подтверждать существование nonce
+	if subtle.ConstantTimeCompare([]byte(hashRefreshToken(secret)), []byte(login.SecretHash)) != 1 {
+		return 0, nil, nil, custom_errors.ErrExpiredTTL
+	}
+	if login.UserID == 0 {
+		return 0, nil, nil, custom_errors.ErrConfirmationNotReceived
 	}
 
-	token, err := u.redis.Get("token:" + phoneNumber)
+	// Из параллельных опросов токены получит только тот, кто первым удалил вход
+	consumed, err := u.redis.GetDel(key)
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			return "", custom_errors.ErrConfirmationNotReceived
-		}
-		return "", err
+		return 0, nil, nil, err
 	}
-
-	if token == "" {
-		return "", custom_errors.ErrConfirmationNotReceived
+	if consumed != raw {
+		return 0, nil, nil, custom_errors.ErrExpiredTTL
 	}
 
-	return token, nil
+	user, err := u.usersRepo.GetByID(ctx, login.UserID)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return u.openSession(ctx, user, device)
 }
 
 func (u *UseCase) register(ctx context.Context, phone string) error {
@@ -146,7 +228,7 @@ func (u *UseCase) register(ctx context.Context, phone string) error {
 		return err
 	}
 
-	otp := generateOTP()
+	otp := u.newOTP(phone)
 
 	if err := u.redis.Set(phone, otp, 5*time.Minute); err != nil {
 		return err
@@ -159,31 +241,205 @@ func (u *UseCase) register(ctx context.Context, phone string) error {
 	return nil
 }
 
-func (u *UseCase) Verify(ctx context.Context, phone string, otp string, role string) (userID int64, chefID *int64, token string, err error) {
-	isValid, err := u.validateOTP(phone, otp)
-	if err != nil || !isValid {
-		return 0, nil, "", fmt.Errorf("invalid or expired OTP")
+// Verify проверяет OTP, открывает сессию для устройства и выдаёт пару токенов.
+// Роль в токене определяется по данным пользователя в БД.
+func (u *UseCase) Verify(ctx context.Context, phone string, otp string, device auth.Device) (userID int64, chefID *int64, tokens *auth.Tokens, err error) {
+	if err := u.validateOTP(phone, otp); err != nil {
+		return 0, nil, nil, err
 	}
 	user, err := u.usersRepo.GetByPhone(ctx, phone)
 	if err != nil {
-		return 0, nil, "", err
+		return 0, nil, nil, err
+	}
+	return u.openSession(ctx, user, device)
+}
+
+// VerifyEmail — то же, что Verify, для входа по почте: otp — код из письма или токен из ссылки
+func (u *UseCase) VerifyEmail(ctx context.Context, email string, otp string, device auth.Device) (userID int64, chefID *int64, tokens *auth.Tokens, err error) {
+	email = normalizeEmail(email)
+	// Поддельную ссылку отбрасываем до счётчика попыток, чтобы ею нельзя было заблокировать вход
+	if isMagicToken(otp) && !u.validMagicToken(email, otp) {
+		return 0, nil, nil, custom_errors.ErrInvalidOTP
+	}
+	if err := u.validateOTP(email, otp); err != nil {
+		return 0, nil, nil, err
 	}
-	chefID, _, err = u.usersRepo.CheckIfUserIsChef(ctx, user.ID)
+	user, err := u.usersRepo.GetByEmail(ctx, email)
 	if err != nil {
-		return 0, nil, "", err
+		return 0, nil, nil, err
 	}
-	token, err = u.jwt.GenerateJWT(user.ID, chefID, role)
+	return u.openSession(ctx, user, device)
+}
+
+// openSession создаёт сессию для устройства и выдаёт в ней пару токенов
+func (u *UseCase) openSession(ctx context.Context, user *userentity.User, device auth.Device) (userID int64, chefID *int64, tokens *auth.Tokens, err error) {
+	session := &auth.Session{ID: uuid.NewString(), UserID: user.ID, Device: device}
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.sessionsRepo.CreateSession(ctx, session); err != nil {
+			return err
+		}
+		chefID, tokens, err = u.issueTokens(ctx, user, session.ID)
+		return err
+	})
 	if err != nil {
-		return 0, nil, "", err
+		return 0, nil, nil, err
 	}
 
-	return user.ID, chefID, token, nil
+	return user.ID, chefID, tokens, nil
+}
+
+// Refresh меняет refresh-токен на новую пару. Старый токен отзывается; его повторное
+// предъявление означает утечку, и тогда отзывается всё семейство вместе с access-токенами.
+func (u *UseCase) Refresh(ctx context.Context, refreshToken string) (*auth.Tokens, error) {
+	stored, err := u.tokensRepo.GetRefreshTokenByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if stored.RevokedAt != nil {
+		log.Printf("auth: reuse of revoked refresh token %d, revoking session %s", stored.ID, stored.FamilyID)
+		if err := u.revokeSession(ctx, stored.UserID, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, custom_errors.ErrInvalidRefreshToken
+	}
+	if !stored.ExpiresAt.After(time.Now()) {
+		return nil, custom_errors.ErrInvalidRefreshToken
+	}
+
+	user, err := u.usersRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens *auth.Tokens
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Отзыв условный: из двух одновременных обновлений одним токеном пройдёт только одно
+		if err := u.tokensRepo.RevokeRefreshToken(ctx, stored.ID); err != nil {
+			return err
+		}
+		if _, tokens, err = u.issueTokens(ctx, user, stored.FamilyID); err != nil {
+			return err
+		}
+		return u.sessionsRepo.TouchSession(ctx, stored.FamilyID, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Logout отзывает текущий access-токен и сессию, в которой он выдан
+func (u *UseCase) Logout(ctx context.Context, accessUUID string, accessExpiresAt time.Time) error {
+	if err := u.jwt.Revoke(accessUUID, accessExpiresAt); err != nil {
+		return err
+	}
+
+	stored, err := u.tokensRepo.GetRefreshTokenByAccessUUID(ctx, accessUUID)
+	if err != nil {
+		// Токен выдан без refresh (например, через Telegram) — отзывать больше нечего
+		if errors.Is(err, custom_errors.ErrInvalidRefreshToken) {
+			return nil
+		}
+		return err
+	}
+	return u.revokeSession(ctx, stored.UserID, stored.FamilyID)
+}
+
+// GetSessions возвращает активные сессии пользователя
+func (u *UseCase) GetSessions(ctx context.Context, userID int64) ([]auth.Session, error) {
+	return u.sessionsRepo.GetActiveSessionsByUserID(ctx, userID)
+}
+
+// RevokeSession завершает сессию пользователя на одном устройстве
+func (u *UseCase) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	if err := u.sessionsRepo.RevokeSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+	return u.revokeFamily(ctx, sessionID)
+}
+
+// RevokeAllSessions завершает все сессии пользователя, включая текущую
+func (u *UseCase) RevokeAllSessions(ctx context.Context, userID int64) error {
+	sessionIDs, err := u.sessionsRepo.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, sessionID := range sessionIDs {
+		if err := u.revokeFamily(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TouchSession отмечает активность сессии. В БД пишем не чаще sessionTouchInterval,
+// чтобы не обновлять строку на каждый запрос.
+func (u *UseCase) TouchSession(ctx context.Context, sessionID, ip string) error {
+	first, err := u.redis.SetNX("session_seen:"+sessionID, ip, sessionTouchInterval)
+	if err != nil || !first {
+		return err
+	}
+	return u.sessionsRepo.TouchSession(ctx, sessionID, ip)
+}
+
+// revokeSession отзывает сессию вместе с её токенами; уже отозванная сессия не ошибка
+func (u *UseCase) revokeSession(ctx context.Context, userID int64, sessionID string) error {
+	err := u.RevokeSession(ctx, userID, sessionID)
+	if errors.Is(err, custom_errors.ErrSessionNotFound) {
+		return u.revokeFamily(ctx, sessionID)
+	}
+	return err
+}
+
+// issueTokens выпускает access- и refresh-токен в сессии familyID
+func (u *UseCase) issueTokens(ctx context.Context, user *userentity.User, familyID string) (*int64, *auth.Tokens, error) {
+	chefID, isChef, err := u.usersRepo.CheckIfUserIsChef(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	access, err := u.jwt.IssueAccessToken(user.ID, chefID, auth.ResolveRole(user.Role, isChef), familyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken := generateRefreshToken()
+	_, err = u.tokensRepo.CreateRefreshToken(ctx, &auth.RefreshToken{
+		UserID:          user.ID,
+		TokenHash:       hashRefreshToken(refreshToken),
+		FamilyID:        familyID,
+		AccessUUID:      access.UUID,
+		AccessExpiresAt: access.ExpiresAt,
+		ExpiresAt:       time.Now().Add(u.refreshTTL),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return chefID, &auth.Tokens{
+		AccessToken:  access.Token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    time.Until(access.ExpiresAt).Round(time.Second),
+	}, nil
+}
+
+// revokeFamily отзывает все действующие refresh-токены семейства и выданные с ними access-токены
+func (u *UseCase) revokeFamily(ctx context.Context, familyID string) error {
+	revoked, err := u.tokensRepo.RevokeFamily(ctx, familyID)
+	if err != nil {
+		return err
+	}
+	for _, t := range revoked {
+		if err := u.jwt.Revoke(t.AccessUUID, t.AccessExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (u *UseCase) login(ctx context.Context, user *userentity.User) error {
 	log.Printf("DEBUG: Начало логина для пользователя ID: %d", user.ID)
-	otp := generateOTP()
-	log.Printf("DEBUG: Сгенерирован OTP для логина пользователя ID: %d: %s", user.ID, otp)
+	otp := u.newOTP(*user.NumberPhone)
+	log.Printf("DEBUG: Сгенерирован OTP для логина пользователя ID: %d", user.ID)
 	if err := u.redis.Set(*user.NumberPhone, otp, 5*time.Minute); err != nil {
 		log.Printf("DEBUG: Ошибка установки OTP в Redis для пользователя ID: %d: %v", user.ID, err)
 		return err
@@ -197,16 +453,106 @@ func (u *UseCase) login(ctx context.Context, user *userentity.User) error {
 	return nil
 }
 
-func (u *UseCase) validateOTP(phone string, otp string) (bool, error) {
-	// todo: Убрать костыль перед выгрузкой в прод
-	if otp == "0123" {
-		return true, nil
+// authByEmail регистрирует пользователя при первом входе и отправляет на почту код или ссылку.
+// Код и токен ссылки хранятся там же, где коды из SMS, — под ключом-адресом почты.
+func (u *UseCase) authByEmail(ctx context.Context, email, method string) error {
+	if method == auth.LoginMethodLink && (u.otp.MagicLinkURL == "" || u.otp.MagicLinkSecret == "") {
+		return custom_errors.ErrMagicLinkDisabled
+	}
+
+	user, err := u.usersRepo.GetByEmail(ctx, email)
+	if errors.Is(err, custom_errors.ErrUserNotFound) {
+		user, err = u.usersRepo.CreateWithEmail(ctx, email)
+	}
+	if err != nil {
+		return err
 	}
+
+	if method == auth.LoginMethodLink {
+		token := u.newMagicToken(email)
+		if err := u.redis.Set(email, token, u.otp.MagicLinkTTL); err != nil {
+			return err
+		}
+		link := u.otp.MagicLinkURL + "?" + url.Values{"email": {email}, "token": {token}}.Encode()
+		return u.sendEmailOTP(ctx, user, email, notifentity.EmailTemplateMagicLink, notifentity.EmailTemplateData{Link: link})
+	}
+
+	otp := generateOTP()
+	if err := u.redis.Set(email, otp, 5*time.Minute); err != nil {
+		return err
+	}
+	return u.sendEmailOTP(ctx, user, email, notifentity.EmailTemplateOTP, notifentity.EmailTemplateData{Code: otp})
+}
+
+// validateOTP проверяет код. Каждая попытка учитывается до проверки, поэтому параллельный
+// перебор тоже упирается в лимит. Верный код одноразовый: после проверки он удаляется.
+func (u *UseCase) validateOTP(phone string, otp string) error {
+	attemptsKey := "otp_attempts:" + phone
+	attempts, err := u.redis.Incr(attemptsKey, u.otp.Lockout)
+	if err != nil {
+		return err
+	}
+	if attempts > int64(u.otp.MaxAttempts) {
+		return custom_errors.ErrOTPLocked
+	}
+
 	storedOTP, err := u.redis.Get(phone)
 	if err != nil {
-		return false, err
+		return err
+	}
+	if storedOTP == "" || subtle.ConstantTimeCompare([]byte(storedOTP), []byte(otp)) != 1 {
+		return custom_errors.ErrInvalidOTP
+	}
+
+	if err := u.redis.Delete(phone); err != nil {
+		return err
+	}
+	return u.redis.Delete(attemptsKey)
+}
+
+// newOTP выдаёт код для номера; тестовым номерам из конфига — фиксированный
+func (u *UseCase) newOTP(phone string) string {
+	if u.isTestPhone(phone) {
+		return u.otp.TestCode
+	}
+	return generateOTP()
+}
+
+func (u *UseCase) isTestPhone(phone string) bool {
+	return u.otp.TestCode != "" && slices.Contains(u.otp.TestPhones, phone)
+}
+
+// newMagicToken возвращает токен ссылки для входа: случайная часть и её подпись вместе с адресом почты
+func (u *UseCase) newMagicToken(email string) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal("failed to generate magic link token")
 	}
-	return storedOTP == otp, nil
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+	return nonce + "." + u.signMagicToken(email, nonce)
+}
+
+func (u *UseCase) validMagicToken(email, token string) bool {
+	if u.otp.MagicLinkSecret == "" {
+		return false
+	}
+	nonce, sig, _ := strings.Cut(token, ".")
+	return hmac.Equal([]byte(sig), []byte(u.signMagicToken(email, nonce)))
+}
+
+func (u *UseCase) signMagicToken(email, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(u.otp.MagicLinkSecret))
+	mac.Write([]byte(email + "." + nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// isMagicToken отличает токен ссылки от цифрового кода
+func isMagicToken(otp string) bool {
+	return strings.Contains(otp, ".")
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
 func generateOTP() string {
@@ -221,6 +567,25 @@ func generateOTP() string {
 	return string(otp)
 }
 
+// generateRefreshToken возвращает случайный непрозрачный токен; в БД хранится только его хеш
+func generateRefreshToken() string {
+	return randomToken(32)
+}
+
+// randomToken — size случайных байт в base64url; подходит и для параметра start в ссылках Telegram
+func randomToken(size int) string {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal("failed to generate random token")
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func getSMSDelay(attempts int) time.Duration {
 	switch attempts {
 	case 1:
@@ -3,17 +3,25 @@ package auth
 import (
 	"context"
 	"domashka-backend/internal/utils/pointers"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	"domashka-backend/internal/custom_errors"
 	"domashka-backend/internal/entity/auth"
+	notifentity "domashka-backend/internal/entity/notifications"
 	userentity "domashka-backend/internal/entity/users"
 )
 
+const refreshTTL = 30 * 24 * time.Hour
+
+var otpConfig = OTPConfig{MaxAttempts: 5, Lockout: 15 * time.Minute}
+
 func TestUseCase_Auth(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -42,7 +50,7 @@ func TestUseCase_Auth(t *testing.T) {
 			},
 			sms: func(ctrl *gomock.Controller) SMSClient {
 				m := NewMockSMSClient(ctrl)
-				m.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().SendSMS(gomock.Any(), gomock.Any(), gomock.Any(), notifentity.SMSTemplateOTP, gomock.Any()).Return(nil)
 				return m
 			},
 			jwt: func(ctrl *gomock.Controller) jwtUsecase {
@@ -55,7 +63,7 @@ func TestUseCase_Auth(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl))
+			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl), NewMockEmailClient(ctrl), NewMockrefreshTokensRepo(ctrl), NewMocksessionsRepo(ctrl), passthroughTransactor(ctrl), refreshTTL, otpConfig)
 			if err := u.Auth(context.Background(), tt.req); (err != nil) != tt.wantErr {
 				t.Errorf("Auth() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -64,169 +72,767 @@ func TestUseCase_Auth(t *testing.T) {
 }
 
 func TestUseCase_AuthViaTg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	redis := NewMockredisClient(ctrl)
+	var stored string
+	redis.EXPECT().Set(gomock.Any(), gomock.Any(), auth.TgLoginTTL).DoAndReturn(func(key, value string, _ time.Duration) error {
+		stored = value
+		require.True(t, strings.HasPrefix(key, "tg_login:"))
+		return nil
+	})
+
+	cfg := otpConfig
+	cfg.TelegramBot = "domashka_bot"
+	u := New(NewMockusersRepo(ctrl), redis, NewMockjwtUsecase(ctrl), NewMockSMSClient(ctrl), NewMockEmailClient(ctrl), NewMockrefreshTokensRepo(ctrl), NewMocksessionsRepo(ctrl), passthroughTransactor(ctrl), refreshTTL, cfg)
+	link, err := u.AuthViaTg(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "https://t.me/domashka_bot?start="+link.Nonce, link.Link)
+	require.NotEqual(t, link.Nonce, link.Secret)
+	// Секрет в Redis не попадает
+	require.NotContains(t, stored, link.Secret)
+	require.Contains(t, stored, hashRefreshToken(link.Secret))
+}
+
+func TestUseCase_AuthViaTgStatus(t *testing.T) {
+	accessToken := &auth.AccessToken{Token: "token", UUID: "access-uuid", ExpiresAt: time.Now().Add(time.Minute)}
+	key := auth.TgLoginKey("nonce")
+	confirmed := `{"secret_hash":"` + hashRefreshToken("secret") + `","telegram_id":42,"user_id":1}`
+	pending := `{"secret_hash":"` + hashRefreshToken("secret") + `","telegram_id":42}`
+
 	tests := []struct {
-		name      string
-		usersRepo func(ctrl *gomock.Controller) usersRepo
-		redis     func(ctrl *gomock.Controller) redisClient
-		sms       func(ctrl *gomock.Controller) SMSClient
-		jwt       func(ctrl *gomock.Controller) jwtUsecase
-		in        string
-		wantErr   bool
+		name    string
+		secret  string
+		redis   func(m *MockredisClient)
+		session bool
+		wantErr error
+	}{
+		{
+			name:   "confirmed login is exchanged for tokens",
+			secret: "secret",
+			redis: func(m *MockredisClient) {
+				m.EXPECT().Get(key).Return(confirmed, nil)
+				m.EXPECT().GetDel(key).Return(confirmed, nil)
+			},
+			session: true,
+		},
+		{
+			name:   "not confirmed yet",
+			secret: "secret",
+			redis: func(m *MockredisClient) {
+				m.EXPECT().Get(key).Return(pending, nil)
+			},
+			wantErr: custom_errors.ErrConfirmationNotReceived,
+		},
+		{
+			name:   "wrong secret",
+			secret: "other",
+			redis: func(m *MockredisClient) {
+				m.EXPECT().Get(key).Return(confirmed, nil)
+			},
+			wantErr: custom_errors.ErrExpiredTTL,
+		},
+		{
+			name:   "expired",
+			secret: "secret",
+			redis: func(m *MockredisClient) {
+				m.EXPECT().Get(key).Return("", nil)
+			},
+			wantErr: custom_errors.ErrExpiredTTL,
+		},
+		{
+			name:   "already exchanged by a parallel request",
+			secret: "secret",
+			redis: func(m *MockredisClient) {
+				m.EXPECT().Get(key).Return(confirmed, nil)
+				m.EXPECT().GetDel(key).Return("", nil)
+			},
+			wantErr: custom_errors.ErrExpiredTTL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			redis := NewMockredisClient(ctrl)
+			tt.redis(redis)
+			users := NewMockusersRepo(ctrl)
+			jwt := NewMockjwtUsecase(ctrl)
+			tokens := NewMockrefreshTokensRepo(ctrl)
+			sessions := NewMocksessionsRepo(ctrl)
+			if tt.session {
+				users.EXPECT().GetByID(gomock.Any(), int64(1)).Return(&userentity.User{ID: 1, Role: "client"}, nil)
+				users.EXPECT().CheckIfUserIsChef(gomock.Any(), int64(1)).Return(nil, false, nil)
+				sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(nil)
+				jwt.EXPECT().IssueAccessToken(int64(1), nil, "client", gomock.Any()).Return(accessToken, nil)
+				tokens.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			}
+
+			u := New(users, redis, jwt, NewMockSMSClient(ctrl), NewMockEmailClient(ctrl), tokens, sessions, passthroughTransactor(ctrl), refreshTTL, otpConfig)
+			userID, _, got, err := u.AuthViaTgStatus(context.Background(), "nonce", tt.secret, auth.Device{Name: "iPhone"})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(1), userID)
+			require.Equal(t, "token", got.AccessToken)
+		})
+	}
+}
+
+func TestUseCase_Verify(t *testing.T) {
+	accessToken := &auth.AccessToken{Token: "token", UUID: "access-uuid", ExpiresAt: time.Now().Add(time.Minute)}
+	device := auth.Device{Name: "iPhone", Platform: "ios", IP: "10.0.0.1"}
+	validOTP := func(ctrl *gomock.Controller) redisClient {
+		m := NewMockredisClient(ctrl)
+		m.EXPECT().Incr("otp_attempts:81231234567", otpConfig.Lockout).Return(int64(1), nil)
+		m.EXPECT().Get("81231234567").Return("1234", nil)
+		m.EXPECT().Delete("81231234567").Return(nil)
+		m.EXPECT().Delete("otp_attempts:81231234567").Return(nil)
+		return m
+	}
+
+	tests := []struct {
+		name       string
+		usersRepo  func(ctrl *gomock.Controller) usersRepo
+		redis      func(ctrl *gomock.Controller) redisClient
+		sms        func(ctrl *gomock.Controller) SMSClient
+		jwt        func(ctrl *gomock.Controller) jwtUsecase
+		tokensRepo func(ctrl *gomock.Controller) refreshTokensRepo
+		phone      string
+		otp        string
+		wantUserID int64
+		wantChefID *int64
+		wantToken  string
+		wantErr    bool
 	}{
 		{
 			name: "success",
 			usersRepo: func(ctrl *gomock.Controller) usersRepo {
 				m := NewMockusersRepo(ctrl)
+				m.EXPECT().CheckIfUserIsChef(gomock.Any(), int64(1)).Return(nil, false, nil)
 				m.EXPECT().GetByPhone(gomock.Any(), gomock.Any()).Return(&userentity.User{
-					NumberPhone: pointers.To("81231234567"),
+					ID:   int64(1),
+					Role: "client",
 				}, nil)
 				return m
 			},
-			redis: func(ctrl *gomock.Controller) redisClient {
-				m := NewMockredisClient(ctrl)
-				m.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				return m
-			},
+			redis: validOTP,
 			sms: func(ctrl *gomock.Controller) SMSClient {
 				m := NewMockSMSClient(ctrl)
 				return m
 			},
 			jwt: func(ctrl *gomock.Controller) jwtUsecase {
 				m := NewMockjwtUsecase(ctrl)
+				m.EXPECT().IssueAccessToken(int64(1), nil, "client", gomock.Any()).Return(accessToken, nil)
+				return m
+			},
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				m.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, rt *auth.RefreshToken) (int64, error) {
+						require.Equal(t, int64(1), rt.UserID)
+						require.Equal(t, accessToken.UUID, rt.AccessUUID)
+						require.NotEmpty(t, rt.FamilyID)
+						return 1, nil
+					})
+				return m
+			},
+			phone:      "81231234567",
+			otp:        "1234",
+			wantUserID: 1,
+			wantToken:  "token",
+			wantErr:    false,
+			wantChefID: nil,
+		},
+		{
+			name: "chef role is taken from users_chefs",
+			usersRepo: func(ctrl *gomock.Controller) usersRepo {
+				m := NewMockusersRepo(ctrl)
+				m.EXPECT().CheckIfUserIsChef(gomock.Any(), int64(1)).Return(pointers.To(int64(7)), true, nil)
+				m.EXPECT().GetByPhone(gomock.Any(), gomock.Any()).Return(&userentity.User{
+					ID:   int64(1),
+					Role: "client",
+				}, nil)
+				return m
+			},
+			redis: validOTP,
+			sms: func(ctrl *gomock.Controller) SMSClient {
+				return NewMockSMSClient(ctrl)
+			},
+			jwt: func(ctrl *gomock.Controller) jwtUsecase {
+				m := NewMockjwtUsecase(ctrl)
+				m.EXPECT().IssueAccessToken(int64(1), pointers.To(int64(7)), "chef", gomock.Any()).Return(accessToken, nil)
 				return m
 			},
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				m.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				return m
+			},
+			phone:      "81231234567",
+			otp:        "1234",
+			wantUserID: 1,
+			wantToken:  "token",
+			wantChefID: pointers.To(int64(7)),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl))
-			if err := u.AuthViaTg(context.Background(), tt.in); (err != nil) != tt.wantErr {
-				t.Errorf("Auth() error = %v, wantErr %v", err, tt.wantErr)
+			sessions := NewMocksessionsRepo(ctrl)
+			sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, s *auth.Session) error {
+					require.Equal(t, device, s.Device)
+					require.NotEmpty(t, s.ID)
+					return nil
+				})
+			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl), NewMockEmailClient(ctrl), tt.tokensRepo(ctrl), sessions, passthroughTransactor(ctrl), refreshTTL, otpConfig)
+			gotUserID, gotChefID, gotTokens, err := u.Verify(context.Background(), tt.phone, tt.otp, device)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+				return
 			}
+			if gotUserID != tt.wantUserID {
+				t.Errorf("Verify() gotUserID = %v, want %v", gotUserID, tt.wantUserID)
+			}
+			if !reflect.DeepEqual(gotChefID, tt.wantChefID) {
+				t.Errorf("Verify() gotChefID = %v, want %v", gotChefID, tt.wantChefID)
+			}
+			if gotTokens.AccessToken != tt.wantToken {
+				t.Errorf("Verify() gotToken = %v, want %v", gotTokens.AccessToken, tt.wantToken)
+			}
+			require.NotEmpty(t, gotTokens.RefreshToken)
 		})
 	}
 }
 
-func TestUseCase_AuthViaTgStatus(t *testing.T) {
+func TestUseCase_validateOTP(t *testing.T) {
+	const phone = "81231234567"
+
 	tests := []struct {
-		name      string
-		usersRepo func(ctrl *gomock.Controller) usersRepo
-		redis     func(ctrl *gomock.Controller) redisClient
-		sms       func(ctrl *gomock.Controller) SMSClient
-		jwt       func(ctrl *gomock.Controller) jwtUsecase
-		in        string
-		want      string
-		wantErr   bool
+		name    string
+		redis   func(ctrl *gomock.Controller) redisClient
+		otp     string
+		wantErr error
 	}{
 		{
-			name: "success",
-			usersRepo: func(ctrl *gomock.Controller) usersRepo {
-				m := NewMockusersRepo(ctrl)
+			name: "valid code is consumed",
+			redis: func(ctrl *gomock.Controller) redisClient {
+				m := NewMockredisClient(ctrl)
+				m.EXPECT().Incr("otp_attempts:"+phone, otpConfig.Lockout).Return(int64(3), nil)
+				m.EXPECT().Get(phone).Return("1234", nil)
+				m.EXPECT().Delete(phone).Return(nil)
+				m.EXPECT().Delete("otp_attempts:" + phone).Return(nil)
 				return m
 			},
+			otp: "1234",
+		},
+		{
+			name: "wrong code",
 			redis: func(ctrl *gomock.Controller) redisClient {
 				m := NewMockredisClient(ctrl)
-				m.EXPECT().IsExpired(gomock.Any()).Return(false, nil)
-				m.EXPECT().Get(gomock.Any()).Return("token", nil)
+				m.EXPECT().Incr("otp_attempts:"+phone, otpConfig.Lockout).Return(int64(1), nil)
+				m.EXPECT().Get(phone).Return("1234", nil)
 				return m
 			},
-			sms: func(ctrl *gomock.Controller) SMSClient {
-				m := NewMockSMSClient(ctrl)
+			otp:     "4321",
+			wantErr: custom_errors.ErrInvalidOTP,
+		},
+		{
+			name: "code expired or already used",
+			redis: func(ctrl *gomock.Controller) redisClient {
+				m := NewMockredisClient(ctrl)
+				m.EXPECT().Incr("otp_attempts:"+phone, otpConfig.Lockout).Return(int64(1), nil)
+				m.EXPECT().Get(phone).Return("", nil)
 				return m
 			},
-			jwt: func(ctrl *gomock.Controller) jwtUsecase {
-				m := NewMockjwtUsecase(ctrl)
+			otp:     "",
+			wantErr: custom_errors.ErrInvalidOTP,
+		},
+		{
+			name: "locked after max attempts even with valid code",
+			redis: func(ctrl *gomock.Controller) redisClient {
+				m := NewMockredisClient(ctrl)
+				m.EXPECT().Incr("otp_attempts:"+phone, otpConfig.Lockout).Return(int64(otpConfig.MaxAttempts+1), nil)
 				return m
 			},
-			want: "token",
+			otp:     "1234",
+			wantErr: custom_errors.ErrOTPLocked,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl))
-			if out, err := u.AuthViaTgStatus(context.Background(), tt.in); (err != nil) != tt.wantErr {
-				t.Errorf("Auth() error = %v, wantErr %v", err, tt.wantErr)
-			} else {
-				require.Equal(t, tt.want, out)
+			u := New(NewMockusersRepo(ctrl), tt.redis(ctrl), NewMockjwtUsecase(ctrl), NewMockSMSClient(ctrl), NewMockEmailClient(ctrl), NewMockrefreshTokensRepo(ctrl), NewMocksessionsRepo(ctrl), passthroughTransactor(ctrl), refreshTTL, otpConfig)
+
+			err := u.validateOTP(phone, tt.otp)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
 			}
+			require.NoError(t, err)
 		})
 	}
 }
 
-func TestUseCase_Verify(t *testing.T) {
+func TestUseCase_register_testPhone(t *testing.T) {
+	const phone = "79990000000"
+	ctrl := gomock.NewController(t)
+
+	users := NewMockusersRepo(ctrl)
+	users.EXPECT().CreateWithPhone(gomock.Any(), phone).Return(&userentity.User{ID: 1, NumberPhone: pointers.To(phone)}, nil)
+	users.EXPECT().Update(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	redis := NewMockredisClient(ctrl)
+	redis.EXPECT().Set(phone, "0000", gomock.Any()).Return(nil)
+	// SMS на тестовый номер не отправляется
+	sms := NewMockSMSClient(ctrl)
+
+	cfg := otpConfig
+	cfg.TestPhones = []string{phone}
+	cfg.TestCode = "0000"
+	u := New(users, redis, NewMockjwtUsecase(ctrl), sms, NewMockEmailClient(ctrl), NewMockrefreshTokensRepo(ctrl), NewMocksessionsRepo(ctrl), passthroughTransactor(ctrl), refreshTTL, cfg)
+	require.NoError(t, u.register(context.Background(), phone))
+}
+
+func TestUseCase_Auth_email(t *testing.T) {
+	const email = "cook@example.com"
+	linkConfig := otpConfig
+	linkConfig.MagicLinkURL = "https://domashka.app/auth/magic"
+	linkConfig.MagicLinkSecret = "secret"
+	linkConfig.MagicLinkTTL = 15 * time.Minute
+
+	tests := []struct {
+		name    string
+		otp     OTPConfig
+		req     auth.Request
+		mocks   func(ctrl *gomock.Controller) (usersRepo, redisClient, EmailClient)
+		wantErr error
+	}{
+		{
+			name: "new user gets a code",
+			otp:  otpConfig,
+			req:  auth.Request{Email: " Cook@Example.com "},
+			mocks: func(ctrl *gomock.Controller) (usersRepo, redisClient, EmailClient) {
+				users := NewMockusersRepo(ctrl)
+				users.EXPECT().GetByEmail(gomock.Any(), email).Return(nil, custom_errors.ErrUserNotFound)
+				users.EXPECT().CreateWithEmail(gomock.Any(), email).Return(&userentity.User{ID: 3, Email: pointers.To(email)}, nil)
+				users.EXPECT().Update(gomock.Any(), int64(3), gomock.Any()).Return(nil)
+				var code string
+				redis := NewMockredisClient(ctrl)
+				redis.EXPECT().Set(email, gomock.Any(), 5*time.Minute).DoAndReturn(func(_ string, value string, _ time.Duration) error {
+					code = value
+					return nil
+				})
+				mail := NewMockEmailClient(ctrl)
+				mail.EXPECT().SendEmail(gomock.Any(), int64(3), email, notifentity.EmailTemplateOTP, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, _ string, _ notifentity.EmailTemplate, data notifentity.EmailTemplateData) error {
+						require.Len(t, data.Code, 4)
+						require.Equal(t, code, data.Code)
+						return nil
+					})
+				return users, redis, mail
+			},
+		},
+		{
+			name: "existing user gets a signed link",
+			otp:  linkConfig,
+			req:  auth.Request{Email: email, Method: auth.LoginMethodLink},
+			mocks: func(ctrl *gomock.Controller) (usersRepo, redisClient, EmailClient) {
+				users := NewMockusersRepo(ctrl)
+				users.EXPECT().GetByEmail(gomock.Any(), email).Return(&userentity.User{ID: 3, Email: pointers.To(email)}, nil)
+				users.EXPECT().Update(gomock.Any(), int64(3), gomock.Any()).Return(nil)
+				var token string
+				redis := NewMockredisClient(ctrl)
+				redis.EXPECT().Set(email, gomock.Any(), linkConfig.MagicLinkTTL).DoAndReturn(func(_ string, value string, _ time.Duration) error {
+					token = value
+					return nil
+				})
+				mail := NewMockEmailClient(ctrl)
+				mail.EXPECT().SendEmail(gomock.Any(), int64(3), email, notifentity.EmailTemplateMagicLink, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, _ string, _ notifentity.EmailTemplate, data notifentity.EmailTemplateData) error {
+						link, err := url.Parse(data.Link)
+						require.NoError(t, err)
+						require.Equal(t, "domashka.app", link.Host)
+						require.Equal(t, email, link.Query().Get("email"))
+						require.Equal(t, token, link.Query().Get("token"))
+						return nil
+					})
+				return users, redis, mail
+			},
+		},
+		{
+			name:    "link is rejected when not configured",
+			otp:     otpConfig,
+			req:     auth.Request{Email: email, Method: auth.LoginMethodLink},
+			wantErr: custom_errors.ErrMagicLinkDisabled,
+			mocks: func(ctrl *gomock.Controller) (usersRepo, redisClient, EmailClient) {
+				return NewMockusersRepo(ctrl), NewMockredisClient(ctrl), NewMockEmailClient(ctrl)
+			},
+		},
+		{
+			name:    "spam user gets nothing",
+			otp:     otpConfig,
+			req:     auth.Request{Email: email},
+			wantErr: custom_errors.ErrUserIsSpam,
+			mocks: func(ctrl *gomock.Controller) (usersRepo, redisClient, EmailClient) {
+				users := NewMockusersRepo(ctrl)
+				users.EXPECT().GetByEmail(gomock.Any(), email).Return(&userentity.User{ID: 3, IsSpam: 1}, nil)
+				redis := NewMockredisClient(ctrl)
+				redis.EXPECT().Set(email, gomock.Any(), gomock.Any()).Return(nil)
+				return users, redis, NewMockEmailClient(ctrl)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			users, redis, mail := tt.mocks(ctrl)
+			u := New(users, redis, NewMockjwtUsecase(ctrl), NewMockSMSClient(ctrl), mail, NewMockrefreshTokensRepo(ctrl), NewMocksessionsRepo(ctrl), passthroughTransactor(ctrl), refreshTTL, tt.otp)
+
+			err := u.Auth(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUseCase_VerifyEmail(t *testing.T) {
+	const email = "cook@example.com"
+	cfg := otpConfig
+	cfg.MagicLinkSecret = "secret"
+	accessToken := &auth.AccessToken{Token: "token", UUID: "access-uuid", ExpiresAt: time.Now().Add(time.Minute)}
+
+	t.Run("magic link token opens a session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		users := NewMockusersRepo(ctrl)
+		redis := NewMockredisClient(ctrl)
+		jwt := NewMockjwtUsecase(ctrl)
+		tokens := NewMockrefreshTokensRepo(ctrl)
+		sessions := NewMocksessionsRepo(ctrl)
+		u := New(users, redis, jwt, NewMockSMSClient(ctrl), NewMockEmailClient(ctrl), tokens, sessions, passthroughTransactor(ctrl), refreshTTL, cfg)
+		token := u.newMagicToken(email)
+
+		users.EXPECT().GetByEmail(gomock.Any(), email).Return(&userentity.User{ID: 3, Role: "client"}, nil)
+		users.EXPECT().CheckIfUserIsChef(gomock.Any(), int64(3)).Return(nil, false, nil)
+		redis.EXPECT().Incr("otp_attempts:"+email, cfg.Lockout).Return(int64(1), nil)
+		redis.EXPECT().Get(email).Return(token, nil)
+		redis.EXPECT().Delete(email).Return(nil)
+		redis.EXPECT().Delete("otp_attempts:" + email).Return(nil)
+		jwt.EXPECT().IssueAccessToken(int64(3), nil, "client", gomock.Any()).Return(accessToken, nil)
+		tokens.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+		sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(nil)
+
+		userID, _, got, err := u.VerifyEmail(context.Background(), "Cook@Example.com", token, auth.Device{})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), userID)
+		require.Equal(t, "token", got.AccessToken)
+	})
+
+	t.Run("forged link does not count as an attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		u := New(NewMockusersRepo(ctrl), NewMockredisClient(ctrl), NewMockjwtUsecase(ctrl), NewMockSMSClient(ctrl), NewMockEmailClient(ctrl), NewMockrefreshTokensRepo(ctrl), NewMocksessionsRepo(ctrl), passthroughTransactor(ctrl), refreshTTL, cfg)
+
+		_, _, _, err := u.VerifyEmail(context.Background(), email, "nonce.forged", auth.Device{})
+		require.ErrorIs(t, err, custom_errors.ErrInvalidOTP)
+	})
+}
+
+func TestUseCase_Refresh(t *testing.T) {
+	const refreshToken = "refresh-token"
+	accessToken := &auth.AccessToken{Token: "new-token", UUID: "new-uuid", ExpiresAt: time.Now().Add(time.Minute)}
+	revokedAt := time.Now().Add(-time.Minute)
+	stored := func() *auth.RefreshToken {
+		return &auth.RefreshToken{
+			ID:        10,
+			UserID:    1,
+			FamilyID:  "family",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+
 	tests := []struct {
 		name       string
 		usersRepo  func(ctrl *gomock.Controller) usersRepo
-		redis      func(ctrl *gomock.Controller) redisClient
-		sms        func(ctrl *gomock.Controller) SMSClient
 		jwt        func(ctrl *gomock.Controller) jwtUsecase
-		phone      string
-		otp        string
-		role       string
-		wantUserID int64
-		wantChefID *int64
-		wantToken  string
-		wantErr    bool
+		tokensRepo func(ctrl *gomock.Controller) refreshTokensRepo
+		// sessions — nil, если к сессиям не обращаемся
+		sessions func(ctrl *gomock.Controller) sessionsRepo
+		wantErr  error
 	}{
 		{
-			name: "success",
+			name: "rotates token within family",
 			usersRepo: func(ctrl *gomock.Controller) usersRepo {
 				m := NewMockusersRepo(ctrl)
+				m.EXPECT().GetByID(gomock.Any(), int64(1)).Return(&userentity.User{ID: 1, Role: "client"}, nil)
 				m.EXPECT().CheckIfUserIsChef(gomock.Any(), int64(1)).Return(nil, false, nil)
-				m.EXPECT().GetByPhone(gomock.Any(), gomock.Any()).Return(&userentity.User{
-					ID: int64(1),
-				}, nil)
 				return m
 			},
-			redis: func(ctrl *gomock.Controller) redisClient {
-				m := NewMockredisClient(ctrl)
+			jwt: func(ctrl *gomock.Controller) jwtUsecase {
+				m := NewMockjwtUsecase(ctrl)
+				m.EXPECT().IssueAccessToken(int64(1), nil, "client", "family").Return(accessToken, nil)
 				return m
 			},
-			sms: func(ctrl *gomock.Controller) SMSClient {
-				m := NewMockSMSClient(ctrl)
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				m.EXPECT().GetRefreshTokenByHash(gomock.Any(), hashRefreshToken(refreshToken)).Return(stored(), nil)
+				m.EXPECT().RevokeRefreshToken(gomock.Any(), int64(10)).Return(nil)
+				m.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, rt *auth.RefreshToken) (int64, error) {
+						require.Equal(t, "family", rt.FamilyID)
+						require.Equal(t, "new-uuid", rt.AccessUUID)
+						require.NotEqual(t, hashRefreshToken(refreshToken), rt.TokenHash)
+						return 11, nil
+					})
+				return m
+			},
+			sessions: func(ctrl *gomock.Controller) sessionsRepo {
+				m := NewMocksessionsRepo(ctrl)
+				m.EXPECT().TouchSession(gomock.Any(), "family", "").Return(nil)
+				return m
+			},
+		},
+		{
+			name: "unknown token",
+			usersRepo: func(ctrl *gomock.Controller) usersRepo {
+				return NewMockusersRepo(ctrl)
+			},
+			jwt: func(ctrl *gomock.Controller) jwtUsecase {
+				return NewMockjwtUsecase(ctrl)
+			},
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				m.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(nil, custom_errors.ErrInvalidRefreshToken)
+				return m
+			},
+			wantErr: custom_errors.ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token",
+			usersRepo: func(ctrl *gomock.Controller) usersRepo {
+				return NewMockusersRepo(ctrl)
+			},
+			jwt: func(ctrl *gomock.Controller) jwtUsecase {
+				return NewMockjwtUsecase(ctrl)
+			},
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				expired := stored()
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				m.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(expired, nil)
 				return m
 			},
+			wantErr: custom_errors.ErrInvalidRefreshToken,
+		},
+		{
+			name: "reused token revokes whole family",
+			usersRepo: func(ctrl *gomock.Controller) usersRepo {
+				return NewMockusersRepo(ctrl)
+			},
 			jwt: func(ctrl *gomock.Controller) jwtUsecase {
 				m := NewMockjwtUsecase(ctrl)
-				m.EXPECT().GenerateJWT(int64(1), nil, "admin").Return("token", nil)
+				m.EXPECT().Revoke("active-uuid", gomock.Any()).Return(nil)
 				return m
 			},
-			phone:      "81231234567",
-			otp:        "0123",
-			role:       "admin",
-			wantUserID: 1,
-			wantToken:  "token",
-			wantErr:    false,
-			wantChefID: nil,
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				reused := stored()
+				reused.RevokedAt = &revokedAt
+				m.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(reused, nil)
+				m.EXPECT().RevokeFamily(gomock.Any(), "family").Return([]auth.RefreshToken{
+					{ID: 11, FamilyID: "family", AccessUUID: "active-uuid", AccessExpiresAt: time.Now().Add(time.Minute)},
+				}, nil)
+				return m
+			},
+			sessions: func(ctrl *gomock.Controller) sessionsRepo {
+				m := NewMocksessionsRepo(ctrl)
+				m.EXPECT().RevokeSession(gomock.Any(), int64(1), "family").Return(nil)
+				return m
+			},
+			wantErr: custom_errors.ErrInvalidRefreshToken,
+		},
+		{
+			name: "concurrent refresh with same token",
+			usersRepo: func(ctrl *gomock.Controller) usersRepo {
+				m := NewMockusersRepo(ctrl)
+				m.EXPECT().GetByID(gomock.Any(), int64(1)).Return(&userentity.User{ID: 1, Role: "client"}, nil)
+				return m
+			},
+			jwt: func(ctrl *gomock.Controller) jwtUsecase {
+				return NewMockjwtUsecase(ctrl)
+			},
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				m.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(stored(), nil)
+				m.EXPECT().RevokeRefreshToken(gomock.Any(), int64(10)).Return(custom_errors.ErrInvalidRefreshToken)
+				return m
+			},
+			wantErr: custom_errors.ErrInvalidRefreshToken,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl))
-			gotUserID, gotChefID, gotToken, err := u.Verify(context.Background(), tt.phone, tt.otp, tt.role)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if gotUserID != tt.wantUserID {
-				t.Errorf("Verify() gotUserID = %v, want %v", gotUserID, tt.wantUserID)
-			}
-			if !reflect.DeepEqual(gotChefID, tt.wantChefID) {
-				t.Errorf("Verify() gotChefID = %v, want %v", gotChefID, tt.wantChefID)
+			var sessions sessionsRepo = NewMocksessionsRepo(ctrl)
+			if tt.sessions != nil {
+				sessions = tt.sessions(ctrl)
 			}
-			if gotToken != tt.wantToken {
-				t.Errorf("Verify() gotToken = %v, want %v", gotToken, tt.wantToken)
+			u := New(tt.usersRepo(ctrl), NewMockredisClient(ctrl), tt.jwt(ctrl), NewMockSMSClient(ctrl), NewMockEmailClient(ctrl), tt.tokensRepo(ctrl), sessions, passthroughTransactor(ctrl), refreshTTL, otpConfig)
+
+			tokens, err := u.Refresh(context.Background(), refreshToken)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
 			}
+			require.NoError(t, err)
+			require.Equal(t, "new-token", tokens.AccessToken)
+			require.NotEqual(t, refreshToken, tokens.RefreshToken)
 		})
 	}
 }
 
+func TestUseCase_Logout(t *testing.T) {
+	expiresAt := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name       string
+		jwt        func(ctrl *gomock.Controller) jwtUsecase
+		tokensRepo func(ctrl *gomock.Controller) refreshTokensRepo
+		sessions   func(ctrl *gomock.Controller) sessionsRepo
+		wantErr    bool
+	}{
+		{
+			name: "revokes access token and session",
+			jwt: func(ctrl *gomock.Controller) jwtUsecase {
+				m := NewMockjwtUsecase(ctrl)
+				m.EXPECT().Revoke("access-uuid", expiresAt).Return(nil)
+				return m
+			},
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				m.EXPECT().GetRefreshTokenByAccessUUID(gomock.Any(), "access-uuid").Return(&auth.RefreshToken{UserID: 1, FamilyID: "family"}, nil)
+				m.EXPECT().RevokeFamily(gomock.Any(), "family").Return(nil, nil)
+				return m
+			},
+			sessions: func(ctrl *gomock.Controller) sessionsRepo {
+				m := NewMocksessionsRepo(ctrl)
+				m.EXPECT().RevokeSession(gomock.Any(), int64(1), "family").Return(nil)
+				return m
+			},
+		},
+		{
+			name: "session already revoked",
+			jwt: func(ctrl *gomock.Controller) jwtUsecase {
+				m := NewMockjwtUsecase(ctrl)
+				m.EXPECT().Revoke("access-uuid", expiresAt).Return(nil)
+				return m
+			},
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				m.EXPECT().GetRefreshTokenByAccessUUID(gomock.Any(), "access-uuid").Return(&auth.RefreshToken{UserID: 1, FamilyID: "family"}, nil)
+				m.EXPECT().RevokeFamily(gomock.Any(), "family").Return(nil, nil)
+				return m
+			},
+			sessions: func(ctrl *gomock.Controller) sessionsRepo {
+				m := NewMocksessionsRepo(ctrl)
+				m.EXPECT().RevokeSession(gomock.Any(), int64(1), "family").Return(custom_errors.ErrSessionNotFound)
+				return m
+			},
+		},
+		{
+			name: "token without refresh",
+			jwt: func(ctrl *gomock.Controller) jwtUsecase {
+				m := NewMockjwtUsecase(ctrl)
+				m.EXPECT().Revoke("access-uuid", expiresAt).Return(nil)
+				return m
+			},
+			tokensRepo: func(ctrl *gomock.Controller) refreshTokensRepo {
+				m := NewMockrefreshTokensRepo(ctrl)
+				m.EXPECT().GetRefreshTokenByAccessUUID(gomock.Any(), "access-uuid").Return(nil, custom_errors.ErrInvalidRefreshToken)
+				return m
+			},
+			sessions: func(ctrl *gomock.Controller) sessionsRepo {
+				return NewMocksessionsRepo(ctrl)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			u := New(NewMockusersRepo(ctrl), NewMockredisClient(ctrl), tt.jwt(ctrl), NewMockSMSClient(ctrl), NewMockEmailClient(ctrl), tt.tokensRepo(ctrl), tt.sessions(ctrl), passthroughTransactor(ctrl), refreshTTL, otpConfig)
+
+			err := u.Logout(context.Background(), "access-uuid", expiresAt)
+			require.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestUseCase_RevokeAllSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	accessExpiresAt := time.Now().Add(time.Minute)
+
+	sessions := NewMocksessionsRepo(ctrl)
+	sessions.EXPECT().RevokeUserSessions(gomock.Any(), int64(1)).Return([]string{"phone", "tablet"}, nil)
+	tokens := NewMockrefreshTokensRepo(ctrl)
+	tokens.EXPECT().RevokeFamily(gomock.Any(), "phone").Return([]auth.RefreshToken{{AccessUUID: "a1", AccessExpiresAt: accessExpiresAt}}, nil)
+	tokens.EXPECT().RevokeFamily(gomock.Any(), "tablet").Return([]auth.RefreshToken{{AccessUUID: "a2", AccessExpiresAt: accessExpiresAt}}, nil)
+	jwt := NewMockjwtUsecase(ctrl)
+	jwt.EXPECT().Revoke("a1", accessExpiresAt).Return(nil)
+	jwt.EXPECT().Revoke("a2", accessExpiresAt).Return(nil)
+
+	u := New(NewMockusersRepo(ctrl), NewMockredisClient(ctrl), jwt, NewMockSMSClient(ctrl), NewMockEmailClient(ctrl), tokens, sessions, passthroughTransactor(ctrl), refreshTTL, otpConfig)
+	require.NoError(t, u.RevokeAllSessions(context.Background(), 1))
+}
+
+func TestUseCase_TouchSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    bool
+		sessions func(ctrl *gomock.Controller) sessionsRepo
+	}{
+		{
+			name:  "first request in interval updates last seen",
+			first: true,
+			sessions: func(ctrl *gomock.Controller) sessionsRepo {
+				m := NewMocksessionsRepo(ctrl)
+				m.EXPECT().TouchSession(gomock.Any(), "session", "10.0.0.1").Return(nil)
+				return m
+			},
+		},
+		{
+			name:  "throttled",
+			first: false,
+			sessions: func(ctrl *gomock.Controller) sessionsRepo {
+				return NewMocksessionsRepo(ctrl)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			redis := NewMockredisClient(ctrl)
+			redis.EXPECT().SetNX("session_seen:session", "10.0.0.1", sessionTouchInterval).Return(tt.first, nil)
+
+			u := New(NewMockusersRepo(ctrl), redis, NewMockjwtUsecase(ctrl), NewMockSMSClient(ctrl), NewMockEmailClient(ctrl), NewMockrefreshTokensRepo(ctrl), tt.sessions(ctrl), passthroughTransactor(ctrl), refreshTTL, otpConfig)
+			require.NoError(t, u.TouchSession(context.Background(), "session", "10.0.0.1"))
+		})
+	}
+}
+
+func passthroughTransactor(ctrl *gomock.Controller) transactor {
+	m := NewMocktransactor(ctrl)
+	m.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	return m
+}
+
 func TestUseCase_login(t *testing.T) {
 	type args struct {
 		ctx  context.Context
@@ -256,7 +862,7 @@ func TestUseCase_login(t *testing.T) {
 			},
 			sms: func(ctrl *gomock.Controller) SMSClient {
 				m := NewMockSMSClient(ctrl)
-				m.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().SendSMS(gomock.Any(), gomock.Any(), gomock.Any(), notifentity.SMSTemplateOTP, gomock.Any()).Return(nil)
 				return m
 			},
 			jwt: func(ctrl *gomock.Controller) jwtUsecase {
@@ -274,7 +880,7 @@ func TestUseCase_login(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl))
+			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl), NewMockEmailClient(ctrl), NewMockrefreshTokensRepo(ctrl), NewMocksessionsRepo(ctrl), passthroughTransactor(ctrl), refreshTTL, otpConfig)
 			if err := u.login(tt.args.ctx, tt.args.user); (err != nil) != tt.wantErr {
 				t.Errorf("login() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -309,7 +915,7 @@ func TestUseCase_register(t *testing.T) {
 			},
 			sms: func(ctrl *gomock.Controller) SMSClient {
 				m := NewMockSMSClient(ctrl)
-				m.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().SendSMS(gomock.Any(), gomock.Any(), gomock.Any(), notifentity.SMSTemplateOTP, gomock.Any()).Return(nil)
 				return m
 			},
 			jwt: func(ctrl *gomock.Controller) jwtUsecase {
@@ -351,7 +957,7 @@ func TestUseCase_register(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl))
+			u := New(tt.usersRepo(ctrl), tt.redis(ctrl), tt.jwt(ctrl), tt.sms(ctrl), NewMockEmailClient(ctrl), NewMockrefreshTokensRepo(ctrl), NewMocksessionsRepo(ctrl), passthroughTransactor(ctrl), refreshTTL, otpConfig)
 			if err := u.register(tt.args.ctx, tt.args.phone); (err != nil) != tt.wantErr {
 				t.Errorf("register() error = %v, wantErr %v", err, tt.wantErr)
 			}
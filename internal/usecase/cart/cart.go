@@ -8,13 +8,18 @@ import (
 )
 
 type Usecase struct {
-	cartRepo CartRepository
+	cartRepo   CartRepository
+	transactor Transactor
 }
 
-func New(cartRepo CartRepository) *Usecase {
-	return &Usecase{cartRepo: cartRepo}
+func New(cartRepo CartRepository, transactor Transactor) *Usecase {
+	return &Usecase{cartRepo: cartRepo, transactor: transactor}
 }
 
+// AddItem кладёт блюдо в корзину. В корзине могут быть блюда только одного повара:
+// если там уже лежат блюда другого, возвращается *cartentity.ChefConflictError,
+// а при replaceCart корзина очищается и блюдо добавляется в пустую.
+// Параллельные добавления одного пользователя выполняются по очереди.
 func (u *Usecase) AddItem(
 	ctx context.Context,
 	userID int64,
@@ -23,12 +28,41 @@ func (u *Usecase) AddItem(
 	addedIngredients []int64,
 	removedIngredients []int64,
 	notes string,
-) (int64, error) {
-	return u.cartRepo.AddItem(ctx, userID, dish, sizeID, addedIngredients, removedIngredients, notes)
+	replaceCart bool,
+) (cartItemID int64, err error) {
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.cartRepo.LockCart(ctx, userID); err != nil {
+			return err
+		}
+		cartItems, err := u.cartRepo.GetCartItems(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, chefID := range cartentity.GetChefIDs(cartItems) {
+			if chefID == dish.ChefID {
+				continue
+			}
+			if !replaceCart {
+				return &cartentity.ChefConflictError{CartChefID: chefID, DishChefID: dish.ChefID}
+			}
+			if err := u.cartRepo.Clear(ctx, userID); err != nil {
+				return err
+			}
+			break
+		}
+
+		cartItemID, err = u.cartRepo.AddItem(ctx, userID, dish, sizeID, addedIngredients, removedIngredients, notes)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return cartItemID, nil
 }
 
-func (u *Usecase) RemoveItem(ctx context.Context, cartItemID int64) error {
-	return u.cartRepo.RemoveItem(ctx, cartItemID)
+// RemoveItem, IncrementCartItem и DecrementCartItem меняют только позиции корзины userID
+func (u *Usecase) RemoveItem(ctx context.Context, userID, cartItemID int64) error {
+	return u.cartRepo.RemoveItem(ctx, userID, cartItemID)
 }
 
 func (u *Usecase) GetCartItems(ctx context.Context, userID int64) ([]cartentity.CartItem, error) {
@@ -39,14 +73,10 @@ func (u *Usecase) ClearCart(ctx context.Context, userID int64) error {
 	return u.cartRepo.Clear(ctx, userID)
 }
 
-func (u *Usecase) IncrementCartItem(ctx context.Context, cartItemID int64) (newQuantity int32, err error) {
-	return u.cartRepo.IncrementCartItemQuantity(ctx, cartItemID)
-}
-
-func (u *Usecase) DecrementCartItem(ctx context.Context, cartItemID int64) (newQuantity int32, err error) {
-	return u.cartRepo.DecrementCartItemQuantity(ctx, cartItemID)
+func (u *Usecase) IncrementCartItem(ctx context.Context, userID, cartItemID int64) (newQuantity int32, err error) {
+	return u.cartRepo.IncrementCartItemQuantity(ctx, userID, cartItemID)
 }
 
-func (u *Usecase) GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cartentity.CartItem, error) {
-	return u.cartRepo.GetCartItemsByOrderID(ctx, orderID)
+func (u *Usecase) DecrementCartItem(ctx context.Context, userID, cartItemID int64) (newQuantity int32, err error) {
+	return u.cartRepo.DecrementCartItemQuantity(ctx, userID, cartItemID)
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	cartentity "domashka-backend/internal/entity/cart"
 	"domashka-backend/internal/entity/dishes"
+	"domashka-backend/internal/entity/money"
+	"errors"
 	"github.com/golang/mock/gomock"
 	"reflect"
 	"testing"
@@ -18,18 +20,23 @@ func TestUsecase_AddItem(t *testing.T) {
 		addedIngredients   []int64
 		removedIngredients []int64
 		notes              string
+		replaceCart        bool
 	}
+	errLock := errors.New("lock failed")
+	otherChefCart := []cartentity.CartItem{{ID: 5, Dish: dishes.Dish{ID: 2, ChefID: 2}, Quantity: 1}}
 	tests := []struct {
 		name     string
 		cartRepo func(ctrl *gomock.Controller) CartRepository
 		args     args
 		want     int64
-		wantErr  bool
+		wantErr  error
 	}{
 		{
 			name: "success",
 			cartRepo: func(ctrl *gomock.Controller) CartRepository {
 				m := NewMockCartRepository(ctrl)
+				m.EXPECT().LockCart(gomock.Any(), int64(1)).Return(nil)
+				m.EXPECT().GetCartItems(gomock.Any(), int64(1)).Return(nil, nil)
 				m.EXPECT().AddItem(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil)
 				return m
 			},
@@ -50,14 +57,79 @@ func TestUsecase_AddItem(t *testing.T) {
 			},
 			want: int64(1),
 		},
+		{
+			name: "same chef",
+			cartRepo: func(ctrl *gomock.Controller) CartRepository {
+				m := NewMockCartRepository(ctrl)
+				m.EXPECT().LockCart(gomock.Any(), int64(1)).Return(nil)
+				m.EXPECT().GetCartItems(gomock.Any(), int64(1)).Return(otherChefCart, nil)
+				m.EXPECT().AddItem(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(6), nil)
+				return m
+			},
+			args: args{
+				ctx:    context.Background(),
+				userID: 1,
+				dish:   dishes.Dish{ID: 3, ChefID: 2},
+			},
+			want: int64(6),
+		},
+		{
+			name: "chef conflict",
+			cartRepo: func(ctrl *gomock.Controller) CartRepository {
+				m := NewMockCartRepository(ctrl)
+				m.EXPECT().LockCart(gomock.Any(), int64(1)).Return(nil)
+				m.EXPECT().GetCartItems(gomock.Any(), int64(1)).Return(otherChefCart, nil)
+				return m
+			},
+			args: args{
+				ctx:    context.Background(),
+				userID: 1,
+				dish:   dishes.Dish{ID: 1, ChefID: 1},
+			},
+			wantErr: cartentity.ErrChefConflict,
+		},
+		{
+			name: "chef conflict, replace cart",
+			cartRepo: func(ctrl *gomock.Controller) CartRepository {
+				m := NewMockCartRepository(ctrl)
+				gomock.InOrder(
+					m.EXPECT().LockCart(gomock.Any(), int64(1)).Return(nil),
+					m.EXPECT().GetCartItems(gomock.Any(), int64(1)).Return(otherChefCart, nil),
+					m.EXPECT().Clear(gomock.Any(), int64(1)).Return(nil),
+					m.EXPECT().AddItem(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(7), nil),
+				)
+				return m
+			},
+			args: args{
+				ctx:         context.Background(),
+				userID:      1,
+				dish:        dishes.Dish{ID: 1, ChefID: 1},
+				replaceCart: true,
+			},
+			want: int64(7),
+		},
+		{
+			name: "lock failed",
+			cartRepo: func(ctrl *gomock.Controller) CartRepository {
+				m := NewMockCartRepository(ctrl)
+				m.EXPECT().LockCart(gomock.Any(), int64(1)).Return(errLock)
+				return m
+			},
+			args: args{
+				ctx:    context.Background(),
+				userID: 1,
+				dish:   dishes.Dish{ID: 1, ChefID: 1},
+			},
+			wantErr: errLock,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.cartRepo(ctrl))
-			got, err := u.AddItem(tt.args.ctx, tt.args.userID, tt.args.dish, tt.args.sizeID, tt.args.addedIngredients, tt.args.removedIngredients, tt.args.notes)
-			if (err != nil) != tt.wantErr {
+			u := New(tt.cartRepo(ctrl), passthroughTransactor(ctrl))
+			got, err := u.AddItem(tt.args.ctx, tt.args.userID, tt.args.dish, tt.args.sizeID, tt.args.addedIngredients, tt.args.removedIngredients, tt.args.notes, tt.args.replaceCart)
+			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("AddItem() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
@@ -96,7 +168,7 @@ func TestUsecase_ClearCart(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.cartRepo(ctrl))
+			u := New(tt.cartRepo(ctrl), passthroughTransactor(ctrl))
 			if err := u.ClearCart(tt.args.ctx, tt.args.userID); (err != nil) != tt.wantErr {
 				t.Errorf("ClearCart() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -107,6 +179,7 @@ func TestUsecase_ClearCart(t *testing.T) {
 func TestUsecase_DecrementCartItem(t *testing.T) {
 	type args struct {
 		ctx        context.Context
+		userID     int64
 		cartItemID int64
 	}
 	tests := []struct {
@@ -120,11 +193,12 @@ func TestUsecase_DecrementCartItem(t *testing.T) {
 			name: "success",
 			cartRepo: func(ctrl *gomock.Controller) CartRepository {
 				m := NewMockCartRepository(ctrl)
-				m.EXPECT().DecrementCartItemQuantity(gomock.Any(), int64(1)).Return(int32(2), nil)
+				m.EXPECT().DecrementCartItemQuantity(gomock.Any(), int64(7), int64(1)).Return(int32(2), nil)
 				return m
 			},
 			args: args{
 				ctx:        context.Background(),
+				userID:     7,
 				cartItemID: 1,
 			},
 			wantNewQuantity: 2,
@@ -134,8 +208,8 @@ func TestUsecase_DecrementCartItem(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.cartRepo(ctrl))
-			gotNewQuantity, err := u.DecrementCartItem(tt.args.ctx, tt.args.cartItemID)
+			u := New(tt.cartRepo(ctrl), passthroughTransactor(ctrl))
+			gotNewQuantity, err := u.DecrementCartItem(tt.args.ctx, tt.args.userID, tt.args.cartItemID)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DecrementCartItem() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -185,13 +259,12 @@ func TestUsecase_GetCartItems(t *testing.T) {
 						AddedIngredients:   nil,
 						RemovedIngredients: nil,
 						Size: dishes.Size{
-							ID:            0,
-							DishID:        0,
-							Label:         "",
-							WeightValue:   0,
-							WeightUnit:    "",
-							PriceValue:    0,
-							PriceCurrency: "",
+							ID:          0,
+							DishID:      0,
+							Label:       "",
+							WeightValue: 0,
+							WeightUnit:  "",
+							Price:       money.Money{},
 						},
 						Notes: "",
 					},
@@ -216,13 +289,12 @@ func TestUsecase_GetCartItems(t *testing.T) {
 					AddedIngredients:   nil,
 					RemovedIngredients: nil,
 					Size: dishes.Size{
-						ID:            0,
-						DishID:        0,
-						Label:         "",
-						WeightValue:   0,
-						WeightUnit:    "",
-						PriceValue:    0,
-						PriceCurrency: "",
+						ID:          0,
+						DishID:      0,
+						Label:       "",
+						WeightValue: 0,
+						WeightUnit:  "",
+						Price:       money.Money{},
 					},
 					Notes: "",
 				},
@@ -233,7 +305,7 @@ func TestUsecase_GetCartItems(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.cartRepo(ctrl))
+			u := New(tt.cartRepo(ctrl), passthroughTransactor(ctrl))
 			got, err := u.GetCartItems(tt.args.ctx, tt.args.userID)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetCartItems() error = %v, wantErr %v", err, tt.wantErr)
@@ -246,109 +318,10 @@ func TestUsecase_GetCartItems(t *testing.T) {
 	}
 }
 
-func TestUsecase_GetCartItemsByOrderID(t *testing.T) {
-	type args struct {
-		ctx     context.Context
-		orderID int64
-	}
-	tests := []struct {
-		name     string
-		cartRepo func(ctrl *gomock.Controller) CartRepository
-		args     args
-		want     []cartentity.CartItem
-		wantErr  bool
-	}{
-		{
-			name: "success",
-			cartRepo: func(ctrl *gomock.Controller) CartRepository {
-				m := NewMockCartRepository(ctrl)
-				m.EXPECT().GetCartItemsByOrderID(gomock.Any(), int64(1)).Return([]cartentity.CartItem{
-					{
-						ID: 1,
-						Dish: dishes.Dish{
-							ID:           0,
-							Name:         "",
-							Description:  "",
-							ChefID:       0,
-							ImageURL:     "",
-							Rating:       nil,
-							ReviewsCount: nil,
-							CategoryID:   0,
-							IsDeleted:    false,
-						},
-						Quantity:           0,
-						AddedIngredients:   nil,
-						RemovedIngredients: nil,
-						Size: dishes.Size{
-							ID:            0,
-							DishID:        0,
-							Label:         "",
-							WeightValue:   0,
-							WeightUnit:    "",
-							PriceValue:    0,
-							PriceCurrency: "",
-						},
-						Notes: "",
-					},
-				}, nil)
-				return m
-			},
-			want: []cartentity.CartItem{
-
-				{
-					ID: 1,
-					Dish: dishes.Dish{
-						ID:           0,
-						Name:         "",
-						Description:  "",
-						ChefID:       0,
-						ImageURL:     "",
-						Rating:       nil,
-						ReviewsCount: nil,
-						CategoryID:   0,
-						IsDeleted:    false,
-					},
-					Quantity:           0,
-					AddedIngredients:   nil,
-					RemovedIngredients: nil,
-					Size: dishes.Size{
-						ID:            0,
-						DishID:        0,
-						Label:         "",
-						WeightValue:   0,
-						WeightUnit:    "",
-						PriceValue:    0,
-						PriceCurrency: "",
-					},
-					Notes: "",
-				},
-			},
-			args: args{
-				ctx:     context.Background(),
-				orderID: 1,
-			},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-			u := New(tt.cartRepo(ctrl))
-			got, err := u.GetCartItemsByOrderID(tt.args.ctx, tt.args.orderID)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GetCartItemsByOrderID() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetCartItemsByOrderID() got = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestUsecase_IncrementCartItem(t *testing.T) {
 	type args struct {
 		ctx        context.Context
+		userID     int64
 		cartItemID int64
 	}
 	tests := []struct {
@@ -362,12 +335,13 @@ func TestUsecase_IncrementCartItem(t *testing.T) {
 			name: "success",
 			args: args{
 				ctx:        context.Background(),
+				userID:     7,
 				cartItemID: 1,
 			},
 			wantNewQuantity: 2,
 			cartRepo: func(ctrl *gomock.Controller) CartRepository {
 				m := NewMockCartRepository(ctrl)
-				m.EXPECT().IncrementCartItemQuantity(gomock.Any(), int64(1)).Return(int32(2), nil)
+				m.EXPECT().IncrementCartItemQuantity(gomock.Any(), int64(7), int64(1)).Return(int32(2), nil)
 				return m
 			},
 		},
@@ -376,8 +350,8 @@ func TestUsecase_IncrementCartItem(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.cartRepo(ctrl))
-			gotNewQuantity, err := u.IncrementCartItem(tt.args.ctx, tt.args.cartItemID)
+			u := New(tt.cartRepo(ctrl), passthroughTransactor(ctrl))
+			gotNewQuantity, err := u.IncrementCartItem(tt.args.ctx, tt.args.userID, tt.args.cartItemID)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("IncrementCartItem() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -392,6 +366,7 @@ func TestUsecase_IncrementCartItem(t *testing.T) {
 func TestUsecase_RemoveItem(t *testing.T) {
 	type args struct {
 		ctx        context.Context
+		userID     int64
 		cartItemID int64
 	}
 	tests := []struct {
@@ -404,11 +379,12 @@ func TestUsecase_RemoveItem(t *testing.T) {
 			name: "success",
 			cartRepo: func(ctrl *gomock.Controller) CartRepository {
 				m := NewMockCartRepository(ctrl)
-				m.EXPECT().RemoveItem(gomock.Any(), int64(1)).Return(nil)
+				m.EXPECT().RemoveItem(gomock.Any(), int64(7), int64(1)).Return(nil)
 				return m
 			},
 			args: args{
 				ctx:        context.Background(),
+				userID:     7,
 				cartItemID: 1,
 			},
 		},
@@ -417,10 +393,20 @@ func TestUsecase_RemoveItem(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.cartRepo(ctrl))
-			if err := u.RemoveItem(tt.args.ctx, tt.args.cartItemID); (err != nil) != tt.wantErr {
+			u := New(tt.cartRepo(ctrl), passthroughTransactor(ctrl))
+			if err := u.RemoveItem(tt.args.ctx, tt.args.userID, tt.args.cartItemID); (err != nil) != tt.wantErr {
 				t.Errorf("RemoveItem() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+// passthroughTransactor выполняет fn сразу, без реальной транзакции
+func passthroughTransactor(ctrl *gomock.Controller) Transactor {
+	m := NewMockTransactor(ctrl)
+	m.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	return m
+}
@@ -9,7 +9,13 @@ import (
 
 //go:generate mockgen -source=contract.go -destination contract_mocks_test.go -package $GOPACKAGE
 
+// Transactor выполняет fn в одной транзакции БД
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type CartRepository interface {
+	LockCart(ctx context.Context, userID int64) error
 	AddItem(
 		ctx context.Context,
 		userID int64,
@@ -19,10 +25,9 @@ type CartRepository interface {
 		removedIngredients []int64,
 		notes string,
 	) (cartItemID int64, err error)
-	RemoveItem(ctx context.Context, cartItemID int64) error
+	RemoveItem(ctx context.Context, userID, cartItemID int64) error
 	GetCartItems(ctx context.Context, userID int64) ([]cartentity.CartItem, error)
-	GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cartentity.CartItem, error)
 	Clear(ctx context.Context, userID int64) error
-	IncrementCartItemQuantity(ctx context.Context, cartItemID int64) (int32, error)
-	DecrementCartItemQuantity(ctx context.Context, cartItemID int64) (int32, error)
+	IncrementCartItemQuantity(ctx context.Context, userID, cartItemID int64) (int32, error)
+	DecrementCartItemQuantity(ctx context.Context, userID, cartItemID int64) (int32, error)
 }
@@ -13,6 +13,43 @@ import (
 	gomock "github.com/golang/mock/gomock"
 )
 
+// MockTransactor is a mock of Transactor interface.
+type MockTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactorMockRecorder
+}
+
+// MockTransactorMockRecorder is the mock recorder for MockTransactor.
+type MockTransactorMockRecorder struct {
+	mock *MockTransactor
+}
+
+// NewMockTransactor creates a new mock instance.
+func NewMockTransactor(ctrl *gomock.Controller) *MockTransactor {
+	mock := &MockTransactor{ctrl: ctrl}
+	mock.recorder = &MockTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTransaction mocks base method.
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MockTransactorMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockTransactor)(nil).WithinTransaction), ctx, fn)
+}
+
 // MockCartRepository is a mock of CartRepository interface.
 type MockCartRepository struct {
 	ctrl     *gomock.Controller
@@ -66,18 +103,18 @@ func (mr *MockCartRepositoryMockRecorder) Clear(ctx, userID interface{}) *gomock
 }
 
 // DecrementCartItemQuantity mocks base method.
-func (m *MockCartRepository) DecrementCartItemQuantity(ctx context.Context, cartItemID int64) (int32, error) {
+func (m *MockCartRepository) DecrementCartItemQuantity(ctx context.Context, userID, cartItemID int64) (int32, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DecrementCartItemQuantity", ctx, cartItemID)
+	ret := m.ctrl.Call(m, "DecrementCartItemQuantity", ctx, userID, cartItemID)
 	ret0, _ := ret[0].(int32)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DecrementCartItemQuantity indicates an expected call of DecrementCartItemQuantity.
-func (mr *MockCartRepositoryMockRecorder) DecrementCartItemQuantity(ctx, cartItemID interface{}) *gomock.Call {
+func (mr *MockCartRepositoryMockRecorder) DecrementCartItemQuantity(ctx, userID, cartItemID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementCartItemQuantity", reflect.TypeOf((*MockCartRepository)(nil).DecrementCartItemQuantity), ctx, cartItemID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementCartItemQuantity", reflect.TypeOf((*MockCartRepository)(nil).DecrementCartItemQuantity), ctx, userID, cartItemID)
 }
 
 // GetCartItems mocks base method.
@@ -95,46 +132,45 @@ func (mr *MockCartRepositoryMockRecorder) GetCartItems(ctx, userID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItems", reflect.TypeOf((*MockCartRepository)(nil).GetCartItems), ctx, userID)
 }
 
-// GetCartItemsByOrderID mocks base method.
-func (m *MockCartRepository) GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cart.CartItem, error) {
+// IncrementCartItemQuantity mocks base method.
+func (m *MockCartRepository) IncrementCartItemQuantity(ctx context.Context, userID, cartItemID int64) (int32, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCartItemsByOrderID", ctx, orderID)
-	ret0, _ := ret[0].([]cart.CartItem)
+	ret := m.ctrl.Call(m, "IncrementCartItemQuantity", ctx, userID, cartItemID)
+	ret0, _ := ret[0].(int32)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCartItemsByOrderID indicates an expected call of GetCartItemsByOrderID.
-func (mr *MockCartRepositoryMockRecorder) GetCartItemsByOrderID(ctx, orderID interface{}) *gomock.Call {
+// IncrementCartItemQuantity indicates an expected call of IncrementCartItemQuantity.
+func (mr *MockCartRepositoryMockRecorder) IncrementCartItemQuantity(ctx, userID, cartItemID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItemsByOrderID", reflect.TypeOf((*MockCartRepository)(nil).GetCartItemsByOrderID), ctx, orderID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementCartItemQuantity", reflect.TypeOf((*MockCartRepository)(nil).IncrementCartItemQuantity), ctx, userID, cartItemID)
 }
 
-// IncrementCartItemQuantity mocks base method.
-func (m *MockCartRepository) IncrementCartItemQuantity(ctx context.Context, cartItemID int64) (int32, error) {
+// LockCart mocks base method.
+func (m *MockCartRepository) LockCart(ctx context.Context, userID int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncrementCartItemQuantity", ctx, cartItemID)
-	ret0, _ := ret[0].(int32)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "LockCart", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// IncrementCartItemQuantity indicates an expected call of IncrementCartItemQuantity.
-func (mr *MockCartRepositoryMockRecorder) IncrementCartItemQuantity(ctx, cartItemID interface{}) *gomock.Call {
+// LockCart indicates an expected call of LockCart.
+func (mr *MockCartRepositoryMockRecorder) LockCart(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementCartItemQuantity", reflect.TypeOf((*MockCartRepository)(nil).IncrementCartItemQuantity), ctx, cartItemID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockCart", reflect.TypeOf((*MockCartRepository)(nil).LockCart), ctx, userID)
 }
 
 // RemoveItem mocks base method.
-func (m *MockCartRepository) RemoveItem(ctx context.Context, cartItemID int64) error {
+func (m *MockCartRepository) RemoveItem(ctx context.Context, userID, cartItemID int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveItem", ctx, cartItemID)
+	ret := m.ctrl.Call(m, "RemoveItem", ctx, userID, cartItemID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RemoveItem indicates an expected call of RemoveItem.
-func (mr *MockCartRepositoryMockRecorder) RemoveItem(ctx, cartItemID interface{}) *gomock.Call {
+func (mr *MockCartRepositoryMockRecorder) RemoveItem(ctx, userID, cartItemID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveItem", reflect.TypeOf((*MockCartRepository)(nil).RemoveItem), ctx, cartItemID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveItem", reflect.TypeOf((*MockCartRepository)(nil).RemoveItem), ctx, userID, cartItemID)
 }
@@ -24,6 +24,7 @@ type chefRepo interface {
 	GetNearestChefs(ctx context.Context, lat, long float64, distance, limit int) ([]entity.Chef, error)
 	SetSmallAvatar(ctx context.Context, chefID int64, publicURL string) error
 	GetAll(ctx context.Context) ([]entity.Chef, error)
+	GetUserIDByChefID(ctx context.Context, chefID int64) (int64, error)
 }
 
 type geoRepo interface {
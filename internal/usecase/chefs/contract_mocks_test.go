@@ -202,6 +202,21 @@ func (mr *MockchefRepoMockRecorder) GetTopChefs(ctx, limit interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopChefs", reflect.TypeOf((*MockchefRepo)(nil).GetTopChefs), ctx, limit)
 }
 
+// GetUserIDByChefID mocks base method.
+func (m *MockchefRepo) GetUserIDByChefID(ctx context.Context, chefID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIDByChefID", ctx, chefID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIDByChefID indicates an expected call of GetUserIDByChefID.
+func (mr *MockchefRepoMockRecorder) GetUserIDByChefID(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDByChefID", reflect.TypeOf((*MockchefRepo)(nil).GetUserIDByChefID), ctx, chefID)
+}
+
 // SaveChefAvatar mocks base method.
 func (m *MockchefRepo) SaveChefAvatar(ctx context.Context, chefID int64, publicURL string) error {
 	m.ctrl.T.Helper()
@@ -122,6 +122,11 @@ func (u *Usecase) GetAll(ctx context.Context) ([]entity.Chef, error) {
 	return chefs, nil
 }
 
+// GetUserIDByChefID возвращает ID пользователя повара: chefs.id и users.id — разные идентификаторы
+func (u *Usecase) GetUserIDByChefID(ctx context.Context, chefID int64) (int64, error) {
+	return u.chefRepo.GetUserIDByChefID(ctx, chefID)
+}
+
 func (u *Usecase) GetChefAvatarURLByDishID(ctx context.Context, dishID int64) (string, error) {
 	return u.chefRepo.GetChefAvatarURLByDishID(ctx, dishID)
 }
@@ -0,0 +1,35 @@
+package delivery
+
+import (
+	"context"
+	"time"
+
+	"domashka-backend/internal/entity/delivery"
+)
+
+//go:generate mockgen -source=contract.go -destination contract_mocks_test.go -package $GOPACKAGE
+
+// transactor выполняет fn в одной транзакции БД
+type transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type geoRepo interface {
+	GetDistanceToChef(ctx context.Context, lat, long float64, chefID int64) (float64, error)
+}
+
+type deliveryRepo interface {
+	GetChefTariffOverride(ctx context.Context, chefID int64) (*delivery.TariffOverride, error)
+	GetWorkingHours(ctx context.Context, chefID int64) ([]delivery.WorkingHours, error)
+	GetAllWorkingHours(ctx context.Context) ([]delivery.WorkingHours, error)
+	ReplaceWorkingHours(ctx context.Context, chefID int64, hours []delivery.WorkingHours) error
+	EnsureSlots(ctx context.Context, slots []delivery.Slot) error
+	DeleteFreeSlots(ctx context.Context, chefID int64, from time.Time) error
+	GetSlots(ctx context.Context, chefID int64, from, to time.Time) ([]delivery.Slot, error)
+	ReserveSlot(ctx context.Context, chefID, slotID int64, notBefore time.Time) error
+	ReleaseSlot(ctx context.Context, slotID int64) error
+}
+
+type shiftsRepo interface {
+	GetActiveShiftIDByChefID(ctx context.Context, chefID int64) (int64, error)
+}
@@ -0,0 +1,280 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package delivery is a generated GoMock package.
+package delivery
+
+import (
+	context "context"
+	delivery "domashka-backend/internal/entity/delivery"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mocktransactor is a mock of transactor interface.
+type Mocktransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MocktransactorMockRecorder
+}
+
+// MocktransactorMockRecorder is the mock recorder for Mocktransactor.
+type MocktransactorMockRecorder struct {
+	mock *Mocktransactor
+}
+
+// NewMocktransactor creates a new mock instance.
+func NewMocktransactor(ctrl *gomock.Controller) *Mocktransactor {
+	mock := &Mocktransactor{ctrl: ctrl}
+	mock.recorder = &MocktransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mocktransactor) EXPECT() *MocktransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTransaction mocks base method.
+func (m *Mocktransactor) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MocktransactorMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*Mocktransactor)(nil).WithinTransaction), ctx, fn)
+}
+
+// MockgeoRepo is a mock of geoRepo interface.
+type MockgeoRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockgeoRepoMockRecorder
+}
+
+// MockgeoRepoMockRecorder is the mock recorder for MockgeoRepo.
+type MockgeoRepoMockRecorder struct {
+	mock *MockgeoRepo
+}
+
+// NewMockgeoRepo creates a new mock instance.
+func NewMockgeoRepo(ctrl *gomock.Controller) *MockgeoRepo {
+	mock := &MockgeoRepo{ctrl: ctrl}
+	mock.recorder = &MockgeoRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockgeoRepo) EXPECT() *MockgeoRepoMockRecorder {
+	return m.recorder
+}
+
+// GetDistanceToChef mocks base method.
+func (m *MockgeoRepo) GetDistanceToChef(ctx context.Context, lat, long float64, chefID int64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDistanceToChef", ctx, lat, long, chefID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDistanceToChef indicates an expected call of GetDistanceToChef.
+func (mr *MockgeoRepoMockRecorder) GetDistanceToChef(ctx, lat, long, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDistanceToChef", reflect.TypeOf((*MockgeoRepo)(nil).GetDistanceToChef), ctx, lat, long, chefID)
+}
+
+// MockdeliveryRepo is a mock of deliveryRepo interface.
+type MockdeliveryRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockdeliveryRepoMockRecorder
+}
+
+// MockdeliveryRepoMockRecorder is the mock recorder for MockdeliveryRepo.
+type MockdeliveryRepoMockRecorder struct {
+	mock *MockdeliveryRepo
+}
+
+// NewMockdeliveryRepo creates a new mock instance.
+func NewMockdeliveryRepo(ctrl *gomock.Controller) *MockdeliveryRepo {
+	mock := &MockdeliveryRepo{ctrl: ctrl}
+	mock.recorder = &MockdeliveryRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdeliveryRepo) EXPECT() *MockdeliveryRepoMockRecorder {
+	return m.recorder
+}
+
+// DeleteFreeSlots mocks base method.
+func (m *MockdeliveryRepo) DeleteFreeSlots(ctx context.Context, chefID int64, from time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFreeSlots", ctx, chefID, from)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFreeSlots indicates an expected call of DeleteFreeSlots.
+func (mr *MockdeliveryRepoMockRecorder) DeleteFreeSlots(ctx, chefID, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFreeSlots", reflect.TypeOf((*MockdeliveryRepo)(nil).DeleteFreeSlots), ctx, chefID, from)
+}
+
+// EnsureSlots mocks base method.
+func (m *MockdeliveryRepo) EnsureSlots(ctx context.Context, slots []delivery.Slot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureSlots", ctx, slots)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureSlots indicates an expected call of EnsureSlots.
+func (mr *MockdeliveryRepoMockRecorder) EnsureSlots(ctx, slots interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureSlots", reflect.TypeOf((*MockdeliveryRepo)(nil).EnsureSlots), ctx, slots)
+}
+
+// GetAllWorkingHours mocks base method.
+func (m *MockdeliveryRepo) GetAllWorkingHours(ctx context.Context) ([]delivery.WorkingHours, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllWorkingHours", ctx)
+	ret0, _ := ret[0].([]delivery.WorkingHours)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllWorkingHours indicates an expected call of GetAllWorkingHours.
+func (mr *MockdeliveryRepoMockRecorder) GetAllWorkingHours(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllWorkingHours", reflect.TypeOf((*MockdeliveryRepo)(nil).GetAllWorkingHours), ctx)
+}
+
+// GetChefTariffOverride mocks base method.
+func (m *MockdeliveryRepo) GetChefTariffOverride(ctx context.Context, chefID int64) (*delivery.TariffOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChefTariffOverride", ctx, chefID)
+	ret0, _ := ret[0].(*delivery.TariffOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChefTariffOverride indicates an expected call of GetChefTariffOverride.
+func (mr *MockdeliveryRepoMockRecorder) GetChefTariffOverride(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChefTariffOverride", reflect.TypeOf((*MockdeliveryRepo)(nil).GetChefTariffOverride), ctx, chefID)
+}
+
+// GetSlots mocks base method.
+func (m *MockdeliveryRepo) GetSlots(ctx context.Context, chefID int64, from, to time.Time) ([]delivery.Slot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSlots", ctx, chefID, from, to)
+	ret0, _ := ret[0].([]delivery.Slot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSlots indicates an expected call of GetSlots.
+func (mr *MockdeliveryRepoMockRecorder) GetSlots(ctx, chefID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSlots", reflect.TypeOf((*MockdeliveryRepo)(nil).GetSlots), ctx, chefID, from, to)
+}
+
+// GetWorkingHours mocks base method.
+func (m *MockdeliveryRepo) GetWorkingHours(ctx context.Context, chefID int64) ([]delivery.WorkingHours, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkingHours", ctx, chefID)
+	ret0, _ := ret[0].([]delivery.WorkingHours)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkingHours indicates an expected call of GetWorkingHours.
+func (mr *MockdeliveryRepoMockRecorder) GetWorkingHours(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkingHours", reflect.TypeOf((*MockdeliveryRepo)(nil).GetWorkingHours), ctx, chefID)
+}
+
+// ReleaseSlot mocks base method.
+func (m *MockdeliveryRepo) ReleaseSlot(ctx context.Context, slotID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSlot", ctx, slotID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSlot indicates an expected call of ReleaseSlot.
+func (mr *MockdeliveryRepoMockRecorder) ReleaseSlot(ctx, slotID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSlot", reflect.TypeOf((*MockdeliveryRepo)(nil).ReleaseSlot), ctx, slotID)
+}
+
+// ReplaceWorkingHours mocks base method.
+func (m *MockdeliveryRepo) ReplaceWorkingHours(ctx context.Context, chefID int64, hours []delivery.WorkingHours) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceWorkingHours", ctx, chefID, hours)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceWorkingHours indicates an expected call of ReplaceWorkingHours.
+func (mr *MockdeliveryRepoMockRecorder) ReplaceWorkingHours(ctx, chefID, hours interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceWorkingHours", reflect.TypeOf((*MockdeliveryRepo)(nil).ReplaceWorkingHours), ctx, chefID, hours)
+}
+
+// ReserveSlot mocks base method.
+func (m *MockdeliveryRepo) ReserveSlot(ctx context.Context, chefID, slotID int64, notBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveSlot", ctx, chefID, slotID, notBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveSlot indicates an expected call of ReserveSlot.
+func (mr *MockdeliveryRepoMockRecorder) ReserveSlot(ctx, chefID, slotID, notBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveSlot", reflect.TypeOf((*MockdeliveryRepo)(nil).ReserveSlot), ctx, chefID, slotID, notBefore)
+}
+
+// MockshiftsRepo is a mock of shiftsRepo interface.
+type MockshiftsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockshiftsRepoMockRecorder
+}
+
+// MockshiftsRepoMockRecorder is the mock recorder for MockshiftsRepo.
+type MockshiftsRepoMockRecorder struct {
+	mock *MockshiftsRepo
+}
+
+// NewMockshiftsRepo creates a new mock instance.
+func NewMockshiftsRepo(ctrl *gomock.Controller) *MockshiftsRepo {
+	mock := &MockshiftsRepo{ctrl: ctrl}
+	mock.recorder = &MockshiftsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockshiftsRepo) EXPECT() *MockshiftsRepoMockRecorder {
+	return m.recorder
+}
+
+// GetActiveShiftIDByChefID mocks base method.
+func (m *MockshiftsRepo) GetActiveShiftIDByChefID(ctx context.Context, chefID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveShiftIDByChefID", ctx, chefID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveShiftIDByChefID indicates an expected call of GetActiveShiftIDByChefID.
+func (mr *MockshiftsRepoMockRecorder) GetActiveShiftIDByChefID(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveShiftIDByChefID", reflect.TypeOf((*MockshiftsRepo)(nil).GetActiveShiftIDByChefID), ctx, chefID)
+}
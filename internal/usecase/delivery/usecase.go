@@ -0,0 +1,196 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"domashka-backend/internal/entity/delivery"
+	addressentity "domashka-backend/internal/entity/geo"
+	"domashka-backend/internal/entity/money"
+)
+
+const (
+	// slotLeadTime — за сколько до начала слота его ещё можно выбрать
+	slotLeadTime = 30 * time.Minute
+	// slotDays — на сколько дней вперёд показываются слоты, включая сегодня
+	slotDays = 2
+)
+
+type Usecase struct {
+	tariff       delivery.Tariff
+	location     *time.Location
+	geoRepo      geoRepo
+	deliveryRepo deliveryRepo
+	shiftsRepo   shiftsRepo
+	transactor   transactor
+	now          func() time.Time
+}
+
+// New создаёт usecase доставки. location — часовой пояс, в котором заданы рабочие часы поваров
+func New(tariff delivery.Tariff, location *time.Location, geoRepo geoRepo, deliveryRepo deliveryRepo, shiftsRepo shiftsRepo, transactor transactor) *Usecase {
+	return &Usecase{
+		tariff:       tariff,
+		location:     location,
+		geoRepo:      geoRepo,
+		deliveryRepo: deliveryRepo,
+		shiftsRepo:   shiftsRepo,
+		transactor:   transactor,
+		now:          time.Now,
+	}
+}
+
+// CalculateFee считает стоимость доставки от повара до адреса клиента
+// по общему тарифу с учётом настроек повара
+func (u *Usecase) CalculateFee(ctx context.Context, chefID int64, address *addressentity.Address, cartTotal money.Money) (*delivery.Quote, error) {
+	if address == nil {
+		return nil, fmt.Errorf("address is nil")
+	}
+	override, err := u.deliveryRepo.GetChefTariffOverride(ctx, chefID)
+	if err != nil {
+		return nil, fmt.Errorf("get chef tariff: %w", err)
+	}
+	distance, err := u.geoRepo.GetDistanceToChef(ctx, address.Latitude, address.Longitude, chefID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := u.tariff.WithOverride(override).Quote(distance, cartTotal)
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// GetAvailableSlots возвращает свободные слоты доставки повара на сегодня и завтра.
+// Сегодняшние слоты доступны, только пока открыта смена. Сами слоты создаются заранее:
+// при сохранении рабочих часов и в GenerateUpcomingSlots.
+func (u *Usecase) GetAvailableSlots(ctx context.Context, chefID int64) ([]delivery.Slot, error) {
+	shiftID, err := u.shiftsRepo.GetActiveShiftIDByChefID(ctx, chefID)
+	if err != nil {
+		return nil, fmt.Errorf("get active shift: %w", err)
+	}
+
+	now := u.now().In(u.location)
+	today, to := u.slotWindow()
+	from := now.Add(slotLeadTime)
+	if shiftID == 0 {
+		from = today.AddDate(0, 0, 1)
+	}
+
+	slots, err := u.deliveryRepo.GetSlots(ctx, chefID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("get slots: %w", err)
+	}
+	available := make([]delivery.Slot, 0, len(slots))
+	for _, s := range slots {
+		if !s.IsAvailable() {
+			continue
+		}
+		s.StartsAt = s.StartsAt.In(u.location)
+		s.EndsAt = s.EndsAt.In(u.location)
+		available = append(available, s)
+	}
+	return available, nil
+}
+
+func (u *Usecase) GetWorkingHours(ctx context.Context, chefID int64) ([]delivery.WorkingHours, error) {
+	return u.deliveryRepo.GetWorkingHours(ctx, chefID)
+}
+
+// SetWorkingHours заменяет рабочие часы повара и сразу пересоздаёт его свободные слоты
+// на ближайшие дни. Слоты, на которые уже есть заказы, не трогаются.
+func (u *Usecase) SetWorkingHours(ctx context.Context, chefID int64, hours []delivery.WorkingHours) error {
+	for i := range hours {
+		hours[i].ChefID = chefID
+	}
+	if err := delivery.ValidateWorkingHours(hours); err != nil {
+		return err
+	}
+	today, _ := u.slotWindow()
+	return u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.deliveryRepo.ReplaceWorkingHours(ctx, chefID, hours); err != nil {
+			return fmt.Errorf("replace working hours: %w", err)
+		}
+		if err := u.deliveryRepo.DeleteFreeSlots(ctx, chefID, today); err != nil {
+			return fmt.Errorf("delete free slots: %w", err)
+		}
+		if err := u.deliveryRepo.EnsureSlots(ctx, u.generateSlots(hours)); err != nil {
+			return fmt.Errorf("ensure slots: %w", err)
+		}
+		return nil
+	})
+}
+
+// GenerateUpcomingSlots создаёт недостающие слоты всех поваров на ближайшие дни.
+// Уже созданные слоты и их брони не меняются, поэтому вызывать можно сколько угодно раз.
+func (u *Usecase) GenerateUpcomingSlots(ctx context.Context) error {
+	hours, err := u.deliveryRepo.GetAllWorkingHours(ctx)
+	if err != nil {
+		return fmt.Errorf("get working hours: %w", err)
+	}
+	if err := u.deliveryRepo.EnsureSlots(ctx, u.generateSlots(hours)); err != nil {
+		return fmt.Errorf("ensure slots: %w", err)
+	}
+	return nil
+}
+
+// RunSlotGenerator вызывает GenerateUpcomingSlots раз в interval, пока не отменён ctx
+func (u *Usecase) RunSlotGenerator(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := u.GenerateUpcomingSlots(ctx); err != nil {
+			log.Printf("Ошибка создания слотов доставки: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// slotWindow — дни, на которые показываются слоты: [today, to)
+func (u *Usecase) slotWindow() (today, to time.Time) {
+	now := u.now().In(u.location)
+	today = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, u.location)
+	return today, today.AddDate(0, 0, slotDays)
+}
+
+func (u *Usecase) generateSlots(hours []delivery.WorkingHours) []delivery.Slot {
+	today, to := u.slotWindow()
+	slots := make([]delivery.Slot, 0)
+	for day := today; day.Before(to); day = day.AddDate(0, 0, 1) {
+		slots = append(slots, delivery.GenerateSlots(hours, day)...)
+	}
+	return slots
+}
+
+// ReserveSlot занимает место в слоте под заказ. Вызывается в транзакции создания заказа,
+// чтобы при откате заказа место вернулось. Пока у повара нет рабочих часов, слотов у него
+// тоже нет, и заказ оформляется без слота (slotID = 0).
+func (u *Usecase) ReserveSlot(ctx context.Context, chefID, slotID int64) error {
+	if slotID == 0 {
+		hours, err := u.deliveryRepo.GetWorkingHours(ctx, chefID)
+		if err != nil {
+			return fmt.Errorf("get working hours: %w", err)
+		}
+		if len(hours) > 0 {
+			return delivery.ErrSlotRequired
+		}
+		return nil
+	}
+	return u.deliveryRepo.ReserveSlot(ctx, chefID, slotID, u.now().Add(slotLeadTime))
+}
+
+// ReleaseSlot возвращает место в слоте отклонённого или отменённого заказа.
+// Вызывается в той же транзакции, что и смена статуса
+func (u *Usecase) ReleaseSlot(ctx context.Context, slotID *int64) error {
+	if slotID == nil {
+		return nil
+	}
+	return u.deliveryRepo.ReleaseSlot(ctx, *slotID)
+}
@@ -0,0 +1,389 @@
+package delivery
+
+import (
+	"context"
+	"domashka-backend/internal/entity/delivery"
+	geoEntity "domashka-backend/internal/entity/geo"
+	"domashka-backend/internal/entity/money"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUsecase_CalculateFee(t *testing.T) {
+	tariff := delivery.Tariff{
+		BaseFee:           money.New(100_00, money.RUB),
+		PerKmFee:          money.New(20_00, money.RUB),
+		FreeThreshold:     money.New(3000_00, money.RUB),
+		MaxDistanceMeters: 10_000,
+	}
+	chefBaseFee := money.New(0, money.RUB)
+	type args struct {
+		ctx       context.Context
+		chefID    int64
+		address   *geoEntity.Address
+		cartTotal money.Money
+	}
+	tests := []struct {
+		name         string
+		geoRepo      func(ctrl *gomock.Controller) geoRepo
+		deliveryRepo func(ctrl *gomock.Controller) deliveryRepo
+		args         args
+		want         *delivery.Quote
+		wantErr      error
+	}{
+		{
+			name: "base fee and started kilometers",
+			geoRepo: func(ctrl *gomock.Controller) geoRepo {
+				m := NewMockgeoRepo(ctrl)
+				m.EXPECT().GetDistanceToChef(gomock.Any(), 55.75, 37.61, int64(1)).Return(2500.0, nil)
+				return m
+			},
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().GetChefTariffOverride(gomock.Any(), int64(1)).Return(nil, nil)
+				return m
+			},
+			args: args{
+				ctx:       context.Background(),
+				chefID:    1,
+				address:   &geoEntity.Address{Latitude: 55.75, Longitude: 37.61},
+				cartTotal: money.New(1000_00, money.RUB),
+			},
+			want: &delivery.Quote{Fee: money.New(160_00, money.RUB), DistanceMeters: 2500},
+		},
+		{
+			name: "free from threshold",
+			geoRepo: func(ctrl *gomock.Controller) geoRepo {
+				m := NewMockgeoRepo(ctrl)
+				m.EXPECT().GetDistanceToChef(gomock.Any(), gomock.Any(), gomock.Any(), int64(1)).Return(2500.0, nil)
+				return m
+			},
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().GetChefTariffOverride(gomock.Any(), int64(1)).Return(nil, nil)
+				return m
+			},
+			args: args{
+				ctx:       context.Background(),
+				chefID:    1,
+				address:   &geoEntity.Address{},
+				cartTotal: money.New(3000_00, money.RUB),
+			},
+			want: &delivery.Quote{Fee: money.New(0, money.RUB), DistanceMeters: 2500, IsFree: true},
+		},
+		{
+			name: "chef override",
+			geoRepo: func(ctrl *gomock.Controller) geoRepo {
+				m := NewMockgeoRepo(ctrl)
+				m.EXPECT().GetDistanceToChef(gomock.Any(), gomock.Any(), gomock.Any(), int64(2)).Return(1000.0, nil)
+				return m
+			},
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().GetChefTariffOverride(gomock.Any(), int64(2)).Return(&delivery.TariffOverride{
+					ChefID:  2,
+					BaseFee: &chefBaseFee,
+				}, nil)
+				return m
+			},
+			args: args{
+				ctx:       context.Background(),
+				chefID:    2,
+				address:   &geoEntity.Address{},
+				cartTotal: money.New(500_00, money.RUB),
+			},
+			want: &delivery.Quote{Fee: money.New(20_00, money.RUB), DistanceMeters: 1000},
+		},
+		{
+			name: "too far",
+			geoRepo: func(ctrl *gomock.Controller) geoRepo {
+				m := NewMockgeoRepo(ctrl)
+				m.EXPECT().GetDistanceToChef(gomock.Any(), gomock.Any(), gomock.Any(), int64(1)).Return(10_001.0, nil)
+				return m
+			},
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().GetChefTariffOverride(gomock.Any(), int64(1)).Return(nil, nil)
+				return m
+			},
+			args: args{
+				ctx:       context.Background(),
+				chefID:    1,
+				address:   &geoEntity.Address{},
+				cartTotal: money.New(500_00, money.RUB),
+			},
+			wantErr: delivery.ErrTooFar,
+		},
+		{
+			name: "no address",
+			geoRepo: func(ctrl *gomock.Controller) geoRepo {
+				return NewMockgeoRepo(ctrl)
+			},
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				return NewMockdeliveryRepo(ctrl)
+			},
+			args: args{
+				ctx:    context.Background(),
+				chefID: 1,
+			},
+			wantErr: errors.New("address is nil"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(tariff, time.UTC, tt.geoRepo(ctrl), tt.deliveryRepo(ctrl), NewMockshiftsRepo(ctrl), NewMocktransactor(ctrl))
+			got, err := u.CalculateFee(tt.args.ctx, tt.args.chefID, tt.args.address, tt.args.cartTotal)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("CalculateFee() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+				t.Errorf("CalculateFee() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CalculateFee() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsecase_GetAvailableSlots(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// Понедельник, 10:00 по Москве
+	now := time.Date(2024, time.March, 4, 10, 0, 0, 0, moscow)
+	slot := func(id int64, day, hour int, reserved int32) delivery.Slot {
+		start := time.Date(2024, time.March, day, hour, 0, 0, 0, moscow)
+		return delivery.Slot{ID: id, ChefID: 1, StartsAt: start, EndsAt: start.Add(time.Hour), Capacity: 2, Reserved: reserved}
+	}
+	tomorrow := time.Date(2024, time.March, 5, 0, 0, 0, 0, moscow)
+	dayAfterTomorrow := time.Date(2024, time.March, 6, 0, 0, 0, 0, moscow)
+	tests := []struct {
+		name         string
+		deliveryRepo func(ctrl *gomock.Controller) deliveryRepo
+		shiftsRepo   func(ctrl *gomock.Controller) shiftsRepo
+		want         []delivery.Slot
+		wantErr      bool
+	}{
+		{
+			name: "shift is open, full slots are hidden",
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().GetSlots(gomock.Any(), int64(1), now.Add(slotLeadTime), dayAfterTomorrow).Return([]delivery.Slot{
+					slot(2, 4, 11, 2),
+					slot(3, 4, 12, 1),
+					slot(4, 5, 12, 0),
+				}, nil)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				m.EXPECT().GetActiveShiftIDByChefID(gomock.Any(), int64(1)).Return(int64(7), nil)
+				return m
+			},
+			want: []delivery.Slot{slot(3, 4, 12, 1), slot(4, 5, 12, 0)},
+		},
+		{
+			name: "shift is closed, only tomorrow",
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().GetSlots(gomock.Any(), int64(1), tomorrow, dayAfterTomorrow).Return([]delivery.Slot{
+					slot(4, 5, 12, 0),
+				}, nil)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				m.EXPECT().GetActiveShiftIDByChefID(gomock.Any(), int64(1)).Return(int64(0), nil)
+				return m
+			},
+			want: []delivery.Slot{slot(4, 5, 12, 0)},
+		},
+		{
+			name: "repo error",
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().GetSlots(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(nil, errors.New("db is down"))
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				m.EXPECT().GetActiveShiftIDByChefID(gomock.Any(), int64(1)).Return(int64(7), nil)
+				return m
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(delivery.Tariff{}, moscow, NewMockgeoRepo(ctrl), tt.deliveryRepo(ctrl), tt.shiftsRepo(ctrl), NewMocktransactor(ctrl))
+			u.now = func() time.Time { return now }
+			got, err := u.GetAvailableSlots(context.Background(), 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAvailableSlots() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAvailableSlots() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsecase_SetWorkingHours(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// Понедельник, 10:00 по Москве
+	now := time.Date(2024, time.March, 4, 10, 0, 0, 0, moscow)
+	today := time.Date(2024, time.March, 4, 0, 0, 0, 0, moscow)
+	hours := []delivery.WorkingHours{
+		{Weekday: time.Monday, Start: 10 * time.Hour, End: 13 * time.Hour, SlotDuration: time.Hour, SlotCapacity: 2},
+		{Weekday: time.Tuesday, Start: 12 * time.Hour, End: 13 * time.Hour, SlotDuration: time.Hour, SlotCapacity: 2},
+	}
+	tests := []struct {
+		name         string
+		hours        []delivery.WorkingHours
+		deliveryRepo func(ctrl *gomock.Controller) deliveryRepo
+		wantErr      error
+	}{
+		{
+			name:  "slots are created right away",
+			hours: hours,
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				gomock.InOrder(
+					m.EXPECT().ReplaceWorkingHours(gomock.Any(), int64(1), gomock.Len(2)).Return(nil),
+					m.EXPECT().DeleteFreeSlots(gomock.Any(), int64(1), today).Return(nil),
+					m.EXPECT().EnsureSlots(gomock.Any(), gomock.Len(4)).Return(nil),
+				)
+				return m
+			},
+		},
+		{
+			name:  "clearing hours removes free slots",
+			hours: nil,
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().ReplaceWorkingHours(gomock.Any(), int64(1), gomock.Len(0)).Return(nil)
+				m.EXPECT().DeleteFreeSlots(gomock.Any(), int64(1), today).Return(nil)
+				m.EXPECT().EnsureSlots(gomock.Any(), gomock.Len(0)).Return(nil)
+				return m
+			},
+		},
+		{
+			name: "overlapping intervals",
+			hours: []delivery.WorkingHours{
+				{Weekday: time.Monday, Start: 10 * time.Hour, End: 13 * time.Hour, SlotDuration: time.Hour, SlotCapacity: 2},
+				{Weekday: time.Monday, Start: 12 * time.Hour, End: 14 * time.Hour, SlotDuration: time.Hour, SlotCapacity: 2},
+			},
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				return NewMockdeliveryRepo(ctrl)
+			},
+			wantErr: delivery.ErrInvalidWorkingHours,
+		},
+		{
+			name: "interval shorter than a slot",
+			hours: []delivery.WorkingHours{
+				{Weekday: time.Monday, Start: 10 * time.Hour, End: 10*time.Hour + 30*time.Minute, SlotDuration: time.Hour, SlotCapacity: 2},
+			},
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				return NewMockdeliveryRepo(ctrl)
+			},
+			wantErr: delivery.ErrInvalidWorkingHours,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(delivery.Tariff{}, moscow, NewMockgeoRepo(ctrl), tt.deliveryRepo(ctrl), NewMockshiftsRepo(ctrl), passthroughTransactor(ctrl))
+			u.now = func() time.Time { return now }
+			if err := u.SetWorkingHours(context.Background(), 1, tt.hours); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetWorkingHours() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUsecase_ReserveSlot(t *testing.T) {
+	hours := []delivery.WorkingHours{
+		{ChefID: 1, Weekday: time.Monday, Start: 10 * time.Hour, End: 13 * time.Hour, SlotDuration: time.Hour, SlotCapacity: 2},
+	}
+	tests := []struct {
+		name         string
+		slotID       int64
+		deliveryRepo func(ctrl *gomock.Controller) deliveryRepo
+		wantErr      error
+	}{
+		{
+			name:   "slot",
+			slotID: 10,
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().ReserveSlot(gomock.Any(), int64(1), int64(10), gomock.Any()).Return(nil)
+				return m
+			},
+		},
+		{
+			name: "no slot, chef has no working hours",
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().GetWorkingHours(gomock.Any(), int64(1)).Return(nil, nil)
+				return m
+			},
+		},
+		{
+			name: "no slot, chef has working hours",
+			deliveryRepo: func(ctrl *gomock.Controller) deliveryRepo {
+				m := NewMockdeliveryRepo(ctrl)
+				m.EXPECT().GetWorkingHours(gomock.Any(), int64(1)).Return(hours, nil)
+				return m
+			},
+			wantErr: delivery.ErrSlotRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(delivery.Tariff{}, time.UTC, NewMockgeoRepo(ctrl), tt.deliveryRepo(ctrl), NewMockshiftsRepo(ctrl), NewMocktransactor(ctrl))
+			if err := u.ReserveSlot(context.Background(), 1, tt.slotID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReserveSlot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUsecase_GenerateUpcomingSlots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+	repo := NewMockdeliveryRepo(ctrl)
+	repo.EXPECT().GetAllWorkingHours(gomock.Any()).Return([]delivery.WorkingHours{
+		{ChefID: 1, Weekday: time.Monday, Start: 10 * time.Hour, End: 13 * time.Hour, SlotDuration: time.Hour, SlotCapacity: 2},
+		{ChefID: 2, Weekday: time.Tuesday, Start: 12 * time.Hour, End: 13 * time.Hour, SlotDuration: time.Hour, SlotCapacity: 2},
+	}, nil)
+	repo.EXPECT().EnsureSlots(gomock.Any(), gomock.Len(4)).Return(nil)
+
+	u := New(delivery.Tariff{}, moscow, NewMockgeoRepo(ctrl), repo, NewMockshiftsRepo(ctrl), NewMocktransactor(ctrl))
+	u.now = func() time.Time { return time.Date(2024, time.March, 4, 10, 0, 0, 0, moscow) }
+	if err := u.GenerateUpcomingSlots(context.Background()); err != nil {
+		t.Errorf("GenerateUpcomingSlots() error = %v", err)
+	}
+}
+
+func passthroughTransactor(ctrl *gomock.Controller) transactor {
+	m := NewMocktransactor(ctrl)
+	m.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	return m
+}
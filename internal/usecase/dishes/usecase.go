@@ -6,6 +6,7 @@ import (
 	"mime/multipart"
 
 	entity "domashka-backend/internal/entity/dishes"
+	"domashka-backend/internal/entity/money"
 )
 
 const (
@@ -104,7 +105,7 @@ func (u *Usecase) GetIngredientsByDishID(ctx context.Context, dishID int64) ([]e
 	return u.dishRepo.GetIngredientsByDishID(ctx, dishID)
 }
 
-func (u *Usecase) GetMinimalPriceByDishID(ctx context.Context, dishID int64) (*entity.Price, error) {
+func (u *Usecase) GetMinimalPriceByDishID(ctx context.Context, dishID int64) (*money.Money, error) {
 	sizes, err := u.GetDishSizesByDishID(ctx, dishID)
 	if err != nil {
 		return nil, err
@@ -113,18 +114,18 @@ func (u *Usecase) GetMinimalPriceByDishID(ctx context.Context, dishID int64) (*e
 		return nil, fmt.Errorf("no sizes attached to a dish")
 	}
 	// берем первое значение как минимальное
-	minPrice := &entity.Price{
-		Value:    sizes[0].PriceValue,
-		Currency: sizes[0].PriceCurrency,
-	}
+	minPrice := sizes[0].Price
 
 	for _, size := range sizes {
-		if size.PriceValue < minPrice.Value {
-			minPrice.Value = size.PriceValue
-			minPrice.Currency = size.PriceCurrency
+		less, err := size.Price.Less(minPrice)
+		if err != nil {
+			return nil, err
+		}
+		if less {
+			minPrice = size.Price
 		}
 	}
-	return minPrice, nil
+	return &minPrice, nil
 }
 
 func (u *Usecase) GetTopDishes(ctx context.Context, limit int) ([]entity.Dish, error) {
@@ -3,6 +3,7 @@ package dishes
 import (
 	"context"
 	entity "domashka-backend/internal/entity/dishes"
+	"domashka-backend/internal/entity/money"
 	"github.com/golang/mock/gomock"
 	"mime/multipart"
 	"reflect"
@@ -530,20 +531,40 @@ func TestUsecase_GetMinimalPriceByDishID(t *testing.T) {
 		dishRepo func(ctrl *gomock.Controller) dishRepo
 		s3Client func(ctrl *gomock.Controller) s3Client
 		args     args
-		want     *entity.Price
+		want     *money.Money
 		wantErr  bool
 	}{
 		{
 			name: "success",
-			args: args{},
+			args: args{ctx: context.Background(), dishID: 1},
+			dishRepo: func(ctrl *gomock.Controller) dishRepo {
+				m := NewMockdishRepo(ctrl)
+				m.EXPECT().GetDishSizesByDishID(gomock.Any(), int64(1)).Return([]entity.Size{
+					{ID: 1, Price: money.New(350_50, money.RUB)},
+					{ID: 2, Price: money.New(299_00, money.RUB)},
+					{ID: 3, Price: money.New(420_00, money.RUB)},
+				}, nil)
+				return m
+			},
+			s3Client: func(ctrl *gomock.Controller) s3Client {
+				m := NewMocks3Client(ctrl)
+				return m
+			},
+			want: &money.Money{Amount: 299_00, Currency: money.RUB},
+		},
+		{
+			name: "no sizes",
+			args: args{ctx: context.Background(), dishID: 1},
 			dishRepo: func(ctrl *gomock.Controller) dishRepo {
 				m := NewMockdishRepo(ctrl)
+				m.EXPECT().GetDishSizesByDishID(gomock.Any(), int64(1)).Return(nil, nil)
 				return m
 			},
 			s3Client: func(ctrl *gomock.Controller) s3Client {
 				m := NewMocks3Client(ctrl)
 				return m
 			},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,11 @@
+package jwt
+
+import "time"
+
+//go:generate mockgen -source=contract.go -destination contract_mocks_test.go -package $GOPACKAGE
+
+// revocationStore — список отозванных токенов по claim uuid
+type revocationStore interface {
+	Set(key string, value string, ttl time.Duration) error
+	Get(key string) (string, error)
+}
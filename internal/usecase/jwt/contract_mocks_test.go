@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package jwt is a generated GoMock package.
+package jwt
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockrevocationStore is a mock of revocationStore interface.
+type MockrevocationStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockrevocationStoreMockRecorder
+}
+
+// MockrevocationStoreMockRecorder is the mock recorder for MockrevocationStore.
+type MockrevocationStoreMockRecorder struct {
+	mock *MockrevocationStore
+}
+
+// NewMockrevocationStore creates a new mock instance.
+func NewMockrevocationStore(ctrl *gomock.Controller) *MockrevocationStore {
+	mock := &MockrevocationStore{ctrl: ctrl}
+	mock.recorder = &MockrevocationStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrevocationStore) EXPECT() *MockrevocationStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockrevocationStore) Get(key string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockrevocationStoreMockRecorder) Get(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockrevocationStore)(nil).Get), key)
+}
+
+// Set mocks base method.
+func (m *MockrevocationStore) Set(key, value string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", key, value, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockrevocationStoreMockRecorder) Set(key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockrevocationStore)(nil).Set), key, value, ttl)
+}
@@ -0,0 +1,87 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt"
+
+	"domashka-backend/config"
+	"domashka-backend/internal/entity/auth"
+)
+
+// signingKey — ключ из конфига с алгоритмом, определённым по типу ключа
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	// private — nil, если ключ оставлен только для проверки подписи
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+func parseKey(k config.JWTKey) (*signingKey, error) {
+	block, _ := pem.Decode(k.PEM)
+	if block == nil {
+		return nil, fmt.Errorf("jwt key %s: no PEM block", k.ID)
+	}
+
+	var (
+		parsed interface{}
+		err    error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("jwt key %s: unsupported PEM block %q", k.ID, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt key %s: %w", k.ID, err)
+	}
+
+	key := &signingKey{id: k.ID}
+	switch p := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, p, &p.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, p
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, p, p.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, p
+	default:
+		return nil, fmt.Errorf("jwt key %s: unsupported key type %T", k.ID, parsed)
+	}
+	return key, nil
+}
+
+func (k *signingKey) jwk() auth.JWK {
+	jwk := auth.JWK{
+		KeyID:     k.id,
+		Use:       "sig",
+		Algorithm: k.method.Alg(),
+	}
+	switch p := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(p.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(p)
+	}
+	return jwk
+}
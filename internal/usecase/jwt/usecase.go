@@ -2,69 +2,173 @@ package jwt
 
 import (
 	"domashka-backend/config"
+	"domashka-backend/internal/custom_errors"
+	"domashka-backend/internal/entity/auth"
+	"fmt"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
+	"sort"
 	"strconv"
 	"time"
 )
 
+const revokedKeyPrefix = "jwt:revoked:"
+
 type UseCase struct {
-	cfg *config.JWTConfig
+	cfg     *config.JWTConfig
+	revoked revocationStore
+	// keys — все ключи из конфига по kid; signing — ключ для новых токенов,
+	// nil — подписываем HS256 общим секретом
+	keys    map[string]*signingKey
+	signing *signingKey
 }
 
-func New(cfg *config.JWTConfig) *UseCase {
-	return &UseCase{
-		cfg: cfg,
+func New(cfg *config.JWTConfig, revoked revocationStore) (*UseCase, error) {
+	u := &UseCase{
+		cfg:     cfg,
+		revoked: revoked,
+		keys:    make(map[string]*signingKey, len(cfg.Keys)),
+	}
+
+	for _, k := range cfg.Keys {
+		if _, ok := u.keys[k.ID]; ok {
+			return nil, fmt.Errorf("jwt key %s: duplicate kid", k.ID)
+		}
+		key, err := parseKey(k)
+		if err != nil {
+			return nil, err
+		}
+		u.keys[k.ID] = key
+	}
+
+	if len(u.keys) > 0 || cfg.SigningKeyID != "" {
+		signing, ok := u.keys[cfg.SigningKeyID]
+		if !ok {
+			return nil, fmt.Errorf("jwt signing key %q not found", cfg.SigningKeyID)
+		}
+		if signing.private == nil {
+			return nil, fmt.Errorf("jwt signing key %q has no private part", cfg.SigningKeyID)
+		}
+		u.signing = signing
 	}
+
+	return u, nil
 }
 
 func (u *UseCase) ValidateJWT(tokenString string) (map[string]interface{}, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return u.cfg.Secret, nil
-	})
+	token, err := jwt.Parse(tokenString, u.verificationKey)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
 	}
 
-	return nil, jwt.ErrSignatureInvalid
+	// Токен без uuid нельзя отозвать, такие не принимаем
+	tokenUUID, _ := claims["uuid"].(string)
+	if tokenUUID == "" {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	revoked, err := u.revoked.Get(revokedKeyPrefix + tokenUUID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked != "" {
+		return nil, custom_errors.ErrTokenRevoked
+	}
+
+	return claims, nil
 }
 
-func (u *UseCase) GenerateJWT(userID int64, chefID *int64, role string) (string, error) {
-	tokenUUID := uuid.New()
-	var claims jwt.MapClaims
-	if chefID == nil {
-		claims = jwt.MapClaims{
-			"uuid":    tokenUUID.String(),
-			"exp":     time.Now().Add(u.cfg.Exp).Unix(),
-			"iat":     time.Now().Unix(),
-			"role":    role,
-			"user_id": strconv.FormatInt(userID, 10),
-		}
-	} else {
-		claims = jwt.MapClaims{
-			"uuid":    tokenUUID.String(),
-			"exp":     time.Now().Add(u.cfg.Exp).Unix(),
-			"iat":     time.Now().Unix(),
-			"role":    role,
-			"user_id": strconv.FormatInt(userID, 10),
-			"chef_id": *chefID,
+// verificationKey выбирает ключ проверки по kid из заголовка. Алгоритм токена должен
+// совпадать с алгоритмом ключа, иначе открытый ключ можно подсунуть как HMAC-секрет.
+func (u *UseCase) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		// Без ключей HS256 — единственная схема. С ключами принимаем его только до HS256Until:
+		// так доживают токены, выданные до перехода на ключи
+		if len(u.cfg.Secret) == 0 || (u.signing != nil && !time.Now().Before(u.cfg.HS256Until)) {
+			return nil, jwt.ErrSignatureInvalid
 		}
+		return u.cfg.Secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := u.keys[kid]
+	if !ok || key.method.Alg() != token.Method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return key.public, nil
+}
+
+// JWKS возвращает открытые части всех ключей, включая выводимые из ротации:
+// ими ещё подписаны действующие токены
+func (u *UseCase) JWKS() auth.JWKS {
+	jwks := auth.JWKS{Keys: make([]auth.JWK, 0, len(u.keys))}
+	for _, key := range u.keys {
+		jwks.Keys = append(jwks.Keys, key.jwk())
 	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+	return jwks
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+// Revoke отзывает access-токен до истечения его срока: после этого ValidateJWT его не примет
+func (u *UseCase) Revoke(tokenUUID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return u.revoked.Set(revokedKeyPrefix+tokenUUID, "1", ttl)
+}
 
-	signedToken, err := token.SignedString(u.cfg.Secret)
+func (u *UseCase) GenerateJWT(userID int64, chefID *int64, role string) (string, error) {
+	token, err := u.IssueAccessToken(userID, chefID, role, "")
 	if err != nil {
 		return "", err
 	}
+	return token.Token, nil
+}
+
+// IssueAccessToken выпускает access-токен и возвращает его uuid и срок для последующего отзыва.
+// sessionID попадает в claim sid; пустой — токен выдан вне сессии.
+func (u *UseCase) IssueAccessToken(userID int64, chefID *int64, role string, sessionID string) (*auth.AccessToken, error) {
+	tokenUUID := uuid.New()
+	now := time.Now()
+	expiresAt := now.Add(u.cfg.Exp)
+	claims := jwt.MapClaims{
+		"uuid":    tokenUUID.String(),
+		"exp":     expiresAt.Unix(),
+		"iat":     now.Unix(),
+		"role":    role,
+		"user_id": strconv.FormatInt(userID, 10),
+	}
+	if chefID != nil {
+		claims["chef_id"] = *chefID
+	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
+
+	var (
+		signedToken string
+		err         error
+	)
+	if u.signing != nil {
+		token := jwt.NewWithClaims(u.signing.method, claims)
+		token.Header["kid"] = u.signing.id
+		signedToken, err = token.SignedString(u.signing.private)
+	} else {
+		signedToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(u.cfg.Secret)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	return signedToken, nil
+	return &auth.AccessToken{
+		Token:     signedToken,
+		UUID:      tokenUUID.String(),
+		ExpiresAt: time.Unix(expiresAt.Unix(), 0),
+	}, nil
 }
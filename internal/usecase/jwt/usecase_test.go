@@ -0,0 +1,251 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"domashka-backend/config"
+	"domashka-backend/internal/custom_errors"
+)
+
+func TestUseCase_ValidateJWT(t *testing.T) {
+	cfg := &config.JWTConfig{Secret: []byte("secret"), Exp: time.Minute}
+	issuer, err := New(cfg, nil)
+	require.NoError(t, err)
+	token, err := issuer.IssueAccessToken(1, nil, "client", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		revoked func(ctrl *gomock.Controller) revocationStore
+		wantErr error
+	}{
+		{
+			name: "valid",
+			revoked: func(ctrl *gomock.Controller) revocationStore {
+				m := NewMockrevocationStore(ctrl)
+				m.EXPECT().Get(revokedKeyPrefix+token.UUID).Return("", nil)
+				return m
+			},
+		},
+		{
+			name: "revoked",
+			revoked: func(ctrl *gomock.Controller) revocationStore {
+				m := NewMockrevocationStore(ctrl)
+				m.EXPECT().Get(revokedKeyPrefix+token.UUID).Return("1", nil)
+				return m
+			},
+			wantErr: custom_errors.ErrTokenRevoked,
+		},
+		{
+			name: "revocation list unavailable",
+			revoked: func(ctrl *gomock.Controller) revocationStore {
+				m := NewMockrevocationStore(ctrl)
+				m.EXPECT().Get(gomock.Any()).Return("", errors.New("redis down"))
+				return m
+			},
+			wantErr: errors.New("redis down"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			u, err := New(cfg, tt.revoked(ctrl))
+			require.NoError(t, err)
+
+			claims, err := u.ValidateJWT(token.Token)
+			if tt.wantErr != nil {
+				require.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "1", claims["user_id"])
+			require.Equal(t, token.UUID, claims["uuid"])
+		})
+	}
+}
+
+func TestUseCase_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockrevocationStore(ctrl)
+	m.EXPECT().Set(revokedKeyPrefix+"abc", "1", gomock.Any()).Return(nil)
+	u, err := New(&config.JWTConfig{Secret: []byte("secret"), Exp: time.Minute}, m)
+	require.NoError(t, err)
+
+	require.NoError(t, u.Revoke("abc", time.Now().Add(time.Minute)))
+	// Истёкший токен отзывать незачем
+	require.NoError(t, u.Revoke("old", time.Now().Add(-time.Minute)))
+}
+
+func TestUseCase_KeyRotation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	oldKey := config.JWTKey{ID: "2026-01", PEM: privatePEM(t, rsaKey)}
+	oldPublic := config.JWTKey{ID: "2026-01", PEM: publicPEM(t, &rsaKey.PublicKey)}
+	newKey := config.JWTKey{ID: "2026-02", PEM: privatePEM(t, edKey)}
+
+	notRevoked := func(ctrl *gomock.Controller) revocationStore {
+		m := NewMockrevocationStore(ctrl)
+		m.EXPECT().Get(gomock.Any()).Return("", nil).AnyTimes()
+		return m
+	}
+
+	ctrl := gomock.NewController(t)
+	// До ротации подписываем RS256 старым ключом
+	before, err := New(&config.JWTConfig{Exp: time.Minute, Keys: []config.JWTKey{oldKey}, SigningKeyID: "2026-01"}, notRevoked(ctrl))
+	require.NoError(t, err)
+	oldToken, err := before.IssueAccessToken(1, nil, "client", "")
+	require.NoError(t, err)
+
+	// После ротации новые токены подписаны EdDSA, старый ключ остался только открытым
+	after, err := New(&config.JWTConfig{Exp: time.Minute, Keys: []config.JWTKey{oldPublic, newKey}, SigningKeyID: "2026-02"}, notRevoked(ctrl))
+	require.NoError(t, err)
+	newToken, err := after.IssueAccessToken(2, nil, "chef", "session")
+	require.NoError(t, err)
+
+	parsed, _, err := new(jwtlib.Parser).ParseUnverified(newToken.Token, jwtlib.MapClaims{})
+	require.NoError(t, err)
+	require.Equal(t, "EdDSA", parsed.Method.Alg())
+	require.Equal(t, "2026-02", parsed.Header["kid"])
+
+	claims, err := after.ValidateJWT(oldToken.Token)
+	require.NoError(t, err)
+	require.Equal(t, "1", claims["user_id"])
+	claims, err = after.ValidateJWT(newToken.Token)
+	require.NoError(t, err)
+	require.Equal(t, "2", claims["user_id"])
+	require.Equal(t, "session", claims["sid"])
+
+	// Без ключа 2026-02 новый токен не проверить
+	_, err = before.ValidateJWT(newToken.Token)
+	require.Error(t, err)
+
+	// HS256 без секрета не принимается
+	hsToken, err := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, jwtlib.MapClaims{"uuid": "x"}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = after.ValidateJWT(hsToken)
+	require.Error(t, err)
+
+	jwks := after.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, "2026-01", jwks.Keys[0].KeyID)
+	require.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	require.Equal(t, "RS256", jwks.Keys[0].Algorithm)
+	require.Equal(t, "AQAB", jwks.Keys[0].E)
+	require.Equal(t, "2026-02", jwks.Keys[1].KeyID)
+	require.Equal(t, "OKP", jwks.Keys[1].KeyType)
+	require.Equal(t, "Ed25519", jwks.Keys[1].Curve)
+}
+
+func TestUseCase_HS256Cutoff(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys := []config.JWTKey{{ID: "2026-02", PEM: privatePEM(t, edKey)}}
+
+	legacy, err := New(&config.JWTConfig{Secret: []byte("secret"), Exp: time.Minute}, nil)
+	require.NoError(t, err)
+	hsToken, err := legacy.IssueAccessToken(1, nil, "client", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		cfg     *config.JWTConfig
+		wantErr bool
+	}{
+		{
+			name: "no keys, secret only",
+			cfg:  &config.JWTConfig{Secret: []byte("secret"), Exp: time.Minute},
+		},
+		{
+			name: "keys, before deadline",
+			cfg:  &config.JWTConfig{Secret: []byte("secret"), Exp: time.Minute, Keys: keys, SigningKeyID: "2026-02", HS256Until: time.Now().Add(time.Hour)},
+		},
+		{
+			name:    "keys, deadline passed",
+			cfg:     &config.JWTConfig{Secret: []byte("secret"), Exp: time.Minute, Keys: keys, SigningKeyID: "2026-02", HS256Until: time.Now().Add(-time.Hour)},
+			wantErr: true,
+		},
+		{
+			name:    "keys, no deadline",
+			cfg:     &config.JWTConfig{Secret: []byte("secret"), Exp: time.Minute, Keys: keys, SigningKeyID: "2026-02"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			m := NewMockrevocationStore(ctrl)
+			m.EXPECT().Get(gomock.Any()).Return("", nil).AnyTimes()
+			u, err := New(tt.cfg, m)
+			require.NoError(t, err)
+
+			_, err = u.ValidateJWT(hsToken.Token)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNew_InvalidKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		cfg  *config.JWTConfig
+	}{
+		{
+			name: "signing key not configured",
+			cfg:  &config.JWTConfig{Keys: []config.JWTKey{{ID: "a", PEM: privatePEM(t, rsaKey)}}},
+		},
+		{
+			name: "signing key is public only",
+			cfg:  &config.JWTConfig{Keys: []config.JWTKey{{ID: "a", PEM: publicPEM(t, &rsaKey.PublicKey)}}, SigningKeyID: "a"},
+		},
+		{
+			name: "duplicate kid",
+			cfg: &config.JWTConfig{Keys: []config.JWTKey{
+				{ID: "a", PEM: privatePEM(t, rsaKey)},
+				{ID: "a", PEM: privatePEM(t, rsaKey)},
+			}, SigningKeyID: "a"},
+		},
+		{
+			name: "not a PEM",
+			cfg:  &config.JWTConfig{Keys: []config.JWTKey{{ID: "a", PEM: []byte("secret")}}, SigningKeyID: "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg, nil)
+			require.Error(t, err)
+		})
+	}
+}
+
+func privatePEM(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicPEM(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
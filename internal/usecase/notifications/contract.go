@@ -2,6 +2,7 @@ package notifications
 
 import (
 	"context"
+	"database/sql"
 	"domashka-backend/internal/entity/notifications"
 )
 
@@ -12,8 +13,14 @@ type NotificationRepository interface {
 	GetNotifications(ctx context.Context, filters map[string]string, page, limit int) ([]notifications.Notification, int, error)
 	GetNotificationByID(ctx context.Context, id int) (*notifications.Notification, error)
 	UpdateNotification(ctx context.Context, id int, n notifications.Notification) error
+	UpdateDeliveryStatus(ctx context.Context, id int, status string, attempts int, errorMessage, metadata sql.NullString) error
 }
 
 type SMTPClient interface {
 	SendEmail(ctx context.Context, to, subject string, data notifications.EmailData) (int, error)
 }
+
+// SMSClient отправляет SMS с повторами; возвращает id сообщения у шлюза и число попыток
+type SMSClient interface {
+	Send(ctx context.Context, phone, text string) (string, int, error)
+}
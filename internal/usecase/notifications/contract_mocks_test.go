@@ -6,6 +6,7 @@ package notifications
 
 import (
 	context "context"
+	sql "database/sql"
 	notifications "domashka-backend/internal/entity/notifications"
 	reflect "reflect"
 
@@ -81,6 +82,20 @@ func (mr *MockNotificationRepositoryMockRecorder) GetNotifications(ctx, filters,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).GetNotifications), ctx, filters, page, limit)
 }
 
+// UpdateDeliveryStatus mocks base method.
+func (m *MockNotificationRepository) UpdateDeliveryStatus(ctx context.Context, id int, status string, attempts int, errorMessage, metadata sql.NullString) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeliveryStatus", ctx, id, status, attempts, errorMessage, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDeliveryStatus indicates an expected call of UpdateDeliveryStatus.
+func (mr *MockNotificationRepositoryMockRecorder) UpdateDeliveryStatus(ctx, id, status, attempts, errorMessage, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeliveryStatus", reflect.TypeOf((*MockNotificationRepository)(nil).UpdateDeliveryStatus), ctx, id, status, attempts, errorMessage, metadata)
+}
+
 // UpdateNotification mocks base method.
 func (m *MockNotificationRepository) UpdateNotification(ctx context.Context, id int, n notifications.Notification) error {
 	m.ctrl.T.Helper()
@@ -132,3 +147,42 @@ func (mr *MockSMTPClientMockRecorder) SendEmail(ctx, to, subject, data interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmail", reflect.TypeOf((*MockSMTPClient)(nil).SendEmail), ctx, to, subject, data)
 }
+
+// MockSMSClient is a mock of SMSClient interface.
+type MockSMSClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockSMSClientMockRecorder
+}
+
+// MockSMSClientMockRecorder is the mock recorder for MockSMSClient.
+type MockSMSClientMockRecorder struct {
+	mock *MockSMSClient
+}
+
+// NewMockSMSClient creates a new mock instance.
+func NewMockSMSClient(ctrl *gomock.Controller) *MockSMSClient {
+	mock := &MockSMSClient{ctrl: ctrl}
+	mock.recorder = &MockSMSClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSMSClient) EXPECT() *MockSMSClientMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockSMSClient) Send(ctx context.Context, phone, text string) (string, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, phone, text)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockSMSClientMockRecorder) Send(ctx, phone, text interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSMSClient)(nil).Send), ctx, phone, text)
+}
@@ -2,19 +2,23 @@ package notifications
 
 import (
 	"context"
+	"database/sql"
 	notifEntity "domashka-backend/internal/entity/notifications"
+	"encoding/json"
 	"log"
 )
 
 type NotificationUsecase struct {
 	notificationRepo NotificationRepository
 	smtpClient       SMTPClient
+	smsClient        SMSClient
 }
 
-func New(notificationRepo NotificationRepository, smtp SMTPClient) *NotificationUsecase {
+func New(notificationRepo NotificationRepository, smtp SMTPClient, sms SMSClient) *NotificationUsecase {
 	return &NotificationUsecase{
 		notificationRepo: notificationRepo,
 		smtpClient:       smtp,
+		smsClient:        sms,
 	}
 }
 
@@ -63,6 +67,111 @@ func (u *NotificationUsecase) SendEmailNotification(ctx context.Context, n notif
 	return nil
 }
 
+// SendEmail отправляет системное письмо по шаблону и сохраняет его в notifications вместе со статусом доставки.
+// Секреты из data (код, ссылка для входа) в сохранённое письмо не попадают.
+func (u *NotificationUsecase) SendEmail(ctx context.Context, userID int64, email string, tpl notifEntity.EmailTemplate, data notifEntity.EmailTemplateData) error {
+	subject, body, err := tpl.Render(data)
+	if err != nil {
+		return err
+	}
+	_, stored, err := tpl.Render(data.Masked())
+	if err != nil {
+		return err
+	}
+
+	metaJSON, err := json.Marshal(notifEntity.EmailMetadata{Template: tpl, Masked: data.Sensitive()})
+	if err != nil {
+		return err
+	}
+	metadata := sql.NullString{String: string(metaJSON), Valid: true}
+	id, err := u.notificationRepo.CreateNotification(ctx, notifEntity.Notification{
+		UserID:    sql.NullInt64{Int64: userID, Valid: userID != 0},
+		Channel:   notifEntity.ChannelEmail,
+		Scenario:  notifEntity.ScenarioSystem,
+		Subject:   sql.NullString{String: subject, Valid: true},
+		Message:   stored.Body,
+		Recipient: email,
+		Metadata:  metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	body.UserName = email
+	attempts, sendErr := u.smtpClient.SendEmail(ctx, email, subject, body)
+
+	status, errorMessage := notifEntity.StatusSent, sql.NullString{}
+	if sendErr != nil {
+		log.Printf("email: notification id=%d not delivered after %d attempts: %v", id, attempts, sendErr)
+		status, errorMessage = notifEntity.StatusError, sql.NullString{String: sendErr.Error(), Valid: true}
+	}
+	if err := u.notificationRepo.UpdateDeliveryStatus(ctx, id, status, attempts, errorMessage, metadata); err != nil {
+		// Письмо уже ушло — не выдаём ошибку записи статуса за ошибку отправки
+		log.Printf("email: update status of notification id=%d: %v", id, err)
+		if sendErr == nil {
+			return nil
+		}
+	}
+	return sendErr
+}
+
+// SendSMS отправляет SMS по шаблону и сохраняет его в notifications вместе со статусом доставки.
+// Секреты из data (код входа) в сохранённый текст не попадают.
+func (u *NotificationUsecase) SendSMS(ctx context.Context, userID int64, phone string, tpl notifEntity.SMSTemplate, data notifEntity.SMSData) error {
+	text, err := tpl.Render(data)
+	if err != nil {
+		return err
+	}
+	stored, err := tpl.Render(data.Masked())
+	if err != nil {
+		return err
+	}
+
+	meta := notifEntity.SMSMetadata{Template: tpl, Masked: data.Sensitive()}
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	id, err := u.notificationRepo.CreateNotification(ctx, notifEntity.Notification{
+		UserID:    sql.NullInt64{Int64: userID, Valid: userID != 0},
+		Channel:   notifEntity.ChannelSMS,
+		Scenario:  notifEntity.ScenarioSystem,
+		Message:   stored,
+		Recipient: phone,
+		Metadata:  sql.NullString{String: string(metaJSON), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.deliverSMS(ctx, id, phone, text, meta)
+}
+
+// deliverSMS отправляет текст и записывает в уведомление статус, число попыток и id у шлюза
+func (u *NotificationUsecase) deliverSMS(ctx context.Context, id int, phone, text string, meta notifEntity.SMSMetadata) error {
+	messageID, attempts, sendErr := u.smsClient.Send(ctx, phone, text)
+
+	status, errorMessage := notifEntity.StatusSent, sql.NullString{}
+	if sendErr != nil {
+		log.Printf("sms: notification id=%d not delivered after %d attempts: %v", id, attempts, sendErr)
+		status, errorMessage = notifEntity.StatusError, sql.NullString{String: sendErr.Error(), Valid: true}
+	}
+	meta.ProviderMessageID = messageID
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	if err := u.notificationRepo.UpdateDeliveryStatus(ctx, id, status, attempts, errorMessage, sql.NullString{String: string(metaJSON), Valid: true}); err != nil {
+		// Сообщение уже ушло — не выдаём ошибку записи статуса за ошибку отправки
+		log.Printf("sms: update status of notification id=%d: %v", id, err)
+		if sendErr == nil {
+			return nil
+		}
+	}
+	return sendErr
+}
+
 func (u *NotificationUsecase) GetNotifications(ctx context.Context, filters map[string]string, page, limit int) ([]notifEntity.Notification, int, error) {
 	notifications, total, err := u.notificationRepo.GetNotifications(ctx, filters, page, limit)
 	if err != nil {
@@ -87,6 +196,25 @@ func (u *NotificationUsecase) ResendNotification(ctx context.Context, id int) er
 		return err
 	}
 
+	if n.Channel == notifEntity.ChannelSMS {
+		var meta notifEntity.SMSMetadata
+		if n.Metadata.Valid {
+			if err := json.Unmarshal([]byte(n.Metadata.String), &meta); err != nil {
+				return err
+			}
+		}
+		if meta.Masked {
+			return notifEntity.ErrNotResendable
+		}
+		return u.deliverSMS(ctx, n.ID, n.Recipient, n.Message, meta)
+	}
+
+	// Письма, созданные вне SendEmail, могут хранить в metadata что угодно — такие просто переотправляем
+	var meta notifEntity.EmailMetadata
+	if n.Metadata.Valid && json.Unmarshal([]byte(n.Metadata.String), &meta) == nil && meta.Masked {
+		return notifEntity.ErrNotResendable
+	}
+
 	err = u.SendEmailNotification(ctx, *n)
 	if err != nil {
 		return err
@@ -2,8 +2,11 @@ package notifications
 
 import (
 	"context"
+	"database/sql"
 	"domashka-backend/internal/entity/notifications"
+	"errors"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
 	"reflect"
 	"testing"
 )
@@ -43,7 +46,7 @@ func TestNotificationUsecase_CreateNotification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl))
+			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl), NewMockSMSClient(ctrl))
 			got, err := u.CreateNotification(tt.args.ctx, tt.args.n)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateNotification() error = %v, wantErr %v", err, tt.wantErr)
@@ -91,7 +94,7 @@ func TestNotificationUsecase_GetNotificationByID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl))
+			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl), NewMockSMSClient(ctrl))
 			got, err := u.GetNotificationByID(tt.args.ctx, tt.args.id)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetNotificationByID() error = %v, wantErr %v", err, tt.wantErr)
@@ -150,7 +153,7 @@ func TestNotificationUsecase_GetNotifications(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl))
+			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl), NewMockSMSClient(ctrl))
 			got, got1, err := u.GetNotifications(tt.args.ctx, tt.args.filters, tt.args.page, tt.args.limit)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetNotifications() error = %v, wantErr %v", err, tt.wantErr)
@@ -205,7 +208,7 @@ func TestNotificationUsecase_ResendNotification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl))
+			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl), NewMockSMSClient(ctrl))
 			if err := u.ResendNotification(tt.args.ctx, tt.args.id); (err != nil) != tt.wantErr {
 				t.Errorf("ResendNotification() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -250,10 +253,169 @@ func TestNotificationUsecase_SendEmailNotification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl))
+			u := New(tt.notificationsRepo(ctrl), tt.smtpClient(ctrl), NewMockSMSClient(ctrl))
 			if err := u.SendEmailNotification(tt.args.ctx, tt.args.n); (err != nil) != tt.wantErr {
 				t.Errorf("SendEmailNotification() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestNotificationUsecase_SendSMS(t *testing.T) {
+	const phone = "79991234567"
+
+	tests := []struct {
+		name              string
+		notificationsRepo func(ctrl *gomock.Controller) NotificationRepository
+		smsClient         func(ctrl *gomock.Controller) SMSClient
+		wantErr           bool
+	}{
+		{
+			name: "sent, code is masked in stored text",
+			notificationsRepo: func(ctrl *gomock.Controller) NotificationRepository {
+				m := NewMockNotificationRepository(ctrl)
+				m.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, n notifications.Notification) (int, error) {
+						require.Equal(t, notifications.ChannelSMS, n.Channel)
+						require.Equal(t, phone, n.Recipient)
+						require.Equal(t, int64(7), n.UserID.Int64)
+						require.NotContains(t, n.Message, "1234")
+						require.Contains(t, n.Message, "****")
+						return 10, nil
+					})
+				m.EXPECT().UpdateDeliveryStatus(gomock.Any(), 10, notifications.StatusSent, 1, sql.NullString{}, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int, _ string, _ int, _ sql.NullString, metadata sql.NullString) error {
+						require.JSONEq(t, `{"template":"otp","masked":true,"provider_message_id":"msg-1"}`, metadata.String)
+						return nil
+					})
+				return m
+			},
+			smsClient: func(ctrl *gomock.Controller) SMSClient {
+				m := NewMockSMSClient(ctrl)
+				m.EXPECT().Send(gomock.Any(), phone, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, text string) (string, int, error) {
+						require.Contains(t, text, "1234")
+						return "msg-1", 1, nil
+					})
+				return m
+			},
+		},
+		{
+			name: "provider failure is recorded",
+			notificationsRepo: func(ctrl *gomock.Controller) NotificationRepository {
+				m := NewMockNotificationRepository(ctrl)
+				m.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(10, nil)
+				m.EXPECT().UpdateDeliveryStatus(gomock.Any(), 10, notifications.StatusError, 3,
+					sql.NullString{String: "gateway down", Valid: true}, gomock.Any()).Return(nil)
+				return m
+			},
+			smsClient: func(ctrl *gomock.Controller) SMSClient {
+				m := NewMockSMSClient(ctrl)
+				m.EXPECT().Send(gomock.Any(), phone, gomock.Any()).Return("", 3, errors.New("gateway down"))
+				return m
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			u := New(tt.notificationsRepo(ctrl), NewMockSMTPClient(ctrl), tt.smsClient(ctrl))
+
+			err := u.SendSMS(context.Background(), 7, phone, notifications.SMSTemplateOTP, notifications.SMSData{Code: "1234"})
+			require.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestNotificationUsecase_ResendNotification_SMS(t *testing.T) {
+	tests := []struct {
+		name      string
+		metadata  string
+		smsClient func(ctrl *gomock.Controller) SMSClient
+		wantErr   error
+	}{
+		{
+			name:     "plain sms is resent",
+			metadata: `{}`,
+			smsClient: func(ctrl *gomock.Controller) SMSClient {
+				m := NewMockSMSClient(ctrl)
+				m.EXPECT().Send(gomock.Any(), "79991234567", "Заказ готов").Return("msg-2", 1, nil)
+				return m
+			},
+		},
+		{
+			name:     "masked code cannot be resent",
+			metadata: `{"template":"otp","masked":true}`,
+			smsClient: func(ctrl *gomock.Controller) SMSClient {
+				return NewMockSMSClient(ctrl)
+			},
+			wantErr: notifications.ErrNotResendable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := NewMockNotificationRepository(ctrl)
+			repo.EXPECT().GetNotificationByID(gomock.Any(), 5).Return(&notifications.Notification{
+				ID:        5,
+				Channel:   notifications.ChannelSMS,
+				Message:   "Заказ готов",
+				Recipient: "79991234567",
+				Metadata:  sql.NullString{String: tt.metadata, Valid: true},
+			}, nil)
+			if tt.wantErr == nil {
+				repo.EXPECT().UpdateDeliveryStatus(gomock.Any(), 5, notifications.StatusSent, 1, sql.NullString{}, gomock.Any()).Return(nil)
+			}
+			u := New(repo, NewMockSMTPClient(ctrl), tt.smsClient(ctrl))
+
+			err := u.ResendNotification(context.Background(), 5)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNotificationUsecase_SendEmail(t *testing.T) {
+	const email = "cook@example.com"
+	link := "https://domashka.app/auth/magic?token=secret-token"
+
+	ctrl := gomock.NewController(t)
+	repo := NewMockNotificationRepository(ctrl)
+	repo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, n notifications.Notification) (int, error) {
+			require.Equal(t, notifications.ChannelEmail, n.Channel)
+			require.Equal(t, email, n.Recipient)
+			require.NotContains(t, n.Message, "secret-token")
+			require.JSONEq(t, `{"template":"magic_link","masked":true}`, n.Metadata.String)
+			return 11, nil
+		})
+	repo.EXPECT().UpdateDeliveryStatus(gomock.Any(), 11, notifications.StatusSent, 1, sql.NullString{}, gomock.Any()).Return(nil)
+	smtp := NewMockSMTPClient(ctrl)
+	smtp.EXPECT().SendEmail(gomock.Any(), email, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _ string, data notifications.EmailData) (int, error) {
+			require.Equal(t, link, data.Link)
+			return 1, nil
+		})
+
+	u := New(repo, smtp, NewMockSMSClient(ctrl))
+	err := u.SendEmail(context.Background(), 7, email, notifications.EmailTemplateMagicLink, notifications.EmailTemplateData{Link: link})
+	require.NoError(t, err)
+}
+
+func TestNotificationUsecase_ResendNotification_maskedEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := NewMockNotificationRepository(ctrl)
+	repo.EXPECT().GetNotificationByID(gomock.Any(), 5).Return(&notifications.Notification{
+		ID:        5,
+		Channel:   notifications.ChannelEmail,
+		Recipient: "cook@example.com",
+		Metadata:  sql.NullString{String: `{"template":"otp","masked":true}`, Valid: true},
+	}, nil)
+
+	u := New(repo, NewMockSMTPClient(ctrl), NewMockSMSClient(ctrl))
+	require.ErrorIs(t, u.ResendNotification(context.Background(), 5), notifications.ErrNotResendable)
+}
@@ -6,24 +6,34 @@ import (
 
 	cartentity "domashka-backend/internal/entity/cart"
 	chefEntity "domashka-backend/internal/entity/chefs"
+	"domashka-backend/internal/entity/delivery"
 	entity "domashka-backend/internal/entity/dishes"
 	addressentity "domashka-backend/internal/entity/geo"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/notifications"
+	"domashka-backend/internal/entity/payments"
 	reviewEntity "domashka-backend/internal/entity/reviews"
 )
 
 //go:generate mockgen -source=contract.go -destination contract_mocks_test.go -package $GOPACKAGE
 
+// transactor выполняет fn в одной транзакции БД (unit of work)
+type transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type geoUsecase interface {
 	GetLastUpdatedClientAddress(ctx context.Context, clientID int64) (*addressentity.Address, error)
 }
 
 type cartUsecase interface {
 	GetCartItems(ctx context.Context, userID int64) ([]cartentity.CartItem, error)
+	ClearCart(ctx context.Context, userID int64) error
 }
 
 type shiftsRepo interface {
 	GetActiveShiftIDByChefID(ctx context.Context, chefID int64) (int64, error)
-	AddToTotalProfit(ctx context.Context, shiftID int64, profit float64) error
+	AddToTotalProfit(ctx context.Context, shiftID int64, profit money.Money) error
 }
 
 type ordersRepo interface {
@@ -33,18 +43,22 @@ type ordersRepo interface {
 		shiftID int64,
 		userID int64,
 		clientAddressID int64,
-		totalCost float32,
+		totalCost money.Money,
+		deliveryFee money.Money,
+		deliverySlotID int64,
 		leaveByTheDoor bool,
 		callBeforehand bool,
-
 	) (int64, error)
 	AddCartItemToOrder(ctx context.Context, cartItem *cartentity.CartItem, orderID, userID int64) error
 	GetOrdersByShiftID(ctx context.Context, shiftID int64) ([]orders.Order, error)
 	GetCartItems(ctx context.Context, userID int64) ([]cartentity.CartItem, error)
 	GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cartentity.CartItem, error)
-	SetStatus(ctx context.Context, orderID int64, status int32) error
+	RemoveOrderedItem(ctx context.Context, orderID, orderedItemID int64) error
+	ChangeStatus(ctx context.Context, orderID int64, from, to int32, actor orders.Actor, reason string) error
+	GetStatusHistory(ctx context.Context, orderID int64) ([]orders.StatusChange, error)
 	GetShiftIDByOrderID(ctx context.Context, orderID int64) (int64, error)
 	GetOrderByID(ctx context.Context, orderID int64) (*orders.Order, error)
+	GetOrderByIDForUpdate(ctx context.Context, orderID int64) (*orders.Order, error)
 	GetOrderedDishesIDsAndChefsIDs(ctx context.Context, userID int64, dishesLimit, chefsLimit int) ([]int64, []int64, error)
 	GetStatus(ctx context.Context, orderID int64) (int32, error)
 	GetOrdersByUserID(ctx context.Context, userID int64) ([]orders.Order, error)
@@ -58,7 +72,37 @@ type dishesUsecase interface {
 
 type chefsUsecase interface {
 	GetChefByID(ctx context.Context, chefID int64) (*chefEntity.Chef, error)
+	GetUserIDByChefID(ctx context.Context, chefID int64) (int64, error)
+}
+type deliveryUsecase interface {
+	CalculateFee(ctx context.Context, chefID int64, address *addressentity.Address, cartTotal money.Money) (*delivery.Quote, error)
+	ReserveSlot(ctx context.Context, chefID, slotID int64) error
+	ReleaseSlot(ctx context.Context, slotID *int64) error
+}
+
+type paymentsUsecase interface {
+	Authorize(ctx context.Context, orderID, userID int64, amount money.Money) (*payments.Payment, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) (*payments.Payment, error)
+	// RequestCapture, RequestRefund и RequestRefundRemaining вызываются в транзакции заказа и только
+	// сохраняют намерение; Capture и CompleteRefund обращаются к провайдеру уже после её фиксации
+	RequestCapture(ctx context.Context, orderID int64) error
+	Capture(ctx context.Context, orderID int64) error
+	RequestRefund(ctx context.Context, orderID int64, orderedItemID *int64, amount money.Money, reason string) (*payments.Refund, error)
+	RequestRefundRemaining(ctx context.Context, orderID int64, reason string) (*payments.Refund, error)
+	CompleteRefund(ctx context.Context, refund *payments.Refund) error
+	GetRefundsByOrderID(ctx context.Context, orderID int64) ([]payments.Refund, error)
 }
+
+type notificationsUsecase interface {
+	CreateNotification(ctx context.Context, n notifications.Notification) (int, error)
+}
+
+// statusNotifier сообщает клиенту и повару о смене статуса заказа. Вызывается после фиксации
+// изменений и ошибок не возвращает: уведомление не должно влиять на сам заказ.
+type statusNotifier interface {
+	OrderStatusChanged(ctx context.Context, order orders.Order, to int32)
+}
+
 type reviewUsecase interface {
 	GetReviewByOrderAndUserID(ctx context.Context, chefID, userID int64) (*reviewEntity.Review, error)
 }
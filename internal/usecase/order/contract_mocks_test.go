@@ -8,15 +8,56 @@ import (
 	context "context"
 	cart "domashka-backend/internal/entity/cart"
 	chefs "domashka-backend/internal/entity/chefs"
+	delivery "domashka-backend/internal/entity/delivery"
 	dishes "domashka-backend/internal/entity/dishes"
 	geo "domashka-backend/internal/entity/geo"
+	money "domashka-backend/internal/entity/money"
+	notifications "domashka-backend/internal/entity/notifications"
 	orders "domashka-backend/internal/entity/orders"
+	payments "domashka-backend/internal/entity/payments"
 	reviews "domashka-backend/internal/entity/reviews"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
 )
 
+// Mocktransactor is a mock of transactor interface.
+type Mocktransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MocktransactorMockRecorder
+}
+
+// MocktransactorMockRecorder is the mock recorder for Mocktransactor.
+type MocktransactorMockRecorder struct {
+	mock *Mocktransactor
+}
+
+// NewMocktransactor creates a new mock instance.
+func NewMocktransactor(ctrl *gomock.Controller) *Mocktransactor {
+	mock := &Mocktransactor{ctrl: ctrl}
+	mock.recorder = &MocktransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mocktransactor) EXPECT() *MocktransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTransaction mocks base method.
+func (m *Mocktransactor) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MocktransactorMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*Mocktransactor)(nil).WithinTransaction), ctx, fn)
+}
+
 // MockgeoUsecase is a mock of geoUsecase interface.
 type MockgeoUsecase struct {
 	ctrl     *gomock.Controller
@@ -78,6 +119,20 @@ func (m *MockcartUsecase) EXPECT() *MockcartUsecaseMockRecorder {
 	return m.recorder
 }
 
+// ClearCart mocks base method.
+func (m *MockcartUsecase) ClearCart(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCart", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCart indicates an expected call of ClearCart.
+func (mr *MockcartUsecaseMockRecorder) ClearCart(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCart", reflect.TypeOf((*MockcartUsecase)(nil).ClearCart), ctx, userID)
+}
+
 // GetCartItems mocks base method.
 func (m *MockcartUsecase) GetCartItems(ctx context.Context, userID int64) ([]cart.CartItem, error) {
 	m.ctrl.T.Helper()
@@ -117,7 +172,7 @@ func (m *MockshiftsRepo) EXPECT() *MockshiftsRepoMockRecorder {
 }
 
 // AddToTotalProfit mocks base method.
-func (m *MockshiftsRepo) AddToTotalProfit(ctx context.Context, shiftID int64, profit float64) error {
+func (m *MockshiftsRepo) AddToTotalProfit(ctx context.Context, shiftID int64, profit money.Money) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AddToTotalProfit", ctx, shiftID, profit)
 	ret0, _ := ret[0].(error)
@@ -182,19 +237,48 @@ func (mr *MockordersRepoMockRecorder) AddCartItemToOrder(ctx, cartItem, orderID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCartItemToOrder", reflect.TypeOf((*MockordersRepo)(nil).AddCartItemToOrder), ctx, cartItem, orderID, userID)
 }
 
+// ChangeStatus mocks base method.
+func (m *MockordersRepo) ChangeStatus(ctx context.Context, orderID int64, from, to int32, actor orders.Actor, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, orderID, from, to, actor, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockordersRepoMockRecorder) ChangeStatus(ctx, orderID, from, to, actor, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockordersRepo)(nil).ChangeStatus), ctx, orderID, from, to, actor, reason)
+}
+
+// CountDishInOrders mocks base method.
+func (m *MockordersRepo) CountDishInOrders(ctx context.Context, dishID int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDishInOrders", ctx, dishID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDishInOrders indicates an expected call of CountDishInOrders.
+func (mr *MockordersRepoMockRecorder) CountDishInOrders(ctx, dishID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDishInOrders", reflect.TypeOf((*MockordersRepo)(nil).CountDishInOrders), ctx, dishID)
+}
+
 // CreateOrder mocks base method.
-func (m *MockordersRepo) CreateOrder(ctx context.Context, chefID, shiftID, userID, clientAddressID int64, totalCost float32, leaveByTheDoor, callBeforehand bool) (int64, error) {
+func (m *MockordersRepo) CreateOrder(ctx context.Context, chefID, shiftID, userID, clientAddressID int64, totalCost, deliveryFee money.Money, deliverySlotID int64, leaveByTheDoor, callBeforehand bool) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateOrder", ctx, chefID, shiftID, userID, clientAddressID, totalCost, leaveByTheDoor, callBeforehand)
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, chefID, shiftID, userID, clientAddressID, totalCost, deliveryFee, deliverySlotID, leaveByTheDoor, callBeforehand)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateOrder indicates an expected call of CreateOrder.
-func (mr *MockordersRepoMockRecorder) CreateOrder(ctx, chefID, shiftID, userID, clientAddressID, totalCost, leaveByTheDoor, callBeforehand interface{}) *gomock.Call {
+func (mr *MockordersRepoMockRecorder) CreateOrder(ctx, chefID, shiftID, userID, clientAddressID, totalCost, deliveryFee, deliverySlotID, leaveByTheDoor, callBeforehand interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockordersRepo)(nil).CreateOrder), ctx, chefID, shiftID, userID, clientAddressID, totalCost, leaveByTheDoor, callBeforehand)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockordersRepo)(nil).CreateOrder), ctx, chefID, shiftID, userID, clientAddressID, totalCost, deliveryFee, deliverySlotID, leaveByTheDoor, callBeforehand)
 }
 
 // GetCartItems mocks base method.
@@ -242,6 +326,21 @@ func (mr *MockordersRepoMockRecorder) GetOrderByID(ctx, orderID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByID", reflect.TypeOf((*MockordersRepo)(nil).GetOrderByID), ctx, orderID)
 }
 
+// GetOrderByIDForUpdate mocks base method.
+func (m *MockordersRepo) GetOrderByIDForUpdate(ctx context.Context, orderID int64) (*orders.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByIDForUpdate", ctx, orderID)
+	ret0, _ := ret[0].(*orders.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByIDForUpdate indicates an expected call of GetOrderByIDForUpdate.
+func (mr *MockordersRepoMockRecorder) GetOrderByIDForUpdate(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByIDForUpdate", reflect.TypeOf((*MockordersRepo)(nil).GetOrderByIDForUpdate), ctx, orderID)
+}
+
 // GetOrderedDishesIDsAndChefsIDs mocks base method.
 func (m *MockordersRepo) GetOrderedDishesIDsAndChefsIDs(ctx context.Context, userID int64, dishesLimit, chefsLimit int) ([]int64, []int64, error) {
 	m.ctrl.T.Helper()
@@ -318,18 +417,33 @@ func (mr *MockordersRepoMockRecorder) GetStatus(ctx, orderID interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockordersRepo)(nil).GetStatus), ctx, orderID)
 }
 
-// SetStatus mocks base method.
-func (m *MockordersRepo) SetStatus(ctx context.Context, orderID int64, status int32) error {
+// GetStatusHistory mocks base method.
+func (m *MockordersRepo) GetStatusHistory(ctx context.Context, orderID int64) ([]orders.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusHistory", ctx, orderID)
+	ret0, _ := ret[0].([]orders.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusHistory indicates an expected call of GetStatusHistory.
+func (mr *MockordersRepoMockRecorder) GetStatusHistory(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusHistory", reflect.TypeOf((*MockordersRepo)(nil).GetStatusHistory), ctx, orderID)
+}
+
+// RemoveOrderedItem mocks base method.
+func (m *MockordersRepo) RemoveOrderedItem(ctx context.Context, orderID, orderedItemID int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetStatus", ctx, orderID, status)
+	ret := m.ctrl.Call(m, "RemoveOrderedItem", ctx, orderID, orderedItemID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetStatus indicates an expected call of SetStatus.
-func (mr *MockordersRepoMockRecorder) SetStatus(ctx, orderID, status interface{}) *gomock.Call {
+// RemoveOrderedItem indicates an expected call of RemoveOrderedItem.
+func (mr *MockordersRepoMockRecorder) RemoveOrderedItem(ctx, orderID, orderedItemID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockordersRepo)(nil).SetStatus), ctx, orderID, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOrderedItem", reflect.TypeOf((*MockordersRepo)(nil).RemoveOrderedItem), ctx, orderID, orderedItemID)
 }
 
 // MockdishesUsecase is a mock of dishesUsecase interface.
@@ -423,6 +537,300 @@ func (mr *MockchefsUsecaseMockRecorder) GetChefByID(ctx, chefID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChefByID", reflect.TypeOf((*MockchefsUsecase)(nil).GetChefByID), ctx, chefID)
 }
 
+// GetUserIDByChefID mocks base method.
+func (m *MockchefsUsecase) GetUserIDByChefID(ctx context.Context, chefID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIDByChefID", ctx, chefID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIDByChefID indicates an expected call of GetUserIDByChefID.
+func (mr *MockchefsUsecaseMockRecorder) GetUserIDByChefID(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDByChefID", reflect.TypeOf((*MockchefsUsecase)(nil).GetUserIDByChefID), ctx, chefID)
+}
+
+// MockdeliveryUsecase is a mock of deliveryUsecase interface.
+type MockdeliveryUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockdeliveryUsecaseMockRecorder
+}
+
+// MockdeliveryUsecaseMockRecorder is the mock recorder for MockdeliveryUsecase.
+type MockdeliveryUsecaseMockRecorder struct {
+	mock *MockdeliveryUsecase
+}
+
+// NewMockdeliveryUsecase creates a new mock instance.
+func NewMockdeliveryUsecase(ctrl *gomock.Controller) *MockdeliveryUsecase {
+	mock := &MockdeliveryUsecase{ctrl: ctrl}
+	mock.recorder = &MockdeliveryUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdeliveryUsecase) EXPECT() *MockdeliveryUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CalculateFee mocks base method.
+func (m *MockdeliveryUsecase) CalculateFee(ctx context.Context, chefID int64, address *geo.Address, cartTotal money.Money) (*delivery.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalculateFee", ctx, chefID, address, cartTotal)
+	ret0, _ := ret[0].(*delivery.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalculateFee indicates an expected call of CalculateFee.
+func (mr *MockdeliveryUsecaseMockRecorder) CalculateFee(ctx, chefID, address, cartTotal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateFee", reflect.TypeOf((*MockdeliveryUsecase)(nil).CalculateFee), ctx, chefID, address, cartTotal)
+}
+
+// ReleaseSlot mocks base method.
+func (m *MockdeliveryUsecase) ReleaseSlot(ctx context.Context, slotID *int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSlot", ctx, slotID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSlot indicates an expected call of ReleaseSlot.
+func (mr *MockdeliveryUsecaseMockRecorder) ReleaseSlot(ctx, slotID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSlot", reflect.TypeOf((*MockdeliveryUsecase)(nil).ReleaseSlot), ctx, slotID)
+}
+
+// ReserveSlot mocks base method.
+func (m *MockdeliveryUsecase) ReserveSlot(ctx context.Context, chefID, slotID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveSlot", ctx, chefID, slotID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveSlot indicates an expected call of ReserveSlot.
+func (mr *MockdeliveryUsecaseMockRecorder) ReserveSlot(ctx, chefID, slotID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveSlot", reflect.TypeOf((*MockdeliveryUsecase)(nil).ReserveSlot), ctx, chefID, slotID)
+}
+
+// MockpaymentsUsecase is a mock of paymentsUsecase interface.
+type MockpaymentsUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockpaymentsUsecaseMockRecorder
+}
+
+// MockpaymentsUsecaseMockRecorder is the mock recorder for MockpaymentsUsecase.
+type MockpaymentsUsecaseMockRecorder struct {
+	mock *MockpaymentsUsecase
+}
+
+// NewMockpaymentsUsecase creates a new mock instance.
+func NewMockpaymentsUsecase(ctrl *gomock.Controller) *MockpaymentsUsecase {
+	mock := &MockpaymentsUsecase{ctrl: ctrl}
+	mock.recorder = &MockpaymentsUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpaymentsUsecase) EXPECT() *MockpaymentsUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockpaymentsUsecase) Authorize(ctx context.Context, orderID, userID int64, amount money.Money) (*payments.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, orderID, userID, amount)
+	ret0, _ := ret[0].(*payments.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockpaymentsUsecaseMockRecorder) Authorize(ctx, orderID, userID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockpaymentsUsecase)(nil).Authorize), ctx, orderID, userID, amount)
+}
+
+// Capture mocks base method.
+func (m *MockpaymentsUsecase) Capture(ctx context.Context, orderID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockpaymentsUsecaseMockRecorder) Capture(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockpaymentsUsecase)(nil).Capture), ctx, orderID)
+}
+
+// CompleteRefund mocks base method.
+func (m *MockpaymentsUsecase) CompleteRefund(ctx context.Context, refund *payments.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteRefund", ctx, refund)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteRefund indicates an expected call of CompleteRefund.
+func (mr *MockpaymentsUsecaseMockRecorder) CompleteRefund(ctx, refund interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteRefund", reflect.TypeOf((*MockpaymentsUsecase)(nil).CompleteRefund), ctx, refund)
+}
+
+// GetRefundsByOrderID mocks base method.
+func (m *MockpaymentsUsecase) GetRefundsByOrderID(ctx context.Context, orderID int64) ([]payments.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundsByOrderID", ctx, orderID)
+	ret0, _ := ret[0].([]payments.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundsByOrderID indicates an expected call of GetRefundsByOrderID.
+func (mr *MockpaymentsUsecaseMockRecorder) GetRefundsByOrderID(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundsByOrderID", reflect.TypeOf((*MockpaymentsUsecase)(nil).GetRefundsByOrderID), ctx, orderID)
+}
+
+// HandleWebhook mocks base method.
+func (m *MockpaymentsUsecase) HandleWebhook(ctx context.Context, payload []byte, signature string) (*payments.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleWebhook", ctx, payload, signature)
+	ret0, _ := ret[0].(*payments.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HandleWebhook indicates an expected call of HandleWebhook.
+func (mr *MockpaymentsUsecaseMockRecorder) HandleWebhook(ctx, payload, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleWebhook", reflect.TypeOf((*MockpaymentsUsecase)(nil).HandleWebhook), ctx, payload, signature)
+}
+
+// RequestCapture mocks base method.
+func (m *MockpaymentsUsecase) RequestCapture(ctx context.Context, orderID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestCapture", ctx, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestCapture indicates an expected call of RequestCapture.
+func (mr *MockpaymentsUsecaseMockRecorder) RequestCapture(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestCapture", reflect.TypeOf((*MockpaymentsUsecase)(nil).RequestCapture), ctx, orderID)
+}
+
+// RequestRefund mocks base method.
+func (m *MockpaymentsUsecase) RequestRefund(ctx context.Context, orderID int64, orderedItemID *int64, amount money.Money, reason string) (*payments.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestRefund", ctx, orderID, orderedItemID, amount, reason)
+	ret0, _ := ret[0].(*payments.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestRefund indicates an expected call of RequestRefund.
+func (mr *MockpaymentsUsecaseMockRecorder) RequestRefund(ctx, orderID, orderedItemID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestRefund", reflect.TypeOf((*MockpaymentsUsecase)(nil).RequestRefund), ctx, orderID, orderedItemID, amount, reason)
+}
+
+// RequestRefundRemaining mocks base method.
+func (m *MockpaymentsUsecase) RequestRefundRemaining(ctx context.Context, orderID int64, reason string) (*payments.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestRefundRemaining", ctx, orderID, reason)
+	ret0, _ := ret[0].(*payments.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestRefundRemaining indicates an expected call of RequestRefundRemaining.
+func (mr *MockpaymentsUsecaseMockRecorder) RequestRefundRemaining(ctx, orderID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestRefundRemaining", reflect.TypeOf((*MockpaymentsUsecase)(nil).RequestRefundRemaining), ctx, orderID, reason)
+}
+
+// MocknotificationsUsecase is a mock of notificationsUsecase interface.
+type MocknotificationsUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MocknotificationsUsecaseMockRecorder
+}
+
+// MocknotificationsUsecaseMockRecorder is the mock recorder for MocknotificationsUsecase.
+type MocknotificationsUsecaseMockRecorder struct {
+	mock *MocknotificationsUsecase
+}
+
+// NewMocknotificationsUsecase creates a new mock instance.
+func NewMocknotificationsUsecase(ctrl *gomock.Controller) *MocknotificationsUsecase {
+	mock := &MocknotificationsUsecase{ctrl: ctrl}
+	mock.recorder = &MocknotificationsUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocknotificationsUsecase) EXPECT() *MocknotificationsUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreateNotification mocks base method.
+func (m *MocknotificationsUsecase) CreateNotification(ctx context.Context, n notifications.Notification) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, n)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MocknotificationsUsecaseMockRecorder) CreateNotification(ctx, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MocknotificationsUsecase)(nil).CreateNotification), ctx, n)
+}
+
+// MockstatusNotifier is a mock of statusNotifier interface.
+type MockstatusNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockstatusNotifierMockRecorder
+}
+
+// MockstatusNotifierMockRecorder is the mock recorder for MockstatusNotifier.
+type MockstatusNotifierMockRecorder struct {
+	mock *MockstatusNotifier
+}
+
+// NewMockstatusNotifier creates a new mock instance.
+func NewMockstatusNotifier(ctrl *gomock.Controller) *MockstatusNotifier {
+	mock := &MockstatusNotifier{ctrl: ctrl}
+	mock.recorder = &MockstatusNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockstatusNotifier) EXPECT() *MockstatusNotifierMockRecorder {
+	return m.recorder
+}
+
+// OrderStatusChanged mocks base method.
+func (m *MockstatusNotifier) OrderStatusChanged(ctx context.Context, order orders.Order, to int32) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OrderStatusChanged", ctx, order, to)
+}
+
+// OrderStatusChanged indicates an expected call of OrderStatusChanged.
+func (mr *MockstatusNotifierMockRecorder) OrderStatusChanged(ctx, order, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderStatusChanged", reflect.TypeOf((*MockstatusNotifier)(nil).OrderStatusChanged), ctx, order, to)
+}
+
 // MockreviewUsecase is a mock of reviewUsecase interface.
 type MockreviewUsecase struct {
 	ctrl     *gomock.Controller
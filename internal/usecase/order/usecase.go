@@ -2,13 +2,24 @@ package order
 
 import (
 	"context"
+	"database/sql"
 	cartentity "domashka-backend/internal/entity/cart"
 	chefEntity "domashka-backend/internal/entity/chefs"
 	dishEntity "domashka-backend/internal/entity/dishes"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/notifications"
 	"domashka-backend/internal/entity/orders"
+	paymententity "domashka-backend/internal/entity/payments"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 )
 
+// systemActor — смена статуса без участия пользователя (оплата, таймауты)
+var systemActor = orders.Actor{Role: orders.ActorRoleSystem}
+
 // ReviewDetail описывает отзыв пользователя на заказ
 type ReviewDetail struct {
 	CanWriteReview bool    // можно ли писать отзыв
@@ -22,8 +33,17 @@ type Usecase struct {
 	dishesUsecase dishesUsecase
 	chefsUsecase  chefsUsecase
 	reviewUsecase reviewUsecase
+	delivery      deliveryUsecase
+	payments      paymentsUsecase
+	notifications notificationsUsecase
+	notifier      statusNotifier
 	shiftsRepo    shiftsRepo
 	ordersRepo    ordersRepo
+	transactor    transactor
+
+	// cancelGracePeriod — сколько клиент может отменить заказ после принятия поваром
+	cancelGracePeriod time.Duration
+	now               func() time.Time
 }
 
 func New(
@@ -34,6 +54,12 @@ func New(
 	dishesUsecase dishesUsecase,
 	chefsUsecase chefsUsecase,
 	reviewUsecase reviewUsecase,
+	delivery deliveryUsecase,
+	payments paymentsUsecase,
+	notifications notificationsUsecase,
+	notifier statusNotifier,
+	transactor transactor,
+	cancelGracePeriod time.Duration,
 ) *Usecase {
 	return &Usecase{
 		geoUsecase:    geoUsecase,
@@ -43,10 +69,19 @@ func New(
 		dishesUsecase: dishesUsecase,
 		chefsUsecase:  chefsUsecase,
 		reviewUsecase: reviewUsecase,
+		delivery:      delivery,
+		payments:      payments,
+		notifications: notifications,
+		notifier:      notifier,
+		transactor:    transactor,
+
+		cancelGracePeriod: cancelGracePeriod,
+		now:               time.Now,
 	}
 }
 
-func (u *Usecase) CreateOrder(ctx context.Context, userID int64, leaveByTheDoor, callBeforehand bool) (int64, error) {
+// CreateOrder оформляет корзину пользователя в заказ с доставкой в слот deliverySlotID
+func (u *Usecase) CreateOrder(ctx context.Context, userID, deliverySlotID int64, leaveByTheDoor, callBeforehand bool) (int64, error) {
 	// Get client address
 	address, err := u.geoUsecase.GetLastUpdatedClientAddress(ctx, userID)
 	if err != nil {
@@ -58,18 +93,34 @@ func (u *Usecase) CreateOrder(ctx context.Context, userID int64, leaveByTheDoor,
 
 	// Get Cart Items
 	cartItems, err := u.cartUsecase.GetCartItems(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
 	if len(cartItems) == 0 {
 		return 0, fmt.Errorf("нет товаров в корзине")
 	}
-	// Calculate total profit
-	totalProfit := float32(0)
-	chefID := int64(0)
-	for _, item := range cartItems {
-		totalProfit += float32(item.Quantity) * item.Size.PriceValue
-		chefID = item.Dish.ChefID
+	// Заказ оформляется у одного повара; смешанную корзину не делим, а отклоняем,
+	// чтобы клиент сам решил, что оставить
+	chefIDs := cartentity.GetChefIDs(cartItems)
+	if len(chefIDs) > 1 {
+		return 0, cartentity.ErrMultipleChefs
 	}
+	chefID := chefIDs[0]
 
-	// Get ChefID
+	// Calculate total profit
+	itemsTotal, err := cartentity.GetTotalCartPrice(cartItems)
+	if err != nil {
+		return 0, err
+	}
+	quote, err := u.delivery.CalculateFee(ctx, chefID, address, itemsTotal)
+	if err != nil {
+		return 0, err
+	}
+	// Доставка входит в стоимость заказа
+	totalProfit, err := itemsTotal.Add(quote.Fee)
+	if err != nil {
+		return 0, err
+	}
 
 	// Get Active Shift
 	shiftID, err := u.shiftsRepo.GetActiveShiftIDByChefID(ctx, chefID)
@@ -81,70 +132,417 @@ func (u *Usecase) CreateOrder(ctx context.Context, userID int64, leaveByTheDoor,
 		return 0, fmt.Errorf("повар сейчас не работает")
 	}
 
-	// Create order
-	orderID, err := u.ordersRepo.CreateOrder(
-		ctx,
-		chefID,
-		shiftID,
-		userID,
-		address.ID,
-		totalProfit,
-		leaveByTheDoor,
-		callBeforehand,
-	)
-
-	// Add cart items to order
-	for _, item := range cartItems {
-		err = u.ordersRepo.AddCartItemToOrder(ctx, &item, orderID, userID)
+	// Заказ, его позиции и бронь слота — одна транзакция:
+	// при любой ошибке не остаётся полузаполненного заказа
+	var orderID int64
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.delivery.ReserveSlot(ctx, chefID, deliverySlotID); err != nil {
+			return err
+		}
+		orderID, err = u.ordersRepo.CreateOrder(
+			ctx,
+			chefID,
+			shiftID,
+			userID,
+			address.ID,
+			totalProfit,
+			quote.Fee,
+			deliverySlotID,
+			leaveByTheDoor,
+			callBeforehand,
+		)
 		if err != nil {
+			return err
+		}
+
+		for i := range cartItems {
+			if err := u.ordersRepo.AddCartItemToOrder(ctx, &cartItems[i], orderID, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Деньги блокируются уже после фиксации заказа, чтобы не держать транзакцию
+	// на время запроса к провайдеру. При отказе заказ отклоняется, корзина остаётся.
+	payment, err := u.payments.Authorize(ctx, orderID, userID, totalProfit)
+	if err != nil {
+		if rejectErr := u.Reject(ctx, orderID, systemActor, "payment failed"); rejectErr != nil {
+			return 0, errors.Join(err, rejectErr)
+		}
+		return 0, err
+	}
+	if payment == nil {
+		// Оплата выключена — заказ оплачивается при получении и подтверждается сразу
+		if err := u.confirmOrder(ctx, orderID, userID); err != nil {
 			return 0, err
 		}
+		return orderID, nil
+	}
+	if err := u.applyPayment(ctx, payment); err != nil {
+		return 0, err
 	}
 	return orderID, nil
 }
 
+// HandlePaymentWebhook применяет уведомление платёжного провайдера к заказу
+func (u *Usecase) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error {
+	payment, err := u.payments.HandleWebhook(ctx, payload, signature)
+	if err != nil {
+		return err
+	}
+	return u.applyPayment(ctx, payment)
+}
+
+// applyPayment переводит ожидающий оплаты заказ в Created после авторизации
+// (и только тогда очищает корзину), либо отклоняет его, если оплата не прошла.
+// Пока платёж в ожидании, заказ не меняется — итог придёт вебхуком.
+func (u *Usecase) applyPayment(ctx context.Context, payment *paymententity.Payment) error {
+	status, err := u.ordersRepo.GetStatus(ctx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+	if status == orders.StatusRejected && payment.Status == paymententity.StatusAuthorized {
+		// Оплата пришла, когда заказ уже отклонён, — снимаем блокировку денег
+		refund, err := u.payments.RequestRefundRemaining(ctx, payment.OrderID, "order rejected")
+		if err != nil {
+			return err
+		}
+		return u.payments.CompleteRefund(ctx, refund)
+	}
+	if status != orders.StatusAwaitingPayment {
+		// Повторное уведомление об уже обработанной оплате
+		return nil
+	}
+	switch payment.Status {
+	case paymententity.StatusAuthorized:
+		return u.confirmOrder(ctx, payment.OrderID, payment.UserID)
+	case paymententity.StatusFailed:
+		return u.Reject(ctx, payment.OrderID, systemActor, "payment failed")
+	}
+	return nil
+}
+
+// confirmOrder переводит ожидающий оплаты заказ в Created и очищает корзину клиента
+func (u *Usecase) confirmOrder(ctx context.Context, orderID, userID int64) error {
+	var order *orders.Order
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if order, err = u.changeStatus(ctx, orderID, orders.StatusCreated, systemActor, ""); err != nil {
+			return err
+		}
+		return u.cartUsecase.ClearCart(ctx, userID)
+	})
+	if err != nil {
+		return err
+	}
+	u.notifier.OrderStatusChanged(ctx, *order, orders.StatusCreated)
+	return nil
+}
+
+// GetOrdersByShiftID возвращает заказы смены, которые видит повар: неоплаченные не показываются
 func (u *Usecase) GetOrdersByShiftID(ctx context.Context, shiftID int64) ([]orders.Order, error) {
-	return u.ordersRepo.GetOrdersByShiftID(ctx, shiftID)
+	shiftOrders, err := u.ordersRepo.GetOrdersByShiftID(ctx, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	paid := make([]orders.Order, 0, len(shiftOrders))
+	for _, order := range shiftOrders {
+		if order.Status != orders.StatusAwaitingPayment {
+			paid = append(paid, order)
+		}
+	}
+	return paid, nil
 }
 
 func (u *Usecase) GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cartentity.CartItem, error) {
 	return u.ordersRepo.GetCartItemsByOrderID(ctx, orderID)
 }
 
-func (u *Usecase) SetStatus(ctx context.Context, orderID int64, status int32) error {
-	return u.ordersRepo.SetStatus(ctx, orderID, status)
+// SetStatus переводит заказ в произвольный статус через проверку допустимости перехода
+func (u *Usecase) SetStatus(ctx context.Context, orderID int64, status int32, actor orders.Actor) error {
+	return u.changeStatusAndNotify(ctx, orderID, status, actor)
 }
 
-func (u *Usecase) Accept(ctx context.Context, orderID int64) error {
-	return u.ordersRepo.SetStatus(ctx, orderID, orders.StatusAccepted)
+func (u *Usecase) Accept(ctx context.Context, orderID int64, actor orders.Actor) error {
+	return u.changeStatusAndNotify(ctx, orderID, orders.StatusAccepted, actor)
 }
 
-func (u *Usecase) CallDelivery(ctx context.Context, orderID int64) error {
-	return u.ordersRepo.SetStatus(ctx, orderID, orders.StatusCooked)
+func (u *Usecase) CallDelivery(ctx context.Context, orderID int64, actor orders.Actor) error {
+	return u.changeStatusAndNotify(ctx, orderID, orders.StatusCooked, actor)
 }
 
-func (u *Usecase) PickUp(ctx context.Context, orderID int64) error {
-	return u.ordersRepo.SetStatus(ctx, orderID, orders.StatusInDelivery)
+func (u *Usecase) PickUp(ctx context.Context, orderID int64, actor orders.Actor) error {
+	return u.changeStatusAndNotify(ctx, orderID, orders.StatusInDelivery, actor)
 }
 
-func (u *Usecase) Deliver(ctx context.Context, orderID int64) error {
-	shiftID, err := u.ordersRepo.GetShiftIDByOrderID(ctx, orderID)
+// Deliver отмечает заказ доставленным, пополняет выручку смены и списывает деньги.
+// Так же подтверждается доставка заказа, закрытого через CloseStaleDelivery.
+func (u *Usecase) Deliver(ctx context.Context, orderID int64, actor orders.Actor) error {
+	// Выручка смены пополняется только при фактическом переходе в Delivered,
+	// поэтому повторный Deliver не задвоит сумму
+	var order *orders.Order
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = u.changeStatus(ctx, orderID, orders.StatusDelivered, actor, "")
+		if err != nil {
+			return err
+		}
+		// Убранные из заказа позиции в выручку не попадают
+		profit, err := u.netTotal(ctx, order)
+		if err != nil {
+			return err
+		}
+		if err := u.shiftsRepo.AddToTotalProfit(ctx, order.ShiftID, profit); err != nil {
+			return err
+		}
+		// Деньги списываются только за доставленный заказ. Здесь списание лишь отмечается,
+		// к провайдеру обращаемся после фиксации, чтобы не держать транзакцию на время запроса
+		return u.payments.RequestCapture(ctx, orderID)
+	})
+	if err != nil {
+		return err
+	}
+	// Заказ уже доставлен, поэтому ошибка списания не возвращается — его повторит воркер
+	if err := u.payments.Capture(ctx, orderID); err != nil {
+		log.Printf("Ошибка списания оплаты заказа %d: %v", orderID, err)
+	}
+	u.notifier.OrderStatusChanged(ctx, *order, orders.StatusDelivered)
+	return nil
+}
+
+// CloseStaleDelivery закрывает от имени системы заказ, который слишком долго в доставке.
+// Доставка не подтверждена, поэтому деньги не списываются и выручка смены не пополняется:
+// такие заказы разбираются вручную по причине из истории статусов — доставку подтверждают
+// через Deliver либо заказ отклоняют с возвратом денег.
+func (u *Usecase) CloseStaleDelivery(ctx context.Context, orderID int64, reason string) error {
+	order, err := u.changeStatus(ctx, orderID, orders.StatusDeliveryUnconfirmed, systemActor, reason)
+	if err != nil {
+		return err
+	}
+	u.notifier.OrderStatusChanged(ctx, *order, orders.StatusDeliveryUnconfirmed)
+	return nil
+}
+
+// Reject отклоняет заказ, освобождает его слот доставки и возвращает клиенту всё, что ещё не вернули
+func (u *Usecase) Reject(ctx context.Context, orderID int64, actor orders.Actor, reason string) error {
+	var (
+		order  *orders.Order
+		refund *paymententity.Refund
+	)
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if order, err = u.changeStatus(ctx, orderID, orders.StatusRejected, actor, reason); err != nil {
+			return err
+		}
+		if err := u.delivery.ReleaseSlot(ctx, order.DeliverySlotID); err != nil {
+			return err
+		}
+		refund, err = u.payments.RequestRefundRemaining(ctx, orderID, reason)
+		return err
+	})
 	if err != nil {
 		return err
 	}
+	u.completeRefund(ctx, orderID, refund)
+	u.notifier.OrderStatusChanged(ctx, *order, orders.StatusRejected)
+	return nil
+}
+
+// Cancel отменяет заказ по просьбе клиента, который его оформил: пока повар не принял заказ,
+// либо в течение cancelGracePeriod после принятия. Слот доставки освобождается, деньги возвращаются,
+// повар получает уведомление.
+func (u *Usecase) Cancel(ctx context.Context, orderID int64, actor orders.Actor, reason string) error {
 	order, err := u.ordersRepo.GetOrderByID(ctx, orderID)
 	if err != nil {
 		return err
 	}
-	err = u.shiftsRepo.AddToTotalProfit(ctx, shiftID, order.TotalCost)
+	if order.UserID != actor.UserID {
+		return orders.ErrNotOrderOwner
+	}
+	var acceptedAt time.Time
+	if order.Status == orders.StatusAccepted {
+		acceptedAt, err = u.acceptedAt(ctx, orderID)
+		if err != nil {
+			return err
+		}
+	}
+	if !orders.CanCancel(order.Status, acceptedAt, u.now(), u.cancelGracePeriod) {
+		return orders.ErrCancelNotAllowed
+	}
+
+	var refund *paymententity.Refund
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := u.changeStatus(ctx, orderID, orders.StatusCancelled, actor, reason); err != nil {
+			return err
+		}
+		if err := u.delivery.ReleaseSlot(ctx, order.DeliverySlotID); err != nil {
+			return err
+		}
+		var err error
+		refund, err = u.payments.RequestRefundRemaining(ctx, orderID, reason)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	u.completeRefund(ctx, orderID, refund)
+
+	// Заказ уже отменён, поэтому ошибка уведомления не возвращается клиенту
+	if err := u.notifyChefCancelled(ctx, order, reason); err != nil {
+		log.Printf("Ошибка уведомления повара %d об отмене заказа %d: %v", order.ChefID, orderID, err)
+	}
+	u.notifier.OrderStatusChanged(ctx, *order, orders.StatusCancelled)
+	return nil
+}
+
+// acceptedAt возвращает время, когда повар принял заказ
+func (u *Usecase) acceptedAt(ctx context.Context, orderID int64) (time.Time, error) {
+	history, err := u.ordersRepo.GetStatusHistory(ctx, orderID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var acceptedAt time.Time
+	for _, change := range history {
+		if change.ToStatus == orders.StatusAccepted {
+			acceptedAt = change.CreatedAt
+		}
+	}
+	return acceptedAt, nil
+}
+
+func (u *Usecase) notifyChefCancelled(ctx context.Context, order *orders.Order, reason string) error {
+	message := fmt.Sprintf("Клиент отменил заказ №%d.", order.ID)
+	if reason != "" {
+		message = fmt.Sprintf("Клиент отменил заказ №%d. Причина: %s", order.ID, reason)
+	}
+	metadata, err := json.Marshal(map[string]any{"order_id": order.ID, "status": orders.StatusCancelled})
+	if err != nil {
+		return err
+	}
+	// Уведомления адресуются пользователям, а в заказе — ID повара
+	chefUserID, err := u.chefsUsecase.GetUserIDByChefID(ctx, order.ChefID)
+	if err != nil {
+		return err
+	}
+	_, err = u.notifications.CreateNotification(ctx, notifications.Notification{
+		UserID:   sql.NullInt64{Int64: chefUserID, Valid: true},
+		Channel:  notifications.ChannelPush,
+		Scenario: notifications.ScenarioOrderStatus,
+		Subject:  sql.NullString{String: "Заказ отменён", Valid: true},
+		Message:  message,
+		Metadata: sql.NullString{String: string(metadata), Valid: true},
+	})
+	return err
+}
+
+// RemoveItem убирает позицию из заказа, который ещё не передан в доставку, и возвращает за неё деньги.
+// Последнюю позицию убрать нельзя — такой заказ нужно отклонить.
+func (u *Usecase) RemoveItem(ctx context.Context, orderID, orderedItemID int64, actor orders.Actor, reason string) error {
+	var refund *paymententity.Refund
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Заказ блокируется, чтобы статус и позиции не поменялись до удаления:
+		// иначе можно убрать последнюю позицию или вернуть деньги за уже отклонённый заказ
+		order, err := u.ordersRepo.GetOrderByIDForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		switch order.Status {
+		case orders.StatusCreated, orders.StatusAccepted, orders.StatusCooked:
+		default:
+			return orders.ErrNotModifiable
+		}
+
+		items, err := u.ordersRepo.GetCartItemsByOrderID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		var removed *cartentity.CartItem
+		for i := range items {
+			if items[i].ID == orderedItemID {
+				removed = &items[i]
+				break
+			}
+		}
+		if removed == nil {
+			return orders.ErrItemNotFound
+		}
+		if len(items) == 1 {
+			return orders.ErrLastItem
+		}
+
+		if err := u.ordersRepo.RemoveOrderedItem(ctx, orderID, orderedItemID); err != nil {
+			return err
+		}
+		amount, err := removed.GetTotalPrice()
+		if err != nil {
+			return err
+		}
+		refund, err = u.payments.RequestRefund(ctx, orderID, &orderedItemID, amount, reason)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	u.completeRefund(ctx, orderID, refund)
+	return nil
+}
+
+// completeRefund проводит у провайдера возврат, сохранённый вместе с изменением заказа.
+// Заказ уже изменён, поэтому ошибка не возвращается — возврат повторит воркер.
+func (u *Usecase) completeRefund(ctx context.Context, orderID int64, refund *paymententity.Refund) {
+	if err := u.payments.CompleteRefund(ctx, refund); err != nil {
+		log.Printf("Ошибка возврата денег по заказу %d: %v", orderID, err)
+	}
+}
+
+// netTotal — стоимость заказа за вычетом возвратов
+func (u *Usecase) netTotal(ctx context.Context, order *orders.Order) (money.Money, error) {
+	refunds, err := u.payments.GetRefundsByOrderID(ctx, order.ID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	total := order.TotalCost
+	for _, refund := range refunds {
+		total, err = total.Sub(refund.Amount)
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// changeStatus проверяет допустимость перехода и атомарно меняет статус заказа,
+// записывая переход в историю. Возвращает заказ в состоянии до перехода.
+func (u *Usecase) changeStatus(ctx context.Context, orderID int64, to int32, actor orders.Actor, reason string) (*orders.Order, error) {
+	order, err := u.ordersRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := orders.ValidateTransition(order.Status, to); err != nil {
+		return nil, err
+	}
+	if err := u.ordersRepo.ChangeStatus(ctx, orderID, order.Status, to, actor, reason); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// changeStatusAndNotify меняет статус вне транзакции и сразу сообщает об этом
+func (u *Usecase) changeStatusAndNotify(ctx context.Context, orderID int64, to int32, actor orders.Actor) error {
+	order, err := u.changeStatus(ctx, orderID, to, actor, "")
 	if err != nil {
 		return err
 	}
-	return u.ordersRepo.SetStatus(ctx, orderID, orders.StatusDelivered)
+	u.notifier.OrderStatusChanged(ctx, *order, to)
+	return nil
 }
 
-func (u *Usecase) Reject(ctx context.Context, orderID int64) error {
-	return u.ordersRepo.SetStatus(ctx, orderID, orders.StatusRejected)
+func (u *Usecase) GetStatusHistory(ctx context.Context, orderID int64) ([]orders.StatusChange, error) {
+	return u.ordersRepo.GetStatusHistory(ctx, orderID)
 }
 
 func (u *Usecase) GetOrderedDishesAndChefsByUserID(ctx context.Context, userID int64) ([]dishEntity.Dish, []chefEntity.Chef, error) {
@@ -226,11 +624,18 @@ func (u *Usecase) GetOrdersByUserID(ctx context.Context, userID int64) ([]orders
 			}
 		}
 
+		// 3d) Возвраты по заказу
+		refunds, err := u.payments.GetRefundsByOrderID(ctx, ord.ID)
+		if err != nil {
+			return nil, fmt.Errorf("GetRefundsByOrderID(order=%d): %w", ord.ID, err)
+		}
+
 		profiles = append(profiles, orders.OrderProfile{
-			Order:  &ord,
-			Items:  items,
-			Chef:   chef,
-			Review: reviewDetail,
+			Order:   &ord,
+			Items:   items,
+			Chef:    chef,
+			Review:  reviewDetail,
+			Refunds: refunds,
 		})
 	}
 
@@ -245,8 +650,10 @@ func (u *Usecase) GetActiveOrdersByUserID(ctx context.Context, userID int64) ([]
 	}
 	activeOrders := make([]orders.Order, 0, len(allOrders))
 	for _, order := range allOrders {
-		if order.Status == orders.StatusCooked ||
+		if order.Status == orders.StatusAwaitingPayment ||
+			order.Status == orders.StatusCooked ||
 			order.Status == orders.StatusInDelivery ||
+			order.Status == orders.StatusDeliveryUnconfirmed ||
 			order.Status == orders.StatusAccepted ||
 			order.Status == orders.StatusCreated {
 			activeOrders = append(activeOrders, order)
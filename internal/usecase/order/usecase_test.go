@@ -2,14 +2,21 @@ package order
 
 import (
 	"context"
+	"domashka-backend/internal/custom_errors"
 	cartentity "domashka-backend/internal/entity/cart"
 	chefEntity "domashka-backend/internal/entity/chefs"
+	deliveryEntity "domashka-backend/internal/entity/delivery"
 	dishEntity "domashka-backend/internal/entity/dishes"
 	geoEntity "domashka-backend/internal/entity/geo"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/notifications"
 	"domashka-backend/internal/entity/orders"
+	paymententity "domashka-backend/internal/entity/payments"
+	"errors"
 	"github.com/golang/mock/gomock"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestUsecase_Accept(t *testing.T) {
@@ -66,8 +73,45 @@ func TestUsecase_Accept(t *testing.T) {
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusCreated}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusCreated), int32(orders.StatusAccepted), gomock.Any(), "").Return(nil)
 				return m
 			},
+			args: args{ctx: context.Background(), orderID: 1},
+		},
+		{
+			name: "invalid transition",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusDelivered}, nil)
+				return m
+			},
+			args:    args{ctx: context.Background(), orderID: 1},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -82,8 +126,14 @@ func TestUsecase_Accept(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			if err := u.Accept(tt.args.ctx, tt.args.orderID); (err != nil) != tt.wantErr {
+			if err := u.Accept(tt.args.ctx, tt.args.orderID, orders.Actor{UserID: 1, Role: orders.ActorRoleChef}); (err != nil) != tt.wantErr {
 				t.Errorf("Accept() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -144,8 +194,45 @@ func TestUsecase_CallDelivery(t *testing.T) {
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusAccepted}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusAccepted), int32(orders.StatusCooked), gomock.Any(), "").Return(nil)
+				return m
+			},
+			args: args{ctx: context.Background(), orderID: 1},
+		},
+		{
+			name: "invalid transition",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusCreated}, nil)
 				return m
 			},
+			args:    args{ctx: context.Background(), orderID: 1},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -160,8 +247,14 @@ func TestUsecase_CallDelivery(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			if err := u.CallDelivery(tt.args.ctx, tt.args.orderID); (err != nil) != tt.wantErr {
+			if err := u.CallDelivery(tt.args.ctx, tt.args.orderID, orders.Actor{UserID: 1, Role: orders.ActorRoleChef}); (err != nil) != tt.wantErr {
 				t.Errorf("CallDelivery() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -175,12 +268,14 @@ func TestUsecase_CreateOrder(t *testing.T) {
 		dishesUsecase dishesUsecase
 		chefsUsecase  chefsUsecase
 		reviewUsecase reviewUsecase
+		delivery      deliveryUsecase
 		shiftsRepo    shiftsRepo
 		ordersRepo    ordersRepo
 	}
 	type args struct {
 		ctx            context.Context
 		userID         int64
+		deliverySlotID int64
 		leaveByTheDoor bool
 		callBeforehand bool
 	}
@@ -191,6 +286,8 @@ func TestUsecase_CreateOrder(t *testing.T) {
 		dishesUsecase func(ctrl *gomock.Controller) dishesUsecase
 		chefsUsecase  func(ctrl *gomock.Controller) chefsUsecase
 		reviewUsecase func(ctrl *gomock.Controller) reviewUsecase
+		delivery      func(ctrl *gomock.Controller) deliveryUsecase
+		payments      func(ctrl *gomock.Controller) paymentsUsecase
 		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
 		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
 		args          args
@@ -214,9 +311,10 @@ func TestUsecase_CreateOrder(t *testing.T) {
 					Quantity:           1,
 					AddedIngredients:   []dishEntity.Ingredient{},
 					RemovedIngredients: []dishEntity.Ingredient{},
-					Size:               dishEntity.Size{},
+					Size:               dishEntity.Size{Price: money.New(500_00, money.RUB)},
 					Notes:              "",
 				}}, nil)
+				m.EXPECT().ClearCart(gomock.Any(), gomock.Any()).Return(nil)
 				return m
 			},
 			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
@@ -231,6 +329,21 @@ func TestUsecase_CreateOrder(t *testing.T) {
 				m := NewMockreviewUsecase(ctrl)
 				return m
 			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				m := NewMockdeliveryUsecase(ctrl)
+				m.EXPECT().CalculateFee(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&deliveryEntity.Quote{Fee: money.New(169_00, money.RUB)}, nil)
+				m.EXPECT().ReserveSlot(gomock.Any(), gomock.Any(), int64(10)).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().Authorize(gomock.Any(), int64(1), int64(1), money.New(669_00, money.RUB)).Return(&paymententity.Payment{
+					OrderID: 1,
+					UserID:  1,
+					Status:  paymententity.StatusAuthorized,
+				}, nil)
+				return m
+			},
 			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
 				m := NewMockshiftsRepo(ctrl)
 				m.EXPECT().GetActiveShiftIDByChefID(gomock.Any(), gomock.Any()).Return(int64(1), nil)
@@ -241,78 +354,117 @@ func TestUsecase_CreateOrder(t *testing.T) {
 				m.EXPECT().CreateOrder(
 					gomock.Any(),
 					gomock.Any(),
+					int64(1),
 					gomock.Any(),
 					gomock.Any(),
+					money.New(669_00, money.RUB),
+					money.New(169_00, money.RUB),
+					int64(10),
 					gomock.Any(),
 					gomock.Any(),
+				).Return(int64(1), nil)
+				m.EXPECT().AddCartItemToOrder(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().GetStatus(gomock.Any(), int64(1)).Return(int32(orders.StatusAwaitingPayment), nil)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusAwaitingPayment}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusAwaitingPayment), int32(orders.StatusCreated), gomock.Any(), "").Return(nil)
+				return m
+			},
+			args: args{ctx: context.Background(), userID: 1, deliverySlotID: 10},
+			want: 1,
+		},
+		{
+			name: "payments disabled, order is confirmed at once",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				m.EXPECT().GetLastUpdatedClientAddress(gomock.Any(), gomock.Any()).Return(&geoEntity.Address{}, nil)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				m.EXPECT().GetCartItems(gomock.Any(), gomock.Any()).Return([]cartentity.CartItem{{
+					ID: 1,
+					Dish: dishEntity.Dish{
+						ID: 1,
+					},
+					Quantity:           1,
+					AddedIngredients:   []dishEntity.Ingredient{},
+					RemovedIngredients: []dishEntity.Ingredient{},
+					Size:               dishEntity.Size{Price: money.New(500_00, money.RUB)},
+					Notes:              "",
+				}}, nil)
+				m.EXPECT().ClearCart(gomock.Any(), gomock.Any()).Return(nil)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				m := NewMockdeliveryUsecase(ctrl)
+				m.EXPECT().CalculateFee(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&deliveryEntity.Quote{Fee: money.New(169_00, money.RUB)}, nil)
+				m.EXPECT().ReserveSlot(gomock.Any(), gomock.Any(), int64(10)).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().Authorize(gomock.Any(), int64(1), int64(1), money.New(669_00, money.RUB)).Return(nil, nil)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				m.EXPECT().GetActiveShiftIDByChefID(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().CreateOrder(
+					gomock.Any(),
+					gomock.Any(),
+					int64(1),
+					gomock.Any(),
+					gomock.Any(),
+					money.New(669_00, money.RUB),
+					money.New(169_00, money.RUB),
+					int64(10),
 					gomock.Any(),
 					gomock.Any(),
 				).Return(int64(1), nil)
 				m.EXPECT().AddCartItemToOrder(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusAwaitingPayment}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusAwaitingPayment), int32(orders.StatusCreated), gomock.Any(), "").Return(nil)
 				return m
 			},
+			args: args{ctx: context.Background(), userID: 1, deliverySlotID: 10},
 			want: 1,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-			u := New(
-				tt.geoUsecase(ctrl),
-				tt.cartUsecase(ctrl),
-				tt.shiftsRepo(ctrl),
-				tt.ordersRepo(ctrl),
-				tt.dishesUsecase(ctrl),
-				tt.chefsUsecase(ctrl),
-				tt.reviewUsecase(ctrl),
-			)
-			got, err := u.CreateOrder(tt.args.ctx, tt.args.userID, tt.args.leaveByTheDoor, tt.args.callBeforehand)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("CreateOrder() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if got != tt.want {
-				t.Errorf("CreateOrder() got = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestUsecase_Deliver(t *testing.T) {
-	type fields struct {
-		geoUsecase    geoUsecase
-		cartUsecase   cartUsecase
-		dishesUsecase dishesUsecase
-		chefsUsecase  chefsUsecase
-		reviewUsecase reviewUsecase
-		shiftsRepo    shiftsRepo
-		ordersRepo    ordersRepo
-	}
-	type args struct {
-		ctx     context.Context
-		orderID int64
-	}
-	tests := []struct {
-		name          string
-		geoUsecase    func(ctrl *gomock.Controller) geoUsecase
-		cartUsecase   func(ctrl *gomock.Controller) cartUsecase
-		dishesUsecase func(ctrl *gomock.Controller) dishesUsecase
-		chefsUsecase  func(ctrl *gomock.Controller) chefsUsecase
-		reviewUsecase func(ctrl *gomock.Controller) reviewUsecase
-		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
-		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
-		args          args
-		wantErr       bool
-	}{
 		{
-			name: "success",
+			name: "payment declined, cart is kept",
 			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
 				m := NewMockgeoUsecase(ctrl)
+				m.EXPECT().GetLastUpdatedClientAddress(gomock.Any(), gomock.Any()).Return(&geoEntity.Address{}, nil)
 				return m
 			},
 			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
 				m := NewMockcartUsecase(ctrl)
+				m.EXPECT().GetCartItems(gomock.Any(), gomock.Any()).Return([]cartentity.CartItem{{
+					ID: 1,
+					Dish: dishEntity.Dish{
+						ID: 1,
+					},
+					Quantity:           1,
+					AddedIngredients:   []dishEntity.Ingredient{},
+					RemovedIngredients: []dishEntity.Ingredient{},
+					Size:               dishEntity.Size{Price: money.New(500_00, money.RUB)},
+					Notes:              "",
+				}}, nil)
 				return m
 			},
 			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
@@ -327,72 +479,64 @@ func TestUsecase_Deliver(t *testing.T) {
 				m := NewMockreviewUsecase(ctrl)
 				return m
 			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				m := NewMockdeliveryUsecase(ctrl)
+				m.EXPECT().CalculateFee(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&deliveryEntity.Quote{Fee: money.New(169_00, money.RUB)}, nil)
+				m.EXPECT().ReserveSlot(gomock.Any(), gomock.Any(), int64(10)).Return(nil)
+				m.EXPECT().ReleaseSlot(gomock.Any(), gomock.Any()).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().Authorize(gomock.Any(), int64(1), int64(1), gomock.Any()).Return(&paymententity.Payment{
+					OrderID: 1,
+					UserID:  1,
+					Status:  paymententity.StatusFailed,
+				}, paymententity.ErrDeclined)
+				m.EXPECT().RequestRefundRemaining(gomock.Any(), int64(1), "payment failed").Return(nil, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), nil).Return(nil)
+				return m
+			},
 			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
 				m := NewMockshiftsRepo(ctrl)
+				m.EXPECT().GetActiveShiftIDByChefID(gomock.Any(), gomock.Any()).Return(int64(1), nil)
 				return m
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().CreateOrder(
+					gomock.Any(),
+					gomock.Any(),
+					int64(1),
+					gomock.Any(),
+					gomock.Any(),
+					money.New(669_00, money.RUB),
+					money.New(169_00, money.RUB),
+					int64(10),
+					gomock.Any(),
+					gomock.Any(),
+				).Return(int64(1), nil)
+				m.EXPECT().AddCartItemToOrder(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusAwaitingPayment}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusAwaitingPayment), int32(orders.StatusRejected), gomock.Any(), "payment failed").Return(nil)
 				return m
 			},
+			args:    args{ctx: context.Background(), userID: 1, deliverySlotID: 10},
+			wantErr: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-			u := New(
-				tt.geoUsecase(ctrl),
-				tt.cartUsecase(ctrl),
-				tt.shiftsRepo(ctrl),
-				tt.ordersRepo(ctrl),
-				tt.dishesUsecase(ctrl),
-				tt.chefsUsecase(ctrl),
-				tt.reviewUsecase(ctrl),
-			)
-			if err := u.Deliver(tt.args.ctx, tt.args.orderID); (err != nil) != tt.wantErr {
-				t.Errorf("Deliver() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestUsecase_GetActiveOrdersByUserID(t *testing.T) {
-	type fields struct {
-		geoUsecase    geoUsecase
-		cartUsecase   cartUsecase
-		dishesUsecase dishesUsecase
-		chefsUsecase  chefsUsecase
-		reviewUsecase reviewUsecase
-		shiftsRepo    shiftsRepo
-		ordersRepo    ordersRepo
-	}
-	type args struct {
-		ctx    context.Context
-		userID int64
-	}
-	tests := []struct {
-		name          string
-		geoUsecase    func(ctrl *gomock.Controller) geoUsecase
-		cartUsecase   func(ctrl *gomock.Controller) cartUsecase
-		dishesUsecase func(ctrl *gomock.Controller) dishesUsecase
-		chefsUsecase  func(ctrl *gomock.Controller) chefsUsecase
-		reviewUsecase func(ctrl *gomock.Controller) reviewUsecase
-		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
-		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
-		args          args
-		want          []orders.Order
-		wantErr       bool
-	}{
-
 		{
-			name: "success",
+			name: "multi-chef cart",
 			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
 				m := NewMockgeoUsecase(ctrl)
+				m.EXPECT().GetLastUpdatedClientAddress(gomock.Any(), gomock.Any()).Return(&geoEntity.Address{}, nil)
 				return m
 			},
 			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
 				m := NewMockcartUsecase(ctrl)
+				m.EXPECT().GetCartItems(gomock.Any(), gomock.Any()).Return([]cartentity.CartItem{
+					{ID: 1, Dish: dishEntity.Dish{ID: 1, ChefID: 1}, Quantity: 1},
+					{ID: 2, Dish: dishEntity.Dish{ID: 2, ChefID: 2}, Quantity: 1},
+				}, nil)
 				return m
 			},
 			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
@@ -407,6 +551,14 @@ func TestUsecase_GetActiveOrdersByUserID(t *testing.T) {
 				m := NewMockreviewUsecase(ctrl)
 				return m
 			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				m := NewMockdeliveryUsecase(ctrl)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				return m
+			},
 			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
 				m := NewMockshiftsRepo(ctrl)
 				return m
@@ -415,34 +567,190 @@ func TestUsecase_GetActiveOrdersByUserID(t *testing.T) {
 				m := NewMockordersRepo(ctrl)
 				return m
 			},
+			args:    args{ctx: context.Background(), userID: 1, deliverySlotID: 10},
+			wantErr: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-			u := New(
-				tt.geoUsecase(ctrl),
+		{
+			name: "create order fails, cart is kept",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				m.EXPECT().GetLastUpdatedClientAddress(gomock.Any(), gomock.Any()).Return(&geoEntity.Address{}, nil)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				m.EXPECT().GetCartItems(gomock.Any(), gomock.Any()).Return([]cartentity.CartItem{{ID: 1, Quantity: 1}}, nil)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				m := NewMockdeliveryUsecase(ctrl)
+				m.EXPECT().CalculateFee(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&deliveryEntity.Quote{Fee: money.New(169_00, money.RUB)}, nil)
+				m.EXPECT().ReserveSlot(gomock.Any(), gomock.Any(), int64(10)).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				m.EXPECT().GetActiveShiftIDByChefID(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().CreateOrder(
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+				).Return(int64(0), errors.New("insert failed"))
+				return m
+			},
+			args:    args{ctx: context.Background(), userID: 1, deliverySlotID: 10},
+			wantErr: true,
+		},
+		{
+			name: "slot is already full",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				m.EXPECT().GetLastUpdatedClientAddress(gomock.Any(), gomock.Any()).Return(&geoEntity.Address{}, nil)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				m.EXPECT().GetCartItems(gomock.Any(), gomock.Any()).Return([]cartentity.CartItem{{ID: 1, Quantity: 1}}, nil)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				m := NewMockdeliveryUsecase(ctrl)
+				m.EXPECT().CalculateFee(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&deliveryEntity.Quote{Fee: money.New(169_00, money.RUB)}, nil)
+				m.EXPECT().ReserveSlot(gomock.Any(), gomock.Any(), int64(10)).Return(deliveryEntity.ErrSlotUnavailable)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				m.EXPECT().GetActiveShiftIDByChefID(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				return m
+			},
+			args:    args{ctx: context.Background(), userID: 1, deliverySlotID: 10},
+			wantErr: true,
+		},
+		{
+			name: "address is too far from chef",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				m.EXPECT().GetLastUpdatedClientAddress(gomock.Any(), gomock.Any()).Return(&geoEntity.Address{}, nil)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				m.EXPECT().GetCartItems(gomock.Any(), gomock.Any()).Return([]cartentity.CartItem{{ID: 1, Quantity: 1}}, nil)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				m := NewMockdeliveryUsecase(ctrl)
+				m.EXPECT().CalculateFee(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, deliveryEntity.ErrTooFar)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				return m
+			},
+			args:    args{ctx: context.Background(), userID: 1, deliverySlotID: 10},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(
+				tt.geoUsecase(ctrl),
 				tt.cartUsecase(ctrl),
 				tt.shiftsRepo(ctrl),
 				tt.ordersRepo(ctrl),
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				tt.delivery(ctrl),
+				tt.payments(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			got, err := u.GetActiveOrdersByUserID(tt.args.ctx, tt.args.userID)
+			got, err := u.CreateOrder(tt.args.ctx, tt.args.userID, tt.args.deliverySlotID, tt.args.leaveByTheDoor, tt.args.callBeforehand)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetActiveOrdersByUserID() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("CreateOrder() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetActiveOrdersByUserID() got = %v, want %v", got, tt.want)
+			if got != tt.want {
+				t.Errorf("CreateOrder() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestUsecase_GetCartItemsByOrderID(t *testing.T) {
+func TestUsecase_Deliver(t *testing.T) {
 	type fields struct {
 		geoUsecase    geoUsecase
 		cartUsecase   cartUsecase
@@ -463,10 +771,10 @@ func TestUsecase_GetCartItemsByOrderID(t *testing.T) {
 		dishesUsecase func(ctrl *gomock.Controller) dishesUsecase
 		chefsUsecase  func(ctrl *gomock.Controller) chefsUsecase
 		reviewUsecase func(ctrl *gomock.Controller) reviewUsecase
+		payments      func(ctrl *gomock.Controller) paymentsUsecase
 		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
 		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
 		args          args
-		want          []cartentity.CartItem
 		wantErr       bool
 	}{
 		{
@@ -491,14 +799,65 @@ func TestUsecase_GetCartItemsByOrderID(t *testing.T) {
 				m := NewMockreviewUsecase(ctrl)
 				return m
 			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]paymententity.Refund{
+					{OrderID: 1, Amount: money.New(120_00, money.RUB)},
+				}, nil)
+				m.EXPECT().RequestCapture(gomock.Any(), int64(1)).Return(nil)
+				m.EXPECT().Capture(gomock.Any(), int64(1)).Return(nil)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				m.EXPECT().AddToTotalProfit(gomock.Any(), int64(7), money.New(380_00, money.RUB)).Return(nil)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, ShiftID: 7, TotalCost: money.New(500_00, money.RUB), Status: orders.StatusInDelivery}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusInDelivery), int32(orders.StatusDelivered), gomock.Any(), "").Return(nil)
+				return m
+			},
+			args: args{ctx: context.Background(), orderID: 1},
+		},
+		{
+			name: "invalid transition",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				return m
+			},
 			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
 				m := NewMockshiftsRepo(ctrl)
 				return m
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusDelivered}, nil)
 				return m
 			},
+			args:    args{ctx: context.Background(), orderID: 1},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -513,20 +872,74 @@ func TestUsecase_GetCartItemsByOrderID(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				tt.payments(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			got, err := u.GetCartItemsByOrderID(tt.args.ctx, tt.args.orderID)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GetCartItemsByOrderID() error = %v, wantErr %v", err, tt.wantErr)
-				return
+			if err := u.Deliver(tt.args.ctx, tt.args.orderID, orders.Actor{UserID: 1, Role: orders.ActorRoleChef}); (err != nil) != tt.wantErr {
+				t.Errorf("Deliver() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetCartItemsByOrderID() got = %v, want %v", got, tt.want)
+		})
+	}
+}
+
+func TestUsecase_CloseStaleDelivery(t *testing.T) {
+	tests := []struct {
+		name       string
+		ordersRepo func(ctrl *gomock.Controller) ordersRepo
+		wantErr    error
+	}{
+		{
+			name: "order waits for manual review",
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, ShiftID: 7, Status: orders.StatusInDelivery}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusInDelivery), int32(orders.StatusDeliveryUnconfirmed), systemActor, "delivery timeout").Return(nil)
+				return m
+			},
+		},
+		{
+			name: "order delivered concurrently",
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusDelivered}, nil)
+				return m
+			},
+			wantErr: orders.ErrInvalidTransition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			// Ни списания, ни выручки смены: моки платежей и смен не ждут вызовов
+			u := New(
+				NewMockgeoUsecase(ctrl),
+				NewMockcartUsecase(ctrl),
+				NewMockshiftsRepo(ctrl),
+				tt.ordersRepo(ctrl),
+				NewMockdishesUsecase(ctrl),
+				NewMockchefsUsecase(ctrl),
+				NewMockreviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
+			)
+			err := u.CloseStaleDelivery(context.Background(), 1, "delivery timeout")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CloseStaleDelivery() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestUsecase_GetOrderByID(t *testing.T) {
+func TestUsecase_GetActiveOrdersByUserID(t *testing.T) {
 	type fields struct {
 		geoUsecase    geoUsecase
 		cartUsecase   cartUsecase
@@ -537,8 +950,8 @@ func TestUsecase_GetOrderByID(t *testing.T) {
 		ordersRepo    ordersRepo
 	}
 	type args struct {
-		ctx     context.Context
-		orderID int64
+		ctx    context.Context
+		userID int64
 	}
 	tests := []struct {
 		name          string
@@ -550,9 +963,10 @@ func TestUsecase_GetOrderByID(t *testing.T) {
 		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
 		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
 		args          args
-		want          *orders.Order
+		want          []orders.Order
 		wantErr       bool
 	}{
+
 		{
 			name: "success",
 			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
@@ -581,8 +995,13 @@ func TestUsecase_GetOrderByID(t *testing.T) {
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrdersByUserID(gomock.Any(), gomock.Any()).Return([]orders.Order{
+					{ID: 1, Status: orders.StatusAccepted},
+					{ID: 2, Status: orders.StatusDelivered},
+				}, nil)
 				return m
 			},
+			want: []orders.Order{{ID: 1, Status: orders.StatusAccepted}},
 		},
 	}
 	for _, tt := range tests {
@@ -597,20 +1016,26 @@ func TestUsecase_GetOrderByID(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			got, err := u.GetOrderByID(tt.args.ctx, tt.args.orderID)
+			got, err := u.GetActiveOrdersByUserID(tt.args.ctx, tt.args.userID)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetOrderByID() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetActiveOrdersByUserID() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetOrderByID() got = %v, want %v", got, tt.want)
+				t.Errorf("GetActiveOrdersByUserID() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestUsecase_GetOrderedDishesAndChefsByUserID(t *testing.T) {
+func TestUsecase_GetCartItemsByOrderID(t *testing.T) {
 	type fields struct {
 		geoUsecase    geoUsecase
 		cartUsecase   cartUsecase
@@ -621,8 +1046,8 @@ func TestUsecase_GetOrderedDishesAndChefsByUserID(t *testing.T) {
 		ordersRepo    ordersRepo
 	}
 	type args struct {
-		ctx    context.Context
-		userID int64
+		ctx     context.Context
+		orderID int64
 	}
 	tests := []struct {
 		name          string
@@ -634,8 +1059,7 @@ func TestUsecase_GetOrderedDishesAndChefsByUserID(t *testing.T) {
 		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
 		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
 		args          args
-		want          []dishEntity.Dish
-		want1         []chefEntity.Chef
+		want          []cartentity.CartItem
 		wantErr       bool
 	}{
 		{
@@ -666,8 +1090,10 @@ func TestUsecase_GetOrderedDishesAndChefsByUserID(t *testing.T) {
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetCartItemsByOrderID(gomock.Any(), gomock.Any()).Return([]cartentity.CartItem{{ID: 1}}, nil)
 				return m
 			},
+			want: []cartentity.CartItem{{ID: 1}},
 		},
 	}
 	for _, tt := range tests {
@@ -682,23 +1108,26 @@ func TestUsecase_GetOrderedDishesAndChefsByUserID(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			got, got1, err := u.GetOrderedDishesAndChefsByUserID(tt.args.ctx, tt.args.userID)
+			got, err := u.GetCartItemsByOrderID(tt.args.ctx, tt.args.orderID)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetOrderedDishesAndChefsByUserID() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetCartItemsByOrderID() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetOrderedDishesAndChefsByUserID() got = %v, want %v", got, tt.want)
-			}
-			if !reflect.DeepEqual(got1, tt.want1) {
-				t.Errorf("GetOrderedDishesAndChefsByUserID() got1 = %v, want %v", got1, tt.want1)
+				t.Errorf("GetCartItemsByOrderID() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestUsecase_GetOrdersByShiftID(t *testing.T) {
+func TestUsecase_GetOrderByID(t *testing.T) {
 	type fields struct {
 		geoUsecase    geoUsecase
 		cartUsecase   cartUsecase
@@ -710,7 +1139,7 @@ func TestUsecase_GetOrdersByShiftID(t *testing.T) {
 	}
 	type args struct {
 		ctx     context.Context
-		shiftID int64
+		orderID int64
 	}
 	tests := []struct {
 		name          string
@@ -722,7 +1151,7 @@ func TestUsecase_GetOrdersByShiftID(t *testing.T) {
 		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
 		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
 		args          args
-		want          []orders.Order
+		want          *orders.Order
 		wantErr       bool
 	}{
 		{
@@ -753,8 +1182,10 @@ func TestUsecase_GetOrdersByShiftID(t *testing.T) {
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), gomock.Any()).Return(&orders.Order{ID: 1}, nil)
 				return m
 			},
+			want: &orders.Order{ID: 1},
 		},
 	}
 	for _, tt := range tests {
@@ -769,20 +1200,26 @@ func TestUsecase_GetOrdersByShiftID(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			got, err := u.GetOrdersByShiftID(tt.args.ctx, tt.args.shiftID)
+			got, err := u.GetOrderByID(tt.args.ctx, tt.args.orderID)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetOrdersByShiftID() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetOrderByID() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetOrdersByShiftID() got = %v, want %v", got, tt.want)
+				t.Errorf("GetOrderByID() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestUsecase_GetOrdersByUserID(t *testing.T) {
+func TestUsecase_GetOrderedDishesAndChefsByUserID(t *testing.T) {
 	type fields struct {
 		geoUsecase    geoUsecase
 		cartUsecase   cartUsecase
@@ -806,7 +1243,8 @@ func TestUsecase_GetOrdersByUserID(t *testing.T) {
 		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
 		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
 		args          args
-		want          []orders.OrderProfile
+		want          []dishEntity.Dish
+		want1         []chefEntity.Chef
 		wantErr       bool
 	}{
 		{
@@ -837,8 +1275,11 @@ func TestUsecase_GetOrdersByUserID(t *testing.T) {
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderedDishesIDsAndChefsIDs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]int64{}, []int64{}, nil)
 				return m
 			},
+			want:  []dishEntity.Dish{},
+			want1: []chefEntity.Chef{},
 		},
 	}
 	for _, tt := range tests {
@@ -853,10 +1294,203 @@ func TestUsecase_GetOrdersByUserID(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			got, err := u.GetOrdersByUserID(tt.args.ctx, tt.args.userID)
+			got, got1, err := u.GetOrderedDishesAndChefsByUserID(tt.args.ctx, tt.args.userID)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetOrdersByUserID() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetOrderedDishesAndChefsByUserID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetOrderedDishesAndChefsByUserID() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("GetOrderedDishesAndChefsByUserID() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestUsecase_GetOrdersByShiftID(t *testing.T) {
+	type fields struct {
+		geoUsecase    geoUsecase
+		cartUsecase   cartUsecase
+		dishesUsecase dishesUsecase
+		chefsUsecase  chefsUsecase
+		reviewUsecase reviewUsecase
+		shiftsRepo    shiftsRepo
+		ordersRepo    ordersRepo
+	}
+	type args struct {
+		ctx     context.Context
+		shiftID int64
+	}
+	tests := []struct {
+		name          string
+		geoUsecase    func(ctrl *gomock.Controller) geoUsecase
+		cartUsecase   func(ctrl *gomock.Controller) cartUsecase
+		dishesUsecase func(ctrl *gomock.Controller) dishesUsecase
+		chefsUsecase  func(ctrl *gomock.Controller) chefsUsecase
+		reviewUsecase func(ctrl *gomock.Controller) reviewUsecase
+		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
+		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
+		args          args
+		want          []orders.Order
+		wantErr       bool
+	}{
+		{
+			name: "success",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrdersByShiftID(gomock.Any(), gomock.Any()).Return([]orders.Order{{ID: 1}}, nil)
+				return m
+			},
+			want: []orders.Order{{ID: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(
+				tt.geoUsecase(ctrl),
+				tt.cartUsecase(ctrl),
+				tt.shiftsRepo(ctrl),
+				tt.ordersRepo(ctrl),
+				tt.dishesUsecase(ctrl),
+				tt.chefsUsecase(ctrl),
+				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
+			)
+			got, err := u.GetOrdersByShiftID(tt.args.ctx, tt.args.shiftID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOrdersByShiftID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetOrdersByShiftID() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsecase_GetOrdersByUserID(t *testing.T) {
+	type fields struct {
+		geoUsecase    geoUsecase
+		cartUsecase   cartUsecase
+		dishesUsecase dishesUsecase
+		chefsUsecase  chefsUsecase
+		reviewUsecase reviewUsecase
+		shiftsRepo    shiftsRepo
+		ordersRepo    ordersRepo
+	}
+	type args struct {
+		ctx    context.Context
+		userID int64
+	}
+	tests := []struct {
+		name          string
+		geoUsecase    func(ctrl *gomock.Controller) geoUsecase
+		cartUsecase   func(ctrl *gomock.Controller) cartUsecase
+		dishesUsecase func(ctrl *gomock.Controller) dishesUsecase
+		chefsUsecase  func(ctrl *gomock.Controller) chefsUsecase
+		reviewUsecase func(ctrl *gomock.Controller) reviewUsecase
+		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
+		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
+		args          args
+		want          []orders.OrderProfile
+		wantErr       bool
+	}{
+		{
+			name: "success",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrdersByUserID(gomock.Any(), gomock.Any()).Return([]orders.Order{}, nil)
+				return m
+			},
+			want: []orders.OrderProfile{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(
+				tt.geoUsecase(ctrl),
+				tt.cartUsecase(ctrl),
+				tt.shiftsRepo(ctrl),
+				tt.ordersRepo(ctrl),
+				tt.dishesUsecase(ctrl),
+				tt.chefsUsecase(ctrl),
+				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
+			)
+			got, err := u.GetOrdersByUserID(tt.args.ctx, tt.args.userID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOrdersByUserID() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
@@ -921,8 +1555,10 @@ func TestUsecase_GetStatus(t *testing.T) {
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Return(int32(orders.StatusCooked), nil)
 				return m
 			},
+			want: orders.StatusCooked,
 		},
 	}
 	for _, tt := range tests {
@@ -937,6 +1573,12 @@ func TestUsecase_GetStatus(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
 			got, err := u.GetStatus(tt.args.ctx, tt.args.orderID)
 			if (err != nil) != tt.wantErr {
@@ -976,7 +1618,6 @@ func TestUsecase_PickUp(t *testing.T) {
 		args          args
 		wantErr       bool
 	}{
-
 		{
 			name: "success",
 			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
@@ -1005,8 +1646,45 @@ func TestUsecase_PickUp(t *testing.T) {
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusCooked}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusCooked), int32(orders.StatusInDelivery), gomock.Any(), "").Return(nil)
 				return m
 			},
+			args: args{ctx: context.Background(), orderID: 1},
+		},
+		{
+			name: "invalid transition",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusRejected}, nil)
+				return m
+			},
+			args:    args{ctx: context.Background(), orderID: 1},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -1021,8 +1699,14 @@ func TestUsecase_PickUp(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			if err := u.PickUp(tt.args.ctx, tt.args.orderID); (err != nil) != tt.wantErr {
+			if err := u.PickUp(tt.args.ctx, tt.args.orderID, orders.Actor{UserID: 1, Role: orders.ActorRoleChef}); (err != nil) != tt.wantErr {
 				t.Errorf("PickUp() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -1043,6 +1727,7 @@ func TestUsecase_Reject(t *testing.T) {
 		ctx     context.Context
 		orderID int64
 	}
+	slotID := int64(10)
 	tests := []struct {
 		name          string
 		geoUsecase    func(ctrl *gomock.Controller) geoUsecase
@@ -1050,6 +1735,8 @@ func TestUsecase_Reject(t *testing.T) {
 		dishesUsecase func(ctrl *gomock.Controller) dishesUsecase
 		chefsUsecase  func(ctrl *gomock.Controller) chefsUsecase
 		reviewUsecase func(ctrl *gomock.Controller) reviewUsecase
+		delivery      func(ctrl *gomock.Controller) deliveryUsecase
+		payments      func(ctrl *gomock.Controller) paymentsUsecase
 		shiftsRepo    func(ctrl *gomock.Controller) shiftsRepo
 		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
 		args          args
@@ -1077,14 +1764,114 @@ func TestUsecase_Reject(t *testing.T) {
 				m := NewMockreviewUsecase(ctrl)
 				return m
 			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				m := NewMockdeliveryUsecase(ctrl)
+				m.EXPECT().ReleaseSlot(gomock.Any(), &slotID).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().RequestRefundRemaining(gomock.Any(), int64(1), "нет ингредиентов").Return(&paymententity.Refund{OrderID: 1}, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), &paymententity.Refund{OrderID: 1}).Return(nil)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusCreated, DeliverySlotID: &slotID}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusCreated), int32(orders.StatusRejected), gomock.Any(), "нет ингредиентов").Return(nil)
+				return m
+			},
+			args: args{ctx: context.Background(), orderID: 1},
+		},
+		{
+			name: "unpaid order releases its slot",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				m := NewMockdeliveryUsecase(ctrl)
+				m.EXPECT().ReleaseSlot(gomock.Any(), &slotID).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				// Платёж в ожидании — возвращать нечего
+				m.EXPECT().RequestRefundRemaining(gomock.Any(), int64(1), "нет ингредиентов").Return(nil, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), nil).Return(nil)
+				return m
+			},
+			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
+				m := NewMockshiftsRepo(ctrl)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusAwaitingPayment, DeliverySlotID: &slotID}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusAwaitingPayment), int32(orders.StatusRejected), gomock.Any(), "нет ингредиентов").Return(nil)
+				return m
+			},
+			args: args{ctx: context.Background(), orderID: 1},
+		},
+		{
+			name: "invalid transition",
+			geoUsecase: func(ctrl *gomock.Controller) geoUsecase {
+				m := NewMockgeoUsecase(ctrl)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				return m
+			},
+			dishesUsecase: func(ctrl *gomock.Controller) dishesUsecase {
+				m := NewMockdishesUsecase(ctrl)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				return m
+			},
+			reviewUsecase: func(ctrl *gomock.Controller) reviewUsecase {
+				m := NewMockreviewUsecase(ctrl)
+				return m
+			},
+			delivery: func(ctrl *gomock.Controller) deliveryUsecase {
+				return NewMockdeliveryUsecase(ctrl)
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				return NewMockpaymentsUsecase(ctrl)
+			},
 			shiftsRepo: func(ctrl *gomock.Controller) shiftsRepo {
 				m := NewMockshiftsRepo(ctrl)
 				return m
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusDelivered}, nil)
 				return m
 			},
+			args:    args{ctx: context.Background(), orderID: 1},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -1099,8 +1886,14 @@ func TestUsecase_Reject(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				tt.delivery(ctrl),
+				tt.payments(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			if err := u.Reject(tt.args.ctx, tt.args.orderID); (err != nil) != tt.wantErr {
+			if err := u.Reject(tt.args.ctx, tt.args.orderID, orders.Actor{UserID: 1, Role: orders.ActorRoleChef}, "нет ингредиентов"); (err != nil) != tt.wantErr {
 				t.Errorf("Reject() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -1162,8 +1955,11 @@ func TestUsecase_SetStatus(t *testing.T) {
 			},
 			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
 				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusCreated}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusCreated), int32(orders.StatusAccepted), gomock.Any(), "").Return(nil)
 				return m
 			},
+			args: args{ctx: context.Background(), orderID: 1, status: orders.StatusAccepted},
 		},
 	}
 	for _, tt := range tests {
@@ -1178,10 +1974,487 @@ func TestUsecase_SetStatus(t *testing.T) {
 				tt.dishesUsecase(ctrl),
 				tt.chefsUsecase(ctrl),
 				tt.reviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
 			)
-			if err := u.SetStatus(tt.args.ctx, tt.args.orderID, tt.args.status); (err != nil) != tt.wantErr {
+			if err := u.SetStatus(tt.args.ctx, tt.args.orderID, tt.args.status, orders.Actor{Role: orders.ActorRoleSystem}); (err != nil) != tt.wantErr {
 				t.Errorf("SetStatus() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestUsecase_HandlePaymentWebhook(t *testing.T) {
+	authorized := &paymententity.Payment{OrderID: 1, UserID: 2, Status: paymententity.StatusAuthorized}
+	tests := []struct {
+		name        string
+		payments    func(ctrl *gomock.Controller) paymentsUsecase
+		cartUsecase func(ctrl *gomock.Controller) cartUsecase
+		ordersRepo  func(ctrl *gomock.Controller) ordersRepo
+		wantErr     error
+	}{
+		{
+			name: "authorized",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().HandleWebhook(gomock.Any(), []byte("{}"), "sig").Return(authorized, nil)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				m := NewMockcartUsecase(ctrl)
+				m.EXPECT().ClearCart(gomock.Any(), int64(2)).Return(nil)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetStatus(gomock.Any(), int64(1)).Return(int32(orders.StatusAwaitingPayment), nil)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusAwaitingPayment}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusAwaitingPayment), int32(orders.StatusCreated), systemActor, "").Return(nil)
+				return m
+			},
+		},
+		{
+			name: "repeated webhook",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().HandleWebhook(gomock.Any(), gomock.Any(), gomock.Any()).Return(authorized, nil)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				return NewMockcartUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetStatus(gomock.Any(), int64(1)).Return(int32(orders.StatusCreated), nil)
+				return m
+			},
+		},
+		{
+			name: "payment failed",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().HandleWebhook(gomock.Any(), gomock.Any(), gomock.Any()).Return(&paymententity.Payment{OrderID: 1, UserID: 2, Status: paymententity.StatusFailed}, nil)
+				m.EXPECT().RequestRefundRemaining(gomock.Any(), int64(1), "payment failed").Return(nil, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), nil).Return(nil)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				return NewMockcartUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetStatus(gomock.Any(), int64(1)).Return(int32(orders.StatusAwaitingPayment), nil)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusAwaitingPayment}, nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusAwaitingPayment), int32(orders.StatusRejected), systemActor, "payment failed").Return(nil)
+				return m
+			},
+		},
+		{
+			name: "invalid signature",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().HandleWebhook(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, paymententity.ErrInvalidSignature)
+				return m
+			},
+			cartUsecase: func(ctrl *gomock.Controller) cartUsecase {
+				return NewMockcartUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				return NewMockordersRepo(ctrl)
+			},
+			wantErr: paymententity.ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(
+				NewMockgeoUsecase(ctrl),
+				tt.cartUsecase(ctrl),
+				NewMockshiftsRepo(ctrl),
+				tt.ordersRepo(ctrl),
+				NewMockdishesUsecase(ctrl),
+				NewMockchefsUsecase(ctrl),
+				NewMockreviewUsecase(ctrl),
+				anySlotRelease(ctrl),
+				tt.payments(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
+			)
+			if err := u.HandlePaymentWebhook(context.Background(), []byte("{}"), "sig"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("HandlePaymentWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+const cancelGracePeriod = 5 * time.Minute
+
+// passthroughTransactor выполняет fn сразу, без реальной транзакции
+func passthroughTransactor(ctrl *gomock.Controller) transactor {
+	m := NewMocktransactor(ctrl)
+	m.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	return m
+}
+
+// anySlotRelease — освобождение слота там, где тест его не проверяет
+func anySlotRelease(ctrl *gomock.Controller) deliveryUsecase {
+	m := NewMockdeliveryUsecase(ctrl)
+	m.EXPECT().ReleaseSlot(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return m
+}
+
+// anyNotifier принимает любые уведомления о смене статуса
+func anyNotifier(ctrl *gomock.Controller) statusNotifier {
+	m := NewMockstatusNotifier(ctrl)
+	m.EXPECT().OrderStatusChanged(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	return m
+}
+
+func TestUsecase_statusNotifications(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int32
+		call       func(u *Usecase) error
+		wantNotify bool
+	}{
+		{
+			name:   "accept notifies after the change",
+			status: orders.StatusCreated,
+			call: func(u *Usecase) error {
+				return u.Accept(context.Background(), 1, orders.Actor{UserID: 5, Role: orders.ActorRoleChef})
+			},
+			wantNotify: true,
+		},
+		{
+			name:   "invalid transition is not announced",
+			status: orders.StatusDelivered,
+			call: func(u *Usecase) error {
+				return u.Accept(context.Background(), 1, orders.Actor{UserID: 5, Role: orders.ActorRoleChef})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			order := &orders.Order{ID: 1, ChefID: 2, UserID: 3, Status: tt.status}
+			repo := NewMockordersRepo(ctrl)
+			repo.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(order, nil)
+			notifier := NewMockstatusNotifier(ctrl)
+			if tt.wantNotify {
+				repo.EXPECT().ChangeStatus(gomock.Any(), int64(1), tt.status, int32(orders.StatusAccepted), gomock.Any(), "").Return(nil)
+				notifier.EXPECT().OrderStatusChanged(gomock.Any(), *order, int32(orders.StatusAccepted))
+			}
+
+			u := New(
+				NewMockgeoUsecase(ctrl),
+				NewMockcartUsecase(ctrl),
+				NewMockshiftsRepo(ctrl),
+				repo,
+				NewMockdishesUsecase(ctrl),
+				NewMockchefsUsecase(ctrl),
+				NewMockreviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				NewMockpaymentsUsecase(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				notifier,
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
+			)
+			if err := tt.call(u); (err == nil) != tt.wantNotify {
+				t.Errorf("error = %v, wantNotify %v", err, tt.wantNotify)
+			}
+		})
+	}
+}
+
+func TestUsecase_RemoveItem(t *testing.T) {
+	items := []cartentity.CartItem{
+		{ID: 11, Quantity: 2, Size: dishEntity.Size{Price: money.New(150_00, money.RUB)}},
+		{ID: 12, Quantity: 1, Size: dishEntity.Size{Price: money.New(300_00, money.RUB)}},
+	}
+	tests := []struct {
+		name       string
+		payments   func(ctrl *gomock.Controller) paymentsUsecase
+		ordersRepo func(ctrl *gomock.Controller) ordersRepo
+		itemID     int64
+		wantErr    error
+	}{
+		{
+			name: "item is refunded",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				itemID := int64(11)
+				refund := &paymententity.Refund{OrderID: 1, Status: paymententity.RefundStatusCompleted}
+				m.EXPECT().RequestRefund(gomock.Any(), int64(1), &itemID, money.New(300_00, money.RUB), "закончилась сметана").Return(refund, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), refund).Return(nil)
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByIDForUpdate(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusAccepted}, nil)
+				m.EXPECT().GetCartItemsByOrderID(gomock.Any(), int64(1)).Return(items, nil)
+				m.EXPECT().RemoveOrderedItem(gomock.Any(), int64(1), int64(11)).Return(nil)
+				return m
+			},
+			itemID: 11,
+		},
+		{
+			name: "order is in delivery",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				return NewMockpaymentsUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByIDForUpdate(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusInDelivery}, nil)
+				return m
+			},
+			itemID:  11,
+			wantErr: orders.ErrNotModifiable,
+		},
+		{
+			name: "unknown item",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				return NewMockpaymentsUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByIDForUpdate(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusCreated}, nil)
+				m.EXPECT().GetCartItemsByOrderID(gomock.Any(), int64(1)).Return(items, nil)
+				return m
+			},
+			itemID:  13,
+			wantErr: orders.ErrItemNotFound,
+		},
+		{
+			name: "last item",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				return NewMockpaymentsUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByIDForUpdate(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, Status: orders.StatusCooked}, nil)
+				m.EXPECT().GetCartItemsByOrderID(gomock.Any(), int64(1)).Return(items[:1], nil)
+				return m
+			},
+			itemID:  11,
+			wantErr: orders.ErrLastItem,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(
+				NewMockgeoUsecase(ctrl),
+				NewMockcartUsecase(ctrl),
+				NewMockshiftsRepo(ctrl),
+				tt.ordersRepo(ctrl),
+				NewMockdishesUsecase(ctrl),
+				NewMockchefsUsecase(ctrl),
+				NewMockreviewUsecase(ctrl),
+				NewMockdeliveryUsecase(ctrl),
+				tt.payments(ctrl),
+				NewMocknotificationsUsecase(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
+			)
+			err := u.RemoveItem(context.Background(), 1, tt.itemID, orders.Actor{UserID: 3, Role: orders.ActorRoleChef}, "закончилась сметана")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RemoveItem() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUsecase_Cancel(t *testing.T) {
+	now := time.Date(2024, time.March, 4, 12, 0, 0, 0, time.UTC)
+	client := orders.Actor{UserID: 2, Role: orders.ActorRoleClient}
+	history := func(acceptedAt time.Time) []orders.StatusChange {
+		return []orders.StatusChange{
+			{ToStatus: orders.StatusAwaitingPayment, CreatedAt: acceptedAt.Add(-10 * time.Minute)},
+			{ToStatus: orders.StatusCreated, CreatedAt: acceptedAt.Add(-9 * time.Minute)},
+			{ToStatus: orders.StatusAccepted, CreatedAt: acceptedAt},
+		}
+	}
+	tests := []struct {
+		name          string
+		payments      func(ctrl *gomock.Controller) paymentsUsecase
+		chefsUsecase  func(ctrl *gomock.Controller) chefsUsecase
+		notifications func(ctrl *gomock.Controller) notificationsUsecase
+		ordersRepo    func(ctrl *gomock.Controller) ordersRepo
+		wantErr       error
+	}{
+		{
+			name: "created order",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().RequestRefundRemaining(gomock.Any(), int64(1), "передумал").Return(&paymententity.Refund{OrderID: 1}, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), &paymententity.Refund{OrderID: 1}).Return(nil)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				m.EXPECT().GetUserIDByChefID(gomock.Any(), int64(3)).Return(int64(30), nil)
+				return m
+			},
+			notifications: func(ctrl *gomock.Controller) notificationsUsecase {
+				m := NewMocknotificationsUsecase(ctrl)
+				m.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, n notifications.Notification) (int, error) {
+						if n.UserID.Int64 != 30 {
+							t.Errorf("CreateNotification() user = %d, want chef's user 30", n.UserID.Int64)
+						}
+						return 1, nil
+					})
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, ChefID: 3, UserID: 2, Status: orders.StatusCreated}, nil).Times(2)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusCreated), int32(orders.StatusCancelled), client, "передумал").Return(nil)
+				return m
+			},
+		},
+		{
+			name: "accepted order within grace period",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().RequestRefundRemaining(gomock.Any(), int64(1), "передумал").Return(&paymententity.Refund{OrderID: 1}, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), &paymententity.Refund{OrderID: 1}).Return(nil)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				m.EXPECT().GetUserIDByChefID(gomock.Any(), int64(3)).Return(int64(30), nil)
+				return m
+			},
+			notifications: func(ctrl *gomock.Controller) notificationsUsecase {
+				m := NewMocknotificationsUsecase(ctrl)
+				m.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(0, errors.New("db is down"))
+				return m
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, ChefID: 3, UserID: 2, Status: orders.StatusAccepted}, nil).Times(2)
+				m.EXPECT().GetStatusHistory(gomock.Any(), int64(1)).Return(history(now.Add(-4*time.Minute)), nil)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusAccepted), int32(orders.StatusCancelled), client, "передумал").Return(nil)
+				return m
+			},
+		},
+		{
+			name: "accepted order after grace period",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				return NewMockpaymentsUsecase(ctrl)
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				return NewMockchefsUsecase(ctrl)
+			},
+			notifications: func(ctrl *gomock.Controller) notificationsUsecase {
+				return NewMocknotificationsUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, UserID: 2, Status: orders.StatusAccepted}, nil)
+				m.EXPECT().GetStatusHistory(gomock.Any(), int64(1)).Return(history(now.Add(-6*time.Minute)), nil)
+				return m
+			},
+			wantErr: orders.ErrCancelNotAllowed,
+		},
+		{
+			name: "order is cooked",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				return NewMockpaymentsUsecase(ctrl)
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				return NewMockchefsUsecase(ctrl)
+			},
+			notifications: func(ctrl *gomock.Controller) notificationsUsecase {
+				return NewMocknotificationsUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, UserID: 2, Status: orders.StatusCooked}, nil)
+				return m
+			},
+			wantErr: orders.ErrCancelNotAllowed,
+		},
+		{
+			name: "another user's order",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				return NewMockpaymentsUsecase(ctrl)
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				return NewMockchefsUsecase(ctrl)
+			},
+			notifications: func(ctrl *gomock.Controller) notificationsUsecase {
+				return NewMocknotificationsUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, UserID: 5, Status: orders.StatusCreated}, nil)
+				return m
+			},
+			wantErr: orders.ErrNotOrderOwner,
+		},
+		{
+			name: "chef without user is not notified",
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().RequestRefundRemaining(gomock.Any(), int64(1), "передумал").Return(nil, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), nil).Return(nil)
+				return m
+			},
+			chefsUsecase: func(ctrl *gomock.Controller) chefsUsecase {
+				m := NewMockchefsUsecase(ctrl)
+				m.EXPECT().GetUserIDByChefID(gomock.Any(), int64(3)).Return(int64(0), custom_errors.ErrUserNotFound)
+				return m
+			},
+			notifications: func(ctrl *gomock.Controller) notificationsUsecase {
+				return NewMocknotificationsUsecase(ctrl)
+			},
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(1)).Return(&orders.Order{ID: 1, ChefID: 3, UserID: 2, Status: orders.StatusCreated}, nil).Times(2)
+				m.EXPECT().ChangeStatus(gomock.Any(), int64(1), int32(orders.StatusCreated), int32(orders.StatusCancelled), client, "передумал").Return(nil)
+				return m
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(
+				NewMockgeoUsecase(ctrl),
+				NewMockcartUsecase(ctrl),
+				NewMockshiftsRepo(ctrl),
+				tt.ordersRepo(ctrl),
+				NewMockdishesUsecase(ctrl),
+				tt.chefsUsecase(ctrl),
+				NewMockreviewUsecase(ctrl),
+				anySlotRelease(ctrl),
+				tt.payments(ctrl),
+				tt.notifications(ctrl),
+				anyNotifier(ctrl),
+				passthroughTransactor(ctrl),
+				cancelGracePeriod,
+			)
+			u.now = func() time.Time { return now }
+			if err := u.Cancel(context.Background(), 1, client, "передумал"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Cancel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package payments
+
+import (
+	"context"
+	"time"
+
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/payments"
+)
+
+//go:generate mockgen -source=contract.go -destination contract_mocks_test.go -package $GOPACKAGE
+
+// Provider — платёжный провайдер. Реализации лежат в internal/clients/payments
+type Provider interface {
+	// Name — код провайдера, под которым хранятся его платежи
+	Name() string
+	// CreateIntent блокирует сумму заказа. Провайдер может сразу вернуть authorized,
+	// либо pending — тогда итог придёт вебхуком
+	CreateIntent(ctx context.Context, req payments.IntentRequest) (*payments.Intent, error)
+	Capture(ctx context.Context, providerPaymentID string, amount money.Money) error
+	// Cancel снимает блокировку, не списывая деньги
+	Cancel(ctx context.Context, providerPaymentID string) error
+	// Refund возвращает часть списанной суммы. Повтор с тем же idempotencyKey деньги второй раз не возвращает
+	Refund(ctx context.Context, providerPaymentID string, amount money.Money, idempotencyKey string) error
+	// VerifyWebhook проверяет подпись уведомления и разбирает его
+	VerifyWebhook(payload []byte, signature string) (*payments.Event, error)
+}
+
+type paymentsRepo interface {
+	CreatePayment(ctx context.Context, p *payments.Payment) (int64, error)
+	GetPaymentByOrderID(ctx context.Context, orderID int64) (*payments.Payment, error)
+	GetPaymentByProviderID(ctx context.Context, provider, providerPaymentID string) (*payments.Payment, error)
+	UpdatePaymentStatus(ctx context.Context, paymentID int64, from, to payments.Status) error
+	RequestCapture(ctx context.Context, paymentID int64) error
+	GetPendingCaptureOrderIDs(ctx context.Context, olderThan time.Duration, limit int) ([]int64, error)
+	CreateRefund(ctx context.Context, r *payments.Refund) (int64, error)
+	CompleteRefund(ctx context.Context, refundID int64) error
+	GetRefundsByOrderID(ctx context.Context, orderID int64) ([]payments.Refund, error)
+	GetPendingRefunds(ctx context.Context, olderThan time.Duration, limit int) ([]payments.Refund, error)
+}
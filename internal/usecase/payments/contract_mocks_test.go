@@ -0,0 +1,294 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package payments is a generated GoMock package.
+package payments
+
+import (
+	context "context"
+	money "domashka-backend/internal/entity/money"
+	payments "domashka-backend/internal/entity/payments"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProvider is a mock of Provider interface.
+type MockProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockProviderMockRecorder
+}
+
+// MockProviderMockRecorder is the mock recorder for MockProvider.
+type MockProviderMockRecorder struct {
+	mock *MockProvider
+}
+
+// NewMockProvider creates a new mock instance.
+func NewMockProvider(ctrl *gomock.Controller) *MockProvider {
+	mock := &MockProvider{ctrl: ctrl}
+	mock.recorder = &MockProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProvider) EXPECT() *MockProviderMockRecorder {
+	return m.recorder
+}
+
+// Cancel mocks base method.
+func (m *MockProvider) Cancel(ctx context.Context, providerPaymentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, providerPaymentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockProviderMockRecorder) Cancel(ctx, providerPaymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockProvider)(nil).Cancel), ctx, providerPaymentID)
+}
+
+// Capture mocks base method.
+func (m *MockProvider) Capture(ctx context.Context, providerPaymentID string, amount money.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, providerPaymentID, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockProviderMockRecorder) Capture(ctx, providerPaymentID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockProvider)(nil).Capture), ctx, providerPaymentID, amount)
+}
+
+// CreateIntent mocks base method.
+func (m *MockProvider) CreateIntent(ctx context.Context, req payments.IntentRequest) (*payments.Intent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIntent", ctx, req)
+	ret0, _ := ret[0].(*payments.Intent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIntent indicates an expected call of CreateIntent.
+func (mr *MockProviderMockRecorder) CreateIntent(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIntent", reflect.TypeOf((*MockProvider)(nil).CreateIntent), ctx, req)
+}
+
+// Name mocks base method.
+func (m *MockProvider) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockProviderMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockProvider)(nil).Name))
+}
+
+// Refund mocks base method.
+func (m *MockProvider) Refund(ctx context.Context, providerPaymentID string, amount money.Money, idempotencyKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, providerPaymentID, amount, idempotencyKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockProviderMockRecorder) Refund(ctx, providerPaymentID, amount, idempotencyKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockProvider)(nil).Refund), ctx, providerPaymentID, amount, idempotencyKey)
+}
+
+// VerifyWebhook mocks base method.
+func (m *MockProvider) VerifyWebhook(payload []byte, signature string) (*payments.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyWebhook", payload, signature)
+	ret0, _ := ret[0].(*payments.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyWebhook indicates an expected call of VerifyWebhook.
+func (mr *MockProviderMockRecorder) VerifyWebhook(payload, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWebhook", reflect.TypeOf((*MockProvider)(nil).VerifyWebhook), payload, signature)
+}
+
+// MockpaymentsRepo is a mock of paymentsRepo interface.
+type MockpaymentsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockpaymentsRepoMockRecorder
+}
+
+// MockpaymentsRepoMockRecorder is the mock recorder for MockpaymentsRepo.
+type MockpaymentsRepoMockRecorder struct {
+	mock *MockpaymentsRepo
+}
+
+// NewMockpaymentsRepo creates a new mock instance.
+func NewMockpaymentsRepo(ctrl *gomock.Controller) *MockpaymentsRepo {
+	mock := &MockpaymentsRepo{ctrl: ctrl}
+	mock.recorder = &MockpaymentsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpaymentsRepo) EXPECT() *MockpaymentsRepoMockRecorder {
+	return m.recorder
+}
+
+// CompleteRefund mocks base method.
+func (m *MockpaymentsRepo) CompleteRefund(ctx context.Context, refundID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteRefund", ctx, refundID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteRefund indicates an expected call of CompleteRefund.
+func (mr *MockpaymentsRepoMockRecorder) CompleteRefund(ctx, refundID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteRefund", reflect.TypeOf((*MockpaymentsRepo)(nil).CompleteRefund), ctx, refundID)
+}
+
+// CreatePayment mocks base method.
+func (m *MockpaymentsRepo) CreatePayment(ctx context.Context, p *payments.Payment) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePayment", ctx, p)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePayment indicates an expected call of CreatePayment.
+func (mr *MockpaymentsRepoMockRecorder) CreatePayment(ctx, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockpaymentsRepo)(nil).CreatePayment), ctx, p)
+}
+
+// CreateRefund mocks base method.
+func (m *MockpaymentsRepo) CreateRefund(ctx context.Context, r *payments.Refund) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefund", ctx, r)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefund indicates an expected call of CreateRefund.
+func (mr *MockpaymentsRepoMockRecorder) CreateRefund(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefund", reflect.TypeOf((*MockpaymentsRepo)(nil).CreateRefund), ctx, r)
+}
+
+// GetPaymentByOrderID mocks base method.
+func (m *MockpaymentsRepo) GetPaymentByOrderID(ctx context.Context, orderID int64) (*payments.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByOrderID", ctx, orderID)
+	ret0, _ := ret[0].(*payments.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByOrderID indicates an expected call of GetPaymentByOrderID.
+func (mr *MockpaymentsRepoMockRecorder) GetPaymentByOrderID(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByOrderID", reflect.TypeOf((*MockpaymentsRepo)(nil).GetPaymentByOrderID), ctx, orderID)
+}
+
+// GetPaymentByProviderID mocks base method.
+func (m *MockpaymentsRepo) GetPaymentByProviderID(ctx context.Context, provider, providerPaymentID string) (*payments.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByProviderID", ctx, provider, providerPaymentID)
+	ret0, _ := ret[0].(*payments.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByProviderID indicates an expected call of GetPaymentByProviderID.
+func (mr *MockpaymentsRepoMockRecorder) GetPaymentByProviderID(ctx, provider, providerPaymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByProviderID", reflect.TypeOf((*MockpaymentsRepo)(nil).GetPaymentByProviderID), ctx, provider, providerPaymentID)
+}
+
+// GetPendingCaptureOrderIDs mocks base method.
+func (m *MockpaymentsRepo) GetPendingCaptureOrderIDs(ctx context.Context, olderThan time.Duration, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingCaptureOrderIDs", ctx, olderThan, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingCaptureOrderIDs indicates an expected call of GetPendingCaptureOrderIDs.
+func (mr *MockpaymentsRepoMockRecorder) GetPendingCaptureOrderIDs(ctx, olderThan, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingCaptureOrderIDs", reflect.TypeOf((*MockpaymentsRepo)(nil).GetPendingCaptureOrderIDs), ctx, olderThan, limit)
+}
+
+// GetPendingRefunds mocks base method.
+func (m *MockpaymentsRepo) GetPendingRefunds(ctx context.Context, olderThan time.Duration, limit int) ([]payments.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingRefunds", ctx, olderThan, limit)
+	ret0, _ := ret[0].([]payments.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingRefunds indicates an expected call of GetPendingRefunds.
+func (mr *MockpaymentsRepoMockRecorder) GetPendingRefunds(ctx, olderThan, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingRefunds", reflect.TypeOf((*MockpaymentsRepo)(nil).GetPendingRefunds), ctx, olderThan, limit)
+}
+
+// GetRefundsByOrderID mocks base method.
+func (m *MockpaymentsRepo) GetRefundsByOrderID(ctx context.Context, orderID int64) ([]payments.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundsByOrderID", ctx, orderID)
+	ret0, _ := ret[0].([]payments.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundsByOrderID indicates an expected call of GetRefundsByOrderID.
+func (mr *MockpaymentsRepoMockRecorder) GetRefundsByOrderID(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundsByOrderID", reflect.TypeOf((*MockpaymentsRepo)(nil).GetRefundsByOrderID), ctx, orderID)
+}
+
+// RequestCapture mocks base method.
+func (m *MockpaymentsRepo) RequestCapture(ctx context.Context, paymentID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestCapture", ctx, paymentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestCapture indicates an expected call of RequestCapture.
+func (mr *MockpaymentsRepoMockRecorder) RequestCapture(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestCapture", reflect.TypeOf((*MockpaymentsRepo)(nil).RequestCapture), ctx, paymentID)
+}
+
+// UpdatePaymentStatus mocks base method.
+func (m *MockpaymentsRepo) UpdatePaymentStatus(ctx context.Context, paymentID int64, from, to payments.Status) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentStatus", ctx, paymentID, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePaymentStatus indicates an expected call of UpdatePaymentStatus.
+func (mr *MockpaymentsRepoMockRecorder) UpdatePaymentStatus(ctx, paymentID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentStatus", reflect.TypeOf((*MockpaymentsRepo)(nil).UpdatePaymentStatus), ctx, paymentID, from, to)
+}
@@ -0,0 +1,365 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/payments"
+)
+
+// methods — способы оплаты, которые поддерживает провайдер
+var methods = []payments.Method{
+	{ID: 1, Type: "CARD", Title: "Банковская карта"},
+}
+
+type Usecase struct {
+	// provider nil — приём оплаты выключен
+	provider Provider
+	repo     paymentsRepo
+}
+
+// New создаёт usecase оплаты. Без провайдера (nil) оплата не принимается:
+// Authorize ничего не блокирует, а заказы оплачиваются при получении
+func New(provider Provider, repo paymentsRepo) *Usecase {
+	return &Usecase{
+		provider: provider,
+		repo:     repo,
+	}
+}
+
+// Enabled сообщает, принимается ли оплата через провайдера
+func (u *Usecase) Enabled() bool {
+	return u.provider != nil
+}
+
+func (u *Usecase) GetMethods() []payments.Method {
+	if !u.Enabled() {
+		return []payments.Method{}
+	}
+	return methods
+}
+
+// Authorize блокирует сумму заказа у провайдера и сохраняет платёж.
+// Если провайдер отказал, возвращает payments.ErrDeclined.
+// Если оплата выключена, ничего не делает и возвращает nil.
+func (u *Usecase) Authorize(ctx context.Context, orderID, userID int64, amount money.Money) (*payments.Payment, error) {
+	if !u.Enabled() {
+		return nil, nil
+	}
+	intent, err := u.provider.CreateIntent(ctx, payments.IntentRequest{
+		OrderID:        orderID,
+		UserID:         userID,
+		Amount:         amount,
+		IdempotencyKey: "order-" + strconv.FormatInt(orderID, 10),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create payment intent: %w", err)
+	}
+
+	payment := &payments.Payment{
+		OrderID:           orderID,
+		UserID:            userID,
+		Provider:          u.provider.Name(),
+		ProviderPaymentID: intent.ProviderPaymentID,
+		Amount:            amount,
+		Status:            intent.Status,
+	}
+	payment.ID, err = u.repo.CreatePayment(ctx, payment)
+	if err != nil {
+		err = fmt.Errorf("save payment: %w", err)
+		// Без записи о платеже блокировку уже не снять и не списать — отменяем её сразу
+		if intent.Status == payments.StatusAuthorized || intent.Status == payments.StatusPending {
+			if cancelErr := u.provider.Cancel(ctx, intent.ProviderPaymentID); cancelErr != nil {
+				return nil, errors.Join(err, fmt.Errorf("cancel payment intent: %w", cancelErr))
+			}
+		}
+		return nil, err
+	}
+	if payment.Status == payments.StatusFailed {
+		return payment, payments.ErrDeclined
+	}
+	return payment, nil
+}
+
+// HandleWebhook применяет уведомление провайдера к платежу и возвращает платёж в новом статусе.
+// Повторное уведомление о том же статусе ничего не меняет.
+func (u *Usecase) HandleWebhook(ctx context.Context, payload []byte, signature string) (*payments.Payment, error) {
+	if !u.Enabled() {
+		return nil, payments.ErrDisabled
+	}
+	event, err := u.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return nil, err
+	}
+	payment, err := u.repo.GetPaymentByProviderID(ctx, u.provider.Name(), event.ProviderPaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == event.Status {
+		return payment, nil
+	}
+	if err := u.setStatus(ctx, payment, event.Status); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// RequestCapture отмечает, что деньги за заказ нужно списать. Вызывается в транзакции доставки заказа,
+// само списание делает Capture после её фиксации. Заказы, оформленные до появления оплаты,
+// платежа не имеют — для них ничего не делается.
+func (u *Usecase) RequestCapture(ctx context.Context, orderID int64) error {
+	payment, err := u.repo.GetPaymentByOrderID(ctx, orderID)
+	if errors.Is(err, payments.ErrPaymentNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if payment.Status == payments.StatusCaptured {
+		return nil
+	}
+	if !payments.CanTransition(payment.Status, payments.StatusCaptured) {
+		return fmt.Errorf("capture payment %d in status %s", payment.ID, payment.Status)
+	}
+	return u.repo.RequestCapture(ctx, payment.ID)
+}
+
+// Capture списывает заблокированную сумму заказа у провайдера.
+// Вызывается вне транзакции: если списание не прошло, его повторит RetryPending.
+func (u *Usecase) Capture(ctx context.Context, orderID int64) error {
+	payment, err := u.repo.GetPaymentByOrderID(ctx, orderID)
+	if errors.Is(err, payments.ErrPaymentNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if payment.Status == payments.StatusCaptured {
+		return nil
+	}
+	if !payments.CanTransition(payment.Status, payments.StatusCaptured) {
+		return fmt.Errorf("capture payment %d in status %s", payment.ID, payment.Status)
+	}
+	// Позиции, за которые уже вернули деньги, не списываются
+	remaining, err := u.remainingAmount(ctx, payment)
+	if err != nil {
+		return err
+	}
+	if !u.Enabled() {
+		return payments.ErrDisabled
+	}
+	if err := u.provider.Capture(ctx, payment.ProviderPaymentID, remaining); err != nil {
+		return fmt.Errorf("capture payment: %w", err)
+	}
+	return u.setStatus(ctx, payment, payments.StatusCaptured)
+}
+
+// RequestRefund сохраняет возврат amount по заказу, например за убранную из заказа позицию.
+// Вызывается в транзакции вместе с изменением заказа, к провайдеру не обращается —
+// это делает CompleteRefund после фиксации. Пока деньги только заблокированы, частичный возврат
+// просто уменьшает будущее списание и сразу считается выполненным.
+// У заказов без платежа возвращать нечего — вернётся nil.
+func (u *Usecase) RequestRefund(ctx context.Context, orderID int64, orderedItemID *int64, amount money.Money, reason string) (*payments.Refund, error) {
+	payment, err := u.repo.GetPaymentByOrderID(ctx, orderID)
+	if errors.Is(err, payments.ErrPaymentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := u.remainingAmount(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	return u.requestRefund(ctx, payment, remaining, orderedItemID, amount, reason)
+}
+
+// RequestRefundRemaining сохраняет возврат всего, что ещё не вернули по заказу, например при отклонении заказа.
+// Как и RequestRefund, вызывается в транзакции. Если платежа нет, он не прошёл или уже отменён,
+// ничего не делает и возвращает nil.
+func (u *Usecase) RequestRefundRemaining(ctx context.Context, orderID int64, reason string) (*payments.Refund, error) {
+	payment, err := u.repo.GetPaymentByOrderID(ctx, orderID)
+	if errors.Is(err, payments.ErrPaymentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != payments.StatusAuthorized && payment.Status != payments.StatusCaptured {
+		return nil, nil
+	}
+	remaining, err := u.remainingAmount(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	if remaining.IsZero() {
+		return nil, nil
+	}
+	return u.requestRefund(ctx, payment, remaining, nil, remaining, reason)
+}
+
+// CompleteRefund проводит сохранённый возврат у провайдера: снимает блокировку, если возвращается
+// весь остаток ещё не списанного платежа, либо возвращает уже списанные деньги.
+// Вызывается вне транзакции; если не получилось, возврат повторит RetryPending.
+func (u *Usecase) CompleteRefund(ctx context.Context, refund *payments.Refund) error {
+	if refund == nil || refund.Status == payments.RefundStatusCompleted {
+		return nil
+	}
+	payment, err := u.repo.GetPaymentByOrderID(ctx, refund.OrderID)
+	if err != nil {
+		return err
+	}
+	if !u.Enabled() {
+		return payments.ErrDisabled
+	}
+	// Сам возврат уже учтён в остатке
+	remaining, err := u.remainingAmount(ctx, payment)
+	if err != nil {
+		return err
+	}
+	full := remaining.IsZero()
+
+	switch payment.Status {
+	case payments.StatusAuthorized:
+		if full {
+			if err := u.provider.Cancel(ctx, payment.ProviderPaymentID); err != nil {
+				return fmt.Errorf("cancel payment: %w", err)
+			}
+			if err := u.setStatus(ctx, payment, payments.StatusCancelled); err != nil {
+				return err
+			}
+		}
+	case payments.StatusCaptured:
+		idempotencyKey := "refund-" + strconv.FormatInt(refund.ID, 10)
+		if err := u.provider.Refund(ctx, payment.ProviderPaymentID, refund.Amount, idempotencyKey); err != nil {
+			return fmt.Errorf("refund payment: %w", err)
+		}
+		if full {
+			if err := u.setStatus(ctx, payment, payments.StatusRefunded); err != nil {
+				return err
+			}
+		}
+	case payments.StatusCancelled, payments.StatusRefunded:
+		// Провайдер провёл возврат в прошлой попытке, не успели только отметить его
+	default:
+		return fmt.Errorf("payment %d in status %s: %w", payment.ID, payment.Status, payments.ErrNotRefundable)
+	}
+
+	if err := u.repo.CompleteRefund(ctx, refund.ID); err != nil {
+		return fmt.Errorf("complete refund: %w", err)
+	}
+	refund.Status = payments.RefundStatusCompleted
+	return nil
+}
+
+// RetryPending повторяет возвраты и списания, которые не удалось провести сразу после фиксации заказа.
+// Берутся только запрошенные больше olderThan назад, чтобы не пересечься с первой попыткой.
+func (u *Usecase) RetryPending(ctx context.Context, olderThan time.Duration, limit int) error {
+	refunds, err := u.repo.GetPendingRefunds(ctx, olderThan, limit)
+	if err != nil {
+		return fmt.Errorf("get pending refunds: %w", err)
+	}
+	var errs []error
+	for i := range refunds {
+		if err := u.CompleteRefund(ctx, &refunds[i]); err != nil {
+			errs = append(errs, fmt.Errorf("refund %d: %w", refunds[i].ID, err))
+		}
+	}
+
+	orderIDs, err := u.repo.GetPendingCaptureOrderIDs(ctx, olderThan, limit)
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("get pending captures: %w", err))...)
+	}
+	for _, orderID := range orderIDs {
+		if err := u.Capture(ctx, orderID); err != nil {
+			errs = append(errs, fmt.Errorf("capture order %d: %w", orderID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (u *Usecase) GetRefundsByOrderID(ctx context.Context, orderID int64) ([]payments.Refund, error) {
+	return u.repo.GetRefundsByOrderID(ctx, orderID)
+}
+
+func (u *Usecase) requestRefund(
+	ctx context.Context,
+	payment *payments.Payment,
+	remaining money.Money,
+	orderedItemID *int64,
+	amount money.Money,
+	reason string,
+) (*payments.Refund, error) {
+	tooLarge, err := remaining.Less(amount)
+	if err != nil {
+		return nil, err
+	}
+	if tooLarge {
+		return nil, fmt.Errorf("refund %s of remaining %s: %w", amount, remaining, payments.ErrRefundTooLarge)
+	}
+	partial, err := amount.Less(remaining)
+	if err != nil {
+		return nil, err
+	}
+	full := !partial
+
+	status := payments.RefundStatusPending
+	switch payment.Status {
+	case payments.StatusAuthorized:
+		if !full {
+			status = payments.RefundStatusCompleted
+		}
+	case payments.StatusCaptured:
+	default:
+		return nil, fmt.Errorf("payment %d in status %s: %w", payment.ID, payment.Status, payments.ErrNotRefundable)
+	}
+
+	refund := &payments.Refund{
+		OrderID:       payment.OrderID,
+		PaymentID:     payment.ID,
+		OrderedItemID: orderedItemID,
+		Amount:        amount,
+		Reason:        reason,
+		Status:        status,
+	}
+	refund.ID, err = u.repo.CreateRefund(ctx, refund)
+	if err != nil {
+		return nil, fmt.Errorf("save refund: %w", err)
+	}
+	return refund, nil
+}
+
+// remainingAmount — сумма платежа за вычетом уже сделанных возвратов
+func (u *Usecase) remainingAmount(ctx context.Context, payment *payments.Payment) (money.Money, error) {
+	refunds, err := u.repo.GetRefundsByOrderID(ctx, payment.OrderID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	remaining := payment.Amount
+	for _, r := range refunds {
+		remaining, err = remaining.Sub(r.Amount)
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+	return remaining, nil
+}
+
+func (u *Usecase) GetByOrderID(ctx context.Context, orderID int64) (*payments.Payment, error) {
+	return u.repo.GetPaymentByOrderID(ctx, orderID)
+}
+
+func (u *Usecase) setStatus(ctx context.Context, payment *payments.Payment, to payments.Status) error {
+	if !payments.CanTransition(payment.Status, to) {
+		return fmt.Errorf("payment %d: %s -> %s: %w", payment.ID, payment.Status, to, payments.ErrStatusConflict)
+	}
+	if err := u.repo.UpdatePaymentStatus(ctx, payment.ID, payment.Status, to); err != nil {
+		return err
+	}
+	payment.Status = to
+	return nil
+}
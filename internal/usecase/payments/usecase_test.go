@@ -0,0 +1,688 @@
+package payments
+
+import (
+	"context"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/payments"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUsecase_Authorize(t *testing.T) {
+	amount := money.New(669_00, money.RUB)
+	tests := []struct {
+		name     string
+		provider func(ctrl *gomock.Controller) Provider
+		repo     func(ctrl *gomock.Controller) paymentsRepo
+		want     *payments.Payment
+		wantErr  error
+	}{
+		{
+			name: "authorized",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().CreateIntent(gomock.Any(), payments.IntentRequest{
+					OrderID:        1,
+					UserID:         2,
+					Amount:         amount,
+					IdempotencyKey: "order-1",
+				}).Return(&payments.Intent{ProviderPaymentID: "pay_1", Status: payments.StatusAuthorized}, nil)
+				m.EXPECT().Name().Return("fake")
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).Return(int64(10), nil)
+				return m
+			},
+			want: &payments.Payment{
+				ID:                10,
+				OrderID:           1,
+				UserID:            2,
+				Provider:          "fake",
+				ProviderPaymentID: "pay_1",
+				Amount:            amount,
+				Status:            payments.StatusAuthorized,
+			},
+		},
+		{
+			name: "declined",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().CreateIntent(gomock.Any(), gomock.Any()).Return(&payments.Intent{ProviderPaymentID: "pay_1", Status: payments.StatusFailed}, nil)
+				m.EXPECT().Name().Return("fake")
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).Return(int64(10), nil)
+				return m
+			},
+			want: &payments.Payment{
+				ID:                10,
+				OrderID:           1,
+				UserID:            2,
+				Provider:          "fake",
+				ProviderPaymentID: "pay_1",
+				Amount:            amount,
+				Status:            payments.StatusFailed,
+			},
+			wantErr: payments.ErrDeclined,
+		},
+		{
+			name: "provider unavailable",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().CreateIntent(gomock.Any(), gomock.Any()).Return(nil, errors.New("timeout"))
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				return NewMockpaymentsRepo(ctrl)
+			},
+			wantErr: errors.New("create payment intent: timeout"),
+		},
+		{
+			name: "save failed, intent is cancelled",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().CreateIntent(gomock.Any(), gomock.Any()).Return(&payments.Intent{ProviderPaymentID: "pay_1", Status: payments.StatusAuthorized}, nil)
+				m.EXPECT().Name().Return("fake")
+				m.EXPECT().Cancel(gomock.Any(), "pay_1").Return(nil)
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db is down"))
+				return m
+			},
+			wantErr: errors.New("save payment: db is down"),
+		},
+		{
+			name: "save failed, declined intent is not cancelled",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().CreateIntent(gomock.Any(), gomock.Any()).Return(&payments.Intent{ProviderPaymentID: "pay_1", Status: payments.StatusFailed}, nil)
+				m.EXPECT().Name().Return("fake")
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db is down"))
+				return m
+			},
+			wantErr: errors.New("save payment: db is down"),
+		},
+		{
+			name: "payments disabled",
+			provider: func(ctrl *gomock.Controller) Provider {
+				return nil
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				return NewMockpaymentsRepo(ctrl)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(tt.provider(ctrl), tt.repo(ctrl))
+			got, err := u.Authorize(context.Background(), 1, 2, amount)
+			if (err != nil) != (tt.wantErr != nil) ||
+				err != nil && !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+				t.Errorf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Authorize() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsecase_HandleWebhook(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider func(ctrl *gomock.Controller) Provider
+		repo     func(ctrl *gomock.Controller) paymentsRepo
+		want     payments.Status
+		wantErr  bool
+	}{
+		{
+			name: "pending becomes authorized",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().VerifyWebhook([]byte("{}"), "sig").Return(&payments.Event{ProviderPaymentID: "pay_1", Status: payments.StatusAuthorized}, nil)
+				m.EXPECT().Name().Return("fake")
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByProviderID(gomock.Any(), "fake", "pay_1").Return(&payments.Payment{ID: 10, Status: payments.StatusPending}, nil)
+				m.EXPECT().UpdatePaymentStatus(gomock.Any(), int64(10), payments.StatusPending, payments.StatusAuthorized).Return(nil)
+				return m
+			},
+			want: payments.StatusAuthorized,
+		},
+		{
+			name: "repeated event",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().VerifyWebhook(gomock.Any(), gomock.Any()).Return(&payments.Event{ProviderPaymentID: "pay_1", Status: payments.StatusAuthorized}, nil)
+				m.EXPECT().Name().Return("fake")
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByProviderID(gomock.Any(), "fake", "pay_1").Return(&payments.Payment{ID: 10, Status: payments.StatusAuthorized}, nil)
+				return m
+			},
+			want: payments.StatusAuthorized,
+		},
+		{
+			name: "invalid transition",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().VerifyWebhook(gomock.Any(), gomock.Any()).Return(&payments.Event{ProviderPaymentID: "pay_1", Status: payments.StatusAuthorized}, nil)
+				m.EXPECT().Name().Return("fake")
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByProviderID(gomock.Any(), "fake", "pay_1").Return(&payments.Payment{ID: 10, Status: payments.StatusFailed}, nil)
+				return m
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid signature",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().VerifyWebhook(gomock.Any(), gomock.Any()).Return(nil, payments.ErrInvalidSignature)
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				return NewMockpaymentsRepo(ctrl)
+			},
+			wantErr: true,
+		},
+		{
+			name: "payments disabled",
+			provider: func(ctrl *gomock.Controller) Provider {
+				return nil
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				return NewMockpaymentsRepo(ctrl)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(tt.provider(ctrl), tt.repo(ctrl))
+			got, err := u.HandleWebhook(context.Background(), []byte("{}"), "sig")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HandleWebhook() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Status != tt.want {
+				t.Errorf("HandleWebhook() status = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsecase_Capture(t *testing.T) {
+	amount := money.New(669_00, money.RUB)
+	tests := []struct {
+		name     string
+		provider func(ctrl *gomock.Controller) Provider
+		repo     func(ctrl *gomock.Controller) paymentsRepo
+		wantErr  bool
+	}{
+		{
+			name: "success",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().Capture(gomock.Any(), "pay_1", amount).Return(nil)
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(&payments.Payment{ID: 10, OrderID: 1, ProviderPaymentID: "pay_1", Amount: amount, Status: payments.StatusAuthorized}, nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return(nil, nil)
+				m.EXPECT().UpdatePaymentStatus(gomock.Any(), int64(10), payments.StatusAuthorized, payments.StatusCaptured).Return(nil)
+				return m
+			},
+		},
+		{
+			name: "refunded items are not captured",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().Capture(gomock.Any(), "pay_1", money.New(469_00, money.RUB)).Return(nil)
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(&payments.Payment{ID: 10, OrderID: 1, ProviderPaymentID: "pay_1", Amount: amount, Status: payments.StatusAuthorized}, nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: money.New(200_00, money.RUB)}}, nil)
+				m.EXPECT().UpdatePaymentStatus(gomock.Any(), int64(10), payments.StatusAuthorized, payments.StatusCaptured).Return(nil)
+				return m
+			},
+		},
+		{
+			name: "order without payment",
+			provider: func(ctrl *gomock.Controller) Provider {
+				return NewMockProvider(ctrl)
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(nil, payments.ErrPaymentNotFound)
+				return m
+			},
+		},
+		{
+			name: "payment is not authorized",
+			provider: func(ctrl *gomock.Controller) Provider {
+				return NewMockProvider(ctrl)
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(&payments.Payment{ID: 10, Status: payments.StatusPending}, nil)
+				return m
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(tt.provider(ctrl), tt.repo(ctrl))
+			if err := u.Capture(context.Background(), 1); (err != nil) != tt.wantErr {
+				t.Errorf("Capture() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUsecase_RequestCapture(t *testing.T) {
+	amount := money.New(669_00, money.RUB)
+	tests := []struct {
+		name    string
+		repo    func(ctrl *gomock.Controller) paymentsRepo
+		wantErr bool
+	}{
+		{
+			name: "authorized payment",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(&payments.Payment{ID: 10, OrderID: 1, Amount: amount, Status: payments.StatusAuthorized}, nil)
+				m.EXPECT().RequestCapture(gomock.Any(), int64(10)).Return(nil)
+				return m
+			},
+		},
+		{
+			name: "already captured",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(&payments.Payment{ID: 10, OrderID: 1, Amount: amount, Status: payments.StatusCaptured}, nil)
+				return m
+			},
+		},
+		{
+			name: "order without payment",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(nil, payments.ErrPaymentNotFound)
+				return m
+			},
+		},
+		{
+			name: "payment is not authorized",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(&payments.Payment{ID: 10, Status: payments.StatusCancelled}, nil)
+				return m
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(NewMockProvider(ctrl), tt.repo(ctrl))
+			if err := u.RequestCapture(context.Background(), 1); (err != nil) != tt.wantErr {
+				t.Errorf("RequestCapture() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUsecase_RequestRefund(t *testing.T) {
+	amount := money.New(669_00, money.RUB)
+	payment := func(status payments.Status) *payments.Payment {
+		return &payments.Payment{ID: 10, OrderID: 1, ProviderPaymentID: "pay_1", Amount: amount, Status: status}
+	}
+	itemID := int64(5)
+	refund := func(status payments.RefundStatus) *payments.Refund {
+		return &payments.Refund{
+			ID:            3,
+			OrderID:       1,
+			PaymentID:     10,
+			OrderedItemID: &itemID,
+			Amount:        money.New(200_00, money.RUB),
+			Reason:        "нет в наличии",
+			Status:        status,
+		}
+	}
+	tests := []struct {
+		name    string
+		repo    func(ctrl *gomock.Controller) paymentsRepo
+		amount  money.Money
+		want    *payments.Refund
+		wantErr error
+	}{
+		{
+			name: "partial refund of authorized payment is completed at once",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(payment(payments.StatusAuthorized), nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return(nil, nil)
+				m.EXPECT().CreateRefund(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+				return m
+			},
+			amount: money.New(200_00, money.RUB),
+			want:   refund(payments.RefundStatusCompleted),
+		},
+		{
+			name: "rest of authorized payment waits for cancel",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(payment(payments.StatusAuthorized), nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: money.New(469_00, money.RUB)}}, nil)
+				m.EXPECT().CreateRefund(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+				return m
+			},
+			amount: money.New(200_00, money.RUB),
+			want:   refund(payments.RefundStatusPending),
+		},
+		{
+			name: "captured payment waits for provider refund",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(payment(payments.StatusCaptured), nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return(nil, nil)
+				m.EXPECT().CreateRefund(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+				return m
+			},
+			amount: money.New(200_00, money.RUB),
+			want:   refund(payments.RefundStatusPending),
+		},
+		{
+			name: "more than remaining",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(payment(payments.StatusAuthorized), nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: money.New(500_00, money.RUB)}}, nil)
+				return m
+			},
+			amount:  money.New(200_00, money.RUB),
+			wantErr: payments.ErrRefundTooLarge,
+		},
+		{
+			name: "failed payment",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(payment(payments.StatusFailed), nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return(nil, nil)
+				return m
+			},
+			amount:  money.New(200_00, money.RUB),
+			wantErr: payments.ErrNotRefundable,
+		},
+		{
+			name: "order without payment",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(nil, payments.ErrPaymentNotFound)
+				return m
+			},
+			amount: money.New(200_00, money.RUB),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			// К провайдеру запрос возврата не обращается
+			u := New(NewMockProvider(ctrl), tt.repo(ctrl))
+			got, err := u.RequestRefund(context.Background(), 1, &itemID, tt.amount, "нет в наличии")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequestRefund() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RequestRefund() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsecase_RequestRefundRemaining(t *testing.T) {
+	amount := money.New(669_00, money.RUB)
+	tests := []struct {
+		name string
+		repo func(ctrl *gomock.Controller) paymentsRepo
+		want *payments.Refund
+	}{
+		{
+			name: "rest of authorized payment",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(&payments.Payment{ID: 10, OrderID: 1, ProviderPaymentID: "pay_1", Amount: amount, Status: payments.StatusAuthorized}, nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: money.New(200_00, money.RUB)}}, nil)
+				m.EXPECT().CreateRefund(gomock.Any(), gomock.Any()).Return(int64(4), nil)
+				return m
+			},
+			want: &payments.Refund{
+				ID:        4,
+				OrderID:   1,
+				PaymentID: 10,
+				Amount:    money.New(469_00, money.RUB),
+				Reason:    "повар отклонил заказ",
+				Status:    payments.RefundStatusPending,
+			},
+		},
+		{
+			name: "nothing left to refund",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(&payments.Payment{ID: 10, OrderID: 1, Amount: amount, Status: payments.StatusCaptured}, nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: amount}}, nil)
+				return m
+			},
+		},
+		{
+			name: "failed payment is skipped",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(&payments.Payment{ID: 10, OrderID: 1, Amount: amount, Status: payments.StatusFailed}, nil)
+				return m
+			},
+		},
+		{
+			name: "order without payment",
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(nil, payments.ErrPaymentNotFound)
+				return m
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(NewMockProvider(ctrl), tt.repo(ctrl))
+			got, err := u.RequestRefundRemaining(context.Background(), 1, "повар отклонил заказ")
+			if err != nil {
+				t.Errorf("RequestRefundRemaining() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RequestRefundRemaining() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsecase_CompleteRefund(t *testing.T) {
+	amount := money.New(669_00, money.RUB)
+	payment := func(status payments.Status) *payments.Payment {
+		return &payments.Payment{ID: 10, OrderID: 1, ProviderPaymentID: "pay_1", Amount: amount, Status: status}
+	}
+	pending := func(value money.Money) *payments.Refund {
+		return &payments.Refund{ID: 3, OrderID: 1, PaymentID: 10, Amount: value, Status: payments.RefundStatusPending}
+	}
+	tests := []struct {
+		name     string
+		provider func(ctrl *gomock.Controller) Provider
+		repo     func(ctrl *gomock.Controller) paymentsRepo
+		refund   *payments.Refund
+		wantErr  bool
+	}{
+		{
+			name: "rest of authorized payment cancels it",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().Cancel(gomock.Any(), "pay_1").Return(nil)
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(payment(payments.StatusAuthorized), nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: amount}}, nil)
+				m.EXPECT().UpdatePaymentStatus(gomock.Any(), int64(10), payments.StatusAuthorized, payments.StatusCancelled).Return(nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), int64(3)).Return(nil)
+				return m
+			},
+			refund: pending(amount),
+		},
+		{
+			name: "captured payment is refunded by provider",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().Refund(gomock.Any(), "pay_1", money.New(200_00, money.RUB), "refund-3").Return(nil)
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(payment(payments.StatusCaptured), nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: money.New(200_00, money.RUB)}}, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), int64(3)).Return(nil)
+				return m
+			},
+			refund: pending(money.New(200_00, money.RUB)),
+		},
+		{
+			name: "provider already cancelled the payment",
+			provider: func(ctrl *gomock.Controller) Provider {
+				return NewMockProvider(ctrl)
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(payment(payments.StatusCancelled), nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: amount}}, nil)
+				m.EXPECT().CompleteRefund(gomock.Any(), int64(3)).Return(nil)
+				return m
+			},
+			refund: pending(amount),
+		},
+		{
+			name: "provider error keeps refund pending",
+			provider: func(ctrl *gomock.Controller) Provider {
+				m := NewMockProvider(ctrl)
+				m.EXPECT().Cancel(gomock.Any(), "pay_1").Return(errors.New("timeout"))
+				return m
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				m := NewMockpaymentsRepo(ctrl)
+				m.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).Return(payment(payments.StatusAuthorized), nil)
+				m.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: amount}}, nil)
+				return m
+			},
+			refund:  pending(amount),
+			wantErr: true,
+		},
+		{
+			name: "completed refund",
+			provider: func(ctrl *gomock.Controller) Provider {
+				return NewMockProvider(ctrl)
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				return NewMockpaymentsRepo(ctrl)
+			},
+			refund: &payments.Refund{ID: 3, OrderID: 1, Amount: amount, Status: payments.RefundStatusCompleted},
+		},
+		{
+			name: "nothing to refund",
+			provider: func(ctrl *gomock.Controller) Provider {
+				return NewMockProvider(ctrl)
+			},
+			repo: func(ctrl *gomock.Controller) paymentsRepo {
+				return NewMockpaymentsRepo(ctrl)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u := New(tt.provider(ctrl), tt.repo(ctrl))
+			err := u.CompleteRefund(context.Background(), tt.refund)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompleteRefund() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && tt.refund != nil && tt.refund.Status != payments.RefundStatusCompleted {
+				t.Errorf("CompleteRefund() refund status = %s, want %s", tt.refund.Status, payments.RefundStatusCompleted)
+			}
+		})
+	}
+}
+
+func TestUsecase_RetryPending(t *testing.T) {
+	amount := money.New(669_00, money.RUB)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := NewMockProvider(ctrl)
+	provider.EXPECT().Cancel(gomock.Any(), "pay_1").Return(nil)
+	provider.EXPECT().Capture(gomock.Any(), "pay_2", amount).Return(errors.New("timeout"))
+
+	repo := NewMockpaymentsRepo(ctrl)
+	repo.EXPECT().GetPendingRefunds(gomock.Any(), time.Minute, 100).
+		Return([]payments.Refund{{ID: 3, OrderID: 1, Amount: amount, Status: payments.RefundStatusPending}}, nil)
+	repo.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(1)).
+		Return(&payments.Payment{ID: 10, OrderID: 1, ProviderPaymentID: "pay_1", Amount: amount, Status: payments.StatusAuthorized}, nil)
+	repo.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(1)).Return([]payments.Refund{{Amount: amount}}, nil)
+	repo.EXPECT().UpdatePaymentStatus(gomock.Any(), int64(10), payments.StatusAuthorized, payments.StatusCancelled).Return(nil)
+	repo.EXPECT().CompleteRefund(gomock.Any(), int64(3)).Return(nil)
+	repo.EXPECT().GetPendingCaptureOrderIDs(gomock.Any(), time.Minute, 100).Return([]int64{2}, nil)
+	repo.EXPECT().GetPaymentByOrderID(gomock.Any(), int64(2)).
+		Return(&payments.Payment{ID: 20, OrderID: 2, ProviderPaymentID: "pay_2", Amount: amount, Status: payments.StatusAuthorized}, nil)
+	repo.EXPECT().GetRefundsByOrderID(gomock.Any(), int64(2)).Return(nil, nil)
+
+	u := New(provider, repo)
+	// Неудавшееся списание не мешает провести возврат и возвращается ошибкой
+	if err := u.RetryPending(context.Background(), time.Minute, 100); err == nil {
+		t.Errorf("RetryPending() error = nil, want capture error")
+	}
+}
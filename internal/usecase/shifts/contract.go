@@ -2,7 +2,9 @@ package shifts
 
 import (
 	"context"
+	"time"
 
+	"domashka-backend/internal/entity/money"
 	"domashka-backend/internal/entity/shifts"
 )
 
@@ -13,4 +15,5 @@ type ShiftsRepo interface {
 	OpenShift(ctx context.Context, chefID int64) error
 	CloseActiveShiftByChefID(ctx context.Context, chefID int64) error
 	GetDailyProfits(ctx context.Context, id int64) ([]shifts.DailyProfit, error)
+	GetProfitSince(ctx context.Context, chefID int64, since time.Time) (money.Money, error)
 }
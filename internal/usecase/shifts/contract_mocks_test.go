@@ -6,8 +6,10 @@ package shifts
 
 import (
 	context "context"
+	money "domashka-backend/internal/entity/money"
 	shifts "domashka-backend/internal/entity/shifts"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -64,6 +66,36 @@ func (mr *MockShiftsRepoMockRecorder) GetActiveShiftByChefID(ctx, chefID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveShiftByChefID", reflect.TypeOf((*MockShiftsRepo)(nil).GetActiveShiftByChefID), ctx, chefID)
 }
 
+// GetDailyProfits mocks base method.
+func (m *MockShiftsRepo) GetDailyProfits(ctx context.Context, id int64) ([]shifts.DailyProfit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDailyProfits", ctx, id)
+	ret0, _ := ret[0].([]shifts.DailyProfit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDailyProfits indicates an expected call of GetDailyProfits.
+func (mr *MockShiftsRepoMockRecorder) GetDailyProfits(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailyProfits", reflect.TypeOf((*MockShiftsRepo)(nil).GetDailyProfits), ctx, id)
+}
+
+// GetProfitSince mocks base method.
+func (m *MockShiftsRepo) GetProfitSince(ctx context.Context, chefID int64, since time.Time) (money.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfitSince", ctx, chefID, since)
+	ret0, _ := ret[0].(money.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfitSince indicates an expected call of GetProfitSince.
+func (mr *MockShiftsRepoMockRecorder) GetProfitSince(ctx, chefID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfitSince", reflect.TypeOf((*MockShiftsRepo)(nil).GetProfitSince), ctx, chefID, since)
+}
+
 // OpenShift mocks base method.
 func (m *MockShiftsRepo) OpenShift(ctx context.Context, chefID int64) error {
 	m.ctrl.T.Helper()
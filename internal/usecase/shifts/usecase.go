@@ -2,16 +2,19 @@ package shifts
 
 import (
 	"context"
+	"time"
 
+	"domashka-backend/internal/entity/money"
 	"domashka-backend/internal/entity/shifts"
 )
 
 type Usecase struct {
 	repo ShiftsRepo
+	now  func() time.Time
 }
 
 func New(repo ShiftsRepo) *Usecase {
-	return &Usecase{repo: repo}
+	return &Usecase{repo: repo, now: time.Now}
 }
 
 func (u *Usecase) GetActiveShiftByChefID(ctx context.Context, chefID int64) (*shifts.Shift, error) {
@@ -29,3 +32,10 @@ func (u *Usecase) CloseShift(ctx context.Context, chefID int64) error {
 func (u *Usecase) GetDailyProfits(ctx context.Context, chefID int64) ([]shifts.DailyProfit, error) {
 	return u.repo.GetDailyProfits(ctx, chefID)
 }
+
+// GetTodayProfit возвращает выручку за сегодня: открытой смены и смен, закрытых с начала дня
+func (u *Usecase) GetTodayProfit(ctx context.Context, chefID int64) (money.Money, error) {
+	now := u.now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return u.repo.GetProfitSince(ctx, chefID, startOfDay)
+}
@@ -2,10 +2,13 @@ package shifts
 
 import (
 	"context"
+	"domashka-backend/internal/entity/money"
 	"domashka-backend/internal/entity/shifts"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestUsecase_CloseShift(t *testing.T) {
@@ -119,3 +122,18 @@ func TestUsecase_OpenShift(t *testing.T) {
 		})
 	}
 }
+
+func TestUsecase_GetTodayProfit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	now := time.Date(2024, 3, 8, 15, 30, 0, 0, time.Local)
+
+	repo := NewMockShiftsRepo(ctrl)
+	repo.EXPECT().GetProfitSince(gomock.Any(), int64(7), time.Date(2024, 3, 8, 0, 0, 0, 0, time.Local)).
+		Return(money.New(150000, money.RUB), nil)
+
+	u := New(repo)
+	u.now = func() time.Time { return now }
+	got, err := u.GetTodayProfit(context.Background(), 7)
+	require.NoError(t, err)
+	require.Equal(t, money.New(150000, money.RUB), got)
+}
@@ -65,21 +65,6 @@ func (mr *MockredisClientMockRecorder) Get(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockredisClient)(nil).Get), key)
 }
 
-// IsExpired mocks base method.
-func (m *MockredisClient) IsExpired(key string) (bool, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsExpired", key)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// IsExpired indicates an expected call of IsExpired.
-func (mr *MockredisClientMockRecorder) IsExpired(key interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsExpired", reflect.TypeOf((*MockredisClient)(nil).IsExpired), key)
-}
-
 // Set mocks base method.
 func (m *MockredisClient) Set(key, value string, ttl time.Duration) error {
 	m.ctrl.T.Helper()
@@ -94,44 +79,6 @@ func (mr *MockredisClientMockRecorder) Set(key, value, ttl interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockredisClient)(nil).Set), key, value, ttl)
 }
 
-// MockjwtUsecase is a mock of jwtUsecase interface.
-type MockjwtUsecase struct {
-	ctrl     *gomock.Controller
-	recorder *MockjwtUsecaseMockRecorder
-}
-
-// MockjwtUsecaseMockRecorder is the mock recorder for MockjwtUsecase.
-type MockjwtUsecaseMockRecorder struct {
-	mock *MockjwtUsecase
-}
-
-// NewMockjwtUsecase creates a new mock instance.
-func NewMockjwtUsecase(ctrl *gomock.Controller) *MockjwtUsecase {
-	mock := &MockjwtUsecase{ctrl: ctrl}
-	mock.recorder = &MockjwtUsecaseMockRecorder{mock}
-	return mock
-}
-
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockjwtUsecase) EXPECT() *MockjwtUsecaseMockRecorder {
-	return m.recorder
-}
-
-// GenerateJWT mocks base method.
-func (m *MockjwtUsecase) GenerateJWT(userID int64, chefID *int64, role string) (string, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateJWT", userID, chefID, role)
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GenerateJWT indicates an expected call of GenerateJWT.
-func (mr *MockjwtUsecaseMockRecorder) GenerateJWT(userID, chefID, role interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateJWT", reflect.TypeOf((*MockjwtUsecase)(nil).GenerateJWT), userID, chefID, role)
-}
-
 // MockusersRepo is a mock of usersRepo interface.
 type MockusersRepo struct {
 	ctrl     *gomock.Controller
@@ -169,6 +116,21 @@ func (mr *MockusersRepoMockRecorder) Create(ctx, user interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockusersRepo)(nil).Create), ctx, user)
 }
 
+// GetByChatID mocks base method.
+func (m *MockusersRepo) GetByChatID(ctx context.Context, chatID string) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByChatID", ctx, chatID)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByChatID indicates an expected call of GetByChatID.
+func (mr *MockusersRepoMockRecorder) GetByChatID(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByChatID", reflect.TypeOf((*MockusersRepo)(nil).GetByChatID), ctx, chatID)
+}
+
 // GetByPhone mocks base method.
 func (m *MockusersRepo) GetByPhone(ctx context.Context, phone string) (*users.User, error) {
 	m.ctrl.T.Helper()
@@ -3,110 +3,141 @@ package tg
 import (
 	"context"
 	"domashka-backend/internal/custom_errors"
+	"domashka-backend/internal/entity/auth"
 	usersentity "domashka-backend/internal/entity/users"
+	"encoding/json"
+	"errors"
 	tele "gopkg.in/telebot.v4"
 	"strconv"
-	"time"
 )
 
 type UseCase struct {
-	Redis      redisClient
-	usersRepo  usersRepo
-	jwtUsecase jwtUsecase
+	Redis     redisClient
+	usersRepo usersRepo
 }
 
-func New(redis redisClient, usersRepo usersRepo, jwtUsecase jwtUsecase) *UseCase {
+func New(redis redisClient, usersRepo usersRepo) *UseCase {
 	return &UseCase{
-		Redis:      redis,
-		usersRepo:  usersRepo,
-		jwtUsecase: jwtUsecase,
+		Redis:     redis,
+		usersRepo: usersRepo,
 	}
 }
 
-func (u *UseCase) HandleContact(c tele.Context, contact *tele.Contact) error {
-	method, err := u.Redis.Get("tg_auth:" + contact.PhoneNumber)
+// StartLogin привязывает вход из ссылки /start <nonce> к пользователю Telegram. Если его чат
+// уже привязан к аккаунту, вход сразу подтверждается; иначе возвращает false — нужен контакт
+func (u *UseCase) StartLogin(ctx context.Context, nonce string, telegramID int64) (bool, error) {
+	login, err := u.getLogin(nonce)
 	if err != nil {
-		return err
+		return false, err
 	}
-	if method == "" {
-		return custom_errors.ErrPhoneNumberMismatch
+	if login.TelegramID != 0 && login.TelegramID != telegramID {
+		return false, custom_errors.ErrTgLoginTaken
 	}
+	login.TelegramID = telegramID
 
-	// Номер совпадает, вызываем нужный метод
-	switch method {
-	case "register":
-		return u.tgRegister(contact)
-	case "login":
-		return u.tgLogin(contact)
-	default:
-		return custom_errors.ErrPhoneNumberMismatch
+	user, err := u.usersRepo.GetByChatID(ctx, strconv.FormatInt(telegramID, 10))
+	if err != nil && !errors.Is(err, custom_errors.ErrUserNotFound) {
+		return false, err
+	}
+	if user != nil {
+		login.UserID = user.ID
+		return true, u.saveLogin(nonce, login)
 	}
-}
 
-func (u *UseCase) tgLogin(contact *tele.Contact) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	if err := u.saveLogin(nonce, login); err != nil {
+		return false, err
+	}
+	return false, u.Redis.Set(auth.TgLoginPendingKey(telegramID), nonce, auth.TgLoginTTL)
+}
 
-	user, err := u.usersRepo.GetByPhone(ctx, contact.PhoneNumber)
+// HandleContact подтверждает вход, ожидающий контакта: находит аккаунт по номеру или создаёт новый
+// и привязывает к нему чат. Что контакт принадлежит отправителю, проверяет обработчик бота
+func (u *UseCase) HandleContact(ctx context.Context, contact *tele.Contact) error {
+	pendingKey := auth.TgLoginPendingKey(contact.UserID)
+	nonce, err := u.Redis.Get(pendingKey)
 	if err != nil {
 		return err
 	}
-
-	user.Name = contact.FirstName
-	if contact.LastName != "" {
-		user.Name += " " + contact.LastName
+	if nonce == "" {
+		return custom_errors.ErrExpiredTTL
 	}
-	user.ChatID = strconv.FormatInt(contact.UserID, 10)
-
-	if err := u.usersRepo.Update(ctx, user.ID, *user); err != nil {
+	login, err := u.getLogin(nonce)
+	if err != nil {
 		return err
 	}
+	if login.TelegramID != contact.UserID {
+		return custom_errors.ErrTgLoginTaken
+	}
 
-	user, err = u.usersRepo.GetByPhone(ctx, contact.PhoneNumber)
-	if err != nil {
+	user, err := u.usersRepo.GetByPhone(ctx, contact.PhoneNumber)
+	if err != nil && !errors.Is(err, custom_errors.ErrUserNotFound) {
 		return err
 	}
-	jwt, err := u.jwtUsecase.GenerateJWT(user.ID, nil, "user")
+	if user == nil {
+		user, err = u.tgRegister(ctx, contact)
+	} else {
+		user, err = u.tgLogin(ctx, user, contact)
+	}
 	if err != nil {
 		return err
 	}
 
-	if err := u.Redis.Set("token:"+contact.PhoneNumber, jwt, 24*time.Hour); err != nil {
+	login.UserID = user.ID
+	if err := u.saveLogin(nonce, login); err != nil {
 		return err
 	}
-
-	return u.Redis.Delete("tg_auth:" + contact.PhoneNumber)
+	return u.Redis.Delete(pendingKey)
 }
 
-func (u *UseCase) tgRegister(contact *tele.Contact) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (u *UseCase) tgLogin(ctx context.Context, user *usersentity.User, contact *tele.Contact) (*usersentity.User, error) {
+	user.Name = contactName(contact)
+	user.ChatID = strconv.FormatInt(contact.UserID, 10)
+
+	if err := u.usersRepo.Update(ctx, user.ID, *user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
 
+func (u *UseCase) tgRegister(ctx context.Context, contact *tele.Contact) (*usersentity.User, error) {
 	user := &usersentity.User{
-		Name:        contact.FirstName,
+		Name:        contactName(contact),
 		ChatID:      strconv.FormatInt(contact.UserID, 10),
 		NumberPhone: &contact.PhoneNumber,
 	}
-	if contact.LastName != "" {
-		user.Name += " " + contact.LastName
-	}
 
 	if err := u.usersRepo.Create(ctx, user); err != nil {
-		return err
+		return nil, err
 	}
-	user, err := u.usersRepo.GetByPhone(ctx, contact.PhoneNumber)
+	return u.usersRepo.GetByPhone(ctx, contact.PhoneNumber)
+}
+
+func (u *UseCase) getLogin(nonce string) (*auth.TgLogin, error) {
+	raw, err := u.Redis.Get(auth.TgLoginKey(nonce))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if raw == "" {
+		return nil, custom_errors.ErrExpiredTTL
 	}
+	var login auth.TgLogin
+	if err := json.Unmarshal([]byte(raw), &login); err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
 
-	jwt, err := u.jwtUsecase.GenerateJWT(user.ID, nil, "user")
+func (u *UseCase) saveLogin(nonce string, login *auth.TgLogin) error {
+	data, err := json.Marshal(login)
 	if err != nil {
 		return err
 	}
+	return u.Redis.Set(auth.TgLoginKey(nonce), string(data), auth.TgLoginTTL)
+}
 
-	if err := u.Redis.Set("token:"+contact.PhoneNumber, jwt, 24*time.Hour); err != nil {
-		return err
+func contactName(contact *tele.Contact) string {
+	if contact.LastName != "" {
+		return contact.FirstName + " " + contact.LastName
 	}
-
-	return u.Redis.Delete("tg_auth:" + contact.PhoneNumber)
+	return contact.FirstName
 }
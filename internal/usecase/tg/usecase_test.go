@@ -1,177 +1,181 @@
 package tg
 
 import (
+	"context"
+	"domashka-backend/internal/custom_errors"
+	"domashka-backend/internal/entity/auth"
 	"domashka-backend/internal/entity/users"
+	"encoding/json"
+	"errors"
 	"github.com/golang/mock/gomock"
 	"gopkg.in/telebot.v4"
 	"testing"
 )
 
-func TestUseCase_HandleContact(t *testing.T) {
-	type fields struct {
-		Redis      redisClient
-		usersRepo  usersRepo
-		jwtUsecase jwtUsecase
-	}
-	type args struct {
-		c       telebot.Context
-		contact *telebot.Contact
+const testNonce = "nonce"
+
+func loginJSON(t *testing.T, login auth.TgLogin) string {
+	t.Helper()
+	data, err := json.Marshal(login)
+	if err != nil {
+		t.Fatal(err)
 	}
+	return string(data)
+}
+
+func TestUseCase_StartLogin(t *testing.T) {
 	tests := []struct {
-		name       string
-		redis      func(ctrl *gomock.Controller) redisClient
-		usersRepo  func(ctrl *gomock.Controller) usersRepo
-		jwtUsecase func(ctrl *gomock.Controller) jwtUsecase
-		args       args
-		wantErr    bool
+		name          string
+		redis         func(t *testing.T, ctrl *gomock.Controller) redisClient
+		usersRepo     func(ctrl *gomock.Controller) usersRepo
+		wantConfirmed bool
+		wantErr       error
 	}{
 		{
-			name: "success",
-			redis: func(ctrl *gomock.Controller) redisClient {
+			name: "bound chat confirms login",
+			redis: func(t *testing.T, ctrl *gomock.Controller) redisClient {
 				m := NewMockredisClient(ctrl)
-				m.EXPECT().Get(gomock.Any()).Return("login", nil)
-				m.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				m.EXPECT().Delete(gomock.Any()).Return(nil)
+				m.EXPECT().Get(auth.TgLoginKey(testNonce)).Return(loginJSON(t, auth.TgLogin{SecretHash: "h"}), nil)
+				m.EXPECT().Set(auth.TgLoginKey(testNonce), loginJSON(t, auth.TgLogin{SecretHash: "h", TelegramID: 42, UserID: 7}), auth.TgLoginTTL).Return(nil)
 				return m
 			},
 			usersRepo: func(ctrl *gomock.Controller) usersRepo {
 				m := NewMockusersRepo(ctrl)
-				m.EXPECT().GetByPhone(gomock.Any(), gomock.Any()).Return(&users.User{}, nil)
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-
-				m.EXPECT().GetByPhone(gomock.Any(), gomock.Any()).Return(&users.User{}, nil)
-				return m
-			},
-			jwtUsecase: func(ctrl *gomock.Controller) jwtUsecase {
-				m := NewMockjwtUsecase(ctrl)
-				m.EXPECT().GenerateJWT(gomock.Any(), gomock.Any(), gomock.Any()).Return("", nil)
+				m.EXPECT().GetByChatID(gomock.Any(), "42").Return(&users.User{ID: 7}, nil)
 				return m
 			},
-			args: args{
-				contact: &telebot.Contact{},
-			},
-			wantErr: false,
+			wantConfirmed: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-			u := New(tt.redis(ctrl), tt.usersRepo(ctrl), tt.jwtUsecase(ctrl))
-			if err := u.HandleContact(tt.args.c, tt.args.contact); (err != nil) != tt.wantErr {
-				t.Errorf("HandleContact() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestUseCase_tgLogin(t *testing.T) {
-	type fields struct {
-		Redis      redisClient
-		usersRepo  usersRepo
-		jwtUsecase jwtUsecase
-	}
-	type args struct {
-		contact *telebot.Contact
-	}
-	tests := []struct {
-		name       string
-		redis      func(ctrl *gomock.Controller) redisClient
-		usersRepo  func(ctrl *gomock.Controller) usersRepo
-		jwtUsecase func(ctrl *gomock.Controller) jwtUsecase
-		args       args
-		wantErr    bool
-	}{
 		{
-			name: "success",
-			redis: func(ctrl *gomock.Controller) redisClient {
+			name: "unknown chat waits for contact",
+			redis: func(t *testing.T, ctrl *gomock.Controller) redisClient {
 				m := NewMockredisClient(ctrl)
-				m.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				m.EXPECT().Delete(gomock.Any()).Return(nil)
+				m.EXPECT().Get(auth.TgLoginKey(testNonce)).Return(loginJSON(t, auth.TgLogin{SecretHash: "h"}), nil)
+				m.EXPECT().Set(auth.TgLoginKey(testNonce), loginJSON(t, auth.TgLogin{SecretHash: "h", TelegramID: 42}), auth.TgLoginTTL).Return(nil)
+				m.EXPECT().Set(auth.TgLoginPendingKey(42), testNonce, auth.TgLoginTTL).Return(nil)
 				return m
 			},
 			usersRepo: func(ctrl *gomock.Controller) usersRepo {
 				m := NewMockusersRepo(ctrl)
-				m.EXPECT().GetByPhone(gomock.Any(), gomock.Any()).Return(&users.User{}, nil)
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-
-				m.EXPECT().GetByPhone(gomock.Any(), gomock.Any()).Return(&users.User{}, nil)
+				m.EXPECT().GetByChatID(gomock.Any(), "42").Return(nil, custom_errors.ErrUserNotFound)
 				return m
 			},
-			jwtUsecase: func(ctrl *gomock.Controller) jwtUsecase {
-				m := NewMockjwtUsecase(ctrl)
-				m.EXPECT().GenerateJWT(gomock.Any(), gomock.Any(), gomock.Any()).Return("", nil)
+		},
+		{
+			name: "expired link",
+			redis: func(t *testing.T, ctrl *gomock.Controller) redisClient {
+				m := NewMockredisClient(ctrl)
+				m.EXPECT().Get(auth.TgLoginKey(testNonce)).Return("", nil)
 				return m
 			},
-			args: args{
-				contact: &telebot.Contact{},
+			usersRepo: func(ctrl *gomock.Controller) usersRepo { return NewMockusersRepo(ctrl) },
+			wantErr:   custom_errors.ErrExpiredTTL,
+		},
+		{
+			name: "link opened by another telegram user",
+			redis: func(t *testing.T, ctrl *gomock.Controller) redisClient {
+				m := NewMockredisClient(ctrl)
+				m.EXPECT().Get(auth.TgLoginKey(testNonce)).Return(loginJSON(t, auth.TgLogin{SecretHash: "h", TelegramID: 99}), nil)
+				return m
 			},
-			wantErr: false,
+			usersRepo: func(ctrl *gomock.Controller) usersRepo { return NewMockusersRepo(ctrl) },
+			wantErr:   custom_errors.ErrTgLoginTaken,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.redis(ctrl), tt.usersRepo(ctrl), tt.jwtUsecase(ctrl))
-			if err := u.tgLogin(tt.args.contact); (err != nil) != tt.wantErr {
-				t.Errorf("tgLogin() error = %v, wantErr %v", err, tt.wantErr)
+			u := New(tt.redis(t, ctrl), tt.usersRepo(ctrl))
+			confirmed, err := u.StartLogin(context.Background(), testNonce, 42)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("StartLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if confirmed != tt.wantConfirmed {
+				t.Errorf("StartLogin() confirmed = %v, want %v", confirmed, tt.wantConfirmed)
 			}
 		})
 	}
 }
 
-func TestUseCase_tgRegister(t *testing.T) {
-	type fields struct {
-		Redis      redisClient
-		usersRepo  usersRepo
-		jwtUsecase jwtUsecase
-	}
-	type args struct {
-		contact *telebot.Contact
-	}
+func TestUseCase_HandleContact(t *testing.T) {
+	contact := &telebot.Contact{PhoneNumber: "79991234567", FirstName: "Иван", UserID: 42}
 	tests := []struct {
-		name       string
-		redis      func(ctrl *gomock.Controller) redisClient
-		usersRepo  func(ctrl *gomock.Controller) usersRepo
-		jwtUsecase func(ctrl *gomock.Controller) jwtUsecase
-		args       args
-		wantErr    bool
+		name      string
+		redis     func(t *testing.T, ctrl *gomock.Controller) redisClient
+		usersRepo func(ctrl *gomock.Controller) usersRepo
+		wantErr   error
 	}{
 		{
-			name: "success",
-			redis: func(ctrl *gomock.Controller) redisClient {
+			name: "existing user",
+			redis: func(t *testing.T, ctrl *gomock.Controller) redisClient {
 				m := NewMockredisClient(ctrl)
-				m.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				m.EXPECT().Delete(gomock.Any()).Return(nil)
-
+				m.EXPECT().Get(auth.TgLoginPendingKey(42)).Return(testNonce, nil)
+				m.EXPECT().Get(auth.TgLoginKey(testNonce)).Return(loginJSON(t, auth.TgLogin{SecretHash: "h", TelegramID: 42}), nil)
+				m.EXPECT().Set(auth.TgLoginKey(testNonce), loginJSON(t, auth.TgLogin{SecretHash: "h", TelegramID: 42, UserID: 7}), auth.TgLoginTTL).Return(nil)
+				m.EXPECT().Delete(auth.TgLoginPendingKey(42)).Return(nil)
 				return m
 			},
 			usersRepo: func(ctrl *gomock.Controller) usersRepo {
 				m := NewMockusersRepo(ctrl)
-				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
-				m.EXPECT().GetByPhone(gomock.Any(), gomock.Any()).Return(&users.User{}, nil)
+				m.EXPECT().GetByPhone(gomock.Any(), "79991234567").Return(&users.User{ID: 7}, nil)
+				m.EXPECT().Update(gomock.Any(), int64(7), gomock.Any()).Return(nil)
 				return m
 			},
-			jwtUsecase: func(ctrl *gomock.Controller) jwtUsecase {
-				m := NewMockjwtUsecase(ctrl)
-				m.EXPECT().GenerateJWT(gomock.Any(), gomock.Any(), gomock.Any()).Return("", nil)
+		},
+		{
+			name: "new user",
+			redis: func(t *testing.T, ctrl *gomock.Controller) redisClient {
+				m := NewMockredisClient(ctrl)
+				m.EXPECT().Get(auth.TgLoginPendingKey(42)).Return(testNonce, nil)
+				m.EXPECT().Get(auth.TgLoginKey(testNonce)).Return(loginJSON(t, auth.TgLogin{SecretHash: "h", TelegramID: 42}), nil)
+				m.EXPECT().Set(auth.TgLoginKey(testNonce), loginJSON(t, auth.TgLogin{SecretHash: "h", TelegramID: 42, UserID: 8}), auth.TgLoginTTL).Return(nil)
+				m.EXPECT().Delete(auth.TgLoginPendingKey(42)).Return(nil)
 				return m
 			},
-			args: args{
-				contact: &telebot.Contact{},
+			usersRepo: func(ctrl *gomock.Controller) usersRepo {
+				m := NewMockusersRepo(ctrl)
+				m.EXPECT().GetByPhone(gomock.Any(), "79991234567").Return(nil, custom_errors.ErrUserNotFound)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().GetByPhone(gomock.Any(), "79991234567").Return(&users.User{ID: 8}, nil)
+				return m
+			},
+		},
+		{
+			name: "no login waiting for contact",
+			redis: func(t *testing.T, ctrl *gomock.Controller) redisClient {
+				m := NewMockredisClient(ctrl)
+				m.EXPECT().Get(auth.TgLoginPendingKey(42)).Return("", nil)
+				return m
 			},
-			wantErr: false,
+			usersRepo: func(ctrl *gomock.Controller) usersRepo { return NewMockusersRepo(ctrl) },
+			wantErr:   custom_errors.ErrExpiredTTL,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			u := New(tt.redis(ctrl), tt.usersRepo(ctrl), tt.jwtUsecase(ctrl))
-			if err := u.tgRegister(tt.args.contact); (err != nil) != tt.wantErr {
-				t.Errorf("tgRegister() error = %v, wantErr %v", err, tt.wantErr)
+			u := New(tt.redis(t, ctrl), tt.usersRepo(ctrl))
+			if err := u.HandleContact(context.Background(), contact); !errors.Is(err, tt.wantErr) {
+				t.Errorf("HandleContact() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestUseCase_tgLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := NewMockusersRepo(ctrl)
+	repo.EXPECT().Update(gomock.Any(), int64(7), users.User{ID: 7, Name: "Иван Петров", ChatID: "42"}).Return(nil)
+
+	u := New(NewMockredisClient(ctrl), repo)
+	user, err := u.tgLogin(context.Background(), &users.User{ID: 7}, &telebot.Contact{FirstName: "Иван", LastName: "Петров", UserID: 42})
+	if err != nil {
+		t.Fatalf("tgLogin() error = %v", err)
+	}
+	if user.ChatID != "42" {
+		t.Errorf("tgLogin() chat_id = %q, want %q", user.ChatID, "42")
+	}
+}
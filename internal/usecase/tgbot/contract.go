@@ -0,0 +1,44 @@
+package tgbot
+
+import (
+	"context"
+
+	tele "gopkg.in/telebot.v4"
+
+	cartentity "domashka-backend/internal/entity/cart"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/orders"
+	"domashka-backend/internal/entity/shifts"
+	usersentity "domashka-backend/internal/entity/users"
+)
+
+//go:generate mockgen -source=contract.go -destination contract_mocks_test.go -package $GOPACKAGE
+
+// messenger отправляет сообщения в Telegram; его реализует *tele.Bot
+type messenger interface {
+	Send(to tele.Recipient, what interface{}, opts ...interface{}) (*tele.Message, error)
+}
+
+type usersRepo interface {
+	GetByChatID(ctx context.Context, chatID string) (*usersentity.User, error)
+	GetChatIDByUserID(ctx context.Context, userID int64) (string, error)
+	GetChatIDByChefID(ctx context.Context, chefID int64) (string, error)
+	CheckIfUserIsChef(ctx context.Context, userID int64) (*int64, bool, error)
+}
+
+type ordersRepo interface {
+	GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cartentity.CartItem, error)
+}
+
+type orderUsecase interface {
+	GetOrderByID(ctx context.Context, orderID int64) (*orders.Order, error)
+	Accept(ctx context.Context, orderID int64, actor orders.Actor) error
+	Reject(ctx context.Context, orderID int64, actor orders.Actor, reason string) error
+}
+
+type shiftsUsecase interface {
+	GetActiveShiftByChefID(ctx context.Context, chefID int64) (*shifts.Shift, error)
+	OpenShift(ctx context.Context, chefID int64) error
+	CloseShift(ctx context.Context, chefID int64) error
+	GetTodayProfit(ctx context.Context, chefID int64) (money.Money, error)
+}
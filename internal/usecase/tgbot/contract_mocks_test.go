@@ -0,0 +1,330 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package tgbot is a generated GoMock package.
+package tgbot
+
+import (
+	context "context"
+	cart "domashka-backend/internal/entity/cart"
+	money "domashka-backend/internal/entity/money"
+	orders "domashka-backend/internal/entity/orders"
+	shifts "domashka-backend/internal/entity/shifts"
+	users "domashka-backend/internal/entity/users"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	telebot "gopkg.in/telebot.v4"
+)
+
+// Mockmessenger is a mock of messenger interface.
+type Mockmessenger struct {
+	ctrl     *gomock.Controller
+	recorder *MockmessengerMockRecorder
+}
+
+// MockmessengerMockRecorder is the mock recorder for Mockmessenger.
+type MockmessengerMockRecorder struct {
+	mock *Mockmessenger
+}
+
+// NewMockmessenger creates a new mock instance.
+func NewMockmessenger(ctrl *gomock.Controller) *Mockmessenger {
+	mock := &Mockmessenger{ctrl: ctrl}
+	mock.recorder = &MockmessengerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockmessenger) EXPECT() *MockmessengerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *Mockmessenger) Send(to telebot.Recipient, what interface{}, opts ...interface{}) (*telebot.Message, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{to, what}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Send", varargs...)
+	ret0, _ := ret[0].(*telebot.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockmessengerMockRecorder) Send(to, what interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{to, what}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*Mockmessenger)(nil).Send), varargs...)
+}
+
+// MockusersRepo is a mock of usersRepo interface.
+type MockusersRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockusersRepoMockRecorder
+}
+
+// MockusersRepoMockRecorder is the mock recorder for MockusersRepo.
+type MockusersRepoMockRecorder struct {
+	mock *MockusersRepo
+}
+
+// NewMockusersRepo creates a new mock instance.
+func NewMockusersRepo(ctrl *gomock.Controller) *MockusersRepo {
+	mock := &MockusersRepo{ctrl: ctrl}
+	mock.recorder = &MockusersRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockusersRepo) EXPECT() *MockusersRepoMockRecorder {
+	return m.recorder
+}
+
+// CheckIfUserIsChef mocks base method.
+func (m *MockusersRepo) CheckIfUserIsChef(ctx context.Context, userID int64) (*int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckIfUserIsChef", ctx, userID)
+	ret0, _ := ret[0].(*int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CheckIfUserIsChef indicates an expected call of CheckIfUserIsChef.
+func (mr *MockusersRepoMockRecorder) CheckIfUserIsChef(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIfUserIsChef", reflect.TypeOf((*MockusersRepo)(nil).CheckIfUserIsChef), ctx, userID)
+}
+
+// GetByChatID mocks base method.
+func (m *MockusersRepo) GetByChatID(ctx context.Context, chatID string) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByChatID", ctx, chatID)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByChatID indicates an expected call of GetByChatID.
+func (mr *MockusersRepoMockRecorder) GetByChatID(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByChatID", reflect.TypeOf((*MockusersRepo)(nil).GetByChatID), ctx, chatID)
+}
+
+// GetChatIDByChefID mocks base method.
+func (m *MockusersRepo) GetChatIDByChefID(ctx context.Context, chefID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatIDByChefID", ctx, chefID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatIDByChefID indicates an expected call of GetChatIDByChefID.
+func (mr *MockusersRepoMockRecorder) GetChatIDByChefID(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatIDByChefID", reflect.TypeOf((*MockusersRepo)(nil).GetChatIDByChefID), ctx, chefID)
+}
+
+// GetChatIDByUserID mocks base method.
+func (m *MockusersRepo) GetChatIDByUserID(ctx context.Context, userID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatIDByUserID", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatIDByUserID indicates an expected call of GetChatIDByUserID.
+func (mr *MockusersRepoMockRecorder) GetChatIDByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatIDByUserID", reflect.TypeOf((*MockusersRepo)(nil).GetChatIDByUserID), ctx, userID)
+}
+
+// MockordersRepo is a mock of ordersRepo interface.
+type MockordersRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockordersRepoMockRecorder
+}
+
+// MockordersRepoMockRecorder is the mock recorder for MockordersRepo.
+type MockordersRepoMockRecorder struct {
+	mock *MockordersRepo
+}
+
+// NewMockordersRepo creates a new mock instance.
+func NewMockordersRepo(ctrl *gomock.Controller) *MockordersRepo {
+	mock := &MockordersRepo{ctrl: ctrl}
+	mock.recorder = &MockordersRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockordersRepo) EXPECT() *MockordersRepoMockRecorder {
+	return m.recorder
+}
+
+// GetCartItemsByOrderID mocks base method.
+func (m *MockordersRepo) GetCartItemsByOrderID(ctx context.Context, orderID int64) ([]cart.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCartItemsByOrderID", ctx, orderID)
+	ret0, _ := ret[0].([]cart.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCartItemsByOrderID indicates an expected call of GetCartItemsByOrderID.
+func (mr *MockordersRepoMockRecorder) GetCartItemsByOrderID(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItemsByOrderID", reflect.TypeOf((*MockordersRepo)(nil).GetCartItemsByOrderID), ctx, orderID)
+}
+
+// MockorderUsecase is a mock of orderUsecase interface.
+type MockorderUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockorderUsecaseMockRecorder
+}
+
+// MockorderUsecaseMockRecorder is the mock recorder for MockorderUsecase.
+type MockorderUsecaseMockRecorder struct {
+	mock *MockorderUsecase
+}
+
+// NewMockorderUsecase creates a new mock instance.
+func NewMockorderUsecase(ctrl *gomock.Controller) *MockorderUsecase {
+	mock := &MockorderUsecase{ctrl: ctrl}
+	mock.recorder = &MockorderUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockorderUsecase) EXPECT() *MockorderUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Accept mocks base method.
+func (m *MockorderUsecase) Accept(ctx context.Context, orderID int64, actor orders.Actor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accept", ctx, orderID, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Accept indicates an expected call of Accept.
+func (mr *MockorderUsecaseMockRecorder) Accept(ctx, orderID, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockorderUsecase)(nil).Accept), ctx, orderID, actor)
+}
+
+// GetOrderByID mocks base method.
+func (m *MockorderUsecase) GetOrderByID(ctx context.Context, orderID int64) (*orders.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByID", ctx, orderID)
+	ret0, _ := ret[0].(*orders.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByID indicates an expected call of GetOrderByID.
+func (mr *MockorderUsecaseMockRecorder) GetOrderByID(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByID", reflect.TypeOf((*MockorderUsecase)(nil).GetOrderByID), ctx, orderID)
+}
+
+// Reject mocks base method.
+func (m *MockorderUsecase) Reject(ctx context.Context, orderID int64, actor orders.Actor, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reject", ctx, orderID, actor, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reject indicates an expected call of Reject.
+func (mr *MockorderUsecaseMockRecorder) Reject(ctx, orderID, actor, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reject", reflect.TypeOf((*MockorderUsecase)(nil).Reject), ctx, orderID, actor, reason)
+}
+
+// MockshiftsUsecase is a mock of shiftsUsecase interface.
+type MockshiftsUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockshiftsUsecaseMockRecorder
+}
+
+// MockshiftsUsecaseMockRecorder is the mock recorder for MockshiftsUsecase.
+type MockshiftsUsecaseMockRecorder struct {
+	mock *MockshiftsUsecase
+}
+
+// NewMockshiftsUsecase creates a new mock instance.
+func NewMockshiftsUsecase(ctrl *gomock.Controller) *MockshiftsUsecase {
+	mock := &MockshiftsUsecase{ctrl: ctrl}
+	mock.recorder = &MockshiftsUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockshiftsUsecase) EXPECT() *MockshiftsUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CloseShift mocks base method.
+func (m *MockshiftsUsecase) CloseShift(ctx context.Context, chefID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseShift", ctx, chefID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseShift indicates an expected call of CloseShift.
+func (mr *MockshiftsUsecaseMockRecorder) CloseShift(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseShift", reflect.TypeOf((*MockshiftsUsecase)(nil).CloseShift), ctx, chefID)
+}
+
+// GetActiveShiftByChefID mocks base method.
+func (m *MockshiftsUsecase) GetActiveShiftByChefID(ctx context.Context, chefID int64) (*shifts.Shift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveShiftByChefID", ctx, chefID)
+	ret0, _ := ret[0].(*shifts.Shift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveShiftByChefID indicates an expected call of GetActiveShiftByChefID.
+func (mr *MockshiftsUsecaseMockRecorder) GetActiveShiftByChefID(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveShiftByChefID", reflect.TypeOf((*MockshiftsUsecase)(nil).GetActiveShiftByChefID), ctx, chefID)
+}
+
+// GetTodayProfit mocks base method.
+func (m *MockshiftsUsecase) GetTodayProfit(ctx context.Context, chefID int64) (money.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTodayProfit", ctx, chefID)
+	ret0, _ := ret[0].(money.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTodayProfit indicates an expected call of GetTodayProfit.
+func (mr *MockshiftsUsecaseMockRecorder) GetTodayProfit(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTodayProfit", reflect.TypeOf((*MockshiftsUsecase)(nil).GetTodayProfit), ctx, chefID)
+}
+
+// OpenShift mocks base method.
+func (m *MockshiftsUsecase) OpenShift(ctx context.Context, chefID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenShift", ctx, chefID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OpenShift indicates an expected call of OpenShift.
+func (mr *MockshiftsUsecaseMockRecorder) OpenShift(ctx, chefID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenShift", reflect.TypeOf((*MockshiftsUsecase)(nil).OpenShift), ctx, chefID)
+}
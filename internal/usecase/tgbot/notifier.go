@@ -0,0 +1,167 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+
+	"domashka-backend/internal/entity/orders"
+)
+
+const (
+	// CallbackAcceptOrder и CallbackRejectOrder — кнопки под сообщением о новом заказе; в данных кнопки — id заказа
+	CallbackAcceptOrder = "order_accept"
+	CallbackRejectOrder = "order_reject"
+
+	notifyTimeout = 10 * time.Second
+)
+
+// Notifier отправляет в Telegram уведомления о смене статуса заказа клиенту и повару
+type Notifier struct {
+	bot    messenger
+	users  usersRepo
+	orders ordersRepo
+	// inflight — отправки, запущенные в фоне; их дожидается Shutdown
+	inflight sync.WaitGroup
+}
+
+// NewNotifier создаёт уведомитель. Если бот не передан (Telegram выключен), уведомления не отправляются
+func NewNotifier(bot *tele.Bot, users usersRepo, orders ordersRepo) *Notifier {
+	n := &Notifier{users: users, orders: orders}
+	if bot != nil {
+		n.bot = bot
+	}
+	return n
+}
+
+func newNotifier(bot messenger, users usersRepo, orders ordersRepo) *Notifier {
+	return &Notifier{bot: bot, users: users, orders: orders}
+}
+
+// OrderStatusChanged отправляет уведомления в фоне, чтобы недоступность Telegram не задерживала смену статуса.
+// order — заказ в состоянии до перехода в статус to.
+func (n *Notifier) OrderStatusChanged(ctx context.Context, order orders.Order, to int32) {
+	if n.bot == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	n.inflight.Add(1)
+	go func() {
+		defer n.inflight.Done()
+		ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		defer cancel()
+		if err := n.notify(ctx, order, to); err != nil {
+			log.Printf("tgbot: уведомление о заказе %d (статус %d): %v", order.ID, to, err)
+		}
+	}()
+}
+
+// Shutdown дожидается уведомлений, отправляемых в фоне, но не дольше, чем позволяет ctx
+func (n *Notifier) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		n.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *Notifier) notify(ctx context.Context, order orders.Order, to int32) error {
+	var errs []error
+	if text := clientMessage(order, to); text != "" {
+		chatID, err := n.users.GetChatIDByUserID(ctx, order.UserID)
+		if err == nil {
+			err = n.send(chatID, text)
+		}
+		errs = append(errs, err)
+	}
+
+	switch to {
+	case orders.StatusCreated:
+		errs = append(errs, n.notifyChefNewOrder(ctx, order))
+	case orders.StatusCancelled:
+		chatID, err := n.users.GetChatIDByChefID(ctx, order.ChefID)
+		if err == nil {
+			err = n.send(chatID, fmt.Sprintf("Клиент отменил заказ №%d.", order.ID))
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) notifyChefNewOrder(ctx context.Context, order orders.Order) error {
+	chatID, err := n.users.GetChatIDByChefID(ctx, order.ChefID)
+	if err != nil || chatID == "" {
+		return err
+	}
+	items, err := n.orders.GetCartItemsByOrderID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Новый заказ №%d\n", order.ID)
+	for _, item := range items {
+		fmt.Fprintf(&text, "\n%s × %d", item.Dish.Name, item.Quantity)
+	}
+	fmt.Fprintf(&text, "\n\nСумма: %s", order.TotalCost.Format())
+
+	return n.send(chatID, text.String(), OrderButtons(order.ID))
+}
+
+// OrderButtons — кнопки «Принять» и «Отклонить» для нового заказа
+func OrderButtons(orderID int64) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	id := strconv.FormatInt(orderID, 10)
+	markup.Inline(markup.Row(
+		markup.Data("Принять", CallbackAcceptOrder, id),
+		markup.Data("Отклонить", CallbackRejectOrder, id),
+	))
+	return markup
+}
+
+// send пропускает пользователей, не привязавших Telegram
+func (n *Notifier) send(chatID, text string, opts ...interface{}) error {
+	if chatID == "" {
+		return nil
+	}
+	id, err := strconv.ParseInt(chatID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("некорректный chat_id %q: %w", chatID, err)
+	}
+	_, err = n.bot.Send(tele.ChatID(id), text, opts...)
+	return err
+}
+
+func clientMessage(order orders.Order, to int32) string {
+	switch to {
+	case orders.StatusCreated:
+		return fmt.Sprintf("Заказ №%d оплачен и передан повару.", order.ID)
+	case orders.StatusAccepted:
+		return fmt.Sprintf("Повар принял заказ №%d и начал готовить.", order.ID)
+	case orders.StatusCooked:
+		return fmt.Sprintf("Заказ №%d готов и ждёт курьера.", order.ID)
+	case orders.StatusInDelivery:
+		return fmt.Sprintf("Заказ №%d передан курьеру.", order.ID)
+	case orders.StatusDelivered:
+		return fmt.Sprintf("Заказ №%d доставлен. Приятного аппетита!", order.ID)
+	case orders.StatusRejected:
+		if order.Status == orders.StatusAwaitingPayment {
+			return fmt.Sprintf("Не удалось оплатить заказ №%d.", order.ID)
+		}
+		return fmt.Sprintf("Заказ №%d отклонён. Деньги вернутся на карту.", order.ID)
+	}
+	return ""
+}
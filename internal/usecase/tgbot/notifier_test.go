@@ -0,0 +1,148 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v4"
+
+	cartentity "domashka-backend/internal/entity/cart"
+	dishentity "domashka-backend/internal/entity/dishes"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/orders"
+)
+
+func TestNotifier_notify(t *testing.T) {
+	order := orders.Order{ID: 10, ChefID: 3, UserID: 7, Status: orders.StatusAwaitingPayment, TotalCost: money.New(90000, money.RUB)}
+	tests := []struct {
+		name    string
+		order   orders.Order
+		to      int32
+		users   func(m *MockusersRepo)
+		orders  func(m *MockordersRepo)
+		bot     func(m *Mockmessenger)
+		wantErr bool
+	}{
+		{
+			name:  "paid order goes to client and to chef with buttons",
+			order: order,
+			to:    orders.StatusCreated,
+			users: func(m *MockusersRepo) {
+				m.EXPECT().GetChatIDByUserID(gomock.Any(), int64(7)).Return("111", nil)
+				m.EXPECT().GetChatIDByChefID(gomock.Any(), int64(3)).Return("333", nil)
+			},
+			orders: func(m *MockordersRepo) {
+				m.EXPECT().GetCartItemsByOrderID(gomock.Any(), int64(10)).Return([]cartentity.CartItem{
+					{Dish: dishentity.Dish{Name: "Борщ"}, Quantity: 2},
+				}, nil)
+			},
+			bot: func(m *Mockmessenger) {
+				m.EXPECT().Send(tele.ChatID(111), "Заказ №10 оплачен и передан повару.").Return(&tele.Message{}, nil)
+				m.EXPECT().Send(tele.ChatID(333), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ tele.Recipient, what interface{}, opts ...interface{}) (*tele.Message, error) {
+						require.Contains(t, what, "Новый заказ №10")
+						require.Contains(t, what, "Борщ × 2")
+						require.Contains(t, what, money.New(90000, money.RUB).Format())
+						markup, ok := opts[0].(*tele.ReplyMarkup)
+						require.True(t, ok)
+						require.Len(t, markup.InlineKeyboard[0], 2)
+						require.Equal(t, "10", markup.InlineKeyboard[0][0].Data)
+						return &tele.Message{}, nil
+					})
+			},
+		},
+		{
+			name:  "failed payment",
+			order: order,
+			to:    orders.StatusRejected,
+			users: func(m *MockusersRepo) {
+				m.EXPECT().GetChatIDByUserID(gomock.Any(), int64(7)).Return("111", nil)
+			},
+			orders: func(m *MockordersRepo) {},
+			bot: func(m *Mockmessenger) {
+				m.EXPECT().Send(tele.ChatID(111), "Не удалось оплатить заказ №10.").Return(&tele.Message{}, nil)
+			},
+		},
+		{
+			name:  "cancelled order goes to chef only",
+			order: orders.Order{ID: 10, ChefID: 3, UserID: 7, Status: orders.StatusCreated},
+			to:    orders.StatusCancelled,
+			users: func(m *MockusersRepo) {
+				m.EXPECT().GetChatIDByChefID(gomock.Any(), int64(3)).Return("333", nil)
+			},
+			orders: func(m *MockordersRepo) {},
+			bot: func(m *Mockmessenger) {
+				m.EXPECT().Send(tele.ChatID(333), "Клиент отменил заказ №10.").Return(&tele.Message{}, nil)
+			},
+		},
+		{
+			name:  "users without telegram are skipped",
+			order: orders.Order{ID: 10, ChefID: 3, UserID: 7, Status: orders.StatusCreated},
+			to:    orders.StatusAccepted,
+			users: func(m *MockusersRepo) {
+				m.EXPECT().GetChatIDByUserID(gomock.Any(), int64(7)).Return("", nil)
+			},
+			orders: func(m *MockordersRepo) {},
+			bot:    func(m *Mockmessenger) {},
+		},
+		{
+			name:  "send error is returned",
+			order: orders.Order{ID: 10, ChefID: 3, UserID: 7, Status: orders.StatusCooked},
+			to:    orders.StatusInDelivery,
+			users: func(m *MockusersRepo) {
+				m.EXPECT().GetChatIDByUserID(gomock.Any(), int64(7)).Return("111", nil)
+			},
+			orders: func(m *MockordersRepo) {},
+			bot: func(m *Mockmessenger) {
+				m.EXPECT().Send(tele.ChatID(111), gomock.Any()).Return(nil, errors.New("blocked by user"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			users := NewMockusersRepo(ctrl)
+			ordersRepo := NewMockordersRepo(ctrl)
+			bot := NewMockmessenger(ctrl)
+			tt.users(users)
+			tt.orders(ordersRepo)
+			tt.bot(bot)
+
+			err := newNotifier(bot, users, ordersRepo).notify(context.Background(), tt.order, tt.to)
+			require.Equal(t, tt.wantErr, err != nil, "notify() error = %v", err)
+		})
+	}
+}
+
+func TestNotifier_Shutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	users := NewMockusersRepo(ctrl)
+	bot := NewMockmessenger(ctrl)
+	release := make(chan struct{})
+	users.EXPECT().GetChatIDByUserID(gomock.Any(), int64(7)).Return("111", nil)
+	bot.EXPECT().Send(tele.ChatID(111), gomock.Any()).DoAndReturn(func(tele.Recipient, interface{}, ...interface{}) (*tele.Message, error) {
+		<-release
+		return &tele.Message{}, nil
+	})
+
+	n := newNotifier(bot, users, NewMockordersRepo(ctrl))
+	n.OrderStatusChanged(context.Background(), orders.Order{ID: 10, UserID: 7, Status: orders.StatusCreated}, orders.StatusAccepted)
+
+	// Пока отправка не завершилась, Shutdown упирается в срок
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, n.Shutdown(ctx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, n.Shutdown(context.Background()))
+}
+
+func TestNotifier_disabled(t *testing.T) {
+	// Без бота уведомления молча пропускаются
+	NewNotifier(nil, nil, nil).OrderStatusChanged(context.Background(), orders.Order{ID: 1}, orders.StatusAccepted)
+}
@@ -0,0 +1,119 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"domashka-backend/internal/custom_errors"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/orders"
+	"domashka-backend/internal/entity/shifts"
+)
+
+// rejectReason записывается в историю заказа, отклонённого из Telegram
+const rejectReason = "отклонён поваром в Telegram"
+
+// UseCase обрабатывает команды повара из Telegram. Чат привязывается к пользователю при входе через Telegram
+type UseCase struct {
+	users  usersRepo
+	orders orderUsecase
+	shifts shiftsUsecase
+}
+
+func New(users usersRepo, orders orderUsecase, shifts shiftsUsecase) *UseCase {
+	return &UseCase{users: users, orders: orders, shifts: shifts}
+}
+
+// chef находит повара, к которому привязан чат
+func (u *UseCase) chef(ctx context.Context, chatID int64) (orders.Actor, int64, error) {
+	user, err := u.users.GetByChatID(ctx, strconv.FormatInt(chatID, 10))
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrUserNotFound) {
+			return orders.Actor{}, 0, custom_errors.ErrChatNotBound
+		}
+		return orders.Actor{}, 0, err
+	}
+	chefID, isChef, err := u.users.CheckIfUserIsChef(ctx, user.ID)
+	if err != nil {
+		return orders.Actor{}, 0, err
+	}
+	if !isChef || chefID == nil {
+		return orders.Actor{}, 0, custom_errors.ErrNotChef
+	}
+	return orders.Actor{UserID: user.ID, Role: orders.ActorRoleChef}, *chefID, nil
+}
+
+func (u *UseCase) OpenShift(ctx context.Context, chatID int64) error {
+	_, chefID, err := u.chef(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	shift, err := u.shifts.GetActiveShiftByChefID(ctx, chefID)
+	if err != nil {
+		return err
+	}
+	if shift != nil {
+		return shifts.ErrShiftAlreadyOpen
+	}
+	return u.shifts.OpenShift(ctx, chefID)
+}
+
+// CloseShift закрывает открытую смену и возвращает её выручку
+func (u *UseCase) CloseShift(ctx context.Context, chatID int64) (money.Money, error) {
+	_, chefID, err := u.chef(ctx, chatID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	shift, err := u.shifts.GetActiveShiftByChefID(ctx, chefID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if shift == nil {
+		return money.Money{}, shifts.ErrShiftNotOpen
+	}
+	if err := u.shifts.CloseShift(ctx, chefID); err != nil {
+		return money.Money{}, err
+	}
+	return shift.TotalProfit, nil
+}
+
+func (u *UseCase) TodayRevenue(ctx context.Context, chatID int64) (money.Money, error) {
+	_, chefID, err := u.chef(ctx, chatID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return u.shifts.GetTodayProfit(ctx, chefID)
+}
+
+func (u *UseCase) AcceptOrder(ctx context.Context, chatID, orderID int64) error {
+	actor, err := u.chefOrder(ctx, chatID, orderID)
+	if err != nil {
+		return err
+	}
+	return u.orders.Accept(ctx, orderID, actor)
+}
+
+func (u *UseCase) RejectOrder(ctx context.Context, chatID, orderID int64) error {
+	actor, err := u.chefOrder(ctx, chatID, orderID)
+	if err != nil {
+		return err
+	}
+	return u.orders.Reject(ctx, orderID, actor, rejectReason)
+}
+
+// chefOrder проверяет, что заказ принадлежит повару, к которому привязан чат
+func (u *UseCase) chefOrder(ctx context.Context, chatID, orderID int64) (orders.Actor, error) {
+	actor, chefID, err := u.chef(ctx, chatID)
+	if err != nil {
+		return orders.Actor{}, err
+	}
+	order, err := u.orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return orders.Actor{}, err
+	}
+	if order.ChefID != chefID {
+		return orders.Actor{}, orders.ErrNotOrderOwner
+	}
+	return actor, nil
+}
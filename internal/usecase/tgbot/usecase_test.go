@@ -0,0 +1,215 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"domashka-backend/internal/custom_errors"
+	"domashka-backend/internal/entity/money"
+	"domashka-backend/internal/entity/orders"
+	"domashka-backend/internal/entity/shifts"
+	usersentity "domashka-backend/internal/entity/users"
+)
+
+const (
+	testChatID = int64(100500)
+	testUserID = int64(7)
+	testChefID = int64(3)
+)
+
+// expectChef привязывает тестовый чат к повару testChefID
+func expectChef(m *MockusersRepo) {
+	chefID := testChefID
+	m.EXPECT().GetByChatID(gomock.Any(), "100500").Return(&usersentity.User{ID: testUserID}, nil)
+	m.EXPECT().CheckIfUserIsChef(gomock.Any(), testUserID).Return(&chefID, true, nil)
+}
+
+func TestUseCase_OpenShift(t *testing.T) {
+	tests := []struct {
+		name    string
+		users   func(m *MockusersRepo)
+		shifts  func(m *MockshiftsUsecase)
+		wantErr error
+	}{
+		{
+			name:  "success",
+			users: expectChef,
+			shifts: func(m *MockshiftsUsecase) {
+				m.EXPECT().GetActiveShiftByChefID(gomock.Any(), testChefID).Return(nil, nil)
+				m.EXPECT().OpenShift(gomock.Any(), testChefID).Return(nil)
+			},
+		},
+		{
+			name:  "already open",
+			users: expectChef,
+			shifts: func(m *MockshiftsUsecase) {
+				m.EXPECT().GetActiveShiftByChefID(gomock.Any(), testChefID).Return(&shifts.Shift{ID: 1, IsActive: true}, nil)
+			},
+			wantErr: shifts.ErrShiftAlreadyOpen,
+		},
+		{
+			name: "chat not bound",
+			users: func(m *MockusersRepo) {
+				m.EXPECT().GetByChatID(gomock.Any(), "100500").Return(nil, custom_errors.ErrUserNotFound)
+			},
+			shifts:  func(m *MockshiftsUsecase) {},
+			wantErr: custom_errors.ErrChatNotBound,
+		},
+		{
+			name: "not a chef",
+			users: func(m *MockusersRepo) {
+				m.EXPECT().GetByChatID(gomock.Any(), "100500").Return(&usersentity.User{ID: testUserID}, nil)
+				m.EXPECT().CheckIfUserIsChef(gomock.Any(), testUserID).Return(nil, false, nil)
+			},
+			shifts:  func(m *MockshiftsUsecase) {},
+			wantErr: custom_errors.ErrNotChef,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			users := NewMockusersRepo(ctrl)
+			shiftsUC := NewMockshiftsUsecase(ctrl)
+			tt.users(users)
+			tt.shifts(shiftsUC)
+
+			u := New(users, NewMockorderUsecase(ctrl), shiftsUC)
+			err := u.OpenShift(context.Background(), testChatID)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUseCase_CloseShift(t *testing.T) {
+	profit := money.New(150000, money.RUB)
+	tests := []struct {
+		name    string
+		shifts  func(m *MockshiftsUsecase)
+		want    money.Money
+		wantErr error
+	}{
+		{
+			name: "returns shift profit",
+			shifts: func(m *MockshiftsUsecase) {
+				m.EXPECT().GetActiveShiftByChefID(gomock.Any(), testChefID).Return(&shifts.Shift{ID: 1, IsActive: true, TotalProfit: profit}, nil)
+				m.EXPECT().CloseShift(gomock.Any(), testChefID).Return(nil)
+			},
+			want: profit,
+		},
+		{
+			name: "no open shift",
+			shifts: func(m *MockshiftsUsecase) {
+				m.EXPECT().GetActiveShiftByChefID(gomock.Any(), testChefID).Return(nil, nil)
+			},
+			wantErr: shifts.ErrShiftNotOpen,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			users := NewMockusersRepo(ctrl)
+			shiftsUC := NewMockshiftsUsecase(ctrl)
+			expectChef(users)
+			tt.shifts(shiftsUC)
+
+			u := New(users, NewMockorderUsecase(ctrl), shiftsUC)
+			got, err := u.CloseShift(context.Background(), testChatID)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUseCase_TodayRevenue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	users := NewMockusersRepo(ctrl)
+	shiftsUC := NewMockshiftsUsecase(ctrl)
+	expectChef(users)
+	shiftsUC.EXPECT().GetTodayProfit(gomock.Any(), testChefID).Return(money.New(42000, money.RUB), nil)
+
+	got, err := New(users, NewMockorderUsecase(ctrl), shiftsUC).TodayRevenue(context.Background(), testChatID)
+	require.NoError(t, err)
+	require.Equal(t, money.New(42000, money.RUB), got)
+}
+
+func TestUseCase_orderButtons(t *testing.T) {
+	chefActor := orders.Actor{UserID: testUserID, Role: orders.ActorRoleChef}
+	errDB := errors.New("db down")
+	tests := []struct {
+		name    string
+		orders  func(m *MockorderUsecase)
+		call    func(u *UseCase) error
+		wantErr error
+	}{
+		{
+			name: "accept own order",
+			orders: func(m *MockorderUsecase) {
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(10)).Return(&orders.Order{ID: 10, ChefID: testChefID}, nil)
+				m.EXPECT().Accept(gomock.Any(), int64(10), chefActor).Return(nil)
+			},
+			call: func(u *UseCase) error { return u.AcceptOrder(context.Background(), testChatID, 10) },
+		},
+		{
+			name: "reject own order",
+			orders: func(m *MockorderUsecase) {
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(10)).Return(&orders.Order{ID: 10, ChefID: testChefID}, nil)
+				m.EXPECT().Reject(gomock.Any(), int64(10), chefActor, rejectReason).Return(nil)
+			},
+			call: func(u *UseCase) error { return u.RejectOrder(context.Background(), testChatID, 10) },
+		},
+		{
+			name: "order of another chef",
+			orders: func(m *MockorderUsecase) {
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(10)).Return(&orders.Order{ID: 10, ChefID: testChefID + 1}, nil)
+			},
+			call:    func(u *UseCase) error { return u.AcceptOrder(context.Background(), testChatID, 10) },
+			wantErr: orders.ErrNotOrderOwner,
+		},
+		{
+			name: "status already changed",
+			orders: func(m *MockorderUsecase) {
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(10)).Return(&orders.Order{ID: 10, ChefID: testChefID}, nil)
+				m.EXPECT().Accept(gomock.Any(), int64(10), chefActor).
+					Return(&orders.TransitionError{From: orders.StatusRejected, To: orders.StatusAccepted})
+			},
+			call:    func(u *UseCase) error { return u.AcceptOrder(context.Background(), testChatID, 10) },
+			wantErr: orders.ErrInvalidTransition,
+		},
+		{
+			name: "order lookup fails",
+			orders: func(m *MockorderUsecase) {
+				m.EXPECT().GetOrderByID(gomock.Any(), int64(10)).Return(nil, errDB)
+			},
+			call:    func(u *UseCase) error { return u.RejectOrder(context.Background(), testChatID, 10) },
+			wantErr: errDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			users := NewMockusersRepo(ctrl)
+			ordersUC := NewMockorderUsecase(ctrl)
+			expectChef(users)
+			tt.orders(ordersUC)
+
+			err := tt.call(New(users, ordersUC, NewMockshiftsUsecase(ctrl)))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
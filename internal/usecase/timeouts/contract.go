@@ -0,0 +1,31 @@
+package timeouts
+
+import (
+	"context"
+	"time"
+
+	"domashka-backend/internal/entity/orders"
+)
+
+//go:generate mockgen -source=contract.go -destination contract_mocks_test.go -package $GOPACKAGE
+
+type ordersRepo interface {
+	GetStaleOrderIDs(ctx context.Context, status int32, olderThan time.Duration, limit int) ([]int64, error)
+}
+
+type orderUsecase interface {
+	Reject(ctx context.Context, orderID int64, actor orders.Actor, reason string) error
+	CloseStaleDelivery(ctx context.Context, orderID int64, reason string) error
+}
+
+// paymentsUsecase повторяет возвраты и списания, которые не удалось провести сразу после изменения заказа
+type paymentsUsecase interface {
+	RetryPending(ctx context.Context, olderThan time.Duration, limit int) error
+}
+
+// leaderLock — ключ в Redis, которым экземпляры приложения выбирают единственного исполнителя
+type leaderLock interface {
+	SetNX(key string, value string, ttl time.Duration) (bool, error)
+	Refresh(key string, value string, ttl time.Duration) (bool, error)
+	DeleteIfEqual(key string, value string) error
+}
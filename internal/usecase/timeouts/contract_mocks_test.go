@@ -0,0 +1,207 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package timeouts is a generated GoMock package.
+package timeouts
+
+import (
+	context "context"
+	orders "domashka-backend/internal/entity/orders"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockordersRepo is a mock of ordersRepo interface.
+type MockordersRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockordersRepoMockRecorder
+}
+
+// MockordersRepoMockRecorder is the mock recorder for MockordersRepo.
+type MockordersRepoMockRecorder struct {
+	mock *MockordersRepo
+}
+
+// NewMockordersRepo creates a new mock instance.
+func NewMockordersRepo(ctrl *gomock.Controller) *MockordersRepo {
+	mock := &MockordersRepo{ctrl: ctrl}
+	mock.recorder = &MockordersRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockordersRepo) EXPECT() *MockordersRepoMockRecorder {
+	return m.recorder
+}
+
+// GetStaleOrderIDs mocks base method.
+func (m *MockordersRepo) GetStaleOrderIDs(ctx context.Context, status int32, olderThan time.Duration, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStaleOrderIDs", ctx, status, olderThan, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStaleOrderIDs indicates an expected call of GetStaleOrderIDs.
+func (mr *MockordersRepoMockRecorder) GetStaleOrderIDs(ctx, status, olderThan, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStaleOrderIDs", reflect.TypeOf((*MockordersRepo)(nil).GetStaleOrderIDs), ctx, status, olderThan, limit)
+}
+
+// MockorderUsecase is a mock of orderUsecase interface.
+type MockorderUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockorderUsecaseMockRecorder
+}
+
+// MockorderUsecaseMockRecorder is the mock recorder for MockorderUsecase.
+type MockorderUsecaseMockRecorder struct {
+	mock *MockorderUsecase
+}
+
+// NewMockorderUsecase creates a new mock instance.
+func NewMockorderUsecase(ctrl *gomock.Controller) *MockorderUsecase {
+	mock := &MockorderUsecase{ctrl: ctrl}
+	mock.recorder = &MockorderUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockorderUsecase) EXPECT() *MockorderUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CloseStaleDelivery mocks base method.
+func (m *MockorderUsecase) CloseStaleDelivery(ctx context.Context, orderID int64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseStaleDelivery", ctx, orderID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseStaleDelivery indicates an expected call of CloseStaleDelivery.
+func (mr *MockorderUsecaseMockRecorder) CloseStaleDelivery(ctx, orderID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseStaleDelivery", reflect.TypeOf((*MockorderUsecase)(nil).CloseStaleDelivery), ctx, orderID, reason)
+}
+
+// Reject mocks base method.
+func (m *MockorderUsecase) Reject(ctx context.Context, orderID int64, actor orders.Actor, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reject", ctx, orderID, actor, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reject indicates an expected call of Reject.
+func (mr *MockorderUsecaseMockRecorder) Reject(ctx, orderID, actor, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reject", reflect.TypeOf((*MockorderUsecase)(nil).Reject), ctx, orderID, actor, reason)
+}
+
+// MockpaymentsUsecase is a mock of paymentsUsecase interface.
+type MockpaymentsUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockpaymentsUsecaseMockRecorder
+}
+
+// MockpaymentsUsecaseMockRecorder is the mock recorder for MockpaymentsUsecase.
+type MockpaymentsUsecaseMockRecorder struct {
+	mock *MockpaymentsUsecase
+}
+
+// NewMockpaymentsUsecase creates a new mock instance.
+func NewMockpaymentsUsecase(ctrl *gomock.Controller) *MockpaymentsUsecase {
+	mock := &MockpaymentsUsecase{ctrl: ctrl}
+	mock.recorder = &MockpaymentsUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpaymentsUsecase) EXPECT() *MockpaymentsUsecaseMockRecorder {
+	return m.recorder
+}
+
+// RetryPending mocks base method.
+func (m *MockpaymentsUsecase) RetryPending(ctx context.Context, olderThan time.Duration, limit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryPending", ctx, olderThan, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryPending indicates an expected call of RetryPending.
+func (mr *MockpaymentsUsecaseMockRecorder) RetryPending(ctx, olderThan, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryPending", reflect.TypeOf((*MockpaymentsUsecase)(nil).RetryPending), ctx, olderThan, limit)
+}
+
+// MockleaderLock is a mock of leaderLock interface.
+type MockleaderLock struct {
+	ctrl     *gomock.Controller
+	recorder *MockleaderLockMockRecorder
+}
+
+// MockleaderLockMockRecorder is the mock recorder for MockleaderLock.
+type MockleaderLockMockRecorder struct {
+	mock *MockleaderLock
+}
+
+// NewMockleaderLock creates a new mock instance.
+func NewMockleaderLock(ctrl *gomock.Controller) *MockleaderLock {
+	mock := &MockleaderLock{ctrl: ctrl}
+	mock.recorder = &MockleaderLockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockleaderLock) EXPECT() *MockleaderLockMockRecorder {
+	return m.recorder
+}
+
+// DeleteIfEqual mocks base method.
+func (m *MockleaderLock) DeleteIfEqual(key, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIfEqual", key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIfEqual indicates an expected call of DeleteIfEqual.
+func (mr *MockleaderLockMockRecorder) DeleteIfEqual(key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIfEqual", reflect.TypeOf((*MockleaderLock)(nil).DeleteIfEqual), key, value)
+}
+
+// Refresh mocks base method.
+func (m *MockleaderLock) Refresh(key, value string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", key, value, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockleaderLockMockRecorder) Refresh(key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockleaderLock)(nil).Refresh), key, value, ttl)
+}
+
+// SetNX mocks base method.
+func (m *MockleaderLock) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNX", key, value, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetNX indicates an expected call of SetNX.
+func (mr *MockleaderLockMockRecorder) SetNX(key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNX", reflect.TypeOf((*MockleaderLock)(nil).SetNX), key, value, ttl)
+}
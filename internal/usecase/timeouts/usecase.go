@@ -0,0 +1,184 @@
+package timeouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"domashka-backend/internal/entity/orders"
+)
+
+const (
+	leaderKey = "order_timeouts:leader"
+	// batchSize — сколько заказов каждого вида обрабатывается за один проход
+	batchSize = 100
+
+	rejectReason  = "not accepted in time"
+	closeReason   = "delivery timeout"
+	paymentReason = "payment timeout"
+
+	actionReject = "reject"
+	actionClose  = "close"
+	actionExpire = "expire"
+
+	resultOK      = "ok"
+	resultSkipped = "skipped"
+	resultFailed  = "failed"
+)
+
+var systemActor = orders.Actor{Role: orders.ActorRoleSystem}
+
+// Config — пороги, после которых заказ считается зависшим, и период проверки
+type Config struct {
+	PaymentTimeout  time.Duration // сколько заказ может ждать подтверждения оплаты от провайдера
+	AcceptTimeout   time.Duration // сколько заказ может ждать, пока повар его примет
+	DeliveryTimeout time.Duration // сколько заказ может находиться в доставке
+	Interval        time.Duration
+}
+
+// Worker автоматически отклоняет заказы, которые не оплатили или повар не принял вовремя,
+// закрывает заказы, застрявшие в доставке, и повторяет незавершённые возвраты и списания. Запускается в каждом экземпляре приложения,
+// но работает только тот, кто держит ключ лидера в Redis.
+type Worker struct {
+	cfg          Config
+	ordersRepo   ordersRepo
+	orderUsecase orderUsecase
+	payments     paymentsUsecase
+	lock         leaderLock
+
+	instanceID string
+	leader     bool
+	actions    metric.Int64Counter
+}
+
+func New(cfg Config, ordersRepo ordersRepo, orderUsecase orderUsecase, payments paymentsUsecase, lock leaderLock) *Worker {
+	actions, _ := otel.Meter("domashka-order-timeouts").Int64Counter(
+		"order_timeouts_actions_total",
+		metric.WithDescription("Orders processed by the order timeouts worker"),
+	)
+	return &Worker{
+		cfg:          cfg,
+		ordersRepo:   ordersRepo,
+		orderUsecase: orderUsecase,
+		payments:     payments,
+		lock:         lock,
+		instanceID:   uuid.NewString(),
+		actions:      actions,
+	}
+}
+
+// Run проверяет заказы раз в Interval, пока не отменён ctx
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	defer w.resign()
+
+	for {
+		if w.elect() {
+			if err := w.Tick(ctx); err != nil {
+				log.Printf("Ошибка проверки зависших заказов: %v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick — один проход по зависшим заказам
+func (w *Worker) Tick(ctx context.Context) error {
+	// Вебхук об оплате мог не прийти — без этого заказ вечно держал бы слот доставки.
+	// Если оплата всё же придёт позже, блокировку денег снимет обработка вебхука
+	expireErr := w.process(ctx, actionExpire, orders.StatusAwaitingPayment, w.cfg.PaymentTimeout, func(ctx context.Context, orderID int64) error {
+		return w.orderUsecase.Reject(ctx, orderID, systemActor, paymentReason)
+	})
+	rejectErr := w.process(ctx, actionReject, orders.StatusCreated, w.cfg.AcceptTimeout, func(ctx context.Context, orderID int64) error {
+		return w.orderUsecase.Reject(ctx, orderID, systemActor, rejectReason)
+	})
+	closeErr := w.process(ctx, actionClose, orders.StatusInDelivery, w.cfg.DeliveryTimeout, func(ctx context.Context, orderID int64) error {
+		return w.orderUsecase.CloseStaleDelivery(ctx, orderID, closeReason)
+	})
+	// Возвраты и списания, запрошенные меньше интервала назад, ещё может проводить сам запрос
+	retryErr := w.payments.RetryPending(ctx, w.cfg.Interval, batchSize)
+	if retryErr != nil {
+		retryErr = fmt.Errorf("retry pending payments: %w", retryErr)
+	}
+	return errors.Join(expireErr, rejectErr, closeErr, retryErr)
+}
+
+// process применяет apply к заказам, которые находятся в статусе status дольше timeout.
+// Ошибка по отдельному заказу не останавливает проход — он повторится на следующем тике.
+func (w *Worker) process(
+	ctx context.Context,
+	action string,
+	status int32,
+	timeout time.Duration,
+	apply func(ctx context.Context, orderID int64) error,
+) error {
+	orderIDs, err := w.ordersRepo.GetStaleOrderIDs(ctx, status, timeout, batchSize)
+	if err != nil {
+		return fmt.Errorf("get stale orders in status %d: %w", status, err)
+	}
+	for _, orderID := range orderIDs {
+		err := apply(ctx, orderID)
+		switch {
+		case err == nil:
+			w.record(ctx, action, resultOK)
+		case errors.Is(err, orders.ErrInvalidTransition), errors.Is(err, orders.ErrStatusConflict):
+			// Статус успели поменять повар или клиент — заказ больше не завис
+			w.record(ctx, action, resultSkipped)
+		default:
+			w.record(ctx, action, resultFailed)
+			log.Printf("Ошибка обработки зависшего заказа %d (%s): %v", orderID, action, err)
+		}
+	}
+	return nil
+}
+
+func (w *Worker) record(ctx context.Context, action, result string) {
+	w.actions.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("action", action),
+		attribute.String("result", result),
+	))
+}
+
+// elect захватывает или продлевает лидерство. Ключ живёт дольше периода проверки,
+// чтобы лидер успевал его продлить, а при падении лидера его место занял другой экземпляр.
+func (w *Worker) elect() bool {
+	ttl := 3 * w.cfg.Interval
+	var (
+		ok  bool
+		err error
+	)
+	if w.leader {
+		ok, err = w.lock.Refresh(leaderKey, w.instanceID, ttl)
+	} else {
+		ok, err = w.lock.SetNX(leaderKey, w.instanceID, ttl)
+	}
+	if err != nil {
+		log.Printf("Ошибка выбора лидера воркера таймаутов заказов: %v", err)
+		ok = false
+	}
+	w.leader = ok
+	return ok
+}
+
+// resign освобождает лидерство, чтобы другой экземпляр не ждал истечения ключа
+func (w *Worker) resign() {
+	if !w.leader {
+		return
+	}
+	if err := w.lock.DeleteIfEqual(leaderKey, w.instanceID); err != nil {
+		log.Printf("Ошибка освобождения лидерства воркера таймаутов заказов: %v", err)
+	}
+	w.leader = false
+}
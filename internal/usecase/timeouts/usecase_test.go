@@ -0,0 +1,191 @@
+package timeouts
+
+import (
+	"context"
+	"domashka-backend/internal/entity/orders"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+var testConfig = Config{
+	PaymentTimeout:  30 * time.Minute,
+	AcceptTimeout:   15 * time.Minute,
+	DeliveryTimeout: 3 * time.Hour,
+	Interval:        time.Minute,
+}
+
+func TestWorker_Tick(t *testing.T) {
+	tests := []struct {
+		name         string
+		ordersRepo   func(ctrl *gomock.Controller) ordersRepo
+		orderUsecase func(ctrl *gomock.Controller) orderUsecase
+		payments     func(ctrl *gomock.Controller) paymentsUsecase
+		wantErr      bool
+	}{
+		{
+			name: "stale orders are expired, rejected and closed",
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusAwaitingPayment), 30*time.Minute, batchSize).Return([]int64{5}, nil)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusCreated), 15*time.Minute, batchSize).Return([]int64{1, 2}, nil)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusInDelivery), 3*time.Hour, batchSize).Return([]int64{3}, nil)
+				return m
+			},
+			orderUsecase: func(ctrl *gomock.Controller) orderUsecase {
+				m := NewMockorderUsecase(ctrl)
+				m.EXPECT().Reject(gomock.Any(), int64(1), systemActor, rejectReason).Return(nil)
+				m.EXPECT().Reject(gomock.Any(), int64(5), systemActor, paymentReason).Return(nil)
+				m.EXPECT().Reject(gomock.Any(), int64(2), systemActor, rejectReason).Return(nil)
+				m.EXPECT().CloseStaleDelivery(gomock.Any(), int64(3), closeReason).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().RetryPending(gomock.Any(), time.Minute, batchSize).Return(nil)
+				return m
+			},
+		},
+		{
+			name: "order accepted concurrently and failed order do not stop the pass",
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusAwaitingPayment), gomock.Any(), gomock.Any()).Return(nil, nil)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusCreated), gomock.Any(), gomock.Any()).Return([]int64{1, 2, 4}, nil)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusInDelivery), gomock.Any(), gomock.Any()).Return(nil, nil)
+				return m
+			},
+			orderUsecase: func(ctrl *gomock.Controller) orderUsecase {
+				m := NewMockorderUsecase(ctrl)
+				m.EXPECT().Reject(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(orders.ErrStatusConflict)
+				m.EXPECT().Reject(gomock.Any(), int64(2), gomock.Any(), gomock.Any()).Return(errors.New("payments are down"))
+				m.EXPECT().Reject(gomock.Any(), int64(4), gomock.Any(), gomock.Any()).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().RetryPending(gomock.Any(), time.Minute, batchSize).Return(nil)
+				return m
+			},
+		},
+		{
+			name: "repo error",
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusAwaitingPayment), gomock.Any(), gomock.Any()).Return(nil, nil)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusCreated), gomock.Any(), gomock.Any()).Return(nil, errors.New("db is down"))
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusInDelivery), gomock.Any(), gomock.Any()).Return([]int64{3}, nil)
+				return m
+			},
+			orderUsecase: func(ctrl *gomock.Controller) orderUsecase {
+				m := NewMockorderUsecase(ctrl)
+				m.EXPECT().CloseStaleDelivery(gomock.Any(), int64(3), closeReason).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().RetryPending(gomock.Any(), time.Minute, batchSize).Return(nil)
+				return m
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed payments retry does not stop the pass",
+			ordersRepo: func(ctrl *gomock.Controller) ordersRepo {
+				m := NewMockordersRepo(ctrl)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusAwaitingPayment), gomock.Any(), gomock.Any()).Return(nil, nil)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusCreated), gomock.Any(), gomock.Any()).Return([]int64{1}, nil)
+				m.EXPECT().GetStaleOrderIDs(gomock.Any(), int32(orders.StatusInDelivery), gomock.Any(), gomock.Any()).Return(nil, nil)
+				return m
+			},
+			orderUsecase: func(ctrl *gomock.Controller) orderUsecase {
+				m := NewMockorderUsecase(ctrl)
+				m.EXPECT().Reject(gomock.Any(), int64(1), systemActor, rejectReason).Return(nil)
+				return m
+			},
+			payments: func(ctrl *gomock.Controller) paymentsUsecase {
+				m := NewMockpaymentsUsecase(ctrl)
+				m.EXPECT().RetryPending(gomock.Any(), time.Minute, batchSize).Return(errors.New("provider is down"))
+				return m
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			w := New(testConfig, tt.ordersRepo(ctrl), tt.orderUsecase(ctrl), tt.payments(ctrl), NewMockleaderLock(ctrl))
+			if err := w.Tick(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("Tick() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWorker_elect(t *testing.T) {
+	tests := []struct {
+		name       string
+		wasLeader  bool
+		lock       func(ctrl *gomock.Controller, instanceID string) leaderLock
+		wantLeader bool
+	}{
+		{
+			name: "takes free lock",
+			lock: func(ctrl *gomock.Controller, instanceID string) leaderLock {
+				m := NewMockleaderLock(ctrl)
+				m.EXPECT().SetNX(leaderKey, instanceID, 3*time.Minute).Return(true, nil)
+				return m
+			},
+			wantLeader: true,
+		},
+		{
+			name: "lock is held by another instance",
+			lock: func(ctrl *gomock.Controller, instanceID string) leaderLock {
+				m := NewMockleaderLock(ctrl)
+				m.EXPECT().SetNX(leaderKey, instanceID, gomock.Any()).Return(false, nil)
+				return m
+			},
+		},
+		{
+			name:      "leader refreshes lock",
+			wasLeader: true,
+			lock: func(ctrl *gomock.Controller, instanceID string) leaderLock {
+				m := NewMockleaderLock(ctrl)
+				m.EXPECT().Refresh(leaderKey, instanceID, 3*time.Minute).Return(true, nil)
+				return m
+			},
+			wantLeader: true,
+		},
+		{
+			name:      "leader lost lock",
+			wasLeader: true,
+			lock: func(ctrl *gomock.Controller, instanceID string) leaderLock {
+				m := NewMockleaderLock(ctrl)
+				m.EXPECT().Refresh(leaderKey, instanceID, gomock.Any()).Return(false, nil)
+				return m
+			},
+		},
+		{
+			name: "redis is unavailable",
+			lock: func(ctrl *gomock.Controller, instanceID string) leaderLock {
+				m := NewMockleaderLock(ctrl)
+				m.EXPECT().SetNX(leaderKey, instanceID, gomock.Any()).Return(false, errors.New("connection refused"))
+				return m
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			w := New(testConfig, NewMockordersRepo(ctrl), NewMockorderUsecase(ctrl), NewMockpaymentsUsecase(ctrl), nil)
+			w.lock = tt.lock(ctrl, w.instanceID)
+			w.leader = tt.wasLeader
+			if got := w.elect(); got != tt.wantLeader || w.leader != tt.wantLeader {
+				t.Errorf("elect() = %v, leader = %v, want %v", got, w.leader, tt.wantLeader)
+			}
+		})
+	}
+}
@@ -24,11 +24,6 @@ func MeterProvider() *metric.MeterProvider {
 		metric.WithReader(metric.NewPeriodicReader(exporter, metric.WithInterval(time.Second*5))),
 	)
 	otel.SetMeterProvider(provider)
-	meter := otel.Meter("domashka-app")
-	requestCounter, _ := meter.Int64Counter(
-		"http_server_requests_seconds_count",
-	)
-	requestCounter.Add(ctx, 400)
 
 	return provider
 }
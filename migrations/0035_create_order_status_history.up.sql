@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS order_status_history
+(
+    id          BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    order_id    BIGINT      NOT NULL,
+    from_status INT,
+    to_status   INT         NOT NULL,
+    actor_id    BIGINT,
+    actor_role  VARCHAR(16) NOT NULL,
+    reason      TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_id ON order_status_history (order_id);
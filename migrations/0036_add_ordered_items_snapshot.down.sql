@@ -0,0 +1,7 @@
+ALTER TABLE ordered_items
+    DROP COLUMN IF EXISTS dish_name,
+    DROP COLUMN IF EXISTS size_label,
+    DROP COLUMN IF EXISTS weight_value,
+    DROP COLUMN IF EXISTS weight_unit,
+    DROP COLUMN IF EXISTS unit_price,
+    DROP COLUMN IF EXISTS price_currency;
@@ -0,0 +1,19 @@
+ALTER TABLE ordered_items
+    ADD COLUMN IF NOT EXISTS dish_name      TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS size_label     VARCHAR(10) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS weight_value   NUMERIC     NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS weight_unit    VARCHAR(10) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS unit_price     NUMERIC     NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS price_currency VARCHAR(3)  NOT NULL DEFAULT 'RUB';
+
+-- Для уже оформленных заказов истории цен нет, поэтому берём текущие данные каталога
+UPDATE ordered_items oi
+SET dish_name      = d.name,
+    size_label     = ds.label,
+    weight_value   = ds.weight_value,
+    weight_unit    = ds.weight_unit,
+    unit_price     = ds.price_value,
+    price_currency = ds.price_currency
+FROM dish_sizes ds
+         JOIN dishes d ON d.id = ds.dish_id
+WHERE ds.id = oi.dish_size_id;
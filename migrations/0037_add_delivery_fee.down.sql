@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS chef_delivery_tariffs;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivery_fee;
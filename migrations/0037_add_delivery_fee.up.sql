@@ -0,0 +1,13 @@
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS delivery_fee NUMERIC NOT NULL DEFAULT 0;
+
+-- Персональные настройки доставки повара; NULL — берётся общий тариф
+CREATE TABLE IF NOT EXISTS chef_delivery_tariffs
+(
+    chef_id             BIGINT PRIMARY KEY,
+    base_fee            NUMERIC,
+    per_km_fee          NUMERIC,
+    free_threshold      NUMERIC,
+    max_distance_meters DOUBLE PRECISION,
+    updated_at          TIMESTAMP NOT NULL DEFAULT now()
+);
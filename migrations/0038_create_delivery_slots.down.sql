@@ -0,0 +1,6 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivery_slot_id;
+
+DROP TABLE IF EXISTS delivery_slots;
+
+DROP TABLE IF EXISTS chef_working_hours;
@@ -0,0 +1,26 @@
+-- Рабочие часы повара по дням недели (0 — воскресенье), из них нарезаются слоты доставки
+CREATE TABLE IF NOT EXISTS chef_working_hours
+(
+    chef_id       BIGINT   NOT NULL,
+    weekday       SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time    TIME     NOT NULL,
+    end_time      TIME     NOT NULL CHECK (end_time > start_time),
+    slot_minutes  INTEGER  NOT NULL DEFAULT 60 CHECK (slot_minutes > 0),
+    slot_capacity INTEGER  NOT NULL DEFAULT 3 CHECK (slot_capacity > 0),
+    PRIMARY KEY (chef_id, weekday, start_time)
+);
+
+CREATE TABLE IF NOT EXISTS delivery_slots
+(
+    id        BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    chef_id   BIGINT      NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at   TIMESTAMPTZ NOT NULL,
+    capacity  INTEGER     NOT NULL,
+    reserved  INTEGER     NOT NULL DEFAULT 0,
+    CONSTRAINT delivery_slots_capacity_check CHECK (reserved BETWEEN 0 AND capacity),
+    UNIQUE (chef_id, starts_at)
+);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS delivery_slot_id BIGINT REFERENCES delivery_slots (id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS payments
+(
+    id                  BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    order_id            BIGINT       NOT NULL UNIQUE REFERENCES orders (id),
+    user_id             BIGINT       NOT NULL,
+    provider            VARCHAR(32)  NOT NULL,
+    provider_payment_id VARCHAR(128) NOT NULL,
+    amount              NUMERIC      NOT NULL,
+    currency            VARCHAR(3)   NOT NULL,
+    status              VARCHAR(16)  NOT NULL,
+    created_at          TIMESTAMP    NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMP    NOT NULL DEFAULT now(),
+    UNIQUE (provider, provider_payment_id)
+);
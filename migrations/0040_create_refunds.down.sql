@@ -0,0 +1,4 @@
+ALTER TABLE ordered_items
+    DROP COLUMN IF EXISTS removed_at;
+
+DROP TABLE IF EXISTS refunds;
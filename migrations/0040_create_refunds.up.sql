@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS refunds
+(
+    id              BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    order_id        BIGINT     NOT NULL REFERENCES orders (id),
+    payment_id      BIGINT     NOT NULL REFERENCES payments (id),
+    ordered_item_id BIGINT REFERENCES ordered_items (id), -- NULL — возврат за весь заказ
+    amount          NUMERIC    NOT NULL CHECK (amount > 0),
+    currency        VARCHAR(3) NOT NULL,
+    reason          TEXT       NOT NULL DEFAULT '',
+    created_at      TIMESTAMP  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_order_id ON refunds (order_id);
+-- За одну позицию деньги возвращаются один раз
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_ordered_item_id ON refunds (ordered_item_id) WHERE ordered_item_id IS NOT NULL;
+
+ALTER TABLE ordered_items
+    ADD COLUMN IF NOT EXISTS removed_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- Поиск зависших заказов воркером таймаутов
+CREATE INDEX IF NOT EXISTS idx_orders_status_updated_at ON orders (status, updated_at);
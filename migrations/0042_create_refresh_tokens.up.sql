@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    id                BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    user_id           BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash        TEXT      NOT NULL UNIQUE, -- sha256 от токена, сам токен не храним
+    family_id         UUID      NOT NULL,        -- цепочка ротаций одного входа
+    access_uuid       UUID      NOT NULL,        -- uuid access-токена, выданного вместе с этим
+    access_expires_at TIMESTAMP NOT NULL,
+    expires_at        TIMESTAMP NOT NULL,
+    revoked_at        TIMESTAMP,
+    created_at        TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_access_uuid ON refresh_tokens (access_uuid);
@@ -0,0 +1,29 @@
+-- Сессия — один вход с устройства; refresh-токены сессии образуют семейство с family_id = sessions.id
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id           UUID PRIMARY KEY,
+    user_id      BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    device_name  TEXT      NOT NULL DEFAULT '',
+    platform     TEXT      NOT NULL DEFAULT '',
+    ip           TEXT      NOT NULL DEFAULT '',
+    user_agent   TEXT      NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT now(),
+    revoked_at   TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id) WHERE revoked_at IS NULL;
+
+-- Входы, сделанные до появления сессий
+INSERT INTO sessions (id, user_id, created_at, last_seen_at, revoked_at)
+SELECT family_id,
+       MIN(user_id),
+       MIN(created_at),
+       MAX(created_at),
+       CASE WHEN BOOL_AND(revoked_at IS NOT NULL) THEN MAX(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_session FOREIGN KEY (family_id) REFERENCES sessions (id) ON DELETE CASCADE;
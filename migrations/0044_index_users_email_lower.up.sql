@@ -0,0 +1,2 @@
+-- Вход по почте ищет пользователя без учёта регистра
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
@@ -0,0 +1,2 @@
+ALTER TABLE order_status_history
+    DROP CONSTRAINT IF EXISTS fk_order_status_history_order;
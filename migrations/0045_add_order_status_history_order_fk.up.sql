@@ -0,0 +1,6 @@
+-- История статусов не должна ссылаться на несуществующие заказы
+DELETE FROM order_status_history h
+WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.id = h.order_id);
+
+ALTER TABLE order_status_history
+    ADD CONSTRAINT fk_order_status_history_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_payments_capture_requested;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS capture_requested_at;
+
+DROP INDEX IF EXISTS idx_refunds_pending;
+
+ALTER TABLE refunds
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,13 @@
+-- Возврат сначала сохраняется в статусе pending вместе с изменением заказа,
+-- а запрос к провайдеру делается после фиксации транзакции
+ALTER TABLE refunds
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'completed';
+
+CREATE INDEX IF NOT EXISTS idx_refunds_pending ON refunds (created_at) WHERE status = 'pending';
+
+-- Списание, которое нужно сделать после доставки заказа
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS capture_requested_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_payments_capture_requested ON payments (capture_requested_at)
+    WHERE capture_requested_at IS NOT NULL AND status = 'authorized';
@@ -22,6 +22,14 @@ body {
 	font-family: Arial, sans-serif;
 	margin: 20px;
 }
+.button {
+	display: inline-block;
+	padding: 10px 20px;
+	background: #2e7d32;
+	color: #fff;
+	text-decoration: none;
+	border-radius: 4px;
+}
 .footer {
 	margin-top: 20px;
 	color: #888;
@@ -32,7 +40,8 @@ body {
 <body>
 <h2>Привет, {{.UserName}}!</h2>
 <p>{{.Body}}</p>
-<div class="footer">
+{{if .Link}}<p><a class="button" href="{{.Link}}">{{.LinkText}}</a></p>
+{{end}}<div class="footer">
 С уважением,<br>
 {{.Footer}}
 </div>
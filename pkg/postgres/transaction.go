@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Querier — общий набор методов пула и транзакции, которым пользуются репозитории
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+type txKey struct{}
+
+// WithinTransaction выполняет fn в одной транзакции (unit of work).
+// Репозитории, которые берут соединение через Conn или Begin, внутри fn
+// автоматически работают в этой транзакции. Если fn вернула ошибку,
+// транзакция откатывается. Вложенный вызов переиспользует внешнюю транзакцию.
+func (p *Postgres) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := p.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres - WithinTransaction - Begin: %w", err)
+	}
+	defer func() {
+		// После Commit откат вернёт ErrTxClosed — это нормально
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres - WithinTransaction - Commit: %w", err)
+	}
+	return nil
+}
+
+// Conn возвращает открытую в контексте транзакцию, либо пул соединений
+func (p *Postgres) Conn(ctx context.Context) Querier {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return p.Pool
+}
+
+// Begin открывает транзакцию. Если в контексте уже есть транзакция,
+// открывается вложенная (savepoint), и её Commit не фиксирует внешнюю.
+func (p *Postgres) Begin(ctx context.Context) (pgx.Tx, error) {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx.Begin(ctx)
+	}
+	return p.Pool.Begin(ctx)
+}
+
+func txFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
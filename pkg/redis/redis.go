@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 
 	"domashka-backend/config"
@@ -47,6 +48,15 @@ func (r *Redis) Get(key string) (string, error) {
 	return result, err
 }
 
+// GetDel возвращает значение и удаляет ключ одной командой; пустая строка — ключа не было
+func (r *Redis) GetDel(key string) (string, error) {
+	result, err := r.client.GetDel(r.ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return result, err
+}
+
 func (r *Redis) Delete(key string) error {
 	return r.client.Del(r.ctx, key).Err()
 }
@@ -70,3 +80,79 @@ func (r *Redis) Ping() error {
 	_, err := r.client.Ping(r.ctx).Result()
 	return err
 }
+
+// SetNX записывает значение, только если ключа ещё нет. Возвращает true, если запись произошла
+func (r *Redis) SetNX(key string, value string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(r.ctx, key, value, ttl).Result()
+}
+
+// refreshScript продлевает ключ, только если в нём лежит ожидаемое значение
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// deleteIfEqualScript удаляет ключ, только если в нём лежит ожидаемое значение
+var deleteIfEqualScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// incrScript увеличивает счётчик и ставит ttl при его создании
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// Incr увеличивает счётчик на 1 и возвращает новое значение. ttl отсчитывается от первого увеличения
+func (r *Redis) Incr(key string, ttl time.Duration) (int64, error) {
+	return incrScript.Run(r.ctx, r.client, []string{key}, ttl.Milliseconds()).Int64()
+}
+
+// Refresh продлевает ttl ключа, если в нём всё ещё value. Возвращает false, если ключ истёк или занят другим значением
+func (r *Redis) Refresh(key string, value string, ttl time.Duration) (bool, error) {
+	res, err := refreshScript.Run(r.ctx, r.client, []string{key}, value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// DeleteIfEqual удаляет ключ, только если в нём value
+func (r *Redis) DeleteIfEqual(key string, value string) error {
+	return deleteIfEqualScript.Run(r.ctx, r.client, []string{key}, value).Err()
+}
+
+// slidingWindowScript считает запросы в скользящем окне по времени Redis, чтобы часы инстансов не расходились.
+// Возвращает {1, 0}, если запрос пропущен, или {0, мс до освобождения места в окне}
+var slidingWindowScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+if redis.call("ZCARD", KEYS[1]) < limit then
+	redis.call("ZADD", KEYS[1], now, now .. ":" .. ARGV[3])
+	redis.call("PEXPIRE", KEYS[1], window)
+	return {1, 0}
+end
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {0, tonumber(oldest[2]) + window - now}
+`)
+
+// AllowSlidingWindow учитывает запрос по ключу, если за последние window их было меньше limit.
+// Иначе возвращает false и время, через которое можно повторить запрос
+func (r *Redis) AllowSlidingWindow(key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	res, err := slidingWindowScript.Run(r.ctx, r.client, []string{key}, window.Milliseconds(), limit, uuid.NewString()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,43 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FakeProvider ничего не отправляет: пишет сообщение в лог и дописывает в файл, если он задан.
+// Для локального запуска и стендов без шлюза.
+type FakeProvider struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFakeProvider(path string) *FakeProvider {
+	return &FakeProvider{path: path}
+}
+
+func (p *FakeProvider) Send(_ context.Context, phone, text string) (string, error) {
+	messageID := "fake-" + uuid.NewString()
+	log.Printf("sms: fake send to %s: %s", phone, text)
+	if p.path == "" {
+		return messageID, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, "%s\t%s\t%s\t%s\n", time.Now().Format(time.RFC3339), messageID, phone, text); err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
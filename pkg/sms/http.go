@@ -0,0 +1,93 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPProvider — шлюз с JSON API: POST {to, from, text} с ключом в заголовке Authorization,
+// в ответ {id} или {error}. 4xx означает, что сообщение отклонено; 5xx и ошибки до отправки запроса —
+// временные. Если запрос ушёл, а ответа нет, исход неизвестен (ErrUncertain).
+type HTTPProvider struct {
+	url    string
+	apiKey string
+	sender string
+	client *http.Client
+}
+
+func NewHTTPProvider(url, apiKey, sender string, timeout time.Duration) *HTTPProvider {
+	return &HTTPProvider{
+		url:    url,
+		apiKey: apiKey,
+		sender: sender,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type httpSendRequest struct {
+	To   string `json:"to"`
+	From string `json:"from,omitempty"`
+	Text string `json:"text"`
+}
+
+type httpSendResponse struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+func (p *HTTPProvider) Send(ctx context.Context, phone, text string) (string, error) {
+	body, err := json.Marshal(httpSendRequest{To: phone, From: p.sender, Text: text})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	// wrote — запрос целиком записан в соединение, после этого шлюз мог его принять
+	var wrote atomic.Bool
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) { wrote.Store(info.Err == nil) },
+	}))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if wrote.Load() {
+			return "", fmt.Errorf("%w: %v", ErrUncertain, err)
+		}
+		return "", fmt.Errorf("sms: send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result httpSendResponse
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("%w: read response: %v", ErrUncertain, err)
+	}
+	// Тело ошибки бывает не JSON — тогда в сообщение попадёт статус
+	_ = json.Unmarshal(raw, &result)
+
+	switch {
+	case resp.StatusCode == http.StatusGatewayTimeout:
+		// Прокси перед шлюзом не дождался ответа — сообщение могло уйти
+		return "", fmt.Errorf("%w: %d", ErrUncertain, resp.StatusCode)
+	case resp.StatusCode >= 500:
+		return "", fmt.Errorf("sms: provider unavailable: %d %s", resp.StatusCode, result.Error)
+	case resp.StatusCode >= 400:
+		return "", fmt.Errorf("%w: %d %s", ErrRejected, resp.StatusCode, result.Error)
+	case result.ID == "":
+		return "", fmt.Errorf("%w: provider response without id: %d", ErrUncertain, resp.StatusCode)
+	}
+	return result.ID, nil
+}
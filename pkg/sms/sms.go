@@ -1,14 +1,81 @@
 package sms
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"domashka-backend/config"
+)
+
+// ErrRejected — шлюз отклонил сообщение (неверный номер, нет средств и т.п.); повтор не поможет
+var ErrRejected = errors.New("sms rejected by provider")
+
+// ErrUncertain — запрос ушёл к шлюзу, но ответа нет (таймаут, обрыв, 504). Сообщение могло
+// быть доставлено, а ключа идемпотентности шлюз не принимает, поэтому повтор может прислать код дважды.
+var ErrUncertain = errors.New("sms delivery status unknown")
+
+// Provider отправляет одно сообщение и возвращает его id у шлюза
+type Provider interface {
+	Send(ctx context.Context, phone, text string) (messageID string, err error)
+}
+
+// Client отправляет SMS через провайдера с повторами и экспоненциальной паузой между ними
 type Client struct {
-	// some fields
+	provider   Provider
+	maxRetries int
+	retryDelay time.Duration
 }
 
-func New() *Client {
-	return &Client{}
+func New(cfg *config.SMSConfig) (*Client, error) {
+	var provider Provider
+	switch cfg.Provider {
+	case "http":
+		if cfg.APIURL == "" {
+			return nil, errors.New("sms: SMS_API_URL is required for http provider")
+		}
+		provider = NewHTTPProvider(cfg.APIURL, cfg.APIKey, cfg.Sender, cfg.Timeout)
+	case "fake":
+		provider = NewFakeProvider(cfg.FakeFile)
+	default:
+		return nil, fmt.Errorf("sms: unknown provider %q", cfg.Provider)
+	}
+
+	return NewWithProvider(provider, cfg.MaxRetries, cfg.RetryDelay), nil
 }
 
-func (s *Client) Send(phone, message string) error {
-	// some code
-	return nil
+func NewWithProvider(provider Provider, maxRetries int, retryDelay time.Duration) *Client {
+	if maxRetries < 1 {
+		maxRetries = 1
+	}
+	return &Client{
+		provider:   provider,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+	}
+}
+
+// Send отправляет сообщение и возвращает id у шлюза и число сделанных попыток
+func (c *Client) Send(ctx context.Context, phone, text string) (string, int, error) {
+	var err error
+	delay := c.retryDelay
+	for attempt := 1; attempt <= c.maxRetries; attempt++ {
+		var messageID string
+		messageID, err = c.provider.Send(ctx, phone, text)
+		if err == nil {
+			return messageID, attempt, nil
+		}
+		if errors.Is(err, ErrRejected) || errors.Is(err, ErrUncertain) || attempt == c.maxRetries {
+			return "", attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", attempt, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return "", c.maxRetries, err
 }
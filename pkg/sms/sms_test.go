@@ -0,0 +1,129 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// gateway отвечает по очереди статусами из statuses и запоминает время каждого запроса
+type gateway struct {
+	mu       sync.Mutex
+	statuses []int
+	delay    time.Duration
+	calls    []time.Time
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	g.calls = append(g.calls, time.Now())
+	status := g.statuses[min(len(g.calls), len(g.statuses))-1]
+	g.mu.Unlock()
+
+	if g.delay > 0 {
+		time.Sleep(g.delay)
+	}
+	w.WriteHeader(status)
+	switch {
+	case status == http.StatusOK:
+		_ = json.NewEncoder(w).Encode(httpSendResponse{ID: "msg-1"})
+	case status == http.StatusAccepted:
+		// 2xx без id
+		_ = json.NewEncoder(w).Encode(httpSendResponse{})
+	default:
+		_ = json.NewEncoder(w).Encode(httpSendResponse{Error: http.StatusText(status)})
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		delay        time.Duration
+		wantID       string
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "first attempt", statuses: []int{200}, wantID: "msg-1", wantAttempts: 1},
+		{name: "retry after 5xx", statuses: []int{500, 503, 200}, wantID: "msg-1", wantAttempts: 3},
+		{name: "5xx on every attempt", statuses: []int{500}, wantAttempts: 3, wantErr: errors.New("sms: provider unavailable: 500 Internal Server Error")},
+		{name: "4xx is not retried", statuses: []int{400, 200}, wantAttempts: 1, wantErr: ErrRejected},
+		{name: "504 is not retried", statuses: []int{504, 200}, wantAttempts: 1, wantErr: ErrUncertain},
+		{name: "response without id is not retried", statuses: []int{202, 200}, wantAttempts: 1, wantErr: ErrUncertain},
+		{name: "timeout after request is not retried", statuses: []int{200}, delay: 200 * time.Millisecond, wantAttempts: 1, wantErr: ErrUncertain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gateway{statuses: tt.statuses, delay: tt.delay}
+			srv := httptest.NewServer(g)
+			defer srv.Close()
+
+			c := NewWithProvider(NewHTTPProvider(srv.URL, "key", "Domashka", 50*time.Millisecond), 3, time.Millisecond)
+			id, attempts, err := c.Send(context.Background(), "79991234567", "code 1234")
+
+			require.Equal(t, tt.wantAttempts, attempts)
+			g.mu.Lock()
+			require.Len(t, g.calls, tt.wantAttempts)
+			g.mu.Unlock()
+			switch {
+			case tt.wantErr == nil:
+				require.NoError(t, err)
+				require.Equal(t, tt.wantID, id)
+			case errors.Is(tt.wantErr, ErrRejected), errors.Is(tt.wantErr, ErrUncertain):
+				require.ErrorIs(t, err, tt.wantErr)
+			default:
+				require.EqualError(t, err, tt.wantErr.Error())
+			}
+		})
+	}
+}
+
+func TestClient_Send_Backoff(t *testing.T) {
+	g := &gateway{statuses: []int{500, 500, 200}}
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+
+	const delay = 20 * time.Millisecond
+	c := NewWithProvider(NewHTTPProvider(srv.URL, "key", "", time.Second), 3, delay)
+	_, attempts, err := c.Send(context.Background(), "79991234567", "code 1234")
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	// Пауза удваивается: delay перед второй попыткой, 2*delay перед третьей
+	require.GreaterOrEqual(t, g.calls[1].Sub(g.calls[0]), delay)
+	require.GreaterOrEqual(t, g.calls[2].Sub(g.calls[1]), 2*delay)
+}
+
+func TestClient_Send_NotConnected(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	// До шлюза запрос не дошёл — повторять безопасно
+	c := NewWithProvider(NewHTTPProvider(url, "key", "", time.Second), 2, time.Millisecond)
+	_, attempts, err := c.Send(context.Background(), "79991234567", "code 1234")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrUncertain)
+	require.Equal(t, 2, attempts)
+}
+
+func TestClient_Send_ContextCancelled(t *testing.T) {
+	g := &gateway{statuses: []int{500}}
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// Запрос с отменённым контекстом не уходит, ожидание паузы тоже прерывается
+	c := NewWithProvider(NewHTTPProvider(srv.URL, "key", "", time.Second), 3, time.Hour)
+	_, attempts, err := c.Send(ctx, "79991234567", "code 1234")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, attempts)
+}
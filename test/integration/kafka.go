@@ -64,7 +64,7 @@ func TestIntegration_KafkaInteraction(t *testing.T) {
 			Value: []byte("test"),
 		}); err != nil {
 			log.Println(err)
-			t.Fatalf(err.Error())
+			t.Errorf("write message: %v", err)
 		}
 	}()
 